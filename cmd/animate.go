@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// animateDescription - animate command description.
+const animateDescription = "Play a software effect on the keyboard backlight."
+
+// newAnimateCmd creates, initializes and returns command to play
+// software effects on the keyboard backlight.
+func newAnimateCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var effect func() string
+	var colors func() []*ite8291.Color
+	var layout func() *ite8291.Layout
+
+	var animateCmd = &cobra.Command{
+		Use:   "animate",
+		Short: animateDescription,
+		Long: fmt.Sprintf(`Play a software effect on the keyboard backlight.
+
+The software effect "(--%s)" is rendered frame by frame with the given frame rate "(--%s)"
+and the given speed "(-%s, --%s)". The effects are:
+  spectrum    all keys cycle through the colors
+  wave        the colors flow over the keys of the layout "(--%s)" from the left to the right
+  breathing   every key breathes in its own color and rhythm
+  starfield   stars twinkle at random keys
+  fire        flames rise from the bottom row; the colors give the heat from cold to hot
+  matrix      drops of the first color fall down the columns
+
+The effects use their own colors unless the colors "(--%s)" are given either by names
+of the colors configured via %q configuration property or by RGB values in a one of
+the following formats %q.
+
+The effect plays until interrupted. Afterwards the previous effect of the keyboard
+backlight is restored. Colors of the keys of the previous 'user' effect are not restored;
+the controller does not report them.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[9]s:
+       effect: fire
+       colors: ["#000", red, yellow]
+       fps: 30`,
+			params.AnimationEffectFlag, params.AnimationFPSFlag, params.SpeedShortFlag, params.SpeedProp,
+			params.LayoutProp, params.AnimationColorsFlag, params.NamedColorsProp,
+			ite8291.SupportedColorStringFormats, params.AnimationProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			anim, err := ite8291.NewAnimation(effect(), ite8291.AnimationOptions{
+				Colors: colors(), Period: params.AnimationPeriod(v), Layout: layout(),
+			})
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+				return ctl.WithUserMode(params.Brightness(v), func() error {
+					return ite8291.Animate(cmd.Context(), ctl, anim, params.FPS(v))
+				})
+			})
+		},
+	}
+
+	effect, colors = params.AddAnimation(animateCmd, v)
+	params.AddFPS(animateCmd, v)
+	layout = params.AddLayout(animateCmd, v)
+	params.AddSpeed(animateCmd, v)
+	params.AddBrightness(animateCmd, v)
+	params.AddReset(animateCmd, v)
+
+	return animateCmd
+}
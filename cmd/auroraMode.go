@@ -14,6 +14,8 @@ const auroraModeDescription = "Set keyboard backlight to 'aurora' mode."
 // to set keyboard backlight to 'aurora' mode.
 func newAuroraModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	auroraModeCmd := &cobra.Command{
 		Use:           "aurora-mode",
 		Short:         auroraModeDescription,
@@ -23,8 +25,10 @@ func newAuroraModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetAuroraMode(params.Speed(v), params.Brightness(v),
-					params.ColorNum(v), params.Reactive(v), params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetAuroraMode(params.Speed(v), brightness, params.ColorNum(v), params.Reactive(v), save)
+					})
 			})
 		},
 	}
@@ -35,6 +39,8 @@ func newAuroraModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	params.AddReactive(auroraModeCmd, v)
 	params.AddSave(auroraModeCmd, v)
 	params.AddReset(auroraModeCmd, v)
+	params.AddFor(auroraModeCmd)
+	transition = params.AddFade(auroraModeCmd, v)
 
 	return auroraModeCmd
 }
@@ -14,6 +14,8 @@ const breathModeDescription = "Set keyboard backlight to 'breathing' mode."
 // to set keyboard backlight to 'breathing' mode.
 func newBreathModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	breathModeCmd := &cobra.Command{
 		Use:           "breath-mode",
 		Short:         breathModeDescription,
@@ -23,8 +25,10 @@ func newBreathModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetBreathingMode(params.Speed(v), params.Brightness(v),
-					params.ColorNum(v), params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetBreathingMode(params.Speed(v), brightness, params.ColorNum(v), save)
+					})
 			})
 		},
 	}
@@ -34,6 +38,8 @@ func newBreathModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	params.AddColorNum(breathModeCmd, v)
 	params.AddSave(breathModeCmd, v)
 	params.AddReset(breathModeCmd, v)
+	params.AddFor(breathModeCmd)
+	transition = params.AddFade(breathModeCmd, v)
 
 	return breathModeCmd
 }
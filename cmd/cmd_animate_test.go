@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("animate", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.dev.ctlChangedData = [][]byte{nil, {8, 2, 3, 5, 30, 0, 1, 1}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		DeferCleanup(cancel)
+		run.ctx = ctx
+	})
+
+	It("plays effect until interrupted and restores previous effect", func() {
+
+		Ω(run.execute("animate", "--effect", "Spectrum", "--colors", "colour.cyAN", "-b", "7",
+			"--fps", "60")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[:3]).Should(Equal(append(getEffectCtlArgs(), userModeCtlArgs(7, 0))))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 3, 5, 30, 0, 1, 0}))
+
+		// a single color never changes, so it's written once
+		assertGetBulkWriteCall(run.dev, 1)
+		Ω(run.dev.bulkBuffer.Contents()).Should(Equal(frameBytes(ite8291.NewFrame(ite8291.NewColor(0x11, 0x22, 0x33)))))
+		Ω(run.dev.closeCallNum).Should(Equal(1))
+	})
+
+	It("plays configured effect", func() {
+
+		run.configure(map[string]any{params.AnimationProp: map[string]any{"effect": "matrix", "fps": 50}})
+
+		Ω(run.execute("animate")).Should(Succeed())
+		Ω(run.dev.getBulkWriteCallNum).Should(BeNumerically(">", 1))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 3, 5, 30, 0, 1, 0}))
+	})
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(append([]string{"animate"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("missing effect"),
+		Entry("unknown effect", "--effect", "disco"),
+		Entry("invalid color", "--effect", "fire", "--colors", "red,nocolor"),
+		Entry("too high frame rate", "--effect", "fire", "--fps", "61"),
+		Entry("zero frame rate", "--effect", "fire", "--fps", "0"),
+	)
+})
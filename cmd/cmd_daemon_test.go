@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/daemon"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("daemon", func() {
+
+	var daemonRun, run *cmdRunT
+	var socket, cfgFile string
+	var cancel context.CancelFunc
+	var done chan error
+
+	// breath effect state as reported by the controller
+	breathState := []byte{8, 2, 2, 5, 30, 0, 1, 0}
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		socket = filepath.Join(dir, "itectl.sock")
+		cfgFile = filepath.Join(dir, "itectl.yml")
+		Ω(os.WriteFile(cfgFile, []byte("namedColors: {}\n"), 0o600)).Should(Succeed())
+
+		// commands are created before the daemon runs since they share named colors configuration
+		run = newCmdRun()
+		run.connect = dialDaemon
+		run.configure(map[string]any{params.DaemonProp: map[string]any{"socket": socket}})
+
+		daemonRun = newCmdRun()
+		daemonRun.dev.ctlChangedData = [][]byte{nil, breathState, nil, breathState}
+		daemonRun.ctx, cancel = context.WithCancel(context.Background())
+
+		done = make(chan error, 1)
+		go func() {
+			done <- daemonRun.execute("daemon", "--"+params.DaemonSocketFlag, socket,
+				"--"+params.ConfigFileFlag, cfgFile)
+		}()
+		Eventually(func() error { _, err := os.Stat(socket); return err }).Should(Succeed())
+	})
+
+	// stop stops the daemon and waits for it to close the device.
+	stop := func() {
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Ω(daemonRun.dev.closeCallNum).Should(Equal(1))
+	}
+
+	It("serves the device to other commands", func() {
+
+		Ω(run.execute("state")).Should(Succeed())
+		Ω(run.out).Should(gbytes.Say(isOnMessage))
+		assertDeviceNotCalled(run.dev)
+
+		stop()
+		Ω(daemonRun.dev.ctlArgs).Should(Equal(getEffectCtlArgs()))
+		Ω(daemonRun.findDevCall.callNum).Should(Equal(1))
+	})
+
+	It("applies commands", func() {
+
+		c, err := daemon.Dial(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer c.Close()
+
+		Ω(c.Apply([]string{"set-brightness", "-b", "10"})).Should(BeEmpty())
+
+		stop()
+		Ω(daemonRun.dev.ctlArgs).Should(HaveLen(1))
+		Ω(daemonRun.dev.ctlArgs[0].data).Should(Equal([]byte{9, 2, 10}))
+	})
+
+	DescribeTable("refuses to apply commands and flags not allowed to clients",
+		func(args ...string) {
+
+			c, err := daemon.Dial(socket)
+			Ω(err).ShouldNot(HaveOccurred())
+			defer c.Close()
+
+			Ω(c.Apply(args)).Error().Should(MatchError(ContainSubstring(errNotAllowed.Error())))
+
+			stop()
+			Ω(daemonRun.dev.ctlArgs).Should(BeEmpty())
+		},
+		Entry("no command"),
+		Entry("state", "state"),
+		Entry("run", "run", "--", "sh", "-c", "true"),
+		Entry("daemon", "daemon"),
+		Entry("image-mode", "image-mode", "/etc/shadow"),
+		Entry("configuration file", "wave-mode", "--config", "/root/itectl.yml"),
+		Entry("inline configuration file", "wave-mode", "--config=/root/itectl.yml"),
+		Entry("layout file", "gradient-mode", "--layout", "/root/layout.yaml"),
+		Entry("preview file", "off-mode", "--preview-file", "/root/off.png"),
+		Entry("device", "set-brightness", "-b", "5", "--device-bus", "1"),
+	)
+
+	It("reloads changed configuration", func() {
+
+		c, err := daemon.Dial(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer c.Close()
+
+		Ω(c.SetColors(map[byte]string{1: "glow"})).ShouldNot(Succeed())
+
+		Ω(os.WriteFile(cfgFile, []byte("namedColors:\n  glow: '#010203'\n"), 0o600)).Should(Succeed())
+		Eventually(func() error { return c.SetColors(map[byte]string{1: "glow"}) }).Should(Succeed())
+
+		stop()
+		Ω(daemonRun.dev.ctlArgs[len(daemonRun.dev.ctlArgs)-1].data).Should(Equal([]byte{0x14, 0, 1, 1, 2, 3}))
+	})
+
+	It("is bypassed on request", func() {
+
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+		Ω(run.execute("state", "--"+params.DirectFlag)).Should(Succeed())
+		Ω(run.dev.ctlArgs).Should(Equal(getEffectCtlArgs()))
+
+		stop()
+		Ω(daemonRun.dev.ctlArgs).Should(BeEmpty())
+	})
+
+	It("restricts the socket to its owner", func() {
+
+		info, err := os.Stat(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(info.Mode().Perm()).Should(Equal(params.DaemonSocketPerm))
+
+		stop()
+	})
+
+	It("refuses to start twice", func() {
+
+		Ω(run.execute("daemon", "--"+params.DaemonSocketFlag, socket)).Should(MatchError(daemon.ErrRunning))
+		assertDeviceNotCalled(run.dev)
+
+		stop()
+	})
+
+	It("fails on unknown group", func() {
+
+		stop()
+		Ω(run.execute("daemon", "--"+params.DaemonGroupFlag, "no-such-group")).Should(MatchError(params.ErrInvalidOptVal))
+		assertDeviceNotCalled(run.dev)
+	})
+})
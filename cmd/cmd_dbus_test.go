@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/dbusapi"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// startSessionBus starts a private session bus and makes it the bus
+// of the current session till the end of the spec.
+func startSessionBus() {
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		Skip("dbus-daemon is not available")
+	}
+
+	dir := GinkgoT().TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	Ω(os.WriteFile(config, []byte(`<busconfig>
+  <type>session</type>
+  <listen>unix:dir=`+dir+`</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`), 0o600)).Should(Succeed())
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+	out, err := cmd.StdoutPipe()
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(cmd.Start()).Should(Succeed())
+	DeferCleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(out).ReadString('\n')
+	Ω(err).ShouldNot(HaveOccurred())
+	GinkgoT().Setenv("DBUS_SESSION_BUS_ADDRESS", strings.TrimSpace(address))
+}
+
+var _ = Describe("dbus", func() {
+
+	var run *cmdRunT
+
+	// breath effect state as reported by the controller
+	breathState := []byte{8, 2, 2, 5, 30, 0, 1, 0}
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	It("provides the keyboard backlight on the session bus", func() {
+		startSessionBus()
+
+		conn, err := dbus.ConnectSessionBus()
+		Ω(err).ShouldNot(HaveOccurred())
+		defer conn.Close()
+
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+		var cancel context.CancelFunc
+		run.ctx, cancel = context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- run.execute("dbus", "--"+params.DbusRefreshFlag, "0") }()
+
+		Eventually(func() (bool, error) {
+			var owned bool
+			err := conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, dbusapi.BusName).Store(&owned)
+			return owned, err
+		}).Should(BeTrue())
+
+		obj := conn.Object(dbusapi.BusName, dbusapi.ObjectPath)
+		mode, err := obj.GetProperty(dbusapi.Interface + "." + dbusapi.ModeProp)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mode.Value()).Should(Equal("breath"))
+
+		Ω(obj.Call(dbusapi.Interface+".SetMode", 0, "off", []string{}).Err).Should(Succeed())
+		Ω(obj.Call(dbusapi.Interface+".SetMode", 0, "state", []string{}).Err).
+			Should(MatchError(ContainSubstring("unknown mode")))
+		Ω(obj.Call(dbusapi.Interface+".SetMode", 0, "image", []string{"/root/image.png"}).Err).
+			Should(MatchError(ContainSubstring("unknown mode")))
+		Ω(obj.Call(dbusapi.Interface+".SetMode", 0, "wave", []string{"--" + params.ConfigFileFlag, "/root/itectl.yml"}).Err).
+			Should(MatchError(ContainSubstring(errNotAllowed.Error())))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Ω(run.dev.closeCallNum).Should(Equal(1))
+		Ω(run.dev.ctlArgs).Should(ContainElement(Equal(&ctlArgsT{
+			requestType: 0x21, request: 9, value: 0x300, index: 1,
+			data: []byte{0x8, 0x1, 0x0, 0, 0, 0, 0, 0}, length: 8, timeout: 0,
+		})))
+	})
+
+	It("fails on negative refresh interval", func() {
+
+		Ω(run.execute("dbus", "--"+params.DbusRefreshFlag, "-1s")).Should(MatchError(params.ErrInvalidOptVal))
+		assertDeviceNotCalled(run.dev)
+	})
+})
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("fade", func() {
+
+	var run *cmdRunT
+
+	// breath effect state with brightness 30 as reported by the controller
+	breathState := []byte{8, 2, 2, 5, 30, 0, 1, 0}
+	// 'user' effect state with brightness 20 as reported by the controller
+	userState := []byte{8, 2, 0x33, 0, 20, 0, 0, 0}
+	// off state as reported by the controller
+	offState := []byte{8, 1, 0, 0, 0, 0, 0, 0}
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.configure(map[string]any{params.AnimationProp: map[string]any{"fps": ite8291.FPSMaxValue}})
+	})
+
+	// brightnessLevels returns brightness levels set by the control calls.
+	brightnessLevels := func() []byte {
+		var levels []byte
+		for _, args := range run.dev.ctlArgs {
+			if args.data[0] == ite8291.SetBrightnessCommand {
+				levels = append(levels, args.data[2])
+			}
+		}
+		return levels
+	}
+
+	// ctlData returns data of the control calls.
+	ctlData := func() [][]byte {
+		var data [][]byte
+		for _, args := range run.dev.ctlArgs {
+			data = append(data, args.data)
+		}
+		return data
+	}
+
+	// lastCtlData returns data of the last control call.
+	lastCtlData := func() []byte {
+		return run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data
+	}
+
+	// writtenFrames returns number of the frames written to the device.
+	writtenFrames := func() int {
+		return len(run.dev.bulkBuffer.Contents()) / len(frameBytes(&ite8291.Frame{}))
+	}
+
+	// bulkFrame returns bulk data of the frame written to the device
+	// with the given index.
+	bulkFrame := func(i int) []byte {
+		size := len(frameBytes(&ite8291.Frame{}))
+		return run.dev.bulkBuffer.Contents()[i*size : (i+1)*size]
+	}
+
+	It("ramps brightness", func() {
+
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+
+		Ω(run.execute("set-brightness", "-b", "10", "--fade", "100ms", "--easing", "linear")).Should(Succeed())
+
+		levels := brightnessLevels()
+		Ω(len(levels)).Should(BeNumerically(">", 2))
+		Ω(levels[0]).Should(BeNumerically("<=", 30))
+		Ω(levels[len(levels)-1]).Should(Equal(byte(10)))
+		for i := 1; i < len(levels); i++ {
+			Ω(levels[i]).Should(BeNumerically("<", levels[i-1]))
+		}
+	})
+
+	It("fades to off", func() {
+
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+
+		Ω(run.execute("off-mode", "--fade", "100ms")).Should(Succeed())
+
+		levels := brightnessLevels()
+		Ω(levels[len(levels)-1]).Should(Equal(byte(0)))
+		Ω(lastCtlData()).Should(Equal([]byte{8, 1, 0, 0, 0, 0, 0, 0}))
+	})
+
+	It("crossfades the frame shown to the new one", func() {
+
+		red, blue := ite8291.NewFrame(ite8291.NewColor(0xff, 0, 0)), ite8291.NewFrame(ite8291.NewColor(0, 0, 0xff))
+		Ω(storeLastFrame(red)).Should(Succeed())
+		run.dev.ctlChangedData = [][]byte{nil, userState}
+
+		Ω(run.execute("single-color-mode", "--rgb", "#0000FF", "-b", "40", "--fade", "100ms")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[2]).Should(Equal(userModeCtlArgs(20, 0)))
+		Ω(writtenFrames()).Should(BeNumerically(">", 2))
+		Ω(bulkFrame(0)).ShouldNot(Equal(frameBytes(blue)))
+		Ω(bulkFrame(writtenFrames() - 1)).Should(Equal(frameBytes(blue)))
+		Ω(brightnessLevels()[len(brightnessLevels())-1]).Should(Equal(byte(40)))
+
+		Ω(loadLastFrame()).Should(Equal(blue))
+		name, err := lastFrameFile()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(os.ReadDir(filepath.Dir(name))).Should(HaveLen(1)) // no temporary files left
+	})
+
+	It("fades the frame in from black if the keyboard backlight is off", func() {
+
+		run.dev.ctlChangedData = [][]byte{nil, offState}
+
+		Ω(run.execute("single-color-mode", "--rgb", "#00FF00", "-b", "40", "--save", "--fade", "100ms")).
+			Should(Succeed())
+
+		Ω(run.dev.ctlArgs[2]).Should(Equal(userModeCtlArgs(40, 1)))
+		Ω(bulkFrame(0)).Should(Equal(frameBytes(ite8291.NewFrame(ite8291.NewColor(0, 0, 0)))))
+		Ω(bulkFrame(writtenFrames() - 1)).Should(Equal(frameBytes(ite8291.NewFrame(ite8291.NewColor(0, 0xff, 0)))))
+		Ω(brightnessLevels()).Should(BeEmpty())
+	})
+
+	It("fades the built-in effect out and the new one in", func() {
+
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+
+		Ω(run.execute("rainbow-mode", "-b", "25", "--save", "--fade", "100ms")).Should(Succeed())
+
+		levels := brightnessLevels()
+		Ω(levels).Should(ContainElement(byte(0)))
+		Ω(levels[len(levels)-1]).Should(Equal(byte(25)))
+		Ω(ctlData()).Should(ContainElement([]byte{8, 2, 5, 0, 0, 0, 0, 0}))
+		Ω(lastCtlData()).Should(Equal([]byte{8, 2, 5, 0, 25, 0, 0, 1}))
+	})
+
+	It("switches instantly by default", func() {
+
+		Ω(run.execute("set-brightness", "-b", "10")).Should(Succeed())
+
+		assertOnlyControlCall(run.dev, []*ctlArgsT{{
+			requestType: 0x21, request: 9, value: 0x300, index: 1, data: []byte{9, 2, 10}, length: 3, timeout: 0,
+		}})
+	})
+
+	DescribeTable("fails on invalid options",
+		func(value, flag string) {
+			err := run.execute("breath-mode", "--"+flag, value)
+			Ω(err).Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("negative duration", "-1s", params.FadeFlag),
+		Entry("unknown easing", "bounce", params.FadeEasingFlag),
+	)
+})
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("for", func() {
+
+	var run *cmdRunT
+
+	// breath effect state with brightness 30 as reported by the controller
+	breathState := []byte{8, 2, 2, 5, 30, 0, 1, 0}
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	// lastCtlData returns data of the last control call.
+	lastCtlData := func() []byte {
+		return run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data
+	}
+
+	It("reverts the previous effect after the duration", func() {
+
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+
+		start := time.Now()
+		Ω(run.execute("single-color-mode", "--rgb", "#FFF", "-b", "50", "--for", "50ms")).Should(Succeed())
+		Ω(time.Since(start)).Should(BeNumerically(">=", 50*time.Millisecond))
+
+		Ω(run.dev.ctlArgs[:2]).Should(Equal(getEffectCtlArgs()))
+		Ω(run.dev.ctlArgs[2]).Should(Equal(userModeCtlArgs(50, 0)))
+		Ω(run.dev.bulkBuffer.Contents()).Should(Equal(frameBytes(ite8291.NewFrame(ite8291.NewColor(0xff, 0xff, 0xff)))))
+		Ω(lastCtlData()).Should(Equal(breathState))
+		Ω(run.out).Should(gbytes.Say(`Reverted to breath-mode with brightness 30\.`))
+	})
+
+	It("never restores the cached frame of 'user' effect", func() {
+
+		cached, red := ite8291.NewFrame(ite8291.NewColor(1, 2, 3)), ite8291.NewFrame(ite8291.NewColor(0xff, 0, 0))
+		Ω(storeLastFrame(cached)).Should(Succeed())
+		run.dev.ctlChangedData = [][]byte{nil, {8, 2, 0x33, 0, 20, 0, 0, 0}}
+
+		Ω(run.execute("single-color-mode", "--rgb", "#F00", "--for", "10ms")).Should(Succeed())
+
+		Ω(run.dev.bulkBuffer.Contents()).Should(Equal(frameBytes(red)))
+		Ω(lastCtlData()).Should(Equal([]byte{8, 2, 0x33, 0, 20, 0, 0, 0}))
+		Ω(run.out).Should(gbytes.Say(`Reverted to 'user' effect with brightness 20; colors of its keys are unknown\.`))
+		Ω(loadLastFrame()).Should(Equal(red))
+	})
+
+	It("reverts brightness when interrupted", func() {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		run.ctx = ctx
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+
+		Ω(run.execute("set-brightness", "-b", "5", "--for", "1h")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[2].data).Should(Equal([]byte{9, 2, 5}))
+		Ω(lastCtlData()).Should(Equal(breathState))
+	})
+
+	It("never saves the temporary state", func() {
+
+		run.configure(map[string]any{params.SaveProp: true})
+		run.dev.ctlChangedData = [][]byte{nil, {8, 1, 0, 0, 0, 0, 0, 0}}
+
+		Ω(run.execute("rainbow-mode", "-b", "10", "--for", "10ms")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[2].data).Should(Equal([]byte{8, 2, 5, 0, 10, 0, 0, 0}))
+		Ω(lastCtlData()).Should(Equal([]byte{8, 1, 0, 0, 0, 0, 0, 0}))
+		Ω(run.out).Should(gbytes.Say(`Reverted to off-mode\.`))
+	})
+
+	It("stops long running commands", func() {
+
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+
+		Ω(run.execute("text-mode", "--text", "hi", "--for", "50ms")).Should(Succeed())
+
+		Ω(lastCtlData()).Should(Equal(breathState))
+	})
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(args...)).ShouldNot(Succeed())
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("negative duration", "breath-mode", "--for", "-1s"),
+		Entry("saved state", "breath-mode", "--for", "1s", "--save"),
+		Entry("reset predefined colors", "wave-mode", "--for", "1s", "--reset"),
+	)
+
+	It("keeps the predefined colors if reset is configured", func() {
+
+		run.configure(map[string]any{params.ResetProp: true})
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+
+		Ω(run.execute("wave-mode", "--for", "10ms")).Should(Succeed())
+
+		for _, args := range run.dev.ctlArgs {
+			Ω(args.data[0]).ShouldNot(Equal(byte(ite8291.SetColorCommand)))
+		}
+		Ω(lastCtlData()).Should(Equal(breathState))
+	})
+})
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("gradient-mode", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	Context("with flags", func() {
+
+		It("sets keyboard to gradient", func() {
+			Ω(run.execute("gradient-mode", "--from", "colour.cyAN", "--via", "#FFF,000", "--to", "#F00",
+				"-d", "up-LEFT", "-b", "7")).Should(Succeed())
+
+			g := ite8291.Gradient{ite8291.NewColor(0x11, 0x22, 0x33), ite8291.NewColor(0xff, 0xff, 0xff),
+				ite8291.NewColor(0, 0, 0), ite8291.NewColor(0xff, 0, 0)}
+			assertFrameModeCall(run.dev, 7, 0, ite8291.GradientFrame(g, ite8291.DefaultLayout(),
+				ite8291.LinearGradient(ite8291.DirectionUp, ite8291.DirectionLeft)))
+		})
+	})
+
+	Context("called implicitly with configuration", func() {
+
+		BeforeEach(func() {
+			run.configure(map[string]any{
+				"mode": "gradient",
+				params.GradientProp: map[string]any{
+					"from": "123-yes", "to": "#00F", "direction": "radial",
+				},
+			})
+		})
+
+		It("sets keyboard to configured gradient", func() {
+			Ω(run.execute()).Should(Succeed())
+
+			g := ite8291.Gradient{ite8291.NewColor(0xdd, 0xee, 0xff), ite8291.NewColor(0, 0, 0xff)}
+			assertFrameModeCall(run.dev, params.BrightnessDefault, 0,
+				ite8291.GradientFrame(g, ite8291.DefaultLayout(), ite8291.RadialGradient))
+		})
+	})
+
+	DescribeTable("validation errors",
+		func(a []string, value, flag string) {
+			Ω(run.execute(append([]string{"gradient-mode"}, a...)...)).
+				Should(MatchError(SatisfyAll(ContainSubstring(value), ContainSubstring(flag))))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("none direction", []string{"-d", "none"}, `"none"`, "--direction"),
+		Entry("opposite directions", []string{"-d", "left-right"}, `"left-right"`, "--direction"),
+		Entry("too many directions", []string{"-d", "up-left-up"}, `"up-left-up"`, "--direction"),
+		Entry("invalid from", []string{"--from", "nocolor"}, `"nocolor"`, "--from"),
+		Entry("invalid to", []string{"--to", "#12"}, `"#12"`, "--to"),
+		Entry("invalid via", []string{"--via", "#FFF,x"}, `"x"`, "--via"),
+	)
+})
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/v4n6/itectl/params"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("idle", func() {
+
+	var run *cmdRunT
+
+	// breath effect state as reported by the controller
+	breathState := []byte{8, 2, 2, 5, 30, 0, 1, 0}
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+		run.openEventsCall.src = newEventSource()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		DeferCleanup(cancel)
+		run.ctx = ctx
+	})
+
+	It("dims idle keyboard and restores it when interrupted", func() {
+
+		Ω(run.execute("idle", "--"+params.IdleTimeoutFlag, "50ms", "--"+params.IdleBrightnessFlag, "5",
+			"--input-device", "/dev/input/event3")).Should(Succeed())
+
+		Ω(run.openEventsCall.devices).Should(Equal([]string{"/dev/input/event3"}))
+		Ω(run.openEventsCall.src.closeNum).Should(Equal(1))
+
+		Ω(run.dev.ctlArgs).Should(HaveLen(4))
+		Ω(run.dev.ctlArgs[:2]).Should(Equal(getEffectCtlArgs()))
+		Ω(run.dev.ctlArgs[2].data).Should(Equal([]byte{9, 2, 5}))
+		Ω(run.dev.ctlArgs[3].data).Should(Equal([]byte{9, 2, 30}))
+	})
+
+	It("turns idle keyboard off and restores its effect", func() {
+
+		Ω(run.execute("idle", "--"+params.IdleTimeoutFlag, "50ms", "--"+params.IdleActionFlag, "OFF")).
+			Should(Succeed())
+
+		Ω(run.dev.ctlArgs).Should(HaveLen(4))
+		Ω(run.dev.ctlArgs[2].data).Should(Equal([]byte{8, 1, 0, 0, 0, 0, 0, 0}))
+		Ω(run.dev.ctlArgs[3].data).Should(Equal(breathState))
+	})
+
+	It("plays animation on idle keyboard", func() {
+
+		Ω(run.execute("idle", "--"+params.IdleTimeoutFlag, "50ms", "--"+params.IdleActionFlag, "animation",
+			"--"+params.IdleAnimationFlag, "spectrum", "--"+params.IdleBrightnessFlag, "10")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[2]).Should(Equal(userModeCtlArgs(10, 0)))
+		Ω(run.dev.bulkWriteCallNum).Should(BeNumerically(">", 0))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal(breathState))
+	})
+
+	It("does not dim keyboard in the excluded periods", func() {
+
+		Ω(run.execute("idle", "--"+params.IdleTimeoutFlag, "50ms",
+			"--"+params.IdleExcludeFlag, "00:00-12:00,12:00-00:00")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs).Should(BeEmpty())
+	})
+
+	It("fails on read error", func() {
+
+		run.openEventsCall.src.rtnError = errors.New("read error") //nolint:err113
+		Ω(run.execute("idle")).Should(MatchError(run.openEventsCall.src.rtnError))
+	})
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(append([]string{"idle"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("zero timeout", "--"+params.IdleTimeoutFlag, "0s"),
+		Entry("unknown action", "--"+params.IdleActionFlag, "sleep"),
+		Entry("too high brightness", "--"+params.IdleBrightnessFlag, "51"),
+		Entry("unknown animation", "--"+params.IdleActionFlag, "animation", "--"+params.IdleAnimationFlag, "unknown"),
+		Entry("invalid excluded period", "--"+params.IdleExcludeFlag, "22:00"),
+	)
+})
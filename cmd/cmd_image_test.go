@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("image-mode", func() {
+
+	var run *cmdRunT
+	var cmdErr error
+	var args []string
+	var dir string
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		dir = GinkgoT().TempDir()
+	})
+
+	JustBeforeEach(func() {
+		cmdErr = run.execute(append([]string{"image-mode"}, args...)...)
+	})
+
+	Context("with PNG image", func() {
+
+		BeforeEach(func() {
+			img := image.NewRGBA(image.Rect(0, 0, 21, 6))
+			img.Set(3, 2, color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff})
+
+			f, err := os.Create(filepath.Join(dir, "logo.png"))
+			Ω(err).Should(Succeed())
+			Ω(png.Encode(f, img)).Should(Succeed())
+			Ω(f.Close()).Should(Succeed())
+
+			args = []string{"--file", f.Name(), "-b", "11"}
+		})
+
+		It("shows the image", func() {
+			Ω(cmdErr).Should(Succeed())
+
+			frame := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+			frame[2][3] = *ite8291.NewColor(0x10, 0x20, 0x30)
+			assertFrameModeCall(run.dev, 11, 0, frame)
+		})
+	})
+
+	Context("with animated GIF image configured", func() {
+
+		BeforeEach(func() {
+			palette := color.Palette{color.RGBA{A: 0xff}, color.RGBA{R: 0xff, A: 0xff}}
+			first, second := image.NewPaletted(image.Rect(0, 0, 21, 6), palette),
+				image.NewPaletted(image.Rect(0, 0, 21, 6), palette)
+			second.SetColorIndex(20, 5, 1)
+
+			f, err := os.Create(filepath.Join(dir, "anim.gif"))
+			Ω(err).Should(Succeed())
+			Ω(gif.EncodeAll(f, &gif.GIF{
+				Image: []*image.Paletted{first, second}, Delay: []int{1, 1}, LoopCount: -1,
+			})).Should(Succeed())
+			Ω(f.Close()).Should(Succeed())
+
+			run.configure(map[string]any{"imageFile": f.Name(), params.SamplingProp: "nearest"})
+			args = nil
+		})
+
+		It("plays all frames once", func() {
+			Ω(cmdErr).Should(Succeed())
+
+			args := []*ctlArgsT{userModeCtlArgs(params.BrightnessDefault, 0)}
+			for range 2 {
+				for i := range ite8291.RowsNumber {
+					args = append(args, rowIndexCtlArgs(i))
+				}
+			}
+			assertControlCall(run.dev, nil, false, args)
+			assertGetBulkWriteCall(run.dev, 2)
+
+			black := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+			red := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+			red[5][20] = *ite8291.NewColor(0xff, 0, 0)
+			Ω(run.dev.bulkBuffer.Contents()).Should(Equal(append(frameBytes(black), frameBytes(red)...)))
+		})
+	})
+
+	Context("without image file", func() {
+
+		BeforeEach(func() {
+			args = nil
+		})
+
+		It("fails and reports the error", func() {
+			Ω(cmdErr).Should(MatchError(ContainSubstring("--file")))
+			assertDeviceNotCalled(run.dev)
+		})
+	})
+
+	Context("with invalid sampling", func() {
+
+		BeforeEach(func() {
+			args = []string{"--file", "logo.png", "--sampling", "bilinear"}
+		})
+
+		It("fails and reports the error", func() {
+			Ω(cmdErr).Should(MatchError(SatisfyAll(ContainSubstring(`"bilinear"`), ContainSubstring("--sampling"))))
+			assertDeviceNotCalled(run.dev)
+		})
+	})
+
+	Context("with invalid layout", func() {
+
+		BeforeEach(func() {
+			layout := filepath.Join(dir, "layout.yml")
+			Ω(os.WriteFile(layout, []byte("keys:\n  - {name: a, row: 9, column: 0, width: 1, height: 1}\n"),
+				0o600)).Should(Succeed())
+			args = []string{"--file", "logo.png", "--sampling", "layout", "--layout", layout}
+		})
+
+		It("fails and reports the error", func() {
+			Ω(cmdErr).Should(MatchError(ite8291.ErrInvalidLayout))
+			assertDeviceNotCalled(run.dev)
+		})
+	})
+})
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("keymap", func() {
+
+	var run *cmdRunT
+	var file string
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		file = filepath.Join(GinkgoT().TempDir(), "keymap.yml")
+	})
+
+	Context("keymap-mode", func() {
+
+		It("sets keys to keymap colors", func() {
+			Ω(os.WriteFile(file, []byte("base: \"123-yes\"\nkeys:\n  r2c3: colour.cyAN\n"), 0o600)).Should(Succeed())
+
+			Ω(run.execute("keymap-mode", "--keymap", file, "-b", "12", "--save")).Should(Succeed())
+
+			frame := ite8291.NewFrame(ite8291.NewColor(0xdd, 0xee, 0xff))
+			frame.Set(ite8291.Cell{Row: 2, Column: 3}, ite8291.NewColor(0x11, 0x22, 0x33))
+			assertFrameModeCall(run.dev, 12, 1, frame)
+		})
+
+		DescribeTable("validation errors",
+			func(content string, a []string, msg string) {
+				if len(content) > 0 {
+					Ω(os.WriteFile(file, []byte(content), 0o600)).Should(Succeed())
+				}
+
+				args := []string{"keymap-mode"}
+				for _, arg := range a {
+					args = append(args, strings.ReplaceAll(arg, "$FILE", file))
+				}
+
+				Ω(run.execute(args...)).
+					Should(MatchError(SatisfyAll(ContainSubstring(msg), ContainSubstring("--keymap"))))
+				assertDeviceNotCalled(run.dev)
+			},
+			Entry("missing keymap", "", nil, "keymap file is missing"),
+			Entry("missing file", "", []string{"--keymap", "/nonexistent/keymap.yml"}, "no such file"),
+			Entry("unknown key", "keys:\n  esc: red\n", []string{"--keymap", "$FILE"}, `key "esc" is missing`),
+			Entry("invalid color", "keys:\n  r0c0: nocolor\n", []string{"--keymap", "$FILE"}, "invalid color format"),
+		)
+	})
+
+	Context("edit", func() {
+
+		It("edits keys live and saves keymap", func() {
+			run.configure(map[string]any{params.KeymapProp: file})
+			run.in = strings.NewReader("l#\x7fcolour.cyAN\rpsq")
+
+			Ω(run.execute("edit", "-b", "30")).Should(Succeed())
+
+			initial := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+			painted := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+			painted.Set(ite8291.Cell{Row: 0, Column: 1}, ite8291.NewColor(0x11, 0x22, 0x33))
+
+			args := []*ctlArgsT{userModeCtlArgs(30, 0)}
+			for range 2 {
+				for i := range ite8291.RowsNumber {
+					args = append(args, rowIndexCtlArgs(i))
+				}
+			}
+			assertControlCall(run.dev, nil, false, args)
+			Ω(run.dev.bulkBuffer.Contents()).Should(Equal(append(frameBytes(initial), frameBytes(painted)...)))
+			Ω(string(run.out.Contents())).Should(ContainSubstring("itectl edit - " + file))
+
+			saved, err := params.KeymapFrame(run.readConfigCall.v, file, ite8291.DefaultLayout(), false)
+			Ω(err).Should(Succeed())
+			Ω(saved).Should(Equal(painted))
+		})
+
+		It("starts with colors of existing keymap", func() {
+			Ω(os.WriteFile(file, []byte("keys:\n  r0c0: \"#010203\"\n"), 0o600)).Should(Succeed())
+			run.in = strings.NewReader("q")
+
+			Ω(run.execute("edit", "--keymap", file)).Should(Succeed())
+
+			frame := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+			frame.Set(ite8291.Cell{Row: 0, Column: 0}, ite8291.NewColor(1, 2, 3))
+			assertFrameModeCall(run.dev, params.BrightnessDefault, 0, frame)
+		})
+	})
+})
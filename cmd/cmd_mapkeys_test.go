@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("map-keys", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.dev.ctlChangedData = [][]byte{nil, {8, 2, 3, 5, 30, 0, 1, 1}}
+		run.openEventsCall.src = newEventSource(1, 59, 57, 57)
+
+		// keys are pressed once their cells are lit
+		lit := make(chan struct{}, ite8291.RowsNumber*ite8291.ColumnsNumber)
+		run.openEventsCall.src.lit = lit
+		run.dev.onBulkWrite = func() {
+			if run.dev.bulkWriteCallNum%ite8291.RowsNumber != 0 {
+				return // a frame is written row by row
+			}
+			select {
+			case lit <- struct{}{}:
+			default: // no more keys are pressed
+			}
+		}
+	})
+
+	// mappedKeys - keys expected to be mapped to the first cells.
+	mappedKeys := []ite8291.Key{
+		{Name: "esc", Code: 1, Row: 0, Column: 0, X: 0, Y: 0, Width: 1, Height: 1},
+		{Name: "f1", Code: 59, Row: 0, Column: 1, X: 1, Y: 0, Width: 1, Height: 1},
+		{Name: "space", Code: 57, Row: 0, Column: 2, X: 2, Y: 0, Width: 1, Height: 1},
+		{Name: "space#2", Code: 57, Row: 0, Column: 3, X: 3, Y: 0, Width: 1, Height: 1},
+	}
+
+	// assertLayout asserts that the layout has the mapped keys and all other cells are empty.
+	assertLayout := func(layout *ite8291.Layout) {
+
+		Ω(layout.Keys).Should(Equal(mappedKeys))
+		Ω(layout.Empty).Should(HaveLen(ite8291.RowsNumber*ite8291.ColumnsNumber - len(mappedKeys)))
+		Ω(layout.Empty[0]).Should(Equal(ite8291.Cell{Row: 0, Column: 4}))
+		Ω(layout.Empty[len(layout.Empty)-1]).Should(Equal(
+			ite8291.Cell{Row: ite8291.RowsNumber - 1, Column: ite8291.ColumnsNumber - 1}))
+	}
+
+	It("maps pressed keys to lit cells and writes layout", func() {
+
+		Ω(run.execute("map-keys", "--wait", "5ms", "-b", "20",
+			"--input-device", "/dev/input/event3", "--input-device", "/dev/input/event7")).Should(Succeed())
+
+		Ω(run.openEventsCall.callNum).Should(Equal(1))
+		Ω(run.openEventsCall.devices).Should(Equal([]string{"/dev/input/event3", "/dev/input/event7"}))
+		Ω(run.openEventsCall.src.closeNum).Should(Equal(1))
+
+		layout, err := ite8291.ReadLayout(bytes.NewReader(run.out.Contents()))
+		Ω(err).ShouldNot(HaveOccurred())
+		assertLayout(layout)
+
+		Ω(string(run.errOut.Contents())).Should(And(
+			ContainSubstring("row 0, column 0: esc (1)\n"),
+			ContainSubstring("row 0, column 3: space#2 (57)\n"),
+			ContainSubstring("row 5, column 20: no key\n")))
+
+		cells := ite8291.RowsNumber * ite8291.ColumnsNumber
+		Ω(run.dev.ctlArgs[:3]).Should(Equal(append(getEffectCtlArgs(), userModeCtlArgs(20, 0))))
+		Ω(run.dev.ctlArgs).Should(HaveLen(3 + cells*ite8291.RowsNumber + 1))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 3, 5, 30, 0, 1, 0}))
+		assertGetBulkWriteCall(run.dev, cells)
+
+		// the first frame lights the first cell
+		frame := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+		frame.Set(ite8291.Cell{Row: 0, Column: 0}, ite8291.NewColor(0xff, 0xff, 0xff))
+		Ω(run.dev.bulkBuffer.Contents()[:len(frameBytes(frame))]).Should(Equal(frameBytes(frame)))
+	})
+
+	It("discards keys pressed before the cell is lit", func() {
+
+		keys := make(chan uint16, 2)
+		keys <- 1
+		keys <- 59
+
+		drainKeys(keys)
+		Ω(keys).ShouldNot(Receive())
+
+		close(keys)
+		drainKeys(keys)
+		Ω(keys).Should(BeClosed())
+	})
+
+	It("writes layout to the given file", func() {
+
+		file := filepath.Join(GinkgoT().TempDir(), "layout.yaml")
+		run.configure(map[string]any{"mapKeys": map[string]any{"wait": "5ms"}})
+
+		Ω(run.execute("map-keys", "-o", file)).Should(Succeed())
+		Ω(run.openEventsCall.devices).Should(BeEmpty())
+		Ω(run.out.Contents()).Should(BeEmpty())
+
+		f, err := os.Open(file)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer f.Close()
+
+		layout, err := ite8291.ReadLayout(f)
+		Ω(err).ShouldNot(HaveOccurred())
+		assertLayout(layout)
+	})
+
+	It("fails and restores previous effect if reading key events fails", func() {
+
+		run.openEventsCall.src.rtnError = errors.New("device removed")
+
+		Ω(run.execute("map-keys")).Should(MatchError(ContainSubstring("device removed")))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 3, 5, 30, 0, 1, 0}))
+		Ω(run.openEventsCall.src.closeNum).Should(Equal(1))
+	})
+
+	It("fails if input devices cannot be opened", func() {
+
+		run.openEventsCall.rtnError = errors.New("permission denied")
+
+		Ω(run.execute("map-keys")).Should(MatchError("permission denied"))
+		assertControlCall(run.dev, nil, false, nil)
+	})
+
+	It("fails on invalid wait time", func() {
+		Ω(run.execute("map-keys", "--wait", "0s")).Should(MatchError(params.ErrInvalidOptVal))
+		assertDeviceNotCalled(run.dev)
+	})
+})
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("meter", func() {
+
+	var run *cmdRunT
+
+	black := ite8291.NewColor(0, 0, 0)
+	ramp := ite8291.Gradient{ite8291.NewColor(0, 0xff, 0), ite8291.NewColor(0xff, 0xff, 0), ite8291.NewColor(0xff, 0, 0)}
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.configure(map[string]any{params.ZonesProp: arrowsZoneConfig})
+	})
+
+	It("maps the numbers to the colors of the whole keyboard", func() {
+
+		run.in = strings.NewReader("0\n50\n\n100\n150\n")
+
+		Ω(run.execute("meter", "-b", "40")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[0]).Should(Equal(userModeCtlArgs(40, 0)))
+		Ω(writtenFrames(run.dev)).Should(Equal([][]byte{
+			frameBytes(ite8291.NewFrame(ramp.At(0))),
+			frameBytes(ite8291.NewFrame(ramp.At(0.5))),
+			frameBytes(ite8291.NewFrame(ramp.At(1))),
+		}))
+	})
+
+	It("colors the zone with range, hysteresis and smoothing", func() {
+
+		run.in = strings.NewReader("-10\n-8\n10\n")
+
+		Ω(run.execute("meter", "--zone", "arrows", "--min", "-10", "--max", "10",
+			"--colors", "#000,colour.cyAN", "--hysteresis", "5", "--smoothing", "0.5")).Should(Succeed())
+
+		cyan := ite8291.Gradient{black, ite8291.NewColor(0x11, 0x22, 0x33)}
+		zoneFrame := func(color *ite8291.Color) []byte {
+			frame := ite8291.NewFrame(black)
+			frame.SetCells([]ite8291.Cell{{Row: 4, Column: 16}, {Row: 5, Column: 15}, {Row: 5, Column: 16}, {Row: 5, Column: 17}}, color)
+			return frameBytes(frame)
+		}
+
+		// -8 smooths to -9 within hysteresis, 10 smooths to 0.5
+		Ω(writtenFrames(run.dev)).Should(Equal([][]byte{zoneFrame(cyan.At(0)), zoneFrame(cyan.At(0.525))}))
+	})
+
+	DescribeTable("fails on invalid number",
+		func(line string) {
+			run.in = strings.NewReader("1\n" + line + "\n")
+
+			Ω(run.execute("meter")).Should(MatchError(params.ErrInvalidOptVal))
+		},
+		Entry("not a number", "high"),
+		Entry("not a number value", "NaN"),
+		Entry("infinity", "-Inf"),
+	)
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(append([]string{"meter"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("max not above min", "--min", "10", "--max", "10"),
+		Entry("invalid color", "--colors", "nocolor"),
+		Entry("unknown zone", "--zone", "nozone"),
+		Entry("smoothing of 1", "--smoothing", "1"),
+		Entry("negative hysteresis", "--hysteresis", "-1"),
+	)
+})
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/v4n6/itectl/params"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("mqtt", func() {
+
+	var run *cmdRunT
+
+	// breath effect state as reported by the controller
+	breathState := []byte{8, 2, 2, 5, 30, 0, 1, 0}
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	It("exposes the keyboard backlight via MQTT broker", func() {
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		address := l.Addr().String()
+		Ω(l.Close()).Should(Succeed())
+
+		broker := mochi.New(&mochi.Options{InlineClient: true,
+			Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+		Ω(broker.AddHook(new(auth.AllowHook), nil)).Should(Succeed())
+		Ω(broker.AddListener(listeners.NewTCP(listeners.Config{ID: "test", Address: address}))).Should(Succeed())
+		Ω(broker.Serve()).Should(Succeed())
+		DeferCleanup(broker.Close)
+
+		messages := make(chan string, 20)
+		Ω(broker.Subscribe("#", 1, func(_ *mochi.Client, _ packets.Subscription, pk packets.Packet) {
+			messages <- pk.TopicName + " " + string(pk.Payload)
+		})).Should(Succeed())
+
+		// firmware version, effect, off mode and effect again
+		run.dev.ctlChangedData = [][]byte{nil, nil, nil, breathState, nil, nil, {8, 1, 0, 0, 0, 0, 0, 0}}
+		var cancel context.CancelFunc
+		run.ctx, cancel = context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- run.execute("mqtt", "--"+params.MQTTBrokerFlag, "tcp://"+address,
+				"--"+params.MQTTIDFlag, "laptop", "--"+params.MQTTRefreshFlag, "0")
+		}()
+
+		Eventually(messages).Should(Receive(And(HavePrefix("homeassistant/light/laptop/config "),
+			ContainSubstring(`"effect_list":["aurora","breath","fireworks","marquee","rainbow","raindrop","random","ripple","wave"]`))))
+		Eventually(messages).Should(Receive(Equal("itectl/laptop/availability online")))
+		Eventually(messages).Should(Receive(And(HavePrefix("itectl/laptop/state "),
+			ContainSubstring(`"state":"ON"`), ContainSubstring(`"effect":"breath"`))))
+
+		Ω(broker.Publish("itectl/laptop/set", []byte(`{"state": "OFF"}`), false, 1)).Should(Succeed())
+		Eventually(messages).Should(Receive(And(HavePrefix("itectl/laptop/state "), ContainSubstring(`"state":"OFF"`))))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Eventually(messages).Should(Receive(Equal("itectl/laptop/availability offline")))
+		Ω(run.dev.closeCallNum).Should(Equal(1))
+		Ω(run.dev.ctlArgs).Should(ContainElement(Equal(&ctlArgsT{
+			requestType: 0x21, request: 9, value: 0x300, index: 1,
+			data: []byte{0x8, 0x1, 0x0, 0, 0, 0, 0, 0}, length: 8, timeout: 0,
+		})))
+	})
+
+	It("fails on invalid broker", func() {
+
+		Ω(run.execute("mqtt", "--"+params.MQTTBrokerFlag, "localhost:1883")).Should(MatchError(params.ErrInvalidOptVal))
+		assertDeviceNotCalled(run.dev)
+	})
+
+	It("fails on invalid id", func() {
+
+		Ω(run.execute("mqtt", "--"+params.MQTTIDFlag, "a/b")).Should(MatchError(params.ErrInvalidOptVal))
+		assertDeviceNotCalled(run.dev)
+	})
+})
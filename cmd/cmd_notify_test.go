@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("notify", func() {
+
+	var run *cmdRunT
+
+	red, black := ite8291.NewFrame(ite8291.NewColor(0xff, 0, 0)), ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	// assertPredefinedColorsUntouched asserts that no predefined color was set.
+	assertPredefinedColorsUntouched := func() {
+		for _, args := range run.dev.ctlArgs {
+			Ω(args.data[0]).ShouldNot(Equal(byte(ite8291.SetColorCommand)))
+		}
+	}
+
+	It("blinks and restores the previous effect", func() {
+
+		run.dev.ctlChangedData = [][]byte{nil, {8, 2, 2, 5, 30, 0, 1, 0}}
+
+		Ω(run.execute("notify", "--rgb", "#F00", "--count", "2", "--period", "40ms", "-b", "50")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[:2]).Should(Equal(getEffectCtlArgs()))
+		Ω(run.dev.ctlArgs[2]).Should(Equal(userModeCtlArgs(50, 0)))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 2, 5, 30, 0, 1, 0}))
+
+		frames := writtenFrames(run.dev)
+		Ω(frames[0]).Should(Equal(frameBytes(red)))
+		Ω(frames).Should(ContainElement(frameBytes(black)))
+		Ω(frames[len(frames)-1]).Should(Equal(frameBytes(black)))
+		assertPredefinedColorsUntouched()
+	})
+
+	It("never restores the cached frame of 'user' effect", func() {
+
+		cached := ite8291.NewFrame(ite8291.NewColor(1, 2, 3))
+		Ω(storeLastFrame(cached)).Should(Succeed())
+		run.dev.ctlChangedData = [][]byte{nil, {8, 2, 0x33, 0, 20, 0, 0, 0}}
+
+		Ω(run.execute("notify", "--color-name", "colour.cyAN", "--pattern", "MORSE", "--text", "e",
+			"--count", "1", "--period", "50ms")).Should(Succeed())
+
+		frames := writtenFrames(run.dev)
+		Ω(frames[0]).Should(Equal(frameBytes(ite8291.NewFrame(ite8291.NewColor(0x11, 0x22, 0x33)))))
+		Ω(frames).ShouldNot(ContainElement(frameBytes(cached)))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 0x33, 0, 20, 0, 0, 0}))
+		assertPredefinedColorsUntouched()
+	})
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(append([]string{"notify", "--rgb", "#F00"}, args...)...)).
+				Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("unknown pattern", "--pattern", "flash"),
+		Entry("zero count", "--count", "0"),
+		Entry("zero period", "--period", "0s"),
+		Entry("missing morse text", "--pattern", "morse"),
+		Entry("unsupported morse text", "--pattern", "morse", "--text", "a#b"),
+	)
+
+	It("requires color", func() {
+		Ω(run.execute("notify")).ShouldNot(Succeed())
+		assertDeviceNotCalled(run.dev)
+	})
+})
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/openrgb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("openrgb-server", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	It("serves the keyboard backlight to OpenRGB clients", func() {
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		address := l.Addr().String()
+		Ω(l.Close()).Should(Succeed())
+
+		var cancel context.CancelFunc
+		run.ctx, cancel = context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- run.execute("openrgb-server", "--"+params.OpenRGBAddressFlag, address) }()
+
+		var conn net.Conn
+		Eventually(func() (err error) { conn, err = net.Dial("tcp", address); return err }).Should(Succeed())
+		defer conn.Close()
+
+		Ω(openrgb.WritePacket(conn, 0, openrgb.RequestControllerCount, nil)).Should(Succeed())
+		h, err := openrgb.ReadHeader(conn)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(h.ID).Should(Equal(uint32(openrgb.RequestControllerCount)))
+		count := make([]byte, h.Size)
+		_, err = io.ReadFull(conn, count)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(binary.LittleEndian.Uint32(count)).Should(Equal(uint32(1)))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Ω(run.dev.closeCallNum).Should(Equal(1))
+		Ω(run.findDevCall.callNum).Should(Equal(1))
+	})
+
+	It("fails on invalid address", func() {
+
+		Ω(run.execute("openrgb-server", "--"+params.OpenRGBAddressFlag, "localhost")).
+			Should(MatchError(params.ErrInvalidOptVal))
+		assertDeviceNotCalled(run.dev)
+	})
+})
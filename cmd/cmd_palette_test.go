@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("palette-cycle", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		DeferCleanup(cancel)
+		run.ctx = ctx
+	})
+
+	// setColorData returns data of the control call setting the predefined color.
+	setColorData := func(num byte, color *ite8291.Color) []byte {
+		return []byte{ite8291.SetColorCommand, 0, num, color.Red, color.Green, color.Blue}
+	}
+
+	It("steps through the palettes and resets the predefined colors", func() {
+
+		run.configure(map[string]any{params.PaletteProp: map[string]any{
+			"cycle": "STEP", "palettes": []any{[]any{"colour.cyAN", "#FFF"}, "123-yes"},
+		}})
+
+		Ω(run.execute("palette-cycle", "--period", "1h", "--fps", "60")).Should(Succeed())
+
+		cyan, white := ite8291.NewColor(0x11, 0x22, 0x33), ite8291.NewColor(0xff, 0xff, 0xff)
+		for i := range ite8291.PaletteSize {
+			col := cyan
+			if i%2 == 1 {
+				col = white
+			}
+			Ω(run.dev.ctlArgs[i].data).Should(Equal(setColorData(byte(i+1), col)))
+		}
+
+		// predefined colors are reset to the configured ones
+		n := len(run.dev.ctlArgs)
+		for i := range ite8291.PaletteSize {
+			col, err := ite8291.ParseColor(params.PredefinedColorsDefault[i])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(run.dev.ctlArgs[n-ite8291.PaletteSize+i].data).Should(Equal(setColorData(byte(i+1), col)))
+		}
+	})
+
+	It("rotates the predefined colors given by flags", func() {
+
+		Ω(run.execute("palette-cycle", "--palette", "#F00,#0F0", "--period", "14ms")).Should(Succeed())
+		Ω(len(run.dev.ctlArgs)).Should(BeNumerically(">", 2*ite8291.PaletteSize))
+	})
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(append([]string{"palette-cycle"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("unknown cycle", "--cycle", "shuffle"),
+		Entry("invalid color", "--palette", "red,nocolor"),
+		Entry("empty color", "--palette", "red,,blue"),
+		Entry("zero period", "--period", "0s"),
+	)
+})
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("play", func() {
+
+	var run *cmdRunT
+	var file string
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.configure(map[string]any{params.ZonesProp: map[string]any{"corner": map[string]any{"keys": []string{"5,20"}}}})
+
+		file = filepath.Join(GinkgoT().TempDir(), "anim.yml")
+		Ω(os.WriteFile(file, []byte(`
+frames:
+  - {duration: 30ms, base: colour.cyAN}
+  - {duration: 30ms, base: "#000", zones: {corner: "#FFF"}, keys: {r0c0: 123-yes}}
+`), 0o600)).Should(Succeed())
+	})
+
+	// lastFrame returns the frame the animation ends with.
+	lastFrame := func() *ite8291.Frame {
+		frame := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+		frame.Set(ite8291.Cell{Row: 5, Column: 20}, ite8291.NewColor(0xff, 0xff, 0xff))
+		frame.Set(ite8291.Cell{Row: 0, Column: 0}, ite8291.NewColor(0xdd, 0xee, 0xff))
+		return frame
+	}
+
+	It("plays the animation once", func() {
+
+		Ω(run.execute("play", file, "-b", "12", "--save", "--fps", "60")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[0]).Should(Equal(userModeCtlArgs(12, 1)))
+		assertGetBulkWriteCall(run.dev, 2)
+		Ω(run.dev.bulkBuffer.Contents()).Should(Equal(append(
+			frameBytes(ite8291.NewFrame(ite8291.NewColor(0x11, 0x22, 0x33))), frameBytes(lastFrame())...)))
+	})
+
+	It("plays the configured animation as the default mode", func() {
+
+		run.configure(map[string]any{"mode": "play", params.PlayProp: map[string]any{"file": file}})
+
+		Ω(run.execute()).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[0]).Should(Equal(userModeCtlArgs(params.BrightnessDefault, 0)))
+		contents := run.dev.bulkBuffer.Contents()
+		Ω(contents[len(contents)-len(frameBytes(lastFrame())):]).Should(Equal(frameBytes(lastFrame())))
+	})
+
+	DescribeTable("fails on invalid animation",
+		func(content string, args ...string) {
+			if len(content) > 0 {
+				Ω(os.WriteFile(file, []byte(content), 0o600)).Should(Succeed())
+			}
+
+			for i := range args {
+				if args[i] == "<file>" {
+					args[i] = file
+				}
+			}
+
+			Ω(run.execute(append([]string{"play"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("missing file", ""),
+		Entry("nonexistent file", "", "nonexistent.yml"),
+		Entry("unknown color", "frames: [{duration: 1s, base: nocolor}]", "<file>"),
+		Entry("unknown zone", "frames: [{duration: 1s, zones: {middle: red}}]", "<file>"),
+	)
+})
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/v4n6/itectl/params"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("preview", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	It("renders frame to terminal instead of applying it", func() {
+		Ω(run.execute("single-color-mode", "--preview", "--rgb", "#102030")).Should(Succeed())
+
+		assertDeviceNotCalled(run.dev)
+		Ω(run.findDevCall.callNum).Should(Equal(0))
+		Ω(string(run.out.Contents())).Should(ContainSubstring("\x1b[0;38;2;16;32;48;48;2;16;32;48m▀"))
+	})
+
+	It("renders simulated built-in effect to PNG file", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "kbd.png")
+		Ω(run.execute("breath-mode", "--preview-file", file, "--color-num", "2")).Should(Succeed())
+
+		assertDeviceNotCalled(run.dev)
+
+		f, err := os.Open(file)
+		Ω(err).Should(Succeed())
+		defer f.Close()
+		img, err := png.Decode(f)
+		Ω(err).Should(Succeed())
+		Ω(color.RGBAModel.Convert(img.At(20, 20))).Should(Equal(color.RGBA{R: 0xff, A: 0xff}))
+	})
+
+	It("rejects unsupported preview file", func() {
+		Ω(run.execute("off-mode", "--preview-file", "kbd.gif")).
+			Should(MatchError(SatisfyAll(ContainSubstring(`"kbd.gif"`), ContainSubstring("--preview-file"))))
+		assertDeviceNotCalled(run.dev)
+	})
+
+	DescribeTable("is rejected by commands serving the device",
+		func(args ...string) {
+			Ω(run.execute(args...)).Should(SatisfyAll(MatchError(params.ErrInvalidOptVal),
+				MatchError(ContainSubstring("--preview"))))
+			assertDeviceNotCalled(run.dev)
+			Ω(run.findDevCall.callNum).Should(Equal(0))
+		},
+		Entry("daemon", "daemon", "--preview"),
+		Entry("dbus", "dbus", "--preview"),
+		Entry("openrgb-server", "openrgb-server", "--preview-file", "kbd.png"),
+		Entry("mqtt", "mqtt", "--preview"),
+	)
+})
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("progress and timer", func() {
+
+	var run *cmdRunT
+
+	// breath effect state with brightness 30 as reported by the controller
+	breathState := []byte{8, 2, 2, 5, 30, 0, 1, 0}
+
+	green, red := ite8291.NewColor(0, 0xff, 0), ite8291.NewColor(0xff, 0, 0)
+	black, white := ite8291.NewColor(0, 0, 0), ite8291.NewColor(0xff, 0xff, 0xff)
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.configure(map[string]any{params.ZonesProp: arrowsZoneConfig})
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+	})
+
+	// barFrame returns frame of base color with the progress bar over the given cells.
+	barFrame := func(base *ite8291.Color, cells []ite8291.Cell, progress float64, fill *ite8291.Color) []byte {
+		frame := ite8291.NewFrame(base)
+		frame.SetProgress(cells, progress, fill, black)
+		return frameBytes(frame)
+	}
+
+	Describe("progress", func() {
+
+		It("shows the percentage over the first row", func() {
+
+			Ω(run.execute("progress", "42%", "-b", "40")).Should(Succeed())
+
+			Ω(run.dev.ctlArgs[0]).Should(Equal(userModeCtlArgs(40, 0)))
+			Ω(run.dev.bulkBuffer.Contents()).Should(Equal(barFrame(black, ite8291.RowCells(0), 0.42, green)))
+		})
+
+		It("shows the percentage over a zone on the background instead of the cached 'user' effect frame", func() {
+
+			Ω(storeLastFrame(ite8291.NewFrame(ite8291.NewColor(1, 2, 3)))).Should(Succeed())
+
+			Ω(run.execute("progress", "50", "--zone", "arrows", "--fill", "colour.cyAN")).Should(Succeed())
+
+			cells := []ite8291.Cell{{Row: 4, Column: 16}, {Row: 5, Column: 15}, {Row: 5, Column: 16}, {Row: 5, Column: 17}}
+			Ω(run.dev.bulkBuffer.Contents()).
+				Should(Equal(barFrame(black, cells, 0.5, ite8291.NewColor(0x11, 0x22, 0x33))))
+		})
+
+		It("reads the percentages from the standard input", func() {
+
+			run.in = strings.NewReader("10\n\n 60% \n100\n")
+
+			Ω(run.execute("progress", "--stdin", "--row", "5")).Should(Succeed())
+
+			Ω(writtenFrames(run.dev)).Should(Equal([][]byte{
+				barFrame(black, ite8291.RowCells(5), 0.1, green),
+				barFrame(black, ite8291.RowCells(5), 0.6, green),
+				barFrame(black, ite8291.RowCells(5), 1, green),
+			}))
+		})
+
+		It("fails on invalid percentage read from the standard input", func() {
+
+			run.in = strings.NewReader("10\nabc\n")
+
+			Ω(run.execute("progress", "--stdin")).Should(MatchError(params.ErrInvalidOptVal))
+		})
+
+		DescribeTable("fails on invalid options",
+			func(args ...string) {
+				Ω(run.execute(append([]string{"progress"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+				assertDeviceNotCalled(run.dev)
+			},
+			Entry("missing percentage"),
+			Entry("percentage above 100", "101"),
+			Entry("negative percentage", "--", "-1"),
+			Entry("not a percentage", "half"),
+			Entry("not a number percentage", "NaN"),
+			Entry("percentage with stdin", "--stdin", "50"),
+			Entry("row out of range", "50", "--row", "6"),
+			Entry("unknown zone", "50", "--zone", "nozone"),
+			Entry("invalid fill color", "50", "--fill", "nocolor"),
+			Entry("invalid background color", "50", "--background", "nocolor"),
+		)
+	})
+
+	Describe("timer", func() {
+
+		It("counts down, flashes and restores the previous effect", func() {
+
+			run.configure(map[string]any{params.AnimationProp: map[string]any{"fps": 60}})
+
+			start := time.Now()
+			Ω(run.execute("timer", "100ms", "--finish-pattern", "blink", "-b", "40")).Should(Succeed())
+			Ω(time.Since(start)).Should(BeNumerically(">=", 100*time.Millisecond))
+
+			Ω(run.dev.ctlArgs[:2]).Should(Equal(getEffectCtlArgs()))
+			Ω(run.dev.ctlArgs[2]).Should(Equal(userModeCtlArgs(40, 0)))
+
+			frames := writtenFrames(run.dev)
+			Ω(frames[0]).Should(Equal(barFrame(black, ite8291.RowCells(0), 1, red)))
+			Ω(frames).Should(ContainElement(barFrame(black, ite8291.RowCells(0), 0, red)))
+			Ω(frames).Should(ContainElement(frameBytes(ite8291.NewFrame(white))))
+			Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal(breathState))
+		})
+
+		DescribeTable("fails on invalid options",
+			func(args ...string) {
+				Ω(run.execute(append([]string{"timer"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+				assertDeviceNotCalled(run.dev)
+			},
+			Entry("zero duration", "0s"),
+			Entry("not a duration", "25"),
+			Entry("unknown finish pattern", "1m", "--finish-pattern", "morse"),
+			Entry("invalid finish color", "1m", "--finish", "nocolor"),
+		)
+
+		It("requires duration", func() {
+			Ω(run.execute("timer")).ShouldNot(Succeed())
+			assertDeviceNotCalled(run.dev)
+		})
+	})
+})
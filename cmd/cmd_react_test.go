@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("react", func() {
+
+	var run *cmdRunT
+	var layout string
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.dev.ctlChangedData = [][]byte{nil, {8, 2, 3, 5, 30, 0, 1, 1}}
+		run.openEventsCall.src = newEventSource(1, 57)
+
+		layout = filepath.Join(GinkgoT().TempDir(), "layout.yml")
+		Ω(os.WriteFile(layout, []byte(`
+keys:
+  - {name: esc, code: 1, row: 0, column: 0, x: 0, y: 0, width: 1, height: 1}
+  - {name: space, row: 5, column: 9, x: 9, y: 5, width: 1, height: 1}
+  - {name: "space#2", row: 5, column: 10, x: 10, y: 5, width: 1, height: 1}
+`), 0o600)).Should(Succeed())
+	})
+
+	It("lights pressed keys until interrupted", func() {
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		run.ctx = ctx
+
+		Ω(run.execute("react", "--effect", "TRAIL", "--colors", "colour.cyAN,123-yes", "--decay", "1m",
+			"--layout", layout, "-b", "20", "--input-device", "/dev/input/event3")).Should(Succeed())
+
+		Ω(run.openEventsCall.devices).Should(Equal([]string{"/dev/input/event3"}))
+		Ω(run.openEventsCall.src.closeNum).Should(Equal(1))
+
+		Ω(run.dev.ctlArgs[:3]).Should(Equal(append(getEffectCtlArgs(), userModeCtlArgs(20, 0))))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 3, 5, 30, 0, 1, 0}))
+
+		lit := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+		lit.Set(ite8291.Cell{Row: 0, Column: 0}, ite8291.NewColor(0x11, 0x22, 0x33))
+		lit.Set(ite8291.Cell{Row: 5, Column: 9}, ite8291.NewColor(0xdd, 0xee, 0xff))
+		lit.Set(ite8291.Cell{Row: 5, Column: 10}, ite8291.NewColor(0xdd, 0xee, 0xff))
+		contents := run.dev.bulkBuffer.Contents()
+		Ω(contents[len(contents)-len(frameBytes(lit)):]).Should(Equal(frameBytes(lit)))
+	})
+
+	It("fails on read error", func() {
+
+		run.openEventsCall.src.rtnError = errors.New("read error") //nolint:err113
+		Ω(run.execute("react", "--effect", "heat", "--layout", layout)).Should(MatchError(run.openEventsCall.src.rtnError))
+		Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 3, 5, 30, 0, 1, 0}))
+	})
+
+	It("fails to open input devices", func() {
+
+		run.openEventsCall.rtnError = errors.New("open error") //nolint:err113
+		Ω(run.execute("react", "--effect", "heat", "--layout", layout)).Should(MatchError(run.openEventsCall.rtnError))
+	})
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(append([]string{"react"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("missing effect"),
+		Entry("unknown effect", "--effect", "unknown"),
+		Entry("invalid color", "--effect", "heat", "--colors", "nocolor"),
+		Entry("invalid background", "--effect", "heat", "--background", "nocolor"),
+		Entry("zero decay", "--effect", "heat", "--decay", "0s"),
+		Entry("layout without key codes", "--effect", "heat"),
+	)
+})
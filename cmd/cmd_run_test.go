@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("run", func() {
+
+	var run *cmdRunT
+
+	// breath effect state with brightness 30 as reported by the controller
+	breathState := []byte{8, 2, 2, 5, 30, 0, 1, 0}
+
+	green, red := ite8291.NewFrame(ite8291.NewColor(0, 0xff, 0)), ite8291.NewFrame(ite8291.NewColor(0xff, 0, 0))
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.dev.ctlChangedData = [][]byte{nil, breathState}
+	})
+
+	// lastFrame returns the last frame written to the device.
+	lastFrame := func() []byte {
+		contents := run.dev.bulkBuffer.Contents()
+		return contents[len(contents)-len(frameBytes(green)):]
+	}
+
+	// lastCtlData returns data of the last control call.
+	lastCtlData := func() []byte {
+		return run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data
+	}
+
+	It("shows success and passes the output of the command through", func() {
+
+		Ω(run.execute("run", "-b", "40", "--", "sh", "-c", "sleep 0.1; echo hi")).Should(Succeed())
+
+		Ω(run.out).Should(gbytes.Say("hi\n"))
+		Ω(run.dev.ctlArgs[:2]).Should(Equal(getEffectCtlArgs()))
+		Ω(run.dev.ctlArgs[2]).Should(Equal(userModeCtlArgs(40, 0)))
+		Ω(len(run.dev.bulkBuffer.Contents())).Should(BeNumerically(">", len(frameBytes(green))))
+		Ω(lastFrame()).Should(Equal(frameBytes(green)))
+	})
+
+	It("shows failure and propagates the exit code", func() {
+
+		err := run.execute("run", "--failure", "colour.cyAN", "sh", "-c", "exit 3")
+
+		Ω(err).Should(Equal(&exitCodeError{code: 3}))
+		Ω(lastFrame()).Should(Equal(frameBytes(ite8291.NewFrame(ite8291.NewColor(0x11, 0x22, 0x33)))))
+	})
+
+	It("reports the signal killing the command", func() {
+
+		err := run.execute("run", "--", "sh", "-c", "kill -TERM $$")
+
+		Ω(err).Should(Equal(&exitCodeError{code: 143}))
+		Ω(lastFrame()).Should(Equal(frameBytes(red)))
+	})
+
+	It("passes the input to the command", func() {
+
+		run.in = strings.NewReader("ping\n")
+
+		Ω(run.execute("run", "cat")).Should(Succeed())
+		Ω(run.out).Should(gbytes.Say("ping\n"))
+	})
+
+	It("plays the outcome pattern and restores the previous effect", func() {
+
+		start := time.Now()
+		Ω(run.execute("run", "--success-pattern", "blink", "--restore", "50ms", "true")).Should(Succeed())
+		Ω(time.Since(start)).Should(BeNumerically(">=", 50*time.Millisecond))
+
+		Ω(run.dev.bulkBuffer.Contents()).Should(ContainSubstring(string(frameBytes(green))))
+		Ω(lastCtlData()).Should(Equal(breathState))
+	})
+
+	It("fails if the command cannot be started", func() {
+
+		Ω(run.execute("run", "--", "/nonexistent/command")).ShouldNot(Succeed())
+		Ω(lastCtlData()).Should(Equal(breathState))
+	})
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(append(append([]string{"run"}, args...), "true")...)).
+				Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("unknown busy effect", "--busy", "flash"),
+		Entry("invalid busy color", "--busy-colors", "nocolor"),
+		Entry("invalid success color", "--success", "nocolor"),
+		Entry("unknown failure pattern", "--failure-pattern", "flash"),
+		Entry("morse pattern", "--success-pattern", "morse"),
+		Entry("negative restore delay", "--restore", "-1s"),
+	)
+
+	It("requires command", func() {
+		Ω(run.execute("run")).ShouldNot(Succeed())
+		assertDeviceNotCalled(run.dev)
+	})
+})
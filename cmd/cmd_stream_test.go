@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("stream", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	It("shows text frames", func() {
+
+		run.in = strings.NewReader("fill colour.cyAN\n5 20 #FFF\n")
+		Ω(run.execute("stream", "-b", "12", "--save")).Should(Succeed())
+
+		second := ite8291.NewFrame(ite8291.NewColor(0x11, 0x22, 0x33))
+		second.Set(ite8291.Cell{Row: 5, Column: 20}, ite8291.NewColor(0xff, 0xff, 0xff))
+
+		Ω(run.dev.ctlArgs[0]).Should(Equal(userModeCtlArgs(12, 1)))
+		assertGetBulkWriteCall(run.dev, 2)
+		Ω(run.dev.bulkBuffer.Contents()).Should(Equal(append(
+			frameBytes(ite8291.NewFrame(ite8291.NewColor(0x11, 0x22, 0x33))), frameBytes(second)...)))
+	})
+
+	It("shows raw frames", func() {
+
+		run.in = bytes.NewReader(bytes.Repeat([]byte{0xdd, 0xee, 0xff}, ite8291.RowsNumber*ite8291.ColumnsNumber))
+		Ω(run.execute("stream", "--format", "RAW")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[0]).Should(Equal(userModeCtlArgs(params.BrightnessDefault, 0)))
+		assertGetBulkWriteCall(run.dev, 1)
+		Ω(run.dev.bulkBuffer.Contents()).Should(Equal(frameBytes(ite8291.NewFrame(ite8291.NewColor(0xdd, 0xee, 0xff)))))
+	})
+
+	It("fails on invalid format", func() {
+		Ω(run.execute("stream", "--format", "json")).Should(MatchError(params.ErrInvalidOptVal))
+		assertDeviceNotCalled(run.dev)
+	})
+
+	It("fails on invalid frame", func() {
+		run.in = strings.NewReader("fill nocolor\n")
+		Ω(run.execute("stream")).Should(MatchError(ite8291.ErrInvalidStream))
+	})
+})
@@ -20,3 +20,8 @@ func TestCmd(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Cmd Suite")
 }
+
+var _ = BeforeEach(func() {
+	// keep the frame written last out of the user cache
+	GinkgoT().Setenv("XDG_CACHE_HOME", GinkgoT().TempDir())
+})
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"slices"
 	"strconv"
@@ -49,9 +50,11 @@ var _ = Describe("cmd package", func() {
 		}
 
 		// execute the command
-		cmdErr = executeCmd(cmdArgs, cmdOut, cmdErrOut,
+		cmdErr = executeCmd(context.Background(), cmdArgs, nil, cmdOut, cmdErrOut,
 			newFindDevice(dev, findDevCall), // find device function
 			newReadConfig(readConfigCall),
+			nil, // key events are not read
+			nil, // daemon is not used
 		)
 		// close out & err streams after command execution
 		Ω(cmdErrOut.Close()).Should(Succeed())
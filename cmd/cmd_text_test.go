@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// getEffectCtlArgs returns control call arguments to retrieve current effect.
+func getEffectCtlArgs() []*ctlArgsT {
+	return []*ctlArgsT{{
+		requestType: 0x21, request: 9, value: 0x300, index: 1, data: []byte{0x88}, length: 1, timeout: 0,
+	}, {
+		requestType: 0xA1, request: 1, value: 0x300, index: 1, data: []byte{8, 0, 0, 0, 0, 0, 0, 0},
+		length: 8, timeout: 0,
+	}}
+}
+
+var _ = Describe("text-mode", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	DescribeTable("scrolls text once and restores previous effect",
+		func(state []byte, restore []byte) {
+			run.dev.ctlChangedData = [][]byte{nil, state}
+
+			Ω(run.execute("text-mode", "--text", ".", "--color", "colour.cyAN", "--background", "#001",
+				"--once", "-s", "10", "-b", "9")).Should(Succeed())
+
+			frames := ite8291.TextFrames(".", ite8291.NewColor(0x11, 0x22, 0x33), ite8291.NewColor(0, 0, 0x11), 0)
+
+			ctlArgs := append(getEffectCtlArgs(), userModeCtlArgs(9, 0))
+			var data []byte
+			for _, f := range frames {
+				for i := range ite8291.RowsNumber {
+					ctlArgs = append(ctlArgs, rowIndexCtlArgs(i))
+				}
+				data = append(data, frameBytes(f.Frame)...)
+			}
+			ctlArgs = append(ctlArgs, &ctlArgsT{
+				requestType: 0x21, request: 9, value: 0x300, index: 1, data: restore, length: 8, timeout: 0,
+			})
+
+			assertControlCall(run.dev, nil, false, ctlArgs)
+			assertGetBulkWriteCall(run.dev, len(frames))
+			Ω(run.dev.bulkBuffer.Contents()).Should(Equal(data))
+		},
+		Entry("wave effect", []byte{8, 2, 3, 5, 30, 0, 1, 1}, []byte{8, 2, 3, 5, 30, 0, 1, 0}),
+		Entry("off state", []byte{8, 1, 3, 5, 30, 0, 1, 0}, []byte{8, 1, 0, 0, 0, 0, 0, 0}),
+	)
+
+	Context("with configured text scrolled until interrupted", func() {
+
+		BeforeEach(func() {
+			run.configure(map[string]any{"mode": "text", params.TextProp: map[string]any{"message": "hello"}})
+			run.dev.ctlChangedData = [][]byte{nil, {8, 2, 0x33, 0, 20, 0, 0, 0}}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			DeferCleanup(cancel)
+			run.ctx = ctx
+		})
+
+		It("stops on interrupt and restores previous effect", func() {
+			Ω(run.execute()).Should(Succeed())
+
+			Ω(run.dev.ctlArgs[:3]).Should(Equal(append(getEffectCtlArgs(),
+				userModeCtlArgs(params.BrightnessDefault, 0))))
+			Ω(run.dev.ctlArgs[len(run.dev.ctlArgs)-1].data).Should(Equal([]byte{8, 2, 0x33, 0, 20, 0, 0, 0}))
+			Ω(run.dev.closeCallNum).Should(Equal(1))
+		})
+	})
+
+	DescribeTable("validation errors",
+		func(a []string, value, flag string) {
+			Ω(run.execute(append([]string{"text-mode"}, a...)...)).
+				Should(MatchError(SatisfyAll(ContainSubstring(value), ContainSubstring(flag))))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("missing text", nil, "text is missing", "--text"),
+		Entry("invalid color", []string{"--text", "a", "--color", "nocolor"}, `"nocolor"`, "--color"),
+		Entry("invalid background", []string{"--text", "a", "--background", "#12"}, `"#12"`, "--background"),
+		Entry("invalid speed", []string{"--text", "a", "-s", "11"}, "11", "--speed"),
+	)
+})
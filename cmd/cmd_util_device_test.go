@@ -45,6 +45,9 @@ type deviceStubT struct {
 	bulkWriteRtnErrorAtCall int
 
 	bulkBuffer *gbytes.Buffer
+
+	// onBulkWrite, if set, is called after every bulk write
+	onBulkWrite func()
 }
 
 // ControlTransfer intercepts controller ControlTransfer calls.
@@ -92,6 +95,9 @@ func (d *deviceStubT) GetBulkWrite() (ite8291.WriteFunc, error) {
 	return func(data []byte) (n int, err error) {
 		d.bulkWriteCallNum++              // increase call counter
 		n, err = d.bulkBuffer.Write(data) // write data
+		if d.onBulkWrite != nil {
+			d.onBulkWrite()
+		}
 
 		// return given d.getBulkWriteRtnError at d.getBulkWriteRtnErrorAtCall call
 		if d.bulkWriteRtnError != nil && d.bulkWriteRtnErrorAtCall == d.bulkWriteCallNum {
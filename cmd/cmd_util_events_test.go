@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"io"
+	"sync"
+
+	"github.com/v4n6/itectl/pkg/evdev"
+)
+
+// eventSourceStubT type provides source of scripted key events. Once
+// events are exhausted, it returns rtnError or, if it's nil, blocks
+// until closed.
+type eventSourceStubT struct {
+	events   []evdev.Event
+	rtnError error
+
+	// lit, if set, gives every key press once received from it,
+	// e.g. once a cell is lit
+	lit chan struct{}
+
+	once     sync.Once
+	closed   chan struct{}
+	closeNum int
+}
+
+// newEventSource returns event source stub providing presses and
+// releases of the keys with the given codes.
+func newEventSource(codes ...uint16) *eventSourceStubT {
+
+	src := &eventSourceStubT{closed: make(chan struct{})}
+	for _, code := range codes {
+		src.events = append(src.events,
+			evdev.Event{Type: evdev.EvKey, Code: code, Value: evdev.KeyPress},
+			evdev.Event{Type: evdev.EvKey, Code: code, Value: evdev.KeyRelease})
+	}
+
+	return src
+}
+
+func (s *eventSourceStubT) ReadEvent() (evdev.Event, error) {
+
+	if len(s.events) > 0 {
+		ev := s.events[0]
+		if s.lit != nil && ev.Pressed() {
+			select {
+			case <-s.lit:
+			case <-s.closed:
+				return evdev.Event{}, io.EOF
+			}
+		}
+		s.events = s.events[1:]
+		return ev, nil
+	}
+
+	if s.rtnError != nil {
+		return evdev.Event{}, s.rtnError
+	}
+
+	<-s.closed
+	return evdev.Event{}, io.EOF
+}
+
+func (s *eventSourceStubT) Close() error {
+
+	// closing is idempotent like closing of merged keyboards
+	s.once.Do(func() {
+		close(s.closed)
+		s.closeNum++
+	})
+
+	return nil
+}
+
+// openEventsCallT type collects openEvents calls.
+type openEventsCallT struct {
+	callNum  int
+	devices  []string
+	src      *eventSourceStubT
+	rtnError error
+}
+
+// newOpenEvents returns openEvents function returning source stub of the given call.
+func newOpenEvents(call *openEventsCallT) func([]string) (evdev.EventSource, error) {
+	return func(devices []string) (evdev.EventSource, error) {
+
+		call.callNum++
+		call.devices = devices
+
+		if call.rtnError != nil {
+			return nil, call.rtnError
+		}
+
+		return call.src, nil
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/gomega"
+)
+
+// cmdRunT type provides environment to execute commands against device stub.
+type cmdRunT struct {
+	dev            *deviceStubT
+	findDevCall    *findDeviceCallT
+	readConfigCall *readConfigCallT
+	openEventsCall *openEventsCallT
+	connect        connectDaemon // daemon is not used if nil
+
+	ctx         context.Context
+	in          io.Reader
+	out, errOut *gbytes.Buffer
+}
+
+// newCmdRun creates command execution environment with named colors configured.
+func newCmdRun() *cmdRunT {
+
+	run := &cmdRunT{
+		dev:            &deviceStubT{},
+		findDevCall:    &findDeviceCallT{},
+		readConfigCall: &readConfigCallT{v: viper.New()},
+		openEventsCall: &openEventsCallT{src: newEventSource()},
+		ctx:            context.Background(),
+		in:             strings.NewReader(""),
+		out:            gbytes.NewBuffer(),
+		errOut:         gbytes.NewBuffer(),
+	}
+
+	run.configure(map[string]any{"namedColors": namedColorsConfig})
+
+	return run
+}
+
+// configure merges the given configuration into viper configuration used by commands.
+func (run *cmdRunT) configure(conf map[string]any) {
+	Ω(run.readConfigCall.v.MergeConfigMap(conf)).Should(Succeed())
+}
+
+// execute executes command given by args and closes output streams.
+func (run *cmdRunT) execute(args ...string) error {
+
+	err := executeCmd(run.ctx, args, run.in, run.out, run.errOut,
+		newFindDevice(run.dev, run.findDevCall), newReadConfig(run.readConfigCall), newOpenEvents(run.openEventsCall),
+		run.connect)
+
+	Ω(run.errOut.Close()).Should(Succeed())
+	Ω(run.out.Close()).Should(Succeed())
+
+	return err
+}
+
+// userModeCtlArgs returns control call arguments to set 'user' effect.
+func userModeCtlArgs(brightness, save byte) *ctlArgsT {
+	return &ctlArgsT{
+		requestType: 0x21, request: 9, value: 0x300, index: 1,
+		data: []byte{0x8, 0x2, 0x33, 0, brightness, 0, 0, save}, length: 8, timeout: 0,
+	}
+}
+
+// rowIndexCtlArgs returns control call arguments to set row index of 'user' effect.
+func rowIndexCtlArgs(row int) *ctlArgsT {
+	return &ctlArgsT{
+		requestType: 0x21, request: 9, value: 0x300, index: 1,
+		data: []byte{0x16, 0x0, byte(row)}, length: 3, timeout: 0,
+	}
+}
+
+// arrowsZoneConfig - configuration of "arrows" zone of the arrow keys.
+var arrowsZoneConfig = map[string]any{
+	"arrows": map[string]any{
+		"keys": []any{"4,16", "5,15", "5,16", "5,17"},
+	},
+}
+
+// writtenFrames returns the frames written to the device as bulk data.
+func writtenFrames(dev *deviceStubT) [][]byte {
+
+	var frames [][]byte
+	size := len(frameBytes(&ite8291.Frame{}))
+	contents := dev.bulkBuffer.Contents()
+	for i := 0; i+size <= len(contents); i += size {
+		frames = append(frames, contents[i:i+size])
+	}
+
+	return frames
+}
+
+// frameBytes returns bulk data written to the device to set the given frame.
+func frameBytes(frame *ite8291.Frame) []byte {
+
+	var buf bytes.Buffer
+	for _, row := range frame {
+		r, g, b := make([]byte, ite8291.ColumnsNumber), make([]byte, ite8291.ColumnsNumber),
+			make([]byte, ite8291.ColumnsNumber)
+		for j, col := range row {
+			r[j], g[j], b[j] = col.Red, col.Green, col.Blue
+		}
+		buf.Write(slices.Concat([]byte{0}, b, g, r, []byte{0}))
+	}
+
+	return buf.Bytes()
+}
+
+// assertFrameModeCall asserts that device was set to 'user' effect with the given frame.
+func assertFrameModeCall(dev *deviceStubT, brightness, save byte, frame *ite8291.Frame) {
+
+	args := []*ctlArgsT{userModeCtlArgs(brightness, save)}
+	for i := range ite8291.RowsNumber {
+		args = append(args, rowIndexCtlArgs(i))
+	}
+
+	assertControlCall(dev, nil, false, args)
+	assertGetBulkWriteCall(dev, 1)
+	assertCloseCallAfter(dev, len(args), 1, ite8291.RowsNumber)
+
+	Ω(dev.bulkBuffer.Contents()).Should(Equal(frameBytes(frame)))
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// sinePCM returns raw PCM of n frames of the given channels playing
+// sine waves of the given frequencies and amplitudes at the given rate.
+func sinePCM(rate, n int, freqs, amplitudes []float64) []byte {
+
+	buf := &bytes.Buffer{}
+	for i := range n {
+		for c := range freqs {
+			s := amplitudes[c] * math.Sin(2*math.Pi*freqs[c]*float64(i)/float64(rate))
+			Ω(binary.Write(buf, binary.LittleEndian, int16(s*32767))).Should(Succeed())
+		}
+	}
+
+	return buf.Bytes()
+}
+
+var _ = Describe("visualizer-mode", func() {
+
+	var run *cmdRunT
+
+	BeforeEach(func() {
+		run = newCmdRun()
+	})
+
+	// frames returns frames written to the device.
+	frames := func() []*ite8291.Frame {
+
+		contents := run.dev.bulkBuffer.Contents()
+		size := len(frameBytes(&ite8291.Frame{}))
+		Ω(len(contents) % size).Should(BeZero())
+
+		var frames []*ite8291.Frame
+		for ; len(contents) > 0; contents = contents[size:] {
+			frame := &ite8291.Frame{}
+			for i := range ite8291.RowsNumber {
+				row := contents[i*size/ite8291.RowsNumber+1:]
+				for j := range ite8291.ColumnsNumber {
+					frame[i][j] = *ite8291.NewColor(row[2*ite8291.ColumnsNumber+j], row[ite8291.ColumnsNumber+j], row[j])
+				}
+			}
+			frames = append(frames, frame)
+		}
+
+		return frames
+	}
+
+	It("shows spectrum of the audio", func() {
+
+		// 1 kHz is in the column 11 of the spectrum
+		run.in = bytes.NewReader(sinePCM(44100, 22050, []float64{1000}, []float64{1}))
+		Ω(run.execute("visualizer-mode", "--channels", "1", "--fps", "10",
+			"--colors", "colour.cyAN", "--smoothing", "0", "-b", "12", "--save")).Should(Succeed())
+
+		Ω(run.dev.ctlArgs[0]).Should(Equal(userModeCtlArgs(12, 1)))
+		Ω(frames()).Should(HaveLen(5))
+
+		last := frames()[4]
+		black, cyan := ite8291.NewColor(0, 0, 0), ite8291.NewColor(0x11, 0x22, 0x33)
+		Ω(last.Get(ite8291.Cell{Row: 0, Column: 11})).ShouldNot(Equal(black))
+		for i := range ite8291.RowsNumber {
+			if i > 0 {
+				Ω(last.Get(ite8291.Cell{Row: i, Column: 11})).Should(Equal(cyan))
+			}
+			Ω(last.Get(ite8291.Cell{Row: i, Column: 0})).Should(Equal(black))
+			Ω(last.Get(ite8291.Cell{Row: i, Column: ite8291.ColumnsNumber - 1})).Should(Equal(black))
+		}
+	})
+
+	It("shows VU meter of the channels of the audio file", func() {
+
+		file := filepath.Join(GinkgoT().TempDir(), "audio.pcm")
+		Ω(os.WriteFile(file, sinePCM(8000, 1600, []float64{440, 440}, []float64{1, 0.001}), 0o600)).Should(Succeed())
+
+		run.configure(map[string]any{params.VisualizerProp: map[string]any{
+			"style": "VU", "file": file, "rate": 8000, "colors": []string{"#FFF"},
+		}})
+		Ω(run.execute("visualizer-mode", "--fps", "20")).Should(Succeed())
+
+		Ω(frames()).Should(HaveLen(4))
+		Ω(frames()[3]).Should(Equal(
+			ite8291.MeterFrame([]float64{1, 0}, ite8291.Gradient{ite8291.NewColor(0xff, 0xff, 0xff)})))
+	})
+
+	DescribeTable("fails on invalid options",
+		func(args ...string) {
+			Ω(run.execute(append([]string{"visualizer-mode"}, args...)...)).Should(MatchError(params.ErrInvalidOptVal))
+			assertDeviceNotCalled(run.dev)
+		},
+		Entry("unknown style", "--style", "unknown"),
+		Entry("invalid color", "--colors", "nocolor"),
+		Entry("negative smoothing", "--smoothing", "-0.1"),
+		Entry("too large smoothing", "--smoothing", "1"),
+		Entry("low rate", "--rate", "100"),
+		Entry("no channels", "--channels", "0"),
+	)
+})
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("zone-color", func() {
+
+	var run *cmdRunT
+	var cmdErr error
+	var args []string
+
+	BeforeEach(func() {
+		run = newCmdRun()
+		run.configure(map[string]any{
+			params.ZonesProp: map[string]any{
+				"arrows": map[string]any{
+					"keys": []any{"4,16", "5,15", "5,16", "5,17"},
+				},
+				"frow": map[string]any{
+					"rects": []any{"0,12-0,1"},
+				},
+				"broken": map[string]any{
+					"keys": []any{"6,1"},
+				},
+			},
+		})
+	})
+
+	JustBeforeEach(func() {
+		cmdErr = run.execute(append([]string{"zone-color"}, args...)...)
+	})
+
+	Context("with single zone", func() {
+
+		BeforeEach(func() {
+			args = []string{"--zone", "arrows", "--color-name", "colour.cyAN", "-b", "30"}
+		})
+
+		It("colors zone keys on top of the default base color", func() {
+			Ω(cmdErr).Should(Succeed())
+
+			frame := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+			cyan := ite8291.NewColor(0x11, 0x22, 0x33)
+			for _, c := range [][2]int{{4, 16}, {5, 15}, {5, 16}, {5, 17}} {
+				frame[c[0]][c[1]] = *cyan
+			}
+
+			assertFrameModeCall(run.dev, 30, 0, frame)
+		})
+	})
+
+	Context("with several zones and base color", func() {
+
+		BeforeEach(func() {
+			args = []string{"--zone", "arrows,FROW", "--rgb", "#F00", "--base-color", "123-yes", "--save"}
+		})
+
+		It("colors keys of all zones", func() {
+			Ω(cmdErr).Should(Succeed())
+
+			frame := ite8291.NewFrame(ite8291.NewColor(0xdd, 0xee, 0xff))
+			red := ite8291.NewColor(0xff, 0, 0)
+			for _, c := range [][2]int{{4, 16}, {5, 15}, {5, 16}, {5, 17}} {
+				frame[c[0]][c[1]] = *red
+			}
+			for j := 1; j <= 12; j++ {
+				frame[0][j] = *red
+			}
+
+			assertFrameModeCall(run.dev, params.BrightnessDefault, 1, frame)
+		})
+	})
+
+	Context("with invalid zone", func() {
+
+		BeforeEach(func() {
+			args = []string{"--zone", "broken", "--rgb", "#F00"}
+		})
+
+		It("fails and reports the error", func() {
+			Ω(cmdErr).Should(MatchError(ContainSubstring(`"6,1"`)))
+			assertDeviceNotCalled(run.dev)
+		})
+	})
+
+	Context("with unknown zone", func() {
+
+		BeforeEach(func() {
+			args = []string{"--zone", "wasd", "--rgb", "#F00"}
+		})
+
+		It("fails and reports the error", func() {
+			Ω(cmdErr).Should(MatchError(SatisfyAll(ContainSubstring(`"wasd"`), ContainSubstring(`"arrows"`))))
+			assertDeviceNotCalled(run.dev)
+		})
+	})
+
+	Context("with invalid base color", func() {
+
+		BeforeEach(func() {
+			args = []string{"--zone", "arrows", "--rgb", "#F00", "--base-color", "nocolor"}
+		})
+
+		It("fails and reports the error", func() {
+			Ω(cmdErr).Should(MatchError(ContainSubstring("--base-color")))
+			assertDeviceNotCalled(run.dev)
+		})
+	})
+})
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/daemon"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// daemonDescription - daemon command description.
+const daemonDescription = "Keep the device open and serve it to other commands and clients."
+
+// errNotAllowed error indicates that a client requested a command or a
+// flag it's not allowed to apply.
+var errNotAllowed = errors.New("not allowed")
+
+// appliedCommands - names of the commands the clients are allowed to
+// apply via the daemon, D-Bus service and MQTT bridge.
+var appliedCommands = []string{
+	"aurora-mode", "breath-mode", "fireworks-mode", "gradient-mode", "marquee-mode", "off-mode",
+	"rainbow-mode", "raindrop-mode", "random-mode", "ripple-mode", "single-color-mode", "text-mode",
+	"wave-mode", "set-brightness",
+}
+
+// deniedFlags - names of the flags the clients are not allowed to give
+// to the applied commands since they access files or devices.
+var deniedFlags = []string{
+	params.ConfigFileFlag, params.DaemonSocketFlag, params.DirectFlag, params.DeviceBusFlag,
+	params.DeviceAddressFlag, params.PollIntervalFlag, params.PollTimeoutFlag, params.PreviewFlag,
+	params.PreviewFileFlag, params.LayoutProp,
+}
+
+// connectDaemon type provides a function that connects to the daemon
+// listening on the given socket. It returns nil device if the daemon
+// is not running.
+type connectDaemon func(socket string) (ite8291.Device, error)
+
+// dialDaemon connects to the daemon listening on the given socket.
+func dialDaemon(socket string) (ite8291.Device, error) {
+
+	c, err := daemon.Dial(socket)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+		return nil, nil // daemon is not running
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// newDaemonCmd creates, initializes and returns command to serve the
+// device over a Unix socket. find is used to obtain the device,
+// readConf and open are used by the commands applied via the daemon.
+func newDaemonCmd(v *viper.Viper, find findDevice, readConf readConfig, open openEvents) *cobra.Command {
+
+	var socketMode func() (os.FileMode, int)
+
+	var daemonCmd = &cobra.Command{
+		Use:   "daemon",
+		Short: daemonDescription,
+		Long: fmt.Sprintf(`Keep the device open and serve it to other commands and clients.
+
+The daemon listens on the Unix socket "(--%s)" for JSON-RPC 2.0 requests, one per line:
+  %-14s apply mode command or set-brightness given by its arguments, e.g. {"args": ["wave-mode", "-s", "5"]};
+                 the flags selecting configuration, layout, preview or device files are refused
+  %-14s set brightness, e.g. {"brightness": 30}
+  %-14s set predefined colors by their numbers, e.g. {"colors": {"1": "#FF0000"}}
+  %-14s show colors of the keys by rows in 'user' effect, e.g. {"frame": [["#FF0000", ...], ...]}
+  %-14s return the current state
+  %-14s return the current state and notify about its changes via %q notifications
+The other commands of itectl access the device via the daemon while it's running unless
+requested otherwise "(--%s)".
+
+The socket is accessible only by the user running the daemon unless the group "(--%s)" is
+given; the members of the group can access it then. The peers are checked by their
+credentials: root, the user running the daemon, the members of the group and the users
+listed by %q configuration property are allowed.
+
+The configuration files are watched; colors and users are taken from the changed
+configuration and the commands applied via the daemon read it anew.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[12]s:
+       socket: /run/itectl.sock
+       group: wheel
+       users: [alice]`,
+			params.DaemonSocketFlag, daemon.ApplyMethod, daemon.SetBrightnessMethod, daemon.SetColorsMethod,
+			daemon.PushFrameMethod, daemon.StateMethod, daemon.SubscribeMethod, daemon.StateChangedMethod,
+			params.DirectFlag, params.DaemonGroupFlag, params.DaemonProp+".users", params.DaemonProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			pollInterval, pollTimeout, err := params.Polls(v)
+			if err != nil {
+				return err
+			}
+
+			useDev, devBus, devAddr, err := params.Device(v)
+			if err != nil {
+				return err
+			}
+
+			perm, gid := socketMode()
+			l, err := daemon.Listen(params.DaemonSocket(v), perm, gid)
+			if err != nil {
+				return err
+			}
+
+			dev, err := find(useDev, devBus, devAddr, pollInterval, pollTimeout)
+			if err != nil {
+				_ = l.Close()
+				return err
+			}
+			defer dev.Close()
+
+			cfgFile := cmd.Flag(params.ConfigFileFlag).Value.String()
+
+			var config atomic.Pointer[viper.Viper] // the configuration read last
+			config.Store(v)
+			params.WatchConfig(cmd, cfgFile, func(changed *viper.Viper) {
+				config.Store(changed)
+				fmt.Fprintln(cmd.ErrOrStderr(), "Configuration reloaded")
+			})
+
+			server := daemon.NewServer(dev)
+			server.ParseColor = func(s string) (*ite8291.Color, error) {
+				return params.ColorValue(config.Load(), s)
+			}
+			server.Authorize = func(cred *daemon.Credentials) error {
+				return authorizePeer(config.Load(), gid, cred)
+			}
+
+			server.Apply = applyCmd(server.Device(), cfgFile, readConf, open)
+
+			return server.Serve(cmd.Context(), l)
+		},
+	}
+
+	socketMode = params.AddDaemon(daemonCmd, v)
+
+	return daemonCmd
+}
+
+// applyCmd returns function executing itectl commands on the given
+// shared device. The commands read the given configuration file, if
+// it's not empty; readConf and open are used by them. Only the
+// commands and flags allowed by checkApplied are executed.
+func applyCmd(shared ite8291.Device, cfgFile string, readConf readConfig,
+	open openEvents) func(ctx context.Context, args []string, out io.Writer) error {
+
+	return func(ctx context.Context, args []string, out io.Writer) error {
+
+		if err := checkApplied(args); err != nil {
+			return err
+		}
+
+		if len(cfgFile) > 0 {
+			args = slices.Concat([]string{"--" + params.ConfigFileFlag, cfgFile}, args)
+		}
+
+		return executeCmd(ctx, args, strings.NewReader(""), out, out,
+			func(bool, int, int, time.Duration, time.Duration) (ite8291.Device, error) { return shared, nil },
+			readConf, open, nil)
+	}
+}
+
+// checkApplied returns errNotAllowed unless the command given by args
+// is one of appliedCommands and none of its flags is one of
+// deniedFlags. The clients are served with rights of the daemon, so
+// they must not run arbitrary commands or access files or devices.
+func checkApplied(args []string) error {
+
+	if len(args) == 0 || !slices.Contains(appliedCommands, args[0]) {
+		return fmt.Errorf("command %q is %w; expected one of %q", strings.Join(args[:min(1, len(args))], ""),
+			errNotAllowed, appliedCommands)
+	}
+
+	for _, arg := range args[1:] {
+		name, _, _ := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if strings.HasPrefix(arg, "--") && slices.Contains(deniedFlags, name) {
+			return fmt.Errorf("flag %q of %q command is %w", "--"+name, args[0], errNotAllowed)
+		}
+	}
+
+	return nil
+}
+
+// authorizePeer returns error unless the peer with the given
+// credentials is root, the user running the daemon, a member of the
+// group given by gid or one of the users allowed by the configuration.
+func authorizePeer(v *viper.Viper, gid int, cred *daemon.Credentials) error {
+
+	if cred.UID == 0 || int(cred.UID) == os.Getuid() {
+		return nil
+	}
+
+	u, err := user.LookupId(strconv.FormatUint(uint64(cred.UID), 10))
+	if err == nil && slices.Contains(params.DaemonUsers(v), u.Username) {
+		return nil
+	}
+
+	if gid >= 0 {
+		if int(cred.GID) == gid {
+			return nil
+		}
+
+		if u != nil {
+			if groups, err := u.GroupIds(); err == nil && slices.Contains(groups, strconv.Itoa(gid)) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("user %d is not allowed", cred.UID)
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/dbusapi"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// dbusDescription - dbus command description.
+const dbusDescription = "Provide D-Bus service to control keyboard backlight."
+
+// modeSuffix - suffix of the names of the commands setting modes.
+const modeSuffix = "-mode"
+
+// newDbusCmd creates, initializes and returns command to provide the
+// keyboard backlight as a D-Bus object. openDev is used to obtain the
+// device, readConf and open are used by the modes applied via the
+// service.
+func newDbusCmd(v *viper.Viper, openDev func() (ite8291.Device, error), readConf readConfig,
+	open openEvents) *cobra.Command {
+
+	var dbusCmd = &cobra.Command{
+		Use:   "dbus",
+		Short: dbusDescription,
+		Long: fmt.Sprintf(`Provide D-Bus service to control keyboard backlight.
+
+The service owns %q name on the session bus or, if requested "(--%s)", on the system bus.
+Its object %q implements %q interface:
+  properties:
+    %-11s b    whether the keyboard backlight is on
+    %-11s s    mode of the keyboard backlight, e.g. "wave"; setting it applies the mode
+    %-11s y    brightness; setting it changes brightness
+    %-11s y    speed of the effect
+    %-11s as   predefined colors; setting them changes the colors
+    %-11s aas  colors of the keys by rows shown via the service; setting them shows them
+  methods:
+    SetMode(s mode, as args) -> s   apply the mode with the arguments of its command, e.g.
+                                    SetMode("wave", ["-s", "5"]), and return its output;
+                                    the modes and flags reading files are refused
+    Stop()                          stop the mode that is still running
+    SetColor(y num, s color)        set predefined color of the given number
+    SetFrame(aas frame)             show colors of the keys by rows in 'user' effect
+The properties emit PropertiesChanged signals. The state changed by other programs is read
+periodically "(--%s)".
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[13]s:
+       system: true
+       refresh: 5s`,
+			dbusapi.BusName, params.DbusSystemFlag, dbusapi.ObjectPath, dbusapi.Interface,
+			dbusapi.OnProp, dbusapi.ModeProp, dbusapi.BrightnessProp, dbusapi.SpeedProp,
+			dbusapi.ColorsProp, dbusapi.FrameProp, params.DbusRefreshFlag, params.DbusProp, params.DbusProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			colors := make([]*ite8291.Color, ite8291.CustomColorNumMaxValue-ite8291.CustomColorNumMinValue+1)
+			for i := range colors {
+				var err error
+				if colors[i], err = params.PredefinedColor(v, i+ite8291.CustomColorNumMinValue); err != nil {
+					return err
+				}
+			}
+
+			connectBus := dbus.ConnectSessionBus
+			if params.DbusSystem(v) {
+				connectBus = dbus.ConnectSystemBus
+			}
+
+			conn, err := connectBus()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			dev, err := openDev()
+			if err != nil {
+				return err
+			}
+			defer dev.Close()
+
+			service := dbusapi.NewService(dev)
+			service.Modes = modeNames(cmd.Root())
+			service.ModeName = modeName
+			service.ParseColor = func(s string) (*ite8291.Color, error) {
+				return params.ColorValue(v, s)
+			}
+			service.Colors = colors
+			service.Apply = applyCmd(service.Device(), cmd.Flag(params.ConfigFileFlag).Value.String(), readConf, open)
+
+			return service.Serve(cmd.Context(), conn, params.DbusRefresh(v))
+		},
+	}
+
+	params.AddDbus(dbusCmd, v)
+
+	return dbusCmd
+}
+
+// modeNames returns names of the modes set by the sub-commands of the
+// given root command that clients are allowed to apply, e.g. "wave" of
+// "wave-mode".
+func modeNames(rootCmd *cobra.Command) []string {
+
+	var modes []string
+	for _, c := range rootCmd.Commands() {
+		if name, found := strings.CutSuffix(c.Name(), modeSuffix); found && slices.Contains(appliedCommands, c.Name()) {
+			modes = append(modes, name)
+		}
+	}
+	slices.Sort(modes)
+
+	return modes
+}
+
+// modeName returns name of the mode of the given state; "user" is
+// returned for colors of the keys set individually.
+func modeName(state *ite8291.EffectState) string {
+
+	switch {
+	case state.Control == ite8291.OffState:
+		return "off"
+	case state.Effect == ite8291.UserEffect:
+		return "user"
+	}
+
+	if mode, found := effectModes[state.Effect]; found {
+		return strings.TrimSuffix(mode, modeSuffix)
+	}
+
+	return fmt.Sprintf("effect %#x", state.Effect)
+}
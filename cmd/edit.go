@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/editor"
+	"github.com/v4n6/itectl/pkg/ite8291"
+	"golang.org/x/term"
+)
+
+// editDescription - edit command description.
+const editDescription = "Edit colors of the keyboard backlight keys interactively."
+
+// newEditCmd creates, initializes and returns command to edit colors
+// of the keyboard backlight keys interactively.
+func newEditCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var file func() string
+	var layout func() *ite8291.Layout
+
+	var editCmd = &cobra.Command{
+		Use:   "edit",
+		Short: editDescription,
+		Long: fmt.Sprintf(`Edit colors of the keyboard backlight keys interactively.
+
+The editor shows the keyboard layout "(--%s)" in the terminal and the edited colors live
+on the keyboard backlight. It starts with the colors of the keymap file "(--%s)" if it exists.
+The keymap is saved to the same file.
+
+Keys:
+  arrows, h j k l     move cursor to the nearest key in the given direction
+  space               select/unselect the key under cursor
+  v                   start/finish selection of the keys in the rectangle between two keys
+  a, esc              select all keys, clear selection
+  enter, p            paint the selected keys (or the key under cursor) with the current color
+  g                   grab color of the key under cursor as the current color
+  #                   enter the current color as an RGB value or a name of a configured color
+  n                   pick the next color configured via %q configuration property
+  c                   adjust the current color by hue, saturation and value sliders
+  s                   save the keymap
+  q, ctrl-c           quit`,
+			params.LayoutProp, params.KeymapProp, params.NamedColorsProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			frame, err := params.KeymapFrame(v, file(), layout(), true)
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				if err := ctl.SetUserMode(params.Brightness(v), false); err != nil {
+					return err
+				}
+
+				ed := editor.New(layout(), frame)
+				ed.Title = fmt.Sprintf("itectl edit - %s", file())
+				ed.NamedColors = params.NamedColors(v)
+				ed.ParseColor = func(val string) (*ite8291.Color, error) { return params.ColorValue(v, val) }
+				ed.Apply = ctl.WriteFrame
+				ed.Save = func(frame *ite8291.Frame) error { return params.WriteKeymap(file(), frame, layout()) }
+
+				in := cmd.InOrStdin()
+				if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+					state, err := term.MakeRaw(int(f.Fd()))
+					if err != nil {
+						return err
+					}
+					defer func() { _ = term.Restore(int(f.Fd()), state) }()
+				}
+
+				return ed.Run(cmd.Context(), in, cmd.OutOrStdout())
+			})
+		},
+	}
+
+	file = params.AddKeymap(editCmd, v)
+	layout = params.AddLayout(editCmd, v)
+	params.AddBrightness(editCmd, v)
+	params.AddReset(editCmd, v)
+
+	return editCmd
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// lastFrameFile returns path of the file keeping the frame written
+// last to the keyboard backlight. The frame cannot be read back from
+// the controller, so it is kept between the commands as a hint to
+// crossfade from it. It's never restored since the keys may have been
+// changed by other means since.
+func lastFrameFile() (string, error) {
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "itectl", "frame"), nil
+}
+
+// loadLastFrame returns the frame written last to the keyboard
+// backlight by itectl or nil if it is not kept.
+func loadLastFrame() *ite8291.Frame {
+
+	name, err := lastFrameFile()
+	if err != nil {
+		return nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	r, err := ite8291.NewFrameReader(f, ite8291.StreamRaw, nil)
+	if err != nil {
+		return nil
+	}
+
+	frame := &ite8291.Frame{}
+	if err := r.ReadFrame(frame); err != nil {
+		return nil
+	}
+
+	return frame
+}
+
+// storeLastFrame keeps the given frame written last to the keyboard
+// backlight in the raw frame stream format. The file is replaced
+// atomically, so concurrent commands never read a partial frame.
+func storeLastFrame(frame *ite8291.Frame) error {
+
+	name, err := lastFrameFile()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	data := make([]byte, 0, ite8291.RawFrameSize)
+	for i := range ite8291.RowsNumber {
+		for j := range ite8291.ColumnsNumber {
+			data = append(data, frame[i][j].Red, frame[i][j].Green, frame[i][j].Blue)
+		}
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err = errors.Join(err, f.Close()); err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+
+	return err
+}
+
+// fadeOut makes the keyboard backlight in the given state black during
+// the transition. The frame hinted to be shown in 'user' effect is
+// crossfaded to black; other effects are dimmed.
+func fadeOut(ctx context.Context, ctl *ite8291.Controller, state *ite8291.EffectState, t ite8291.Transition) error {
+
+	if state.Control == ite8291.OffState {
+		return nil
+	}
+
+	if last := ctl.FrameHint(); state.Effect == ite8291.UserEffect && last != nil {
+		return ite8291.Crossfade(ctx, ctl, last, ite8291.NewFrame(ite8291.NewColor(0, 0, 0)), t)
+	}
+
+	return ite8291.FadeBrightness(ctx, ctl, state.Brightness, 0, t)
+}
+
+// halves splits the transition into two consecutive transitions of
+// half the duration.
+func halves(t ite8291.Transition) (first, second ite8291.Transition) {
+
+	first, second = t, t
+	first.Duration /= 2
+	second.Duration -= first.Duration
+
+	return first, second
+}
+
+// fadeToFrame sets the keyboard backlight to 'user' effect with the
+// given brightness showing the given frame during the transition. The
+// frame hinted to be shown in 'user' effect is crossfaded to the given
+// one along with the brightness. Otherwise the current effect fades
+// out and the frame fades in from black.
+func fadeToFrame(ctx context.Context, ctl *ite8291.Controller, t ite8291.Transition, brightness byte,
+	frame *ite8291.Frame, save bool) error {
+
+	if t.Duration <= 0 {
+		return ctl.SetFrameMode(brightness, frame, save)
+	}
+
+	state, err := ctl.Effect()
+	if err != nil {
+		return err
+	}
+
+	if last := ctl.FrameHint(); state.Control != ite8291.OffState && state.Effect == ite8291.UserEffect &&
+		last != nil {
+
+		if err := ctl.SetUserMode(state.Brightness, save); err != nil {
+			return err
+		}
+
+		shownFrame, shownBrightness := *last, state.Brightness
+		return t.Run(ctx, func(progress float64) error {
+
+			if f := last.Mix(frame, progress); *f != shownFrame {
+				if err := ctl.WriteFrame(f); err != nil {
+					return err
+				}
+				shownFrame = *f
+			}
+
+			b := byte(float64(state.Brightness) + (float64(brightness)-float64(state.Brightness))*progress + 0.5)
+			if b == shownBrightness {
+				return nil
+			}
+			shownBrightness = b
+
+			return ctl.SetBrightness(b)
+		})
+	}
+
+	out, in := halves(t)
+	if state.Control == ite8291.OffState {
+		in = t
+	} else if err := fadeOut(ctx, ctl, state, out); err != nil {
+		return err
+	}
+
+	black := ite8291.NewFrame(ite8291.NewColor(0, 0, 0))
+	if err := ctl.SetFrameMode(brightness, black, save); err != nil {
+		return err
+	}
+
+	return ite8291.Crossfade(ctx, ctl, black, frame, in)
+}
+
+// fadeToEffect sets the keyboard backlight to the built-in effect set
+// by the given set function with the given brightness during the
+// transition. The current effect fades out and the new one fades in by
+// its brightness. If the effect is saved, it is set once again at the
+// end of the transition to save its brightness.
+func fadeToEffect(ctx context.Context, ctl *ite8291.Controller, t ite8291.Transition, brightness byte, save bool,
+	set func(brightness byte, save bool) error) error {
+
+	if t.Duration <= 0 {
+		return set(brightness, save)
+	}
+
+	state, err := ctl.Effect()
+	if err != nil {
+		return err
+	}
+
+	out, in := halves(t)
+	if state.Control == ite8291.OffState {
+		in = t
+	} else if err := fadeOut(ctx, ctl, state, out); err != nil {
+		return err
+	}
+
+	if err := set(0, false); err != nil {
+		return err
+	}
+
+	if err := ite8291.FadeBrightness(ctx, ctl, 0, brightness, in); err != nil {
+		return err
+	}
+
+	if save {
+		return set(brightness, save)
+	}
+
+	return nil
+}
+
+// fadeToOff turns the keyboard backlight off after the current effect
+// fades out during the transition.
+func fadeToOff(ctx context.Context, ctl *ite8291.Controller, t ite8291.Transition) error {
+
+	if t.Duration > 0 {
+		state, err := ctl.Effect()
+		if err != nil {
+			return err
+		}
+
+		if err := fadeOut(ctx, ctl, state, t); err != nil {
+			return err
+		}
+	}
+
+	return ctl.SetOffMode()
+}
+
+// fadeToBrightness ramps brightness of the keyboard backlight to the
+// given one during the transition.
+func fadeToBrightness(ctx context.Context, ctl *ite8291.Controller, t ite8291.Transition, brightness byte) error {
+
+	if t.Duration <= 0 {
+		return ctl.SetBrightness(brightness)
+	}
+
+	from, err := ctl.Brightness()
+	if err != nil {
+		return err
+	}
+
+	return ite8291.FadeBrightness(ctx, ctl, from, brightness, t)
+}
@@ -14,6 +14,8 @@ const fireworksModeDescription = "Set keyboard backlight to 'fireworks' mode."
 // to set keyboard backlight to 'fireworks' mode.
 func newFireworksModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	fireworksModeCmd := &cobra.Command{
 		Use:           "fireworks-mode",
 		Short:         fireworksModeDescription,
@@ -23,8 +25,10 @@ func newFireworksModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetFireworksMode(params.Speed(v), params.Brightness(v),
-					params.ColorNum(v), params.Reactive(v), params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetFireworksMode(params.Speed(v), brightness, params.ColorNum(v), params.Reactive(v), save)
+					})
 			})
 		},
 	}
@@ -35,6 +39,8 @@ func newFireworksModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	params.AddReactive(fireworksModeCmd, v)
 	params.AddSave(fireworksModeCmd, v)
 	params.AddReset(fireworksModeCmd, v)
+	params.AddFor(fireworksModeCmd)
+	transition = params.AddFade(fireworksModeCmd, v)
 
 	return fireworksModeCmd
 }
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// gradientModeDescription - gradient-mode command description.
+const gradientModeDescription = "Set keyboard backlight to 'gradient' mode."
+
+// newGradientModeCmd creates, initializes and returns command to set
+// keyboard backlight to 'gradient' mode.
+func newGradientModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var gradient func() ite8291.Gradient
+	var direction func() ite8291.GradientDirection
+	var layout func() *ite8291.Layout
+	var transition func() ite8291.Transition
+
+	var gradientModeCmd = &cobra.Command{
+		Use:   "gradient-mode",
+		Short: gradientModeDescription,
+		Long: fmt.Sprintf(`Set keyboard backlight to 'gradient' mode.
+
+In the 'gradient' mode colors of the keys smoothly change from the first color "(--%s)"
+through optional intermediate colors "(--%s)" to the last color "(--%s)".
+The colors can be given either by names of the colors configured via %q configuration property
+or by RGB values in a one of the following formats %q.
+The colors are interpolated in the perceptual Oklab color space.
+
+The gradient direction "(-%s, --%s)" is one of "right", "left", "up", "down",
+a diagonal combination of two of them, e.g. "up-right", or "radial" - from the center
+of the keyboard to its edges. Positions of the keys are given by the keyboard layout "(--%s)".
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[9]s:
+       from: azure
+       via: ["#FFFFFF"]
+       to: "#FF00FF"
+       direction: radial`,
+			params.GradientFromFlag, params.GradientViaFlag, params.GradientToFlag,
+			params.NamedColorsProp, ite8291.SupportedColorStringFormats,
+			params.DirectionShortFlag, params.DirectionProp, params.LayoutProp,
+			params.GradientProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return call(cmd, func(ctl *ite8291.Controller) error {
+				return fadeToFrame(cmd.Context(), ctl, transition(), params.Brightness(v),
+					ite8291.GradientFrame(gradient(), layout(), direction()), params.Save(v))
+			})
+		},
+	}
+
+	gradient, direction = params.AddGradient(gradientModeCmd, v)
+	layout = params.AddLayout(gradientModeCmd, v)
+	params.AddBrightness(gradientModeCmd, v)
+	params.AddSave(gradientModeCmd, v)
+	params.AddReset(gradientModeCmd, v)
+	params.AddFor(gradientModeCmd)
+	transition = params.AddFade(gradientModeCmd, v)
+
+	return gradientModeCmd
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/idle"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// idleDescription - idle command description.
+const idleDescription = "Dim or turn off the keyboard backlight while the keyboard is idle."
+
+// newIdleCmd creates, initializes and returns command to apply the
+// idle policy to the keyboard backlight. open is used to read key
+// events.
+func newIdleCmd(v *viper.Viper, call ite8291Ctl, open openEvents) *cobra.Command {
+
+	var action func() string
+	var excluded func() []idle.Period
+	var transition func() ite8291.Transition
+	var layout func() *ite8291.Layout
+
+	var idleCmd = &cobra.Command{
+		Use:   "idle",
+		Short: idleDescription,
+		Long: fmt.Sprintf(`Dim or turn off the keyboard backlight while the keyboard is idle.
+
+The keyboard becomes idle when no key is pressed for the given time "(--%s)". The idle
+keyboard backlight is handled by one of the following actions "(--%s)":
+  %-9s  brightness fades to the given level "(--%s)"
+  %-9s  the keyboard backlight fades out and turns off
+  %-9s  the software effect "(--%s)" plays with the given brightness "(--%s)"
+The next key press restores the keyboard backlight. The fades take the given time "(--%s)".
+The keyboard does not become idle in the given periods of the day "(--%s)", e.g. 22:00-07:00.
+
+Key presses are read from the given input devices "(--%s)" or, if none is given, from all
+keyboards. Reading input devices usually requires root privileges or membership in the
+'input' group.
+
+The policy applies until interrupted. Afterwards the keyboard backlight is restored.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[13]s:
+       timeout: 30s
+       action: off
+       exclude: ["09:00-17:00"]`,
+			params.IdleTimeoutFlag, params.IdleActionFlag, params.IdleActionDim, params.IdleBrightnessFlag,
+			params.IdleActionOff, params.IdleActionAnimation, params.IdleAnimationFlag, params.IdleBrightnessFlag,
+			params.FadeFlag, params.IdleExcludeFlag, params.InputDeviceFlag, params.IdleProp, params.IdleProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			var anim ite8291.Animation
+			if action() == params.IdleActionAnimation {
+				var err error
+				if anim, err = ite8291.NewAnimation(params.IdleAnimation(v), ite8291.AnimationOptions{
+					Period: params.AnimationPeriod(v), Layout: layout(),
+				}); err != nil {
+					return err
+				}
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				src, err := open(params.InputDevices(v))
+				if err != nil {
+					return err
+				}
+				defer src.Close()
+
+				var state *ite8291.EffectState // state of the keyboard backlight before it became idle
+				var last *ite8291.Frame
+
+				policy := &idle.Policy{Timeout: params.IdleTimeout(v), Excluded: excluded()}
+				policy.Idle = func(ctx context.Context) error {
+
+					var err error
+					last = ctl.LastFrame()
+					if state, err = ctl.Effect(); err != nil || state.Control == ite8291.OffState {
+						state = nil // nothing to restore
+						return err
+					}
+
+					return idleAction(ctx, ctl, v, action(), transition(), anim, state)
+				}
+				policy.Wake = func() error {
+
+					if state == nil {
+						return nil
+					}
+
+					t := transition()
+					if cmd.Context().Err() != nil {
+						t.Duration = 0 // stopped; restore at once
+					}
+
+					return wakeAction(cmd.Context(), ctl, action(), t, state, last)
+				}
+
+				return policy.Run(cmd.Context(), src)
+			})
+		},
+	}
+
+	action, excluded = params.AddIdle(idleCmd, v)
+	transition = params.AddFade(idleCmd, v)
+	params.AddFPS(idleCmd, v)
+	layout = params.AddLayout(idleCmd, v)
+	params.AddInputDevices(idleCmd, v)
+
+	return idleCmd
+}
+
+// idleAction applies the given action to the keyboard backlight in
+// the given state when the keyboard becomes idle. The animation plays
+// till ctx is done.
+func idleAction(ctx context.Context, ctl *ite8291.Controller, v *viper.Viper, action string,
+	t ite8291.Transition, anim ite8291.Animation, state *ite8291.EffectState) error {
+
+	switch action {
+	case params.IdleActionOff:
+		return fadeToOff(ctx, ctl, t)
+
+	case params.IdleActionAnimation:
+		if err := ctl.SetUserMode(params.IdleBrightness(v), false); err != nil {
+			return err
+		}
+		return ite8291.Animate(ctx, ctl, anim, params.FPS(v))
+	}
+
+	if brightness := params.IdleBrightness(v); brightness < state.Brightness {
+		return fadeToBrightness(ctx, ctl, t, brightness)
+	}
+
+	return nil
+}
+
+// wakeAction restores the keyboard backlight to the given state and
+// frame of 'user' effect after the given action was applied to it.
+func wakeAction(ctx context.Context, ctl *ite8291.Controller, action string, t ite8291.Transition,
+	state *ite8291.EffectState, last *ite8291.Frame) error {
+
+	switch action {
+	case params.IdleActionOff:
+		if state.Effect == ite8291.UserEffect && last != nil {
+			return fadeToFrame(ctx, ctl, t, state.Brightness, last, false)
+		}
+
+		return fadeToEffect(ctx, ctl, t, state.Brightness, false, func(brightness byte, _ bool) error {
+			restored := *state
+			restored.Brightness = brightness
+			return ctl.RestoreEffect(&restored)
+		})
+
+	case params.IdleActionAnimation:
+		return revertState(ctl, state, last)
+	}
+
+	return fadeToBrightness(ctx, ctl, t, state.Brightness)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	_ "image/jpeg" // register JPEG format
+	_ "image/png"  // register PNG format
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// imageModeDescription - image-mode command description.
+const imageModeDescription = "Show an image on the keyboard backlight."
+
+// readImageFrames reads image from the given file and scales it to
+// the keyboard. It returns frames of animated GIF images together with
+// number of loops to play them (negative number means forever).
+func readImageFrames(file string, layout *ite8291.Layout,
+	sampling ite8291.Sampling) (frames []ite8291.TimedFrame, loops int, err error) {
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading image %q: %w", file, err)
+	}
+
+	if format == "gif" {
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, 0, fmt.Errorf("error reading image %q: %w", file, err)
+		}
+
+		switch {
+		case g.LoopCount == 0:
+			loops = -1 // forever
+		case g.LoopCount < 0:
+			loops = 1 // once
+		default:
+			loops = g.LoopCount + 1
+		}
+
+		return ite8291.GIFFrames(g, layout, sampling), loops, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading image %q: %w", file, err)
+	}
+
+	return []ite8291.TimedFrame{{Frame: ite8291.ImageFrame(img, layout, sampling)}}, 1, nil
+}
+
+// newImageModeCmd creates, initializes and returns command to show an
+// image on the keyboard backlight.
+func newImageModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var file func() string
+	var layout func() *ite8291.Layout
+	var sampling func() ite8291.Sampling
+	var transition func() ite8291.Transition
+
+	var imageModeCmd = &cobra.Command{
+		Use:   "image-mode",
+		Short: imageModeDescription,
+		Long: fmt.Sprintf(`Show an image on the keyboard backlight.
+
+The image is given by PNG, JPEG or GIF file "(--%s)". It is scaled to the keyboard
+matrix using one of the following sampling methods "(--%s)":
+  nearest - color of the image pixel at the center of every key of the keyboard matrix
+  average - average color of the image pixels covered by every key of the keyboard matrix
+  layout  - average color of the image pixels covered by every key of the keyboard layout "(--%s)",
+            i.e. it respects real positions and sizes of the keys.
+
+Animated GIF images are played frame-by-frame with their embedded delays
+as many times as specified by the image. Playing stops on interrupt.`,
+			params.ImageFileFlag, params.SamplingProp, params.LayoutProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			frames, loops, err := readImageFrames(file(), layout(), sampling())
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				// the first frame is faded in, the rest are played
+				var err error
+				if t := transition(); t.Duration > 0 {
+					err = fadeToFrame(cmd.Context(), ctl, t, params.Brightness(v), frames[0].Frame, params.Save(v))
+				} else {
+					err = ctl.SetUserMode(params.Brightness(v), params.Save(v))
+				}
+				if err != nil {
+					return err
+				}
+
+				return ite8291.PlayFrames(cmd.Context(), ctl, frames, loops)
+			})
+		},
+	}
+
+	file = params.AddImageFile(imageModeCmd, v)
+	sampling = params.AddSampling(imageModeCmd, v)
+	layout = params.AddLayout(imageModeCmd, v)
+	params.AddBrightness(imageModeCmd, v)
+	params.AddSave(imageModeCmd, v)
+	params.AddReset(imageModeCmd, v)
+	params.AddFor(imageModeCmd)
+	transition = params.AddFade(imageModeCmd, v)
+
+	return imageModeCmd
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// keymapModeDescription - keymap-mode command description.
+const keymapModeDescription = "Set colors of the keyboard backlight keys from a keymap file."
+
+// newKeymapModeCmd creates, initializes and returns command to set
+// colors of the keyboard backlight keys from a keymap file.
+func newKeymapModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var file func() string
+	var layout func() *ite8291.Layout
+	var transition func() ite8291.Transition
+
+	var keymapModeCmd = &cobra.Command{
+		Use:   "keymap-mode",
+		Short: keymapModeDescription,
+		Long: fmt.Sprintf(`Set colors of the keyboard backlight keys from a keymap file.
+
+The keymap file "(--%s)" is a YAML file providing colors of the keys given by their names
+in the keyboard layout "(--%s)". Keys missing from the keymap are set to its base color or black.
+The colors can be given either by names of the colors configured via %q configuration property
+or by RGB values in a one of the following formats %q.
+e.g.
+  base: "#000000"
+  keys:
+    esc: red
+    space: "#00FF00"
+
+Keymap files can be created interactively by edit command.`,
+			params.KeymapProp, params.LayoutProp, params.NamedColorsProp, ite8291.SupportedColorStringFormats),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			frame, err := params.KeymapFrame(v, file(), layout(), false)
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+				return fadeToFrame(cmd.Context(), ctl, transition(), params.Brightness(v), frame, params.Save(v))
+			})
+		},
+	}
+
+	file = params.AddKeymap(keymapModeCmd, v)
+	layout = params.AddLayout(keymapModeCmd, v)
+	params.AddBrightness(keymapModeCmd, v)
+	params.AddSave(keymapModeCmd, v)
+	params.AddReset(keymapModeCmd, v)
+	params.AddFor(keymapModeCmd)
+	transition = params.AddFade(keymapModeCmd, v)
+
+	return keymapModeCmd
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// lineFrameReader reads lines and renders them into frames. Empty
+// lines are ignored.
+type lineFrameReader struct {
+	scanner *bufio.Scanner
+	render  func(frame *ite8291.Frame, line string) (changed bool, err error)
+}
+
+// newLineFrameReader returns reader of the lines of r rendered into
+// frames by render. render returns false if the frame is not changed
+// by the line and is not to be shown again.
+func newLineFrameReader(r io.Reader,
+	render func(frame *ite8291.Frame, line string) (changed bool, err error)) *lineFrameReader {
+
+	return &lineFrameReader{scanner: bufio.NewScanner(r), render: render}
+}
+
+// ReadFrame reads lines until one of them changes frame.
+func (r *lineFrameReader) ReadFrame(frame *ite8291.Frame) error {
+
+	for r.scanner.Scan() {
+
+		line := strings.TrimSpace(r.scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		changed, err := r.render(frame, line)
+		if err != nil || changed {
+			return err
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/evdev"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// mapKeysDescription - map-keys command description.
+const mapKeysDescription = "Map keys of the keyboard to the cells of the keyboard backlight matrix."
+
+// newMapKeysCmd creates, initializes and returns command to produce
+// keyboard layout by mapping pressed keys to the cells of the keyboard
+// backlight matrix. open is used to read key events.
+func newMapKeysCmd(v *viper.Viper, call ite8291Ctl, open openEvents) *cobra.Command {
+
+	var wait func() time.Duration
+	var output func() string
+
+	var mapKeysCmd = &cobra.Command{
+		Use:   "map-keys",
+		Short: mapKeysDescription,
+		Long: fmt.Sprintf(`Map keys of the keyboard to the cells of the keyboard backlight matrix.
+
+The cells of the keyboard backlight matrix are lit one by one. Press the key that lights up
+to assign it to the lit cell. If no key is pressed in the given time "(--%s)", the cell is
+considered to have no key. Key presses are read from the given input devices "(--%s)"
+or, if none is given, from all keyboards. Reading input devices usually requires root
+privileges or membership in the 'input' group.
+
+The produced layout "(--%s)" lists the keys with their names, key codes and cells
+placed on a grid of regular keys, and the cells without a key. Adjust key positions
+and sizes in the file and use it via %q configuration property. Keys lighting several
+cells are listed once per cell with a "#N" suffix appended to the name of the key.
+Afterwards the previous effect of the keyboard backlight is restored.`,
+			params.MapKeysWaitFlag, params.InputDeviceFlag, params.MapKeysOutputFlag, params.LayoutProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			var layout *ite8291.Layout
+
+			err := call(cmd, func(ctl *ite8291.Controller) error {
+
+				src, err := open(params.InputDevices(v))
+				if err != nil {
+					return err
+				}
+				defer src.Close()
+
+				return ctl.WithUserMode(params.Brightness(v), func() (err error) {
+					layout, err = mapKeys(cmd.Context(), ctl, src, wait(), cmd.ErrOrStderr())
+					return err
+				})
+			})
+			if err != nil {
+				return err
+			}
+
+			return writeLayout(layout, output(), cmd.OutOrStdout())
+		},
+	}
+
+	wait, output = params.AddMapKeys(mapKeysCmd, v)
+	params.AddInputDevices(mapKeysCmd, v)
+	params.AddBrightness(mapKeysCmd, v)
+	params.AddReset(mapKeysCmd, v)
+
+	return mapKeysCmd
+}
+
+// mapKeys lights the cells of the keyboard matrix one by one and
+// assigns them keys pressed by user. A cell is considered empty if no
+// key is pressed within wait. Prompts are written to out.
+func mapKeys(ctx context.Context, ctl *ite8291.Controller, src evdev.EventSource, wait time.Duration,
+	out io.Writer) (*ite8291.Layout, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys, errs := evdev.KeyPresses(ctx, src)
+
+	layout := &ite8291.Layout{Name: "mapped"}
+	seen := map[string]int{} // number of cells lit by a key
+	black, white := ite8291.NewColor(0, 0, 0), ite8291.NewColor(0xff, 0xff, 0xff)
+
+	for i := range ite8291.RowsNumber {
+		for j := range ite8291.ColumnsNumber {
+
+			cell := ite8291.Cell{Row: i, Column: j}
+
+			drainKeys(keys) // keys pressed before the cell is lit are not its keys
+
+			frame := ite8291.NewFrame(black)
+			frame.Set(cell, white)
+			if err := ctl.WriteFrame(frame); err != nil {
+				return nil, err
+			}
+
+			fmt.Fprintf(out, "row %d, column %d: ", i, j)
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				fmt.Fprintln(out)
+				return nil, ctx.Err()
+			case code, ok := <-keys:
+				timer.Stop()
+				if !ok {
+					fmt.Fprintln(out)
+					return nil, fmt.Errorf("reading key events: %w", <-errs)
+				}
+
+				name := evdev.KeyName(code)
+				if seen[name]++; seen[name] > 1 {
+					name = fmt.Sprintf("%s#%d", name, seen[name])
+				}
+
+				layout.Keys = append(layout.Keys, ite8291.Key{
+					Name: name, Code: code, Row: i, Column: j,
+					X: float64(j), Y: float64(i), Width: 1, Height: 1,
+				})
+				fmt.Fprintf(out, "%s (%d)\n", name, code)
+			case <-timer.C:
+				layout.Empty = append(layout.Empty, cell)
+				fmt.Fprintln(out, "no key")
+			}
+		}
+	}
+
+	return layout, nil
+}
+
+// drainKeys discards the key presses pending on keys. The end of keys
+// is left to their next receive.
+func drainKeys(keys <-chan uint16) {
+
+	for {
+		select {
+		case _, ok := <-keys:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// writeLayout writes the given layout to the given file or, if file is
+// empty, to out.
+func writeLayout(layout *ite8291.Layout, file string, out io.Writer) error {
+
+	if len(file) == 0 {
+		return layout.Write(out)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := layout.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -14,6 +14,8 @@ const marqueeModeDescription = "Set keyboard backlight to 'marquee' mode."
 // to set keyboard backlight to 'marquee' mode.
 func newMarqueeModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	var marqueeModeCmd = &cobra.Command{
 		Use:           "marquee-mode",
 		Short:         marqueeModeDescription,
@@ -23,8 +25,10 @@ func newMarqueeModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetMarqueeMode(params.Speed(v), params.Brightness(v),
-					params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetMarqueeMode(params.Speed(v), brightness, save)
+					})
 			})
 		},
 	}
@@ -33,6 +37,8 @@ func newMarqueeModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	params.AddBrightness(marqueeModeCmd, v)
 	params.AddSave(marqueeModeCmd, v)
 	params.AddReset(marqueeModeCmd, v)
+	params.AddFor(marqueeModeCmd)
+	transition = params.AddFade(marqueeModeCmd, v)
 
 	return marqueeModeCmd
 }
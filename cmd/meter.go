@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// meterDescription - meter command description.
+const meterDescription = "Show numbers read from the standard input as colors on the keyboard backlight."
+
+// newMeterCmd creates, initializes and returns command to show
+// numbers read from the standard input as colors of the keyboard
+// backlight.
+func newMeterCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var meter func() *ite8291.Meter
+	var cells func() []ite8291.Cell
+
+	var meterCmd = &cobra.Command{
+		Use:   "meter",
+		Short: meterDescription,
+		Long: fmt.Sprintf(`Show numbers read from the standard input as colors on the keyboard backlight.
+
+Every line of the standard input holds a number, e.g. a queue depth or a temperature.
+The numbers between the min "(--%s)" and the max "(--%s)" values are mapped to the colors
+of the ramp "(--%s)" from its first to its last color; the numbers out of the range are
+clipped. The colors are given either by names of the colors configured via %q configuration
+property or by RGB values in a one of the following formats %q.
+
+The numbers are smoothed "(--%s)": 0 shows them as they are, values closer to 1 follow them
+slower. The color changes only if the smoothed number moves from the number shown last
+at least by the hysteresis "(--%s)".
+
+The color lights the whole keyboard or the keys of the given zone "(--%s)" configured via
+%q configuration property. The other keys are turned off.
+The numbers are shown until the end of the input or an interrupt.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[11]s:
+       min: 40
+       max: 90
+       colors: [blue, green, red]
+       smoothing: 0.5
+       hysteresis: 2`,
+			params.MeterMinFlag, params.MeterMaxFlag, params.MeterColorsFlag, params.NamedColorsProp,
+			ite8291.SupportedColorStringFormats, params.MeterSmoothingFlag, params.MeterHysteresisFlag,
+			params.MeterZoneFlag, params.ZonesProp, params.MeterProp, params.MeterProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				overlays := newOverlays(ite8291.NewColor(0, 0, 0))
+
+				m, level := meter(), ite8291.NewLayer(cmd.Name(), "level")
+				level.Z, level.Mask = 1, cells() // the whole keyboard without zone
+				r := newLineFrameReader(cmd.InOrStdin(), func(frame *ite8291.Frame, line string) (bool, error) {
+
+					value, err := params.ParseMeterValue(line)
+					if err != nil {
+						return false, err
+					}
+
+					color, changed := m.Update(value)
+					level.Fill(color, 1)
+					if err := overlays.Set(level); err != nil {
+						return false, err
+					}
+					*frame = *overlays.Compose()
+
+					return changed, nil
+				})
+
+				if err := ctl.SetUserMode(params.Brightness(v), false); err != nil {
+					return err
+				}
+
+				return ite8291.Stream(cmd.Context(), ctl, r)
+			})
+		},
+	}
+
+	meter, cells = params.AddMeter(meterCmd, v)
+	params.AddBrightness(meterCmd, v)
+
+	return meterCmd
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+	"github.com/v4n6/itectl/pkg/mqttbridge"
+)
+
+// mqttDescription - mqtt command description.
+const mqttDescription = "Expose keyboard backlight as Home Assistant light over MQTT."
+
+// mqttTimeout - timeout of connecting to MQTT broker and publishing.
+const mqttTimeout = 10 * time.Second
+
+// newMQTTCmd creates, initializes and returns command to expose the
+// keyboard backlight as Home Assistant light via MQTT broker. openDev
+// is used to obtain the device, readConf and open are used by the
+// effects applied via the bridge.
+func newMQTTCmd(v *viper.Viper, openDev func() (ite8291.Device, error), readConf readConfig,
+	open openEvents) *cobra.Command {
+
+	var mqttCmd = &cobra.Command{
+		Use:   "mqtt",
+		Short: mqttDescription,
+		Long: fmt.Sprintf(`Expose keyboard backlight as Home Assistant light over MQTT.
+
+The command connects to MQTT broker "(--%s)" and publishes Home Assistant discovery
+configuration of the light to "<%s>/light/<%s>/config". The light uses JSON schema:
+  itectl/<id>/state         state of the light, e.g.
+                            {"state": "ON", "brightness": 25, "color_mode": "rgb",
+                             "color": {"r": 255, "g": 0, "b": 0}, "effect": "wave"}
+  itectl/<id>/set           commands in the same form; omitted attributes are not changed
+  itectl/<id>/availability  "online" or "offline"
+Setting the color shows it on all keys; the effects are the built-in modes applied with
+their configured attributes. The state changed by other programs is read periodically
+"(--%s)".
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[6]s:
+       broker: tcp://homeassistant.local:1883
+       username: itectl
+       password: secret
+       id: laptop`,
+			params.MQTTBrokerFlag, params.MQTTDiscoveryPrefixFlag, params.MQTTIDFlag, params.MQTTRefreshFlag,
+			params.MQTTProp, params.MQTTProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			dev, err := openDev()
+			if err != nil {
+				return err
+			}
+			defer dev.Close()
+
+			bridge := mqttbridge.NewBridge(dev, params.MQTTID(v))
+			bridge.DiscoveryPrefix = params.MQTTDiscoveryPrefix(v)
+			bridge.Effects = effectNames()
+			bridge.EffectName = func(state *ite8291.EffectState) string {
+				if mode, found := effectModes[state.Effect]; found {
+					return strings.TrimSuffix(mode, modeSuffix)
+				}
+				return ""
+			}
+			bridge.OnError = func(err error) {
+				fmt.Fprintln(cmd.ErrOrStderr(), err)
+			}
+
+			apply := applyCmd(bridge.Device(), cmd.Flag(params.ConfigFileFlag).Value.String(), readConf, open)
+			bridge.ApplyEffect = func(ctx context.Context, effect string, brightness byte) error {
+				var out strings.Builder
+				return apply(ctx, []string{effect + modeSuffix, "--" + params.BrightnessProp,
+					strconv.Itoa(int(brightness))}, &out)
+			}
+
+			if bridge.Version, err = ite8291.NewController(bridge.Device()).FirmwareVersion(); err != nil {
+				return err
+			}
+
+			username, password := params.MQTTCredentials(v)
+			client, err := mqttbridge.Dial(&mqttbridge.Options{Broker: params.MQTTBroker(v),
+				ClientID: "itectl-" + bridge.ID, Username: username, Password: password,
+				WillTopic: bridge.AvailabilityTopic(), WillPayload: mqttbridge.Offline, Timeout: mqttTimeout})
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			return bridge.Run(cmd.Context(), client, params.MQTTRefresh(v))
+		},
+	}
+
+	params.AddMQTT(mqttCmd, v)
+
+	return mqttCmd
+}
+
+// effectNames returns names of the built-in effects, e.g. "wave".
+func effectNames() []string {
+
+	names := make([]string, 0, len(effectModes))
+	for _, mode := range effectModes {
+		names = append(names, strings.TrimSuffix(mode, modeSuffix))
+	}
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// notifyDescription - notify command description.
+const notifyDescription = "Flash a notification pattern and restore the keyboard backlight."
+
+// newNotifyCmd creates, initializes and returns command to flash a
+// notification pattern on the keyboard backlight.
+func newNotifyCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var pattern func() ite8291.Animation
+
+	var notifyCmd = &cobra.Command{
+		Use:   "notify",
+		Short: notifyDescription,
+		Long: fmt.Sprintf(`Flash a notification pattern and restore the keyboard backlight.
+
+The keys are lit by the given color in one of the following patterns "(--%s)":
+  %-6s  all keys are lit for the first half of the period
+  %-6s  all keys are smoothly lit and dimmed
+  %-6s  a bar sweeps over the keys from the left to the right
+  %-6s  all keys send the given text "(--%s)" in morse code
+The pattern is repeated the given number of times "(--%s)". One blink, pulse or sweep
+lasts the given period "(--%s)"; a morse dot lasts a fifth of it.
+
+The color is given either by a name "(--%s)" of the color configured via %q
+configuration property, by an RGB string "(--%s)" in a one of the following formats
+%q or by a combination of (--%s, --%s, --%s) flags.
+
+Afterwards the previous effect and its brightness are restored. The controller does not
+report the colors of the keys of 'user' effect, so the keys are left as the pattern ends.
+The predefined colors are not changed. Interrupting the pattern restores the keyboard
+backlight at once.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[17]s:
+       pattern: morse
+       count: 2
+       text: SOS`,
+			params.NotifyPatternFlag, ite8291.NotifyBlink, ite8291.NotifyPulse, ite8291.NotifySweep,
+			ite8291.NotifyMorse, params.NotifyTextFlag, params.NotifyCountFlag, params.NotifyPeriodFlag,
+			params.ColorNameFlag, params.NamedColorsProp, params.ColorRGBFlag, ite8291.SupportedColorStringFormats,
+			params.ColorRedFlag, params.ColorGreenFlag, params.ColorBlueFlag, params.NotifyProp, params.NotifyProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				last := ctl.LastFrame()
+				state, err := ctl.Effect()
+				if err != nil {
+					return err
+				}
+
+				notification := ite8291.NewLayer(cmd.Name(), "pattern")
+				notification.Z = 1
+				flash, err := newOverlays(ite8291.NewColor(0, 0, 0)).Overlay(notification, pattern())
+				if err != nil {
+					return err
+				}
+
+				if err := ctl.SetUserMode(params.Brightness(v), false); err != nil {
+					return err
+				}
+
+				return errors.Join(
+					ite8291.Animate(cmd.Context(), ctl, flash, params.FPS(v)),
+					revertState(ctl, state, last))
+			})
+		},
+	}
+
+	color := params.AddColor(notifyCmd, v)
+	pattern = params.AddNotify(notifyCmd, v, color)
+	params.AddFPS(notifyCmd, v)
+	params.AddBrightness(notifyCmd, v)
+
+	return notifyCmd
+}
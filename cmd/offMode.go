@@ -14,6 +14,8 @@ const offModeDescription = "Turn the keyboard backlight off."
 // to set keyboard backlight off.
 func newOffModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	offModeCmd := &cobra.Command{
 		Use:           "off-mode",
 		Short:         offModeDescription,
@@ -23,12 +25,14 @@ func newOffModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetOffMode()
+				return fadeToOff(cmd.Context(), ctl, transition())
 			})
 		},
 	}
 
 	params.AddReset(offModeCmd, v)
+	params.AddFor(offModeCmd)
+	transition = params.AddFade(offModeCmd, v)
 
 	return offModeCmd
 }
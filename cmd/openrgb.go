@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+	"github.com/v4n6/itectl/pkg/openrgb"
+)
+
+// openRGBServerDescription - openrgb-server command description.
+const openRGBServerDescription = "Serve keyboard backlight to OpenRGB clients."
+
+// newOpenRGBServerCmd creates, initializes and returns command to
+// serve the keyboard backlight over OpenRGB network SDK protocol.
+// openDev is used to obtain the device.
+func newOpenRGBServerCmd(v *viper.Viper, openDev func() (ite8291.Device, error)) *cobra.Command {
+
+	var layout func() *ite8291.Layout
+
+	var openRGBServerCmd = &cobra.Command{
+		Use:   "openrgb-server",
+		Short: openRGBServerDescription,
+		Long: fmt.Sprintf(`Serve keyboard backlight to OpenRGB clients.
+
+The server implements OpenRGB network SDK protocol (up to version %d) on the TCP address "(--%s)".
+The keyboard backlight is presented as a keyboard device with a single matrix zone whose LEDs
+are the keys of the configured layout "(--%s)". Its modes are Direct showing colors of the
+LEDs in 'user' effect, Off and the built-in effects. Colors of the effects are the predefined
+colors; a color that is not predefined replaces one of them.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[4]s:
+       address: 0.0.0.0:6742`,
+			openrgb.ProtocolVersion, params.OpenRGBAddressFlag, params.LayoutProp, params.OpenRGBProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			var err error
+			colors := make([]*ite8291.Color, ite8291.CustomColorNumMaxValue-ite8291.CustomColorNumMinValue+1)
+			for i := range colors {
+				if colors[i], err = params.PredefinedColor(v, i+ite8291.CustomColorNumMinValue); err != nil {
+					return err
+				}
+			}
+
+			l, err := net.Listen("tcp", params.OpenRGBAddress(v))
+			if err != nil {
+				return err
+			}
+
+			dev, err := openDev()
+			if err != nil {
+				_ = l.Close()
+				return err
+			}
+			defer dev.Close()
+
+			server := openrgb.NewServer(dev, layout())
+			server.Colors = colors
+			if server.Version, err = ite8291.NewController(server.Device()).FirmwareVersion(); err != nil {
+				_ = l.Close()
+				return err
+			}
+
+			return server.Serve(cmd.Context(), l)
+		},
+	}
+
+	params.AddOpenRGB(openRGBServerCmd, v)
+	layout = params.AddLayout(openRGBServerCmd, v)
+
+	return openRGBServerCmd
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// paletteCycleDescription - palette-cycle command description.
+const paletteCycleDescription = "Cycle the predefined colors used by the built-in effects."
+
+// newPaletteCycleCmd creates, initializes and returns command to cycle
+// the predefined colors of the keyboard backlight.
+func newPaletteCycleCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var cycle func() ite8291.PaletteCycle
+
+	var paletteCycleCmd = &cobra.Command{
+		Use:   "palette-cycle",
+		Short: paletteCycleDescription,
+		Long: fmt.Sprintf(`Cycle the predefined colors used by the built-in effects.
+
+The built-in effect of the keyboard backlight e.g. breath-mode, ripple-mode or raindrop-mode
+keeps running while its predefined colors %d-%d change smoothly in one of the following
+cycles "(--%s)" lasting the given period "(--%s)":
+  %-6s  colors of the first palette move over the predefined colors one at a time
+  %-6s  hues of the colors of the first palette shift around the color wheel
+  %-6s  the palettes follow each other
+
+Palettes "(--%s)" are lists of colors filling the predefined colors repeatedly. They
+default to the single palette of the configured predefined colors. Colors are either names
+of the colors configured via %q configuration property or RGB values in a one of the
+following formats %q.
+
+The predefined colors are updated with the given rate "(--%s)"; only the changed colors
+are sent to the controller.
+
+The cycle runs until interrupted. Afterwards the predefined colors are reset to their
+configured values.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[12]s:
+       cycle: step
+       palettes:
+         - [red, orange]
+         - [blue, cyan, white]
+       period: 30s`,
+			ite8291.CustomColorNumMinValue, ite8291.CustomColorNumMaxValue, params.PaletteCycleFlag,
+			params.PalettePeriodFlag, ite8291.PaletteRotate, ite8291.PaletteHue, ite8291.PaletteStep,
+			params.PaletteFlag, params.NamedColorsProp, ite8291.SupportedColorStringFormats,
+			params.AnimationFPSFlag, params.PaletteProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			colors := make([]*ite8291.Color, ite8291.PaletteSize)
+			for i := range colors {
+				var err error
+				if colors[i], err = params.PredefinedColor(v, i+1); err != nil {
+					return err
+				}
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+				return errors.Join(
+					ite8291.CyclePalette(cmd.Context(), ctl, cycle(), params.FPS(v)),
+					ctl.SetColors(colors))
+			})
+		},
+	}
+
+	cycle = params.AddPaletteCycle(paletteCycleCmd, v)
+	params.AddFPS(paletteCycleCmd, v)
+
+	return paletteCycleCmd
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// playDescription - play command description.
+const playDescription = "Play a keyframe animation on the keyboard backlight."
+
+// newPlayCmd creates, initializes and returns command to play keyframe
+// animations on the keyboard backlight.
+func newPlayCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var layout func() *ite8291.Layout
+
+	var playCmd = &cobra.Command{
+		Use:     "play [file]",
+		Aliases: []string{"play-mode"},
+		Short:   playDescription,
+		Long: fmt.Sprintf(`Play a keyframe animation on the keyboard backlight.
+
+The animation is given by a YAML or JSON file provided either as the argument or via %q
+configuration property. Every frame of the animation is shown for its duration and,
+unless its easing is "none", transitions into the next frame meanwhile. The easing is
+one of %q; it defaults to the easing of the animation or "none". Colors of the keys of
+a frame are given by layers applied in the following order: the base color, the gradient,
+the colors of the configured zones given by their names and the colors of the keys given
+by their names in the keyboard layout "(--%s)". Colors are either names of the colors
+configured via %q configuration property or RGB values in a one of the following
+formats %q. e.g.
+  easing: ease-in-out
+  frames:
+    - duration: 1s
+      gradient: {colors: [red, blue], direction: up-right}
+    - duration: 500ms
+      base: "#000"
+      zones: {arrows: white}
+      keys: {esc: red}
+
+The animation is played once or, if requested "(--%s)", repeatedly until interrupted.
+It is shown frame by frame with the given frame rate "(--%s)". Playing stops on interrupt.
+The command is also available as "play-mode" to be used as the default mode.`,
+			params.PlayProp+".file", ite8291.EasingNames(), params.LayoutProp, params.NamedColorsProp,
+			ite8291.SupportedColorStringFormats, params.PlayLoopFlag, params.AnimationFPSFlag),
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			file, err := params.PlayFile(v, args)
+			if err != nil {
+				return err
+			}
+
+			anim, err := params.KeyframeAnimation(v, file, layout(), params.PlayLoop(v))
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				if err := ctl.SetUserMode(params.Brightness(v), params.Save(v)); err != nil {
+					return err
+				}
+
+				return ite8291.Animate(cmd.Context(), ctl, anim, params.FPS(v))
+			})
+		},
+	}
+
+	params.AddPlayLoop(playCmd, v)
+	params.AddFPS(playCmd, v)
+	layout = params.AddLayout(playCmd, v)
+	params.AddBrightness(playCmd, v)
+	params.AddSave(playCmd, v)
+	params.AddReset(playCmd, v)
+	params.AddFor(playCmd)
+
+	return playCmd
+}
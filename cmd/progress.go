@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// progressDescription - progress command description.
+const progressDescription = "Show a progress bar on the keyboard backlight."
+
+// newProgressCmd creates, initializes and returns command to show a
+// progress bar on the keyboard backlight.
+func newProgressCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var cells func() []ite8291.Cell
+	var fill, background func() *ite8291.Color
+	var stdin func() bool
+
+	var progressCmd = &cobra.Command{
+		Use:   "progress [percentage]",
+		Short: progressDescription,
+		Long: fmt.Sprintf(`Show a progress bar on the keyboard backlight.
+
+The percentage is given either as the argument e.g. "42" or "42%%" or, if requested
+"(--%s)", by the lines read from the standard input until its end or an interrupt;
+every line updates the bar. The bar grows from the left to the right over the given
+row "(--%s)" of the keyboard matrix or over the keys of the given zone "(--%s)"
+configured via %q configuration property. Keys of a zone in the same column fill
+from the top.
+
+The filled part of the bar is lit by the fill color "(--%s)", the rest by the background
+color "(--%s)". The colors are given either by names of the colors configured via %q
+configuration property or by RGB values in a one of the following formats %q.
+The other keys are set to the background color.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[10]s:
+       zone: frow
+       fill: cyan`,
+			params.ProgressStdinFlag, params.ProgressRowFlag, params.ProgressZoneFlag, params.ZonesProp,
+			params.ProgressFillFlag, params.ProgressBackgroundFlag, params.NamedColorsProp,
+			ite8291.SupportedColorStringFormats, params.ProgressProp, params.ProgressProp),
+		Args:          cobra.MaximumNArgs(1),
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if stdin() {
+				if len(args) > 0 {
+					return fmt.Errorf("%w %q: percentage is read from the standard input",
+						params.ErrInvalidOptVal, args[0])
+				}
+
+				return call(cmd, func(ctl *ite8291.Controller) error {
+
+					overlays := newOverlays(background())
+
+					r := newLineFrameReader(cmd.InOrStdin(), func(frame *ite8291.Frame, line string) (bool, error) {
+
+						progress, err := params.ParseProgress(line)
+						if err != nil {
+							return false, err
+						}
+
+						if err := overlays.Set(progressLayer(cmd.Name(), cells(), progress, fill(),
+							background())); err != nil {
+							return false, err
+						}
+						*frame = *overlays.Compose()
+
+						return true, nil
+					})
+
+					if err := ctl.SetUserMode(params.Brightness(v), false); err != nil {
+						return err
+					}
+
+					return ite8291.Stream(cmd.Context(), ctl, r)
+				})
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("%w percentage is missing (either given as the argument or read via %q)",
+					params.ErrInvalidOptVal, "--"+params.ProgressStdinFlag)
+			}
+
+			progress, err := params.ParseProgress(args[0])
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				overlays := newOverlays(background())
+
+				if err := overlays.Set(progressLayer(cmd.Name(), cells(), progress, fill(),
+					background())); err != nil {
+					return err
+				}
+
+				return ctl.SetFrameMode(params.Brightness(v), overlays.Compose(), false)
+			})
+		},
+	}
+
+	cells, fill, background, stdin = params.AddProgress(progressCmd, v)
+	params.AddBrightness(progressCmd, v)
+
+	return progressCmd
+}
+
+// source and name of the base layer of the overlays drawn by the
+// commands.
+const (
+	baseLayerSource = "keyboard"
+	baseLayerName   = "background"
+)
+
+// newOverlays returns compositor with the base layer filled with the
+// given color. Commands draw their overlays above it as layers of
+// their own source, i.e. of their name.
+func newOverlays(color *ite8291.Color) *ite8291.Compositor {
+
+	base := ite8291.NewLayer(baseLayerSource, baseLayerName)
+	base.Fill(color, 1)
+
+	overlays := ite8291.NewCompositor()
+	_ = overlays.Set(base) // the layer has the default blend mode
+
+	return overlays
+}
+
+// progressLayer returns the layer of the given source drawing progress
+// bar over the given cells (see ite8291.Frame.SetProgress).
+func progressLayer(source string, cells []ite8291.Cell, progress float64,
+	fill, background *ite8291.Color) *ite8291.Layer {
+
+	frame := ite8291.NewFrame(background)
+	frame.SetProgress(cells, progress, fill, background)
+
+	bar := ite8291.NewLayer(source, "bar")
+	bar.Z, bar.Mask = 1, cells
+	bar.SetFrame(frame, 1)
+
+	return bar
+}
@@ -14,6 +14,8 @@ const rainbowModeDescription = "Set keyboard backlight to 'rainbow' mode."
 // to set keyboard backlight to 'rainbow' mode.
 func newRainbowModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	var rainbowModeCmd = &cobra.Command{
 		Use:           "rainbow-mode",
 		Short:         rainbowModeDescription,
@@ -23,7 +25,10 @@ func newRainbowModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetRainbowMode(params.Brightness(v), params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetRainbowMode(brightness, save)
+					})
 			})
 		},
 	}
@@ -31,6 +36,8 @@ func newRainbowModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	params.AddBrightness(rainbowModeCmd, v)
 	params.AddSave(rainbowModeCmd, v)
 	params.AddReset(rainbowModeCmd, v)
+	params.AddFor(rainbowModeCmd)
+	transition = params.AddFade(rainbowModeCmd, v)
 
 	return rainbowModeCmd
 }
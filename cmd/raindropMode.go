@@ -14,6 +14,8 @@ const raindropModeDescription = "Set keyboard backlight to 'raindrop' mode."
 // to set keyboard backlight to 'raindrop' mode.
 func newRaindropModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	var raindropModeCmd = &cobra.Command{
 		Use:           "raindrop-mode",
 		Short:         raindropModeDescription,
@@ -23,8 +25,10 @@ func newRaindropModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetRaindropMode(params.Speed(v), params.Brightness(v),
-					params.ColorNum(v), params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetRaindropMode(params.Speed(v), brightness, params.ColorNum(v), save)
+					})
 			})
 		},
 	}
@@ -34,6 +38,8 @@ func newRaindropModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	params.AddColorNum(raindropModeCmd, v)
 	params.AddSave(raindropModeCmd, v)
 	params.AddReset(raindropModeCmd, v)
+	params.AddFor(raindropModeCmd)
+	transition = params.AddFade(raindropModeCmd, v)
 
 	return raindropModeCmd
 }
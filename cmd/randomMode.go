@@ -14,6 +14,8 @@ const randomModeDescription = "Set keyboard backlight to 'random' mode."
 // to set keyboard backlight to 'random' mode.
 func newRandomModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	var randomModeCmd = &cobra.Command{
 		Use:           "random-mode",
 		Short:         randomModeDescription,
@@ -23,8 +25,10 @@ func newRandomModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetRandomMode(params.Speed(v), params.Brightness(v),
-					params.ColorNum(v), params.Reactive(v), params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetRandomMode(params.Speed(v), brightness, params.ColorNum(v), params.Reactive(v), save)
+					})
 			})
 		},
 	}
@@ -35,6 +39,8 @@ func newRandomModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	params.AddReactive(randomModeCmd, v)
 	params.AddSave(randomModeCmd, v)
 	params.AddReset(randomModeCmd, v)
+	params.AddFor(randomModeCmd)
+	transition = params.AddFade(randomModeCmd, v)
 
 	return randomModeCmd
 }
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/evdev"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// reactDescription - react command description.
+const reactDescription = "Play a software effect reacting to key presses on the keyboard backlight."
+
+// newReactCmd creates, initializes and returns command to play
+// software effects reacting to key presses on the keyboard backlight.
+// open is used to read key events.
+func newReactCmd(v *viper.Viper, call ite8291Ctl, open openEvents) *cobra.Command {
+
+	var effect func() string
+	var options func() ite8291.ReactiveOptions
+	var layout func() *ite8291.Layout
+
+	var reactCmd = &cobra.Command{
+		Use:   "react",
+		Short: reactDescription,
+		Long: fmt.Sprintf(`Play a software effect reacting to key presses on the keyboard backlight.
+
+The software effect "(--%s)" lights keys pressed on the keyboard. The effects are:
+  trail    pressed keys light up and fade out leaving a trail
+  splash   pressed keys splash their color over the keys around
+  ripple   rings of the color spread from pressed keys over the keys of the layout
+  heat     keys warm up with every press and cool down over time
+
+Presses get the colors "(--%s)" one after another or distinct hues if no colors are given.
+The heat effect shows heat of the keys by the colors from cold to hot keys. The keys not
+lit by presses have the background color "(--%s)". Presses fade out in the given time
+"(--%s)". The colors are either names of the colors configured via %q configuration
+property or RGB values in a one of the following formats %q.
+
+Key presses are read from the given input devices "(--%s)" or, if none is given, from all
+keyboards, and mapped to the keys of the keyboard layout "(--%s)" by their key codes or
+names, e.g. the layout produced by map-keys command. The keys of the default layout have
+neither, so the command fails without such a layout. Reading input devices usually
+requires root privileges or membership in the 'input' group.
+
+The effect plays until interrupted. Afterwards the previous effect of the keyboard
+backlight is restored.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[10]s:
+       effect: ripple
+       colors: [cyan, magenta]
+       decay: 1.5s`,
+			params.ReactEffectFlag, params.ReactColorsFlag, params.ReactBackgroundFlag, params.ReactDecayFlag,
+			params.NamedColorsProp, ite8291.SupportedColorStringFormats, params.InputDeviceFlag,
+			params.LayoutProp, params.ReactProp, params.ReactProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			opts := options()
+			opts.Layout = layout()
+			if !mapsKeyPresses(opts.Layout) {
+				return fmt.Errorf("%w %q layout for %q: its keys have neither key codes nor key names; "+
+					"create the layout by map-keys command", params.ErrInvalidOptVal, opts.Layout.Name,
+					"--"+params.LayoutProp)
+			}
+
+			anim, err := ite8291.NewReactiveAnimation(effect(), opts)
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				src, err := open(params.InputDevices(v))
+				if err != nil {
+					return err
+				}
+				defer src.Close()
+
+				return ctl.WithUserMode(params.Brightness(v), func() error {
+					return react(cmd.Context(), ctl, src, anim, opts.Layout, params.FPS(v))
+				})
+			})
+		},
+	}
+
+	effect, options = params.AddReact(reactCmd, v)
+	params.AddFPS(reactCmd, v)
+	layout = params.AddLayout(reactCmd, v)
+	params.AddInputDevices(reactCmd, v)
+	params.AddBrightness(reactCmd, v)
+	params.AddReset(reactCmd, v)
+
+	return reactCmd
+}
+
+// react plays the given reactive animation with the given frame rate
+// and reports to it keys of the layout pressed on the keyboard until
+// ctx is done.
+func react(ctx context.Context, w ite8291.FrameWriter, src evdev.EventSource, anim *ite8291.ReactiveAnimation,
+	layout *ite8291.Layout, fps int) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- ite8291.Animate(ctx, w, anim, fps) }()
+
+	keys, errs := evdev.KeyPresses(ctx, src)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case code, ok := <-keys:
+			if ok {
+				anim.Press(pressedKeys(layout, code)...)
+				continue
+			}
+
+			err := <-errs
+			stopped := ctx.Err() != nil
+			cancel()
+			if animErr := <-done; animErr != nil || stopped {
+				return animErr
+			}
+
+			return fmt.Errorf("reading key events: %w", err)
+		}
+	}
+}
+
+// mapsKeyPresses reports whether pressedKeys finds keys of the
+// layout, i.e. some of its keys have key codes or names of the keys.
+func mapsKeyPresses(layout *ite8291.Layout) bool {
+
+	for _, k := range layout.Keys {
+		name, _, _ := strings.Cut(k.Name, "#")
+		if _, found := evdev.KeyCode(name); k.Code != 0 || found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pressedKeys returns keys of the layout pressed by the key with the
+// given code. The keys are found either by the code or by the name of
+// the key as named by map-keys command.
+func pressedKeys(layout *ite8291.Layout, code uint16) []*ite8291.Key {
+
+	name := evdev.KeyName(code)
+
+	var keys []*ite8291.Key
+	for i := range layout.Keys {
+		k := &layout.Keys[i]
+		if k.Code == code || k.Name == name || strings.HasPrefix(k.Name, name+"#") {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
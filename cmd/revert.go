@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// effectModes maps ite8291r3 built-in effects to the names of the
+// commands setting them.
+var effectModes = map[byte]string{
+	ite8291.AuroraEffect:    "aurora-mode",
+	ite8291.BreathingEffect: "breath-mode",
+	ite8291.FireworksEffect: "fireworks-mode",
+	ite8291.MarqueeEffect:   "marquee-mode",
+	ite8291.RainbowEffect:   "rainbow-mode",
+	ite8291.RaindropEffect:  "raindrop-mode",
+	ite8291.RandomEffect:    "random-mode",
+	ite8291.RippleEffect:    "ripple-mode",
+	ite8291.WaveEffect:      "wave-mode",
+}
+
+// describeState returns description of the keyboard backlight state.
+// frameKnown tells whether colors of the keys of 'user' effect are
+// known.
+func describeState(state *ite8291.EffectState, frameKnown bool) string {
+
+	switch {
+	case state.Control == ite8291.OffState:
+		return "off-mode"
+	case state.Effect == ite8291.UserEffect && frameKnown:
+		return fmt.Sprintf("'user' effect with brightness %d", state.Brightness)
+	case state.Effect == ite8291.UserEffect:
+		return fmt.Sprintf("'user' effect with brightness %d; colors of its keys are unknown", state.Brightness)
+	}
+
+	mode, found := effectModes[state.Effect]
+	if !found {
+		mode = fmt.Sprintf("effect %#x", state.Effect)
+	}
+
+	return fmt.Sprintf("%s with brightness %d", mode, state.Brightness)
+}
+
+// applyFor calls f to apply a new state of the keyboard backlight and
+// reverts the previous state once the given duration elapses or the
+// context of cmd is done. Long running commands are stopped when the
+// duration elapses. The reverted state is reported to the output of
+// cmd.
+func applyFor(cmd *cobra.Command, ctl *ite8291.Controller, d time.Duration, f ite8291Call) error {
+
+	last := ctl.LastFrame()
+	state, err := ctl.Effect()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), d)
+	defer cancel()
+	cmd.SetContext(ctx)
+
+	if err = f(ctl); err == nil {
+		<-ctx.Done()
+	}
+
+	if revertErr := revertState(ctl, state, last); revertErr != nil {
+		return errors.Join(err, revertErr)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Reverted to %s.\n", describeState(state, last != nil))
+
+	return err
+}
+
+// revertState sets the keyboard backlight to the given state. The
+// given frame is shown in 'user' effect if it is known.
+func revertState(ctl *ite8291.Controller, state *ite8291.EffectState, frame *ite8291.Frame) error {
+
+	if state.Control != ite8291.OffState && state.Effect == ite8291.UserEffect && frame != nil {
+		if err := ctl.SetFrameMode(state.Brightness, frame, false); err != nil {
+			return err
+		}
+	}
+
+	return ctl.RestoreEffect(state)
+}
@@ -14,6 +14,8 @@ const rippleModeDescription = "Set keyboard backlight to 'ripple' mode."
 // to set keyboard backlight to 'ripple' mode.
 func newRippleModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	var rippleModeCmd = &cobra.Command{
 		Use:           "ripple-mode",
 		Short:         rippleModeDescription,
@@ -23,8 +25,10 @@ func newRippleModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetRippleMode(params.Speed(v), params.Brightness(v),
-					params.ColorNum(v), params.Reactive(v), params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetRippleMode(params.Speed(v), brightness, params.ColorNum(v), params.Reactive(v), save)
+					})
 			})
 		},
 	}
@@ -35,6 +39,8 @@ func newRippleModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	params.AddReactive(rippleModeCmd, v)
 	params.AddSave(rippleModeCmd, v)
 	params.AddReset(rippleModeCmd, v)
+	params.AddFor(rippleModeCmd)
+	transition = params.AddFade(rippleModeCmd, v)
 
 	return rippleModeCmd
 }
@@ -1,36 +1,62 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"slices"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/evdev"
 	"github.com/v4n6/itectl/pkg/ite8291"
+	"github.com/v4n6/itectl/pkg/preview"
 )
 
+func init() {
+	// persistent hooks of all parent commands are run; set once since
+	// commands can be executed concurrently by the daemon
+	cobra.EnableTraverseRunHooks = true
+}
+
 // Execute runs the application.
 func Execute() {
 
-	cobra.CheckErr(
-		executeCmd(os.Args[1:], os.Stdout, os.Stderr, findIteDevice, params.ReadConfig))
+	// long running commands stop on interrupt or termination
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := executeCmd(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, findIteDevice, params.ReadConfig,
+		openKeyboards, dialDaemon)
+
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) { // exit with the status of the command run by itectl
+		stop()
+		os.Exit(exitErr.code)
+	}
+
+	cobra.CheckErr(err)
 }
 
 // executeCmd invokes the command provided by args or sets keyboard backlight to a configured mode.
-// output, errOut provide corresponding output and error streams.
+// ctx is the context of the command; long running commands stop when it is done.
+// input, output, errOut provide corresponding input, output and error streams.
 // find function is used to look up a supported ite8291 device.
 // readConfig function is used to retrieve configuration either from configuration file provided
 // by corresponding flag or from default global and/or user configuration files.
-func executeCmd(args []string, output, errOut io.Writer,
-	find findDevice, readConf readConfig) (err error) {
-
-	cobra.EnableTraverseRunHooks = true
+// open function is used to read key events from input devices.
+// connect function, if provided, is used to access the device via the daemon if it's running.
+func executeCmd(ctx context.Context, args []string, input io.Reader, output, errOut io.Writer,
+	find findDevice, readConf readConfig, open openEvents, connect connectDaemon) (err error) {
 
 	v := viper.New()
-	rootCmd := newRootCmd(v, find) // root command
+	rootCmd := newRootCmd(v, find, readConf, open, connect) // root command
 	rootCmd.InitDefaultCompletionCmd()
 	rootCmd.InitDefaultHelpCmd()
 	rootCmd.InitDefaultHelpFlag()
@@ -65,9 +91,10 @@ func executeCmd(args []string, output, errOut io.Writer,
 	}
 
 	rootCmd.SetArgs(args)
+	rootCmd.SetIn(input)
 	rootCmd.SetOut(output)
 	rootCmd.SetErr(errOut)
-	return rootCmd.Execute()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 // readConfig type provides a function to retrieve and merge viper
@@ -88,6 +115,16 @@ type ite8291Ctl func(cmd *cobra.Command, f ite8291Call) error
 type findDevice func(useDevice bool, bus, address int,
 	pollInterval, timeout time.Duration) (dev ite8291.Device, err error)
 
+// openEvents type provides a function that opens the given input
+// devices or, if none are given, all keyboards and returns source of
+// their events.
+type openEvents func(devices []string) (evdev.EventSource, error)
+
+// openKeyboards opens the given input devices or all keyboards.
+func openKeyboards(devices []string) (evdev.EventSource, error) {
+	return evdev.OpenKeyboards(devices)
+}
+
 // findIteDevice looks up a supported ite8291r3 device.
 //
 // useDevice specifies whether an ite8291r3 device identified by bus
@@ -128,13 +165,65 @@ func resetColors(ctl *ite8291.Controller, v *viper.Viper, cmd *cobra.Command) (e
 	return ctl.SetColors(colors)
 }
 
+// previewCall calls given f with a controller backed by a virtual
+// device and renders the resulting keyboard backlight state either to
+// the given file in the given format or, if file is empty, to the
+// command output. In the latter case frames written by long running
+// commands are rendered as they are shown.
+func previewCall(cmd *cobra.Command, v *viper.Viper, file string, format preview.Format, f ite8291Call) error {
+
+	layout, err := params.Layout(v)
+	if err != nil {
+		return err
+	}
+
+	dev := ite8291.NewVirtualDevice()
+	ctl := ite8291.NewController(dev)
+	defer ctl.Close()
+
+	var term *preview.Terminal
+	if len(file) == 0 {
+		term = preview.NewTerminal(cmd.OutOrStdout())
+		dev.OnFrame = func(frame *ite8291.Frame) {
+			_ = term.Draw(frame, layout)
+		}
+	}
+
+	if err := resetColors(ctl, v, cmd); err != nil {
+		return err
+	}
+
+	if err := f(ctl); err != nil {
+		return err
+	}
+
+	if term != nil {
+		return term.Draw(dev.Snapshot(layout), layout)
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := preview.Write(out, format, dev.Snapshot(layout), layout); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
 // newRootCmd creates, initializes and returns root command.
 // v is a viper instance used by commands instead of the static one.
 // find is a findDevice function used to obtain ite8291r3 device
-// instance.
+// instance. readConf is used by commands applied via the daemon. open
+// is an openEvents function used to read key events. connect, if not
+// nil, is used to access the device via the daemon if it's running.
 //
 //nolint:funlen
-func newRootCmd(v *viper.Viper, find findDevice) *cobra.Command {
+func newRootCmd(v *viper.Viper, find findDevice, readConf readConfig, open openEvents,
+	connect connectDaemon) *cobra.Command {
 
 	var rootCmd = &cobra.Command{
 		Use:               "itectl",
@@ -149,21 +238,41 @@ func newRootCmd(v *viper.Viper, find findDevice) *cobra.Command {
 	params.AddConfigFlag(rootCmd)
 	params.AddPoll(rootCmd, v)
 	params.AddDevice(rootCmd, v)
+	previewed := params.AddPreview(rootCmd)
+	direct := params.AddDaemonSocket(rootCmd, v)
 
-	// ite8291Ctl
-	exec := func(cmd *cobra.Command, f ite8291Call) error {
+	// openDevice returns the device served by the daemon, if it's
+	// running, or the found one
+	openDevice := func() (dev ite8291.Device, err error) {
 
 		pollInterval, pollTimeout, err := params.Polls(v)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		useDev, devBus, devAddr, err := params.Device(v)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		if connect != nil && !direct() {
+			if dev, err = connect(params.DaemonSocket(v)); err != nil || dev != nil {
+				return dev, err
+			}
+		}
+
+		// daemon is not running
+		return find(useDev, devBus, devAddr, pollInterval, pollTimeout)
+	}
+
+	// ite8291Ctl
+	exec := func(cmd *cobra.Command, f ite8291Call) error {
+
+		if enabled, file, format := previewed(); enabled {
+			return previewCall(cmd, v, file, format, f)
 		}
 
-		dev, err := find(useDev, devBus, devAddr, pollInterval, pollTimeout)
+		dev, err := openDevice()
 		if err != nil {
 			return err
 		}
@@ -171,10 +280,29 @@ func newRootCmd(v *viper.Viper, find findDevice) *cobra.Command {
 		ctl := ite8291.NewController(dev)
 		defer ctl.Close()
 
+		hint := loadLastFrame()
+		ctl.SetFrameHint(hint)
+		defer func() {
+			if last := ctl.LastFrame(); last != nil && (hint == nil || *last != *hint) {
+				_ = storeLastFrame(last) // best effort; only smooth transitions rely on it
+			}
+		}()
+
+		d := params.For(cmd)
+		if d > 0 {
+			// the temporary state is never saved and keeps the predefined colors
+			v.Set(params.SaveProp, false)
+			v.Set(params.ResetProp, false)
+		}
+
 		if err := resetColors(ctl, v, cmd); err != nil {
 			return err
 		}
 
+		if d > 0 {
+			return applyFor(cmd, ctl, d, f)
+		}
+
 		return f(ctl)
 	}
 
@@ -195,6 +323,47 @@ func newRootCmd(v *viper.Viper, find findDevice) *cobra.Command {
 	rootCmd.AddCommand(newFirmwareVersionCmd(exec))
 	rootCmd.AddCommand(newStateCmd(exec))
 	rootCmd.AddCommand(newSetColorCmd(v, exec))
+	rootCmd.AddCommand(newZoneColorCmd(v, exec))
+	rootCmd.AddCommand(newImageModeCmd(v, exec))
+	rootCmd.AddCommand(newGradientModeCmd(v, exec))
+	rootCmd.AddCommand(newTextModeCmd(v, exec))
+	rootCmd.AddCommand(newKeymapModeCmd(v, exec))
+	rootCmd.AddCommand(newEditCmd(v, exec))
+	rootCmd.AddCommand(newMapKeysCmd(v, exec, open))
+	rootCmd.AddCommand(newAnimateCmd(v, exec))
+	rootCmd.AddCommand(newPlayCmd(v, exec))
+	rootCmd.AddCommand(newStreamCmd(v, exec))
+	rootCmd.AddCommand(newReactCmd(v, exec, open))
+	rootCmd.AddCommand(newVisualizerModeCmd(v, exec))
+	rootCmd.AddCommand(newPaletteCycleCmd(v, exec))
+	rootCmd.AddCommand(newNotifyCmd(v, exec))
+	rootCmd.AddCommand(newRunCmd(v, exec))
+	rootCmd.AddCommand(newProgressCmd(v, exec))
+	rootCmd.AddCommand(newTimerCmd(v, exec))
+	rootCmd.AddCommand(newMeterCmd(v, exec))
+	rootCmd.AddCommand(newIdleCmd(v, exec, open))
+	rootCmd.AddCommand(noPreview(newDaemonCmd(v, find, readConf, open), previewed))
+	rootCmd.AddCommand(noPreview(newDbusCmd(v, openDevice, readConf, open), previewed))
+	rootCmd.AddCommand(noPreview(newOpenRGBServerCmd(v, openDevice), previewed))
+	rootCmd.AddCommand(noPreview(newMQTTCmd(v, openDevice, readConf, open), previewed))
 
 	return rootCmd
 }
+
+// noPreview makes the given command reject preview flags and returns
+// it. It's used by the commands serving the device instead of applying
+// a state to it, so there is nothing to preview.
+func noPreview(cmd *cobra.Command, previewed func() (bool, string, preview.Format)) *cobra.Command {
+
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+
+		if enabled, _, _ := previewed(); enabled {
+			return fmt.Errorf("%w for \"--%s\" and \"--%s\": %q command serves the device and cannot be previewed",
+				params.ErrInvalidOptVal, params.PreviewFlag, params.PreviewFileFlag, cmd.Name())
+		}
+
+		return nil
+	}
+
+	return cmd
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// runDescription - run command description.
+const runDescription = "Run a command and show its status on the keyboard backlight."
+
+// signalExitCodeBase - base of the exit code of a command killed by a
+// signal, the code is the base plus the signal number.
+const signalExitCodeBase = 128
+
+// forwardedSignals - signals forwarded to the running command. The
+// terminal sends interrupt and quit to the command by itself.
+var forwardedSignals = []os.Signal{syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2}
+
+// exitCodeError reports exit code of the command run by itectl. It
+// makes itectl exit with the same code.
+type exitCodeError struct {
+	code int
+}
+
+// Error returns description of the exit code.
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// newRunCmd creates, initializes and returns command to run a command
+// while showing its status on the keyboard backlight.
+func newRunCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var busy func() string
+	var busyColors func() []*ite8291.Color
+	var outcome func(success bool) (*ite8291.Color, ite8291.Animation)
+	var restore func() time.Duration
+	var layout func() *ite8291.Layout
+
+	var runCmd = &cobra.Command{
+		Use:   "run [flags] -- command [args...]",
+		Short: runDescription,
+		Long: fmt.Sprintf(`Run a command and show its status on the keyboard backlight.
+
+The software effect "(--%s)" plays on the keyboard backlight while the command runs;
+the effect uses its own colors unless the colors "(--%s)" are given. See animate
+command for the effects. Once the command exits the keys are lit either by the success
+color "(--%s)" or by the failure color "(--%s)" depending on its exit status. The outcome
+color can be shown in one of the patterns "(--%s, --%s)" %q before it stays.
+
+The colors are given either by names of the colors configured via %q configuration
+property or by RGB values in a one of the following formats %q.
+
+The command inherits the standard input and output streams. Termination, hangup and user
+signals received by itectl are forwarded to it. itectl exits with the exit status of the
+command.
+
+If requested "(--%s)", the previous effect of the keyboard backlight is restored after the
+given delay. The colors of the keys of 'user' effect are not reported by the controller,
+so they are left as the command set them.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[12]s:
+       busy:
+         effect: breathing
+         colors: [yellow]
+       failure:
+         pattern: pulse
+       restore: 10s`,
+			params.RunBusyFlag, params.RunBusyColorsFlag, params.RunSuccessFlag, params.RunFailureFlag,
+			params.RunSuccessPatternFlag, params.RunFailurePatternFlag, params.RunPatternNames(),
+			params.NamedColorsProp, ite8291.SupportedColorStringFormats, params.RunRestoreFlag,
+			params.RunProp, params.RunProp),
+		Args:          cobra.MinimumNArgs(1),
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			cmd.SilenceUsage = true // failures of the command are not usage errors
+
+			anim, err := ite8291.NewAnimation(busy(), ite8291.AnimationOptions{
+				Colors: busyColors(), Period: params.AnimationPeriod(v), Layout: layout(),
+			})
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				last := ctl.LastFrame()
+				state, err := ctl.Effect()
+				if err != nil {
+					return err
+				}
+
+				if err := ctl.SetUserMode(params.Brightness(v), false); err != nil {
+					return err
+				}
+
+				code, runErr := runWhile(cmd, args, func(ctx context.Context) error {
+					return ite8291.Animate(ctx, ctl, anim, params.FPS(v))
+				})
+				if runErr != nil {
+					return errors.Join(runErr, revertState(ctl, state, last))
+				}
+
+				color, pattern := outcome(code == 0)
+				if err := showOutcome(cmd.Context(), ctl, color, pattern, params.FPS(v)); err != nil {
+					return err
+				}
+
+				if d := restore(); d > 0 {
+					select {
+					case <-cmd.Context().Done():
+					case <-time.After(d):
+					}
+
+					if err := revertState(ctl, state, last); err != nil {
+						return err
+					}
+				}
+
+				if code != 0 {
+					return &exitCodeError{code: code}
+				}
+
+				return nil
+			})
+		},
+	}
+
+	runCmd.Flags().SetInterspersed(false) // flags after the command belong to it
+
+	busy, busyColors, outcome, restore = params.AddRun(runCmd, v)
+	params.AddFPS(runCmd, v)
+	layout = params.AddLayout(runCmd, v)
+	params.AddSpeed(runCmd, v)
+	params.AddBrightness(runCmd, v)
+
+	return runCmd
+}
+
+// runWhile runs the command given by args with the standard streams
+// of cmd and calls show until the command exits. It returns exit code
+// of the command or an error if it cannot be started. An error of
+// show is ignored, since the command status matters more.
+func runWhile(cmd *cobra.Command, args []string, show func(ctx context.Context) error) (code int, err error) {
+
+	child := exec.Command(args[0], args[1:]...) //nolint:gosec
+	child.Stdin, child.Stdout, child.Stderr = cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err = child.Start(); err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = show(ctx)
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- child.Wait() }()
+
+	for waiting := true; waiting; {
+		select {
+		case sig := <-signals:
+			_ = child.Process.Signal(sig) // the command may have exited meanwhile
+		case err = <-done:
+			waiting = false
+		}
+	}
+
+	cancel()
+	wg.Wait()
+
+	return exitCode(err)
+}
+
+// exitCode returns exit code of a command given by the error of its
+// wait. Code of a command killed by a signal follows shell convention.
+func exitCode(err error) (int, error) {
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, nil
+	case !errors.As(err, &exitErr):
+		return 0, err
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return signalExitCodeBase + int(status.Signal()), nil
+	}
+
+	return exitErr.ExitCode(), nil
+}
+
+// showOutcome plays the given pattern, if any, and lights all keys by
+// the given color afterwards.
+func showOutcome(ctx context.Context, ctl *ite8291.Controller, color *ite8291.Color,
+	pattern ite8291.Animation, fps int) error {
+
+	if pattern != nil {
+		if err := ite8291.Animate(ctx, ctl, pattern, fps); err != nil {
+			return err
+		}
+	}
+
+	return ctl.WriteFrame(ite8291.NewFrame(color))
+}
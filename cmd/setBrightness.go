@@ -14,6 +14,8 @@ const setBrightnessDescription = "Set keyboard backlight brightness."
 // to set keyboard backlight brightness.
 func newSetBrightnessCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
+	var transition func() ite8291.Transition
+
 	var setBrightnessCmd = &cobra.Command{
 		Use:           "set-brightness",
 		Short:         setBrightnessDescription,
@@ -23,12 +25,14 @@ func newSetBrightnessCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetBrightness(params.Brightness(v))
+				return fadeToBrightness(cmd.Context(), ctl, transition(), params.Brightness(v))
 			})
 		},
 	}
 
 	params.AddBrightness(setBrightnessCmd, v)
+	transition = params.AddFade(setBrightnessCmd, v)
+	params.AddFor(setBrightnessCmd)
 
 	if err := setBrightnessCmd.MarkPersistentFlagRequired("brightness"); err != nil {
 		panic(err)
@@ -17,6 +17,7 @@ const singleColorModeDescription = "Set keyboard backlight to 'single color' mod
 func newSingleColorModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 	var color func() *ite8291.Color
+	var transition func() ite8291.Transition
 
 	var singleColorModeCmd = &cobra.Command{
 		Use:   "single-color-mode",
@@ -42,8 +43,8 @@ It can be set to either a color name or an rgb directly.`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetSingleColorMode(params.Brightness(v), color(),
-					params.Save(v))
+				return fadeToFrame(cmd.Context(), ctl, transition(), params.Brightness(v),
+					ite8291.NewFrame(color()), params.Save(v))
 			})
 		},
 	}
@@ -52,6 +53,8 @@ It can be set to either a color name or an rgb directly.`,
 	params.AddBrightness(singleColorModeCmd, v)
 	params.AddSave(singleColorModeCmd, v)
 	params.AddReset(singleColorModeCmd, v)
+	params.AddFor(singleColorModeCmd)
+	transition = params.AddFade(singleColorModeCmd, v)
 
 	return singleColorModeCmd
 }
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// streamDescription - stream command description.
+const streamDescription = "Show frames read from the standard input on the keyboard backlight."
+
+// newStreamCmd creates, initializes and returns command to show frames
+// read from the standard input on the keyboard backlight.
+func newStreamCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var format func() string
+
+	var streamCmd = &cobra.Command{
+		Use:   "stream",
+		Short: streamDescription,
+		Long: fmt.Sprintf(`Show frames read from the standard input on the keyboard backlight.
+
+Frames are shown as they arrive until the end of the input or an interrupt. The frames are
+given in one of the following formats "(--%s)":
+  %s - every line updates the frame shown last and shows it. A line is one of
+         ROW COLUMN COLOR [ROW COLUMN COLOR ...]  sets keys given by rows and columns
+         fill COLOR                               sets all keys
+         frame COLOR...                           sets all %d keys row by row
+       Rows are in [0, %d], columns are in [0, %d]. Colors are either names of the colors
+       configured via %q configuration property or RGB values in a one of the
+       following formats %q. Empty lines are ignored. e.g.
+         fill #000
+         0 0 red 5 20 #00FF00
+  %s  - consecutive frames of %d bytes holding red, green and blue bytes of the keys
+       row by row.`,
+			params.StreamFormatFlag, ite8291.StreamText, ite8291.RowsNumber*ite8291.ColumnsNumber,
+			ite8291.RowsNumber-1, ite8291.ColumnsNumber-1, params.NamedColorsProp,
+			ite8291.SupportedColorStringFormats, ite8291.StreamRaw, ite8291.RawFrameSize),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			r, err := ite8291.NewFrameReader(cmd.InOrStdin(), format(),
+				func(val string) (*ite8291.Color, error) { return params.ColorValue(v, val) })
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				if err := ctl.SetUserMode(params.Brightness(v), params.Save(v)); err != nil {
+					return err
+				}
+
+				return ite8291.Stream(cmd.Context(), ctl, r)
+			})
+		},
+	}
+
+	format = params.AddStreamFormat(streamCmd, v)
+	params.AddBrightness(streamCmd, v)
+	params.AddSave(streamCmd, v)
+	params.AddReset(streamCmd, v)
+
+	return streamCmd
+}
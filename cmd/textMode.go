@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// textModeDescription - text-mode command description.
+const textModeDescription = "Scroll text on the keyboard backlight."
+
+// newTextModeCmd creates, initializes and returns command to scroll
+// text on the keyboard backlight.
+func newTextModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var text func() string
+	var color, background func() *ite8291.Color
+
+	var textModeCmd = &cobra.Command{
+		Use:   "text-mode",
+		Short: textModeDescription,
+		Long: fmt.Sprintf(`Scroll text on the keyboard backlight.
+
+The text "(--%s)" is drawn by an embedded 5 keys high font with the given color "(--%s)"
+on the given background color "(--%s)" and scrolled from the right to the left edge
+of the keyboard. The colors can be given either by names of the colors configured
+via %q configuration property or by RGB values in a one of the following formats %q.
+Lowercase letters are shown as uppercase ones, unsupported characters are shown as '?'.
+
+The text scrolls with the given speed "(-%s, --%s)" until interrupted or, if requested
+"(--%s)", only once. Afterwards the previous effect of the keyboard backlight is restored.
+Colors of the keys of the previous 'user' effect are not restored; the controller does not
+report them.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[10]s:
+       message: "build passed"
+       color: green
+       background: "#000"
+       once: true`,
+			params.TextMessageFlag, params.TextColorFlag, params.TextBackgroundFlag,
+			params.NamedColorsProp, ite8291.SupportedColorStringFormats,
+			params.SpeedShortFlag, params.SpeedProp, params.TextOnceFlag, params.TextProp, params.TextProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			frames := ite8291.TextFrames(text(), color(), background(), params.ScrollDelay(v))
+
+			loops := -1 // until interrupted
+			if params.TextOnce(v) {
+				loops = 1
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+				return ctl.WithUserMode(params.Brightness(v), func() error {
+					return ite8291.PlayFrames(cmd.Context(), ctl, frames, loops)
+				})
+			})
+		},
+	}
+
+	text, color, background = params.AddText(textModeCmd, v)
+	params.AddTextOnce(textModeCmd, v)
+	params.AddSpeed(textModeCmd, v)
+	params.AddBrightness(textModeCmd, v)
+	params.AddReset(textModeCmd, v)
+	params.AddFor(textModeCmd)
+
+	return textModeCmd
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// timerDescription - timer command description.
+const timerDescription = "Count a duration down on the keyboard backlight."
+
+// newTimerCmd creates, initializes and returns command to count a
+// duration down on the keyboard backlight.
+func newTimerCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var cells func() []ite8291.Cell
+	var fill, background func() *ite8291.Color
+	var finish func() ite8291.Animation
+
+	var timerCmd = &cobra.Command{
+		Use:   "timer duration",
+		Short: timerDescription,
+		Long: fmt.Sprintf(`Count a duration down on the keyboard backlight.
+
+The duration is given as the argument e.g. "25m" or "1h30m". A bar over the given row
+"(--%s)" of the keyboard matrix or over the keys of the given zone "(--%s)" configured
+via %q configuration property is full at the start and shrinks from the right to the
+left until the duration is over. The bar is lit by the fill color "(--%s)", its empty
+part by the background color "(--%s)". The other keys are set to the background
+color.
+
+Once the duration is over the finish color "(--%s)" is flashed in one of the patterns
+"(--%s)" %q. Afterwards or if interrupted the previous effect is restored. The colors
+are given either by names of the colors configured via %q configuration property or by
+RGB values in a one of the following formats %q.
+
+If values are not provided via flags, the values of %q configuration property are used.
+e.g. %[12]s:
+       row: 0
+       fill: tomato
+       finish:
+         color: "#FFF"
+         pattern: pulse`,
+			params.ProgressRowFlag, params.ProgressZoneFlag, params.ZonesProp, params.ProgressFillFlag,
+			params.ProgressBackgroundFlag, params.TimerFinishFlag, params.TimerFinishPatternFlag,
+			params.FlashPatternNames(), params.NamedColorsProp, ite8291.SupportedColorStringFormats,
+			params.TimerProp, params.TimerProp),
+		Args:          cobra.ExactArgs(1),
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			d, err := params.ParseTimerDuration(args[0])
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				last := ctl.LastFrame()
+				state, err := ctl.Effect()
+				if err != nil {
+					return err
+				}
+
+				overlays := newOverlays(background())
+
+				bar, flash := ite8291.NewLayer(cmd.Name(), "bar"), ite8291.NewLayer(cmd.Name(), "finish")
+				bar.Z, bar.Mask, flash.Z = 1, cells(), 2
+
+				countdown, err := overlays.Overlay(bar, ite8291.NewCountdownAnimation(
+					ite8291.NewFrame(background()), cells(), d, fill(), background()))
+				if err != nil {
+					return err
+				}
+				finished, err := overlays.Overlay(flash, finish())
+				if err != nil {
+					return err
+				}
+
+				if err := ctl.SetUserMode(params.Brightness(v), false); err != nil {
+					return err
+				}
+
+				err = ite8291.Animate(cmd.Context(), ctl, countdown, params.FPS(v))
+				if err == nil && cmd.Context().Err() == nil {
+					err = ite8291.Animate(cmd.Context(), ctl, finished, params.FPS(v))
+				}
+
+				return errors.Join(err, revertState(ctl, state, last))
+			})
+		},
+	}
+
+	cells, fill, background, finish = params.AddTimer(timerCmd, v)
+	params.AddFPS(timerCmd, v)
+	params.AddBrightness(timerCmd, v)
+
+	return timerCmd
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/audio"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// visualizer parameters.
+const (
+	// visualizerWindow - number of the most recent frames the spectrum is computed of.
+	visualizerWindow = 2048
+	// visualizerMinFreq - frequency of the left column of the spectrum.
+	visualizerMinFreq = 40.0
+	// visualizerMaxFreq - frequency of the right column of the spectrum.
+	visualizerMaxFreq = 16000.0
+)
+
+// visualizerModeDescription - visualizer-mode command description.
+const visualizerModeDescription = "Visualize PCM audio on the keyboard backlight."
+
+// newVisualizerModeCmd creates, initializes and returns command to
+// visualize PCM audio on the keyboard backlight.
+func newVisualizerModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var style func() string
+	var ramp func() ite8291.Gradient
+	var smoothing func() float64
+	var file func() string
+	var format func() audio.Format
+
+	var visualizerModeCmd = &cobra.Command{
+		Use:   "visualizer-mode",
+		Short: visualizerModeDescription,
+		Long: fmt.Sprintf(`Visualize PCM audio on the keyboard backlight.
+
+Raw PCM audio of interleaved signed 16 bit little endian samples with the given rate "(--%s)"
+and number of channels "(--%s)" is read from the given file or named pipe "(--%s)" or from the
+standard input, e.g.
+  parec --format=s16le --rate=44100 --channels=2 | itectl visualizer-mode
+  pw-cat --record --format=s16 --rate=44100 --channels=2 - | itectl visualizer-mode
+
+The audio is shown in one of the following styles "(--%s)":
+  %-9s  bars of %d frequency bands from %.0f Hz to %.0f Hz spaced logarithmically
+             over the columns rise from the bottom row
+  %-9s  bars of the levels of the left and the right channel grow from the left
+             column over the top and the bottom half of the rows
+
+The bars are colored by the ramp "(--%s)" from low to high levels. Falling levels are
+smoothed "(--%s)". The frames are shown with the given frame rate "(--%s)"; the audio read
+from a regular file is played in real time. The visualizer runs until the end of the audio
+or an interrupt.`,
+			params.VisualizerRateFlag, params.VisualizerChannelsFlag, params.VisualizerFileFlag,
+			params.VisualizerStyleFlag, params.VisualizerSpectrum, ite8291.ColumnsNumber, visualizerMinFreq,
+			visualizerMaxFreq, params.VisualizerVU, params.VisualizerColorsFlag, params.VisualizerSmoothingFlag,
+			params.AnimationFPSFlag),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			in, paced := cmd.InOrStdin(), false
+			if name := file(); len(name) > 0 {
+				f, err := os.Open(name)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				stat, err := f.Stat()
+				if err != nil {
+					return err
+				}
+				in, paced = f, stat.Mode().IsRegular()
+			}
+
+			r, err := audio.NewReader(in, format())
+			if err != nil {
+				return err
+			}
+
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				if err := ctl.SetUserMode(params.Brightness(v), params.Save(v)); err != nil {
+					return err
+				}
+
+				return visualize(cmd.Context(), ctl, r, visualizer{
+					style: style(), ramp: ramp(), smoothing: smoothing(), fps: params.FPS(v), paced: paced,
+				})
+			})
+		},
+	}
+
+	style, ramp, smoothing, file, format = params.AddVisualizer(visualizerModeCmd, v)
+	params.AddFPS(visualizerModeCmd, v)
+	params.AddBrightness(visualizerModeCmd, v)
+	params.AddSave(visualizerModeCmd, v)
+	params.AddReset(visualizerModeCmd, v)
+	params.AddFor(visualizerModeCmd)
+
+	return visualizerModeCmd
+}
+
+// visualizer provides parameters of audio visualization.
+type visualizer struct {
+	style     string
+	ramp      ite8291.Gradient
+	smoothing float64
+	fps       int
+	paced     bool // whether the frames are shown in real time of the audio
+}
+
+// visualize shows frames visualizing audio read from r until the end
+// of the audio or until ctx is done. Every frame shows the audio read
+// since the previous one.
+func visualize(ctx context.Context, w ite8291.FrameWriter, r *audio.Reader, vis visualizer) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hop := r.Format().Rate / vis.fps
+	blocks := make(chan [][]float64)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(blocks)
+
+		for {
+			samples, err := r.Read(hop)
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					errs <- err
+				}
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case blocks <- samples:
+			}
+		}
+	}()
+
+	var ticker *time.Ticker
+	if vis.paced {
+		ticker = time.NewTicker(time.Second / time.Duration(vis.fps))
+		defer ticker.Stop()
+	}
+
+	analyzer := audio.NewAnalyzer(r.Format().Rate, visualizerWindow)
+	var levels []float64
+
+	for {
+		var samples [][]float64
+		select {
+		case <-ctx.Done():
+			return nil
+		case block, ok := <-blocks:
+			if !ok {
+				select {
+				case err := <-errs:
+					return err
+				default:
+					return nil
+				}
+			}
+			samples = block
+		}
+
+		var current []float64
+		if vis.style == params.VisualizerVU {
+			for _, ch := range samples[:min(2, len(samples))] {
+				current = append(current, audio.RMSLevel(ch))
+			}
+		} else {
+			analyzer.Write(audio.Mix(samples))
+			current = analyzer.Bands(ite8291.ColumnsNumber, visualizerMinFreq, visualizerMaxFreq)
+		}
+
+		// rising levels are shown at once, falling ones are smoothed
+		for i := range current {
+			if i < len(levels) {
+				current[i] = max(current[i], vis.smoothing*levels[i]+(1-vis.smoothing)*current[i])
+			}
+		}
+		levels = current
+
+		frame := ite8291.BarsFrame(levels, vis.ramp)
+		if vis.style == params.VisualizerVU {
+			frame = ite8291.MeterFrame(levels, vis.ramp)
+		}
+
+		if err := w.WriteFrame(frame); err != nil {
+			return err
+		}
+
+		if ticker != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	}
+}
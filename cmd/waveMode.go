@@ -15,6 +15,7 @@ const waveModeDescription = "Set keyboard backlight to 'wave' mode."
 func newWaveModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 	var direction func() ite8291.Direction
+	var transition func() ite8291.Transition
 
 	var waveModeCmd = &cobra.Command{
 		Use:           "wave-mode",
@@ -25,8 +26,10 @@ func newWaveModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return call(cmd, func(ctl *ite8291.Controller) error {
-				return ctl.SetWaveMode(params.Speed(v), params.Brightness(v),
-					direction(), params.Save(v))
+				return fadeToEffect(cmd.Context(), ctl, transition(), params.Brightness(v), params.Save(v),
+					func(brightness byte, save bool) error {
+						return ctl.SetWaveMode(params.Speed(v), brightness, direction(), save)
+					})
 			})
 		},
 	}
@@ -36,6 +39,8 @@ func newWaveModeCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
 	direction = params.AddDirection(waveModeCmd, v)
 	params.AddSave(waveModeCmd, v)
 	params.AddReset(waveModeCmd, v)
+	params.AddFor(waveModeCmd)
+	transition = params.AddFade(waveModeCmd, v)
 
 	return waveModeCmd
 }
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/params"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// zoneColorDescription - zone-color command description.
+const zoneColorDescription = "Set color of the keyboard backlight keys of the configured zone(s)."
+
+// newZoneColorCmd creates, initializes and returns command to set
+// color of the keys of the configured zone(s).
+func newZoneColorCmd(v *viper.Viper, call ite8291Ctl) *cobra.Command {
+
+	var cells func() []ite8291.Cell
+	var color, baseColor func() *ite8291.Color
+	var transition func() ite8291.Transition
+
+	var zoneColorCmd = &cobra.Command{
+		Use:   "zone-color",
+		Short: zoneColorDescription,
+		Long: fmt.Sprintf(`Set color of the keyboard backlight keys of the configured zone(s).
+
+Zones are configured via %q configuration property. A zone consists of keys
+given by their "row,column" position in the keyboard matrix and/or of rectangles
+of the keyboard matrix given by "row,column-row,column" positions of their corners.
+e.g. %[1]s:
+       arrows:
+         keys: ["4,16", "5,15", "5,16", "5,17"]
+       frow:
+         rects: ["0,1-0,12"]
+
+The zone(s) to color are given by "(--%s)" flag(s).
+The color can be by given by a name "(--%s)" of the color configured via %q configuration property.
+It can also be specified by RGB string "(--%s)" directly in a one of the following formats %q.
+The color can also be provided by a combination of (--%s, --%s, --%s) flags.
+
+All other keys are set to the base color "(--%s)". It can be either a color name
+or an rgb value. If it is not provided, the value of %q configuration property is used.`,
+			params.ZonesProp, params.ZoneFlag,
+			params.ColorNameFlag, params.NamedColorsProp,
+			params.ColorRGBFlag, ite8291.SupportedColorStringFormats,
+			params.ColorRedFlag, params.ColorGreenFlag, params.ColorBlueFlag,
+			params.ZoneBaseColorFlag, params.ZoneBaseColorProp),
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return call(cmd, func(ctl *ite8291.Controller) error {
+
+				frame := ite8291.NewFrame(baseColor())
+				frame.SetCells(cells(), color())
+
+				return fadeToFrame(cmd.Context(), ctl, transition(), params.Brightness(v), frame, params.Save(v))
+			})
+		},
+	}
+
+	cells = params.AddZones(zoneColorCmd, v)
+	color = params.AddColor(zoneColorCmd, v)
+	baseColor = params.AddZoneBaseColor(zoneColorCmd, v)
+	params.AddBrightness(zoneColorCmd, v)
+	params.AddSave(zoneColorCmd, v)
+	params.AddReset(zoneColorCmd, v)
+	transition = params.AddFade(zoneColorCmd, v)
+
+	return zoneColorCmd
+}
@@ -0,0 +1,94 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// animation properties and flags names.
+const (
+	// AnimationProp - name of the animation configuration property.
+	AnimationProp = "animation"
+
+	// animationEffectProp - name of the animation effect configuration property.
+	animationEffectProp = AnimationProp + ".effect"
+	// AnimationEffectFlag - name of the animation effect flag.
+	AnimationEffectFlag = "effect"
+
+	// animationColorsProp - name of the animation colors configuration property.
+	animationColorsProp = AnimationProp + ".colors"
+	// AnimationColorsFlag - name of the animation colors flag.
+	AnimationColorsFlag = "colors"
+
+	// animationFPSProp - name of the animation frame rate configuration property.
+	animationFPSProp = AnimationProp + ".fps"
+	// AnimationFPSFlag - name of the animation frame rate flag.
+	AnimationFPSFlag = "fps"
+)
+
+// boundaries of the duration of one cycle of the animation.
+const (
+	animationPeriodMax  = 10 * time.Second
+	animationPeriodStep = 900 * time.Millisecond
+)
+
+// AddAnimation adds animation related flags to the given cmd. It
+// returns functions to retrieve name of the software effect and its
+// colors. The effect must be provided either via the flag or via
+// configuration.
+func AddAnimation(cmd *cobra.Command, v *viper.Viper) (effect func() string, colors func() []*ite8291.Color) {
+
+	cmd.PersistentFlags().String(AnimationEffectFlag, "",
+		fmt.Sprintf("Software effect to play %q. %s", ite8291.AnimationNames(), configurationWarning))
+	bindAndValidate(cmd, v, AnimationEffectFlag, animationEffectProp, func() error {
+
+		if slices.Contains(ite8291.AnimationNames(), strings.ToLower(v.GetString(animationEffectProp))) {
+			return nil
+		}
+
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, v.GetString(animationEffectProp), "--"+AnimationEffectFlag, ite8291.AnimationNames())
+	})
+
+	colors = addColorValues(cmd, v, AnimationColorsFlag, animationColorsProp,
+		"Color(s) of the software effect; the effect uses its own colors if none are given.")
+
+	return func() string { return strings.ToLower(v.GetString(animationEffectProp)) }, colors
+}
+
+// AddFPS adds frame rate flag to the given cmd. It also adds hook to
+// bind it to the corresponding viper configuration property and to
+// validate its value.
+func AddFPS(cmd *cobra.Command, v *viper.Viper) {
+
+	cmd.PersistentFlags().Int(AnimationFPSFlag, ite8291.FPSDefault,
+		fmt.Sprintf("Number of frames per second; min value %d, max value %d. %s",
+			ite8291.FPSMinValue, ite8291.FPSMaxValue, configurationWarning))
+	bindAndValidate(cmd, v, AnimationFPSFlag, animationFPSProp, func() error {
+
+		if fps := v.GetInt(animationFPSProp); fps < ite8291.FPSMinValue || fps > ite8291.FPSMaxValue {
+			return fmt.Errorf("%w %d for %q; expected value in [%d, %d]", ErrInvalidOptVal, fps,
+				"--"+AnimationFPSFlag, ite8291.FPSMinValue, ite8291.FPSMaxValue)
+		}
+
+		return nil
+	})
+}
+
+// FPS returns animation frame rate property value.
+func FPS(v *viper.Viper) int {
+	return v.GetInt(animationFPSProp)
+}
+
+// AnimationPeriod returns duration of one cycle of the software effect
+// corresponding to speed property value: 10s for speed 0 down to 1s
+// for the maximum speed.
+func AnimationPeriod(v *viper.Viper) time.Duration {
+	return animationPeriodMax - time.Duration(v.GetUint(SpeedProp))*animationPeriodStep
+}
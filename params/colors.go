@@ -62,6 +62,71 @@ func colorNameToColor(name string, v *viper.Viper) (color *ite8291.Color, err er
 		"--"+ColorNameFlag)
 }
 
+// colorValueToColor converts given color value to the corresponding
+// instance of ite8291.Color. The value can be either a name of a
+// configured named color or an RGB value in one of the supported
+// formats.
+func colorValueToColor(val string, v *viper.Viper) (*ite8291.Color, error) {
+
+	if rgb := v.GetString(fmt.Sprintf("%s.%s", NamedColorsProp, val)); len(rgb) > 0 {
+		return ite8291.ParseColor(rgb)
+	}
+
+	return ite8291.ParseColor(val)
+}
+
+// addColorValue adds flag accepting a color value (see
+// colorValueToColor) to the given cmd. It also adds hook to bind it
+// to the given configuration property and to validate its value. It
+// returns function to retrieve the color.
+func addColorValue(cmd *cobra.Command, v *viper.Viper, flag, prop, defaultValue, usage string) (color func() *ite8291.Color) {
+
+	var col *ite8291.Color
+
+	cmd.PersistentFlags().String(flag, defaultValue,
+		fmt.Sprintf("%s Either a name of the color configured via %q property or an RGB value in a one of the following formats %q. %s",
+			usage, NamedColorsProp, ite8291.SupportedColorStringFormats, configurationWarning))
+	bindAndValidate(cmd, v, flag, prop, func() (err error) {
+
+		val := v.GetString(prop)
+		if col, err = colorValueToColor(val, v); err != nil {
+			return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, val, "--"+flag, err)
+		}
+
+		return nil
+	})
+
+	return func() *ite8291.Color { return col }
+}
+
+// addColorValues adds flag with the given name and usage providing
+// list of colors to the given cmd. It also adds hook to bind it to the
+// given viper configuration property and to validate its values. It
+// returns function to retrieve the colors.
+func addColorValues(cmd *cobra.Command, v *viper.Viper, flag, prop, usage string) (colors func() []*ite8291.Color) {
+
+	var cols []*ite8291.Color
+
+	cmd.PersistentFlags().StringSlice(flag, nil,
+		fmt.Sprintf("%s Either names of the colors configured via %q property or RGB values in a one of the following formats %q. %s",
+			usage, NamedColorsProp, ite8291.SupportedColorStringFormats, configurationWarning))
+	bindAndValidate(cmd, v, flag, prop, func() error {
+
+		cols = nil
+		for _, val := range v.GetStringSlice(prop) {
+			col, err := colorValueToColor(val, v)
+			if err != nil {
+				return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, val, "--"+flag, err)
+			}
+			cols = append(cols, col)
+		}
+
+		return nil
+	})
+
+	return func() []*ite8291.Color { return cols }
+}
+
 // addColorFlags adds color related flags to the provided cmd. It also
 // adds hook to validate their values. The 'required' parameter
 // specifies whether color must be specified explicitly.
@@ -175,3 +240,19 @@ func AddSingleModeColor(cmd *cobra.Command, v *viper.Viper) (color func() *ite82
 
 	return func() *ite8291.Color { return *col }
 }
+
+// rampOrDefault returns ramp of the given colors or, if there are
+// none, of the given default colors.
+func rampOrDefault(colors []*ite8291.Color, defaults []string) ite8291.Gradient {
+
+	if len(colors) > 0 {
+		return colors
+	}
+
+	ramp := make(ite8291.Gradient, len(defaults))
+	for i, val := range defaults {
+		ramp[i], _ = ite8291.ParseColor(val)
+	}
+
+	return ramp
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/adrg/xdg"
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -147,6 +148,44 @@ func ReadConfig(cmd *cobra.Command, v *viper.Viper, cfgFile string) error {
 	return nil
 }
 
+// WatchConfig watches configuration files read by ReadConfig with the
+// given cfgFile. Whenever one of them changes, it reads configuration
+// anew and passes it to onChange. Only the files existing when
+// WatchConfig is called are watched.
+func WatchConfig(cmd *cobra.Command, cfgFile string, onChange func(v *viper.Viper)) {
+
+	reload := func(fsnotify.Event) {
+
+		v := viper.New()
+		if err := ReadConfig(cmd, v, cfgFile); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err) // keep the configuration read before
+			return
+		}
+
+		onChange(v)
+	}
+
+	watch := func(cfgFile, dir string) {
+
+		v := viper.New() // local viper instance
+		if err := readInConfig(v, cfgFile, dir); err != nil {
+			return
+		}
+
+		v.OnConfigChange(reload)
+		v.WatchConfig()
+	}
+
+	if len(cfgFile) > 0 {
+		watch(cfgFile, "")
+		return
+	}
+
+	for _, dir := range slices.Concat(xdg.ConfigDirs, []string{xdg.ConfigHome}) {
+		watch("", dir)
+	}
+}
+
 // mergeConfig merges configuration found in specified dir into the
 // provided viper instance.
 func mergeConfig(v *viper.Viper, dir string) error {
@@ -0,0 +1,136 @@
+package params
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// daemon socket permissions.
+const (
+	// DaemonSocketPerm - permissions of the daemon socket accessible
+	// only by its owner.
+	DaemonSocketPerm os.FileMode = 0o600
+	// DaemonGroupSocketPerm - permissions of the daemon socket
+	// accessible by the members of the daemon group.
+	DaemonGroupSocketPerm os.FileMode = 0o660
+)
+
+// daemon properties and flags names.
+const (
+	// DaemonProp - name of the daemon configuration property.
+	DaemonProp = "daemon"
+
+	// daemonSocketProp - name of the daemon socket configuration property.
+	daemonSocketProp = DaemonProp + ".socket"
+	// DaemonSocketFlag - name of the daemon socket flag.
+	DaemonSocketFlag = "daemon-socket"
+
+	// DirectFlag - name of the flag to access the device directly.
+	DirectFlag = "direct"
+
+	// daemonGroupProp - name of the daemon group configuration property.
+	daemonGroupProp = DaemonProp + ".group"
+	// DaemonGroupFlag - name of the daemon group flag.
+	DaemonGroupFlag = "group"
+
+	// daemonUsersProp - name of the configuration property listing
+	// users allowed to access the daemon.
+	daemonUsersProp = DaemonProp + ".users"
+)
+
+// DaemonSocketDefault returns default path of the daemon socket in
+// the XDG runtime directory.
+func DaemonSocketDefault() string {
+	return filepath.Join(xdg.RuntimeDir, ConfigName+".sock")
+}
+
+// AddDaemonSocket adds daemon socket and direct flags to the given
+// cmd. The daemon socket flag is bound to the corresponding viper
+// config property; direct flag is not. AddDaemonSocket returns
+// function to retrieve whether the device must be accessed directly
+// even if the daemon is running.
+func AddDaemonSocket(cmd *cobra.Command, v *viper.Viper) (direct func() bool) {
+
+	var isDirect bool
+
+	cmd.PersistentFlags().String(DaemonSocketFlag, DaemonSocketDefault(),
+		"Unix socket of the daemon used to access the device if the daemon is running. "+configurationWarning)
+	bindAndValidate(cmd, v, DaemonSocketFlag, daemonSocketProp, nil)
+
+	cmd.PersistentFlags().BoolVar(&isDirect, DirectFlag, false,
+		"Access the device directly instead of via the daemon.")
+
+	return func() bool { return isDirect }
+}
+
+// DaemonSocket returns path of the daemon socket.
+func DaemonSocket(v *viper.Viper) string {
+	return v.GetString(daemonSocketProp)
+}
+
+// AddDaemon adds daemon related flags to the given cmd. It also adds
+// hook to bind them to the corresponding viper config properties and
+// to validate their values. It returns function to retrieve
+// permissions and group id of the daemon socket; the group id is
+// negative if the group is not set.
+func AddDaemon(cmd *cobra.Command, v *viper.Viper) (socketMode func() (perm os.FileMode, gid int)) {
+
+	gid := -1
+
+	cmd.PersistentFlags().String(DaemonGroupFlag, "",
+		"Group whose members are allowed to access the daemon; only root and the user running the daemon are allowed by default. "+
+			configurationWarning)
+	bindAndValidate(cmd, v, DaemonGroupFlag, daemonGroupProp, func() error {
+
+		gid = -1
+		if name := v.GetString(daemonGroupProp); len(name) > 0 {
+			id, err := lookupGroupID(name)
+			if err != nil {
+				return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, name, "--"+DaemonGroupFlag, err)
+			}
+			gid = id
+		}
+
+		return nil
+	})
+
+	return func() (os.FileMode, int) {
+		if gid < 0 {
+			return DaemonSocketPerm, gid
+		}
+		return DaemonGroupSocketPerm, gid
+	}
+}
+
+// DaemonGroup returns name of the group whose members are allowed to
+// access the daemon.
+func DaemonGroup(v *viper.Viper) string {
+	return v.GetString(daemonGroupProp)
+}
+
+// DaemonUsers returns names of the users allowed to access the daemon
+// in addition to root, the user running the daemon and the members of
+// the daemon group.
+func DaemonUsers(v *viper.Viper) []string {
+	return v.GetStringSlice(daemonUsersProp)
+}
+
+// lookupGroupID returns id of the group given by its name or id.
+func lookupGroupID(name string) (int, error) {
+
+	group, err := user.LookupGroup(name)
+	if err != nil {
+		if group, err = user.LookupGroupId(name); err != nil {
+			return 0, err
+		}
+	}
+
+	return strconv.Atoi(group.Gid)
+}
@@ -0,0 +1,68 @@
+package params
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// dbus properties default values.
+const (
+	// DbusSystemDefault - default value of dbus system property.
+	DbusSystemDefault = false
+	// DbusRefreshDefault - default value of dbus refresh property.
+	DbusRefreshDefault = 2 * time.Second
+)
+
+// dbus properties and flags names.
+const (
+	// DbusProp - name of the dbus configuration property.
+	DbusProp = "dbus"
+
+	// dbusSystemProp - name of the dbus system configuration property.
+	dbusSystemProp = DbusProp + ".system"
+	// DbusSystemFlag - name of the dbus system flag.
+	DbusSystemFlag = "system"
+
+	// dbusRefreshProp - name of the dbus refresh configuration property.
+	dbusRefreshProp = DbusProp + ".refresh"
+	// DbusRefreshFlag - name of the dbus refresh flag.
+	DbusRefreshFlag = "refresh"
+)
+
+// AddDbus adds D-Bus service related flags to the given cmd. It also
+// adds hook to bind them to the corresponding viper config properties
+// and to validate their values.
+func AddDbus(cmd *cobra.Command, v *viper.Viper) {
+
+	cmd.PersistentFlags().Bool(DbusSystemFlag, DbusSystemDefault,
+		"Provide the service on the system bus instead of the session bus. "+configurationWarning)
+	bindAndValidate(cmd, v, DbusSystemFlag, dbusSystemProp, nil)
+
+	cmd.PersistentFlags().Duration(DbusRefreshFlag, DbusRefreshDefault,
+		"Interval of reading the state changed by other programs; 0 reads it only after changes made via the service. "+
+			configurationWarning)
+	bindAndValidate(cmd, v, DbusRefreshFlag, dbusRefreshProp, func() error {
+
+		if v.GetDuration(dbusRefreshProp) < 0 {
+			return fmt.Errorf("%w %q for %q: duration must not be negative",
+				ErrInvalidOptVal, v.GetDuration(dbusRefreshProp), "--"+DbusRefreshFlag)
+		}
+
+		return nil
+	})
+}
+
+// DbusSystem returns whether the D-Bus service is provided on the
+// system bus.
+func DbusSystem(v *viper.Viper) bool {
+	return v.GetBool(dbusSystemProp)
+}
+
+// DbusRefresh returns interval of reading the state of the keyboard
+// backlight by the D-Bus service.
+func DbusRefresh(v *viper.Viper) time.Duration {
+	return v.GetDuration(dbusRefreshProp)
+}
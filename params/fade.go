@@ -0,0 +1,74 @@
+package params
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// fade properties default values.
+const (
+	// FadeDurationDefault - default value of fade duration property.
+	FadeDurationDefault = 0
+	// FadeEasingDefault - default value of fade easing property.
+	FadeEasingDefault = ite8291.EasingInOut
+)
+
+// fade properties and flags names.
+const (
+	// FadeProp - name of the fade configuration property.
+	FadeProp = "fade"
+
+	// fadeDurationProp - name of the fade duration configuration property.
+	fadeDurationProp = FadeProp + ".duration"
+	// FadeFlag - name of the fade duration flag.
+	FadeFlag = "fade"
+
+	// fadeEasingProp - name of the fade easing configuration property.
+	fadeEasingProp = FadeProp + ".easing"
+	// FadeEasingFlag - name of the fade easing flag.
+	FadeEasingFlag = "easing"
+)
+
+// AddFade adds flags of smooth transitions to the given cmd. It
+// returns function to retrieve the transition. Its frame rate is given
+// by the animation frame rate property.
+func AddFade(cmd *cobra.Command, v *viper.Viper) (transition func() ite8291.Transition) {
+
+	cmd.PersistentFlags().Duration(FadeFlag, FadeDurationDefault,
+		"Duration of the smooth transition to the new state; 0 switches instantly. "+configurationWarning)
+	bindAndValidate(cmd, v, FadeFlag, fadeDurationProp, func() error {
+
+		if v.GetDuration(fadeDurationProp) < 0 {
+			return fmt.Errorf("%w %q for %q: duration must not be negative",
+				ErrInvalidOptVal, v.GetDuration(fadeDurationProp), "--"+FadeFlag)
+		}
+
+		return nil
+	})
+
+	var easing ite8291.Easing
+
+	cmd.PersistentFlags().String(FadeEasingFlag, FadeEasingDefault,
+		fmt.Sprintf("Easing curve of the smooth transition %q. %s", ite8291.EasingNames(), configurationWarning))
+	bindAndValidate(cmd, v, FadeEasingFlag, fadeEasingProp, func() (err error) {
+
+		if easing, err = ite8291.ParseEasing(v.GetString(fadeEasingProp)); err != nil {
+			return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, v.GetString(fadeEasingProp), "--"+FadeEasingFlag, err)
+		}
+
+		return nil
+	})
+
+	return func() ite8291.Transition {
+
+		fps := v.GetInt(animationFPSProp)
+		if fps < ite8291.FPSMinValue || fps > ite8291.FPSMaxValue {
+			fps = ite8291.FPSDefault
+		}
+
+		return ite8291.Transition{Duration: v.GetDuration(fadeDurationProp), Easing: easing, FPS: fps}
+	}
+}
@@ -0,0 +1,55 @@
+package params
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ForFlag - name of the flag giving duration a state of the keyboard
+// backlight is applied for.
+const ForFlag = "for"
+
+// AddFor adds for flag to the given cmd. It is not bound to a
+// configuration property. It also adds hook to validate its value. The
+// flag cannot be combined with save flag since the temporary state is
+// never saved, nor with reset flag since the predefined colors cannot
+// be read back from the controller to revert them.
+func AddFor(cmd *cobra.Command) {
+
+	var duration time.Duration
+
+	cmd.PersistentFlags().DurationVar(&duration, ForFlag, 0,
+		"Apply the state only for the given duration and revert the previous state afterwards; 0 applies it permanently.")
+	for _, flag := range []string{SaveProp, ResetProp} {
+		if cmd.Flag(flag) != nil {
+			cmd.MarkFlagsMutuallyExclusive(ForFlag, flag)
+		}
+	}
+
+	addValidationHook(cmd, func() error {
+
+		if duration < 0 {
+			return fmt.Errorf("%w %q for %q: duration must not be negative", ErrInvalidOptVal, duration, "--"+ForFlag)
+		}
+
+		return nil
+	})
+}
+
+// For returns value of for flag of the given cmd or 0 if cmd has no
+// such flag.
+func For(cmd *cobra.Command) time.Duration {
+
+	if cmd.Flag(ForFlag) == nil {
+		return 0
+	}
+
+	d, err := cmd.Flags().GetDuration(ForFlag)
+	if err != nil {
+		return 0
+	}
+
+	return d
+}
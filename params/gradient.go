@@ -0,0 +1,133 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// gradient properties default values.
+const (
+	// GradientFromDefault - default value of gradient from property.
+	GradientFromDefault = "#FF0000"
+	// GradientToDefault - default value of gradient to property.
+	GradientToDefault = "#0000FF"
+	// GradientDirectionDefault - default value of gradient direction property.
+	GradientDirectionDefault = "right"
+)
+
+// gradient properties and flags names.
+const (
+	// GradientProp - name of the gradient configuration property.
+	GradientProp = "gradient"
+
+	// gradientFromProp - name of the gradient from configuration property.
+	gradientFromProp = GradientProp + ".from"
+	// GradientFromFlag - name of the gradient from flag.
+	GradientFromFlag = "from"
+
+	// gradientToProp - name of the gradient to configuration property.
+	gradientToProp = GradientProp + ".to"
+	// GradientToFlag - name of the gradient to flag.
+	GradientToFlag = "to"
+
+	// gradientViaProp - name of the gradient via configuration property.
+	gradientViaProp = GradientProp + ".via"
+	// GradientViaFlag - name of the gradient via flag.
+	GradientViaFlag = "via"
+
+	// gradientDirectionProp - name of the gradient direction configuration property.
+	gradientDirectionProp = GradientProp + ".direction"
+)
+
+// radialGradientName - name of radial gradient direction.
+const radialGradientName = "radial"
+
+// gradientDirectionNames - examples of gradient directions.
+var gradientDirectionNames = []string{"right", "left", "up", "down", "up-right", "down-left", radialGradientName}
+
+// ParseGradientDirection parses gradient direction. It is either
+// "radial" or one of the directions accepted by ParseDirectionName
+// except "none", or two such directions joined by "-" for diagonal
+// gradients, e.g. "up-right".
+func ParseGradientDirection(name string) (ite8291.GradientDirection, error) {
+
+	if strings.EqualFold(name, radialGradientName) {
+		return ite8291.RadialGradient, nil
+	}
+
+	newErr := func() error {
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, name,
+			fmt.Sprintf("-%s, --%s", DirectionShortFlag, DirectionProp), gradientDirectionNames)
+	}
+
+	parts := strings.Split(name, "-")
+	if len(parts) > 2 {
+		return ite8291.GradientDirection{}, newErr()
+	}
+
+	dirs := make([]ite8291.Direction, len(parts))
+	for i, p := range parts {
+		dir, err := ParseDirectionName(p)
+		if err != nil || dir == ite8291.DirectionNone {
+			return ite8291.GradientDirection{}, newErr()
+		}
+		dirs[i] = dir
+	}
+
+	d := ite8291.LinearGradient(dirs...)
+	if d.X == 0 && d.Y == 0 {
+		return ite8291.GradientDirection{}, newErr() // opposite directions
+	}
+
+	return d, nil
+}
+
+// AddGradient adds gradient related flags to the given cmd. It
+// returns functions to retrieve the gradient and its direction.
+//
+//nolint:lll
+func AddGradient(cmd *cobra.Command, v *viper.Viper) (gradient func() ite8291.Gradient, direction func() ite8291.GradientDirection) {
+
+	var via []*ite8291.Color
+	var dir ite8291.GradientDirection
+
+	from := addColorValue(cmd, v, GradientFromFlag, gradientFromProp, GradientFromDefault,
+		"First color of the gradient.")
+	to := addColorValue(cmd, v, GradientToFlag, gradientToProp, GradientToDefault, "Last color of the gradient.")
+
+	cmd.PersistentFlags().StringSlice(GradientViaFlag, nil,
+		fmt.Sprintf("Intermediate color(s) of the gradient between the first and the last one. Either names of the colors configured via %q property or RGB values in a one of the following formats %q. %s",
+			NamedColorsProp, ite8291.SupportedColorStringFormats, configurationWarning))
+	bindAndValidate(cmd, v, GradientViaFlag, gradientViaProp, func() error {
+
+		via = nil
+		for _, val := range v.GetStringSlice(gradientViaProp) {
+			col, err := colorValueToColor(val, v)
+			if err != nil {
+				return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, val, "--"+GradientViaFlag, err)
+			}
+			via = append(via, col)
+		}
+
+		return nil
+	})
+
+	cmd.PersistentFlags().StringP(DirectionProp, DirectionShortFlag, GradientDirectionDefault,
+		fmt.Sprintf("Direction of the gradient %q. %s", gradientDirectionNames, configurationWarning))
+	bindAndValidate(cmd, v, DirectionProp, gradientDirectionProp, func() (err error) {
+		dir, err = ParseGradientDirection(v.GetString(gradientDirectionProp))
+		return err
+	})
+
+	return func() ite8291.Gradient {
+			g := ite8291.Gradient{from()}
+			g = append(g, via...)
+			return append(g, to())
+		},
+		func() ite8291.GradientDirection { return dir }
+}
@@ -0,0 +1,164 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/idle"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// idle actions names.
+const (
+	// IdleActionDim - name of the action dimming the keyboard.
+	IdleActionDim = "dim"
+	// IdleActionOff - name of the action turning the keyboard off.
+	IdleActionOff = "off"
+	// IdleActionAnimation - name of the action playing the animation.
+	IdleActionAnimation = "animation"
+)
+
+// idleActionNames - names of the idle actions.
+var idleActionNames = []string{IdleActionDim, IdleActionOff, IdleActionAnimation}
+
+// idle properties default values.
+const (
+	// IdleTimeoutDefault - default value of idle timeout property.
+	IdleTimeoutDefault = time.Minute
+	// IdleActionDefault - default value of idle action property.
+	IdleActionDefault = IdleActionDim
+	// IdleBrightnessDefault - default value of idle brightness property.
+	IdleBrightnessDefault = 5
+	// IdleAnimationDefault - default value of idle animation property.
+	IdleAnimationDefault = "starfield"
+)
+
+// idle properties and flags names.
+const (
+	// IdleProp - name of the idle configuration property.
+	IdleProp = "idle"
+
+	// idleTimeoutProp - name of the idle timeout configuration property.
+	idleTimeoutProp = IdleProp + ".timeout"
+	// IdleTimeoutFlag - name of the idle timeout flag.
+	IdleTimeoutFlag = "timeout"
+
+	// idleActionProp - name of the idle action configuration property.
+	idleActionProp = IdleProp + ".action"
+	// IdleActionFlag - name of the idle action flag.
+	IdleActionFlag = "action"
+
+	// idleBrightnessProp - name of the idle brightness configuration property.
+	idleBrightnessProp = IdleProp + ".brightness"
+	// IdleBrightnessFlag - name of the idle brightness flag.
+	IdleBrightnessFlag = "idle-brightness"
+
+	// idleAnimationProp - name of the idle animation configuration property.
+	idleAnimationProp = IdleProp + ".animation"
+	// IdleAnimationFlag - name of the idle animation flag.
+	IdleAnimationFlag = "animation"
+
+	// idleExcludeProp - name of the idle exclude configuration property.
+	idleExcludeProp = IdleProp + ".exclude"
+	// IdleExcludeFlag - name of the idle exclude flag.
+	IdleExcludeFlag = "exclude"
+)
+
+// AddIdle adds idle policy related flags to the given cmd. It also
+// adds hook to bind them to the corresponding viper config properties
+// and to validate their values. It returns functions to retrieve the
+// idle action and the periods of the day excluded from the policy.
+func AddIdle(cmd *cobra.Command, v *viper.Viper) (action func() string, excluded func() []idle.Period) {
+
+	cmd.PersistentFlags().Duration(IdleTimeoutFlag, IdleTimeoutDefault,
+		"Period without key presses after which the keyboard is idle. "+configurationWarning)
+	bindAndValidate(cmd, v, IdleTimeoutFlag, idleTimeoutProp, func() error {
+
+		if v.GetDuration(idleTimeoutProp) <= 0 {
+			return fmt.Errorf("%w %q for %q: duration must be positive",
+				ErrInvalidOptVal, v.GetDuration(idleTimeoutProp), "--"+IdleTimeoutFlag)
+		}
+
+		return nil
+	})
+
+	var a string
+
+	cmd.PersistentFlags().String(IdleActionFlag, IdleActionDefault,
+		fmt.Sprintf("Action applied to the idle keyboard %q. %s", idleActionNames, configurationWarning))
+	bindAndValidate(cmd, v, IdleActionFlag, idleActionProp, func() error {
+
+		a = strings.ToLower(v.GetString(idleActionProp))
+		if !slices.Contains(idleActionNames, a) {
+			return fmt.Errorf("%w %q for %q; expected one of %q",
+				ErrInvalidOptVal, v.GetString(idleActionProp), "--"+IdleActionFlag, idleActionNames)
+		}
+
+		return nil
+	})
+
+	cmd.PersistentFlags().Uint8(IdleBrightnessFlag, IdleBrightnessDefault,
+		fmt.Sprintf("Brightness of the dimmed keyboard and of the animation; min value 0, max value %d. %s",
+			ite8291.BrightnessMaxValue, configurationWarning))
+	bindAndValidate(cmd, v, IdleBrightnessFlag, idleBrightnessProp, func() error {
+		return validateMaxUint8Value("--"+IdleBrightnessFlag, byte(v.GetUint(idleBrightnessProp)),
+			ite8291.BrightnessMaxValue)
+	})
+
+	cmd.PersistentFlags().String(IdleAnimationFlag, IdleAnimationDefault,
+		fmt.Sprintf("Software effect played on the idle keyboard by %q action %q. %s",
+			IdleActionAnimation, ite8291.AnimationNames(), configurationWarning))
+	bindAndValidate(cmd, v, IdleAnimationFlag, idleAnimationProp, func() error {
+
+		name := strings.ToLower(v.GetString(idleAnimationProp))
+		if a != IdleActionAnimation || slices.Contains(ite8291.AnimationNames(), name) {
+			return nil
+		}
+
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, v.GetString(idleAnimationProp), "--"+IdleAnimationFlag, ite8291.AnimationNames())
+	})
+
+	var periods []idle.Period
+
+	cmd.PersistentFlags().StringSlice(IdleExcludeFlag, nil,
+		"Period(s) of the day \"HH:MM-HH:MM\" when the keyboard does not become idle, e.g. 22:00-07:00. "+
+			configurationWarning)
+	bindAndValidate(cmd, v, IdleExcludeFlag, idleExcludeProp, func() error {
+
+		periods = nil
+		for _, s := range v.GetStringSlice(idleExcludeProp) {
+			p, err := idle.ParsePeriod(s)
+			if err != nil {
+				return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, s, "--"+IdleExcludeFlag, err)
+			}
+			periods = append(periods, p)
+		}
+
+		return nil
+	})
+
+	return func() string { return a }, func() []idle.Period { return periods }
+}
+
+// IdleTimeout returns period without key presses after which the
+// keyboard is idle.
+func IdleTimeout(v *viper.Viper) time.Duration {
+	return v.GetDuration(idleTimeoutProp)
+}
+
+// IdleAnimation returns name of the software effect played on the
+// idle keyboard.
+func IdleAnimation(v *viper.Viper) string {
+	return strings.ToLower(v.GetString(idleAnimationProp))
+}
+
+// IdleBrightness returns brightness of the dimmed keyboard and of the
+// animation.
+func IdleBrightness(v *viper.Viper) byte {
+	return byte(v.GetUint(idleBrightnessProp))
+}
@@ -0,0 +1,78 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// SamplingDefault - default value of sampling property.
+const SamplingDefault = "average"
+
+// image properties and flags names.
+const (
+	// SamplingProp - name of sampling flag and configuration property.
+	SamplingProp = "sampling"
+
+	// imageFileProp - name of image file configuration property.
+	imageFileProp = "imageFile"
+	// ImageFileFlag - name of image file flag.
+	ImageFileFlag = "file"
+)
+
+// samplings maps names of image sampling methods to their values.
+var samplings = map[string]ite8291.Sampling{
+	"nearest": ite8291.NearestSampling,
+	"average": ite8291.AverageSampling,
+	"layout":  ite8291.LayoutSampling,
+}
+
+// samplingNames - names of image sampling methods.
+var samplingNames = []string{"nearest", "average", "layout"}
+
+// AddSampling adds sampling flag to the given cmd. It returns
+// function to retrieve sampling method used to scale images to the
+// keyboard.
+func AddSampling(cmd *cobra.Command, v *viper.Viper) (sampling func() ite8291.Sampling) {
+
+	var s ite8291.Sampling
+
+	cmd.PersistentFlags().String(SamplingProp, SamplingDefault,
+		fmt.Sprintf("Method used to scale image to the keyboard %q. %s", samplingNames, configurationWarning))
+	bindAndValidate(cmd, v, SamplingProp, SamplingProp, func() error {
+
+		name := v.GetString(SamplingProp)
+		var found bool
+		if s, found = samplings[strings.ToLower(name)]; !found {
+			return fmt.Errorf("%w %q for %q; expected one of %q",
+				ErrInvalidOptVal, name, "--"+SamplingProp, samplingNames)
+		}
+
+		return nil
+	})
+
+	return func() ite8291.Sampling { return s }
+}
+
+// AddImageFile adds image file flag to the given cmd. It returns
+// function to retrieve name of the image file to show. The file must
+// be provided either via the flag or via configuration.
+func AddImageFile(cmd *cobra.Command, v *viper.Viper) (file func() string) {
+
+	cmd.PersistentFlags().String(ImageFileFlag, "",
+		"Image file in PNG, JPEG or GIF format to show on the keyboard. "+configurationWarning)
+	bindAndValidate(cmd, v, ImageFileFlag, imageFileProp, func() error {
+
+		if len(v.GetString(imageFileProp)) == 0 {
+			return fmt.Errorf("%w image file is missing for %q (either configured or specified explicitly)",
+				ErrInvalidOptVal, "--"+ImageFileFlag)
+		}
+
+		return nil
+	})
+
+	return func() string { return v.GetString(imageFileProp) }
+}
@@ -0,0 +1,31 @@
+package params
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// input properties and flags names.
+const (
+	// inputDevicesProp - name of the input devices configuration property.
+	inputDevicesProp = "input.devices"
+	// InputDeviceFlag - name of the input device flag.
+	InputDeviceFlag = "input-device"
+)
+
+// AddInputDevices adds input device flag to the given cmd. The flag
+// can be repeated to read key events from several input devices. It
+// also adds hook to bind it to the corresponding viper configuration
+// property.
+func AddInputDevices(cmd *cobra.Command, v *viper.Viper) {
+
+	cmd.PersistentFlags().StringSlice(InputDeviceFlag, nil,
+		"Input device(s) e.g. /dev/input/event3 to read key events from. All keyboards are used if none is specified. "+
+			configurationWarning)
+	bindAndValidate(cmd, v, InputDeviceFlag, inputDevicesProp, nil)
+}
+
+// InputDevices returns input devices property value.
+func InputDevices(v *viper.Viper) []string {
+	return v.GetStringSlice(inputDevicesProp)
+}
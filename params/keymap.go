@@ -0,0 +1,103 @@
+package params
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// KeymapProp - name of keymap flag and configuration property.
+const KeymapProp = "keymap"
+
+// AddKeymap adds keymap flag to the given cmd. The flag specifies a
+// YAML file with colors of the keys given by their names in the
+// keyboard layout. The file must be provided either via the flag or
+// via configuration. AddKeymap returns function to retrieve name of
+// the keymap file.
+func AddKeymap(cmd *cobra.Command, v *viper.Viper) (file func() string) {
+
+	cmd.PersistentFlags().String(KeymapProp, "",
+		"File with colors of the keyboard keys given by their names in the keyboard layout. "+configurationWarning)
+	bindAndValidate(cmd, v, KeymapProp, KeymapProp, func() error {
+
+		if len(v.GetString(KeymapProp)) == 0 {
+			return fmt.Errorf("%w keymap file is missing for %q (either configured or specified explicitly)",
+				ErrInvalidOptVal, "--"+KeymapProp)
+		}
+
+		return nil
+	})
+
+	return func() string { return v.GetString(KeymapProp) }
+}
+
+// KeymapFrame reads keymap from the given file and returns frame with
+// its colors placed using the given layout. Colors of the keymap are
+// either names of configured named colors or RGB values. If missingOk
+// is true and the file doesn't exist, KeymapFrame returns frame with
+// all keys set to black.
+func KeymapFrame(v *viper.Viper, file string, layout *ite8291.Layout, missingOk bool) (*ite8291.Frame, error) {
+
+	f, err := os.Open(file)
+	if missingOk && errors.Is(err, fs.ErrNotExist) {
+		return ite8291.NewFrame(ite8291.NewColor(0, 0, 0)), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, file, "--"+KeymapProp, err)
+	}
+	defer f.Close()
+
+	m, err := ite8291.ReadKeymap(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, file, "--"+KeymapProp, err)
+	}
+
+	frame, err := m.Frame(layout, func(val string) (*ite8291.Color, error) { return ColorValue(v, val) })
+	if err != nil {
+		return nil, fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, file, "--"+KeymapProp, err)
+	}
+
+	return frame, nil
+}
+
+// WriteKeymap writes colors of all keys of the given layout from the
+// given frame to the given keymap file.
+func WriteKeymap(file string, frame *ite8291.Frame, layout *ite8291.Layout) error {
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := ite8291.NewKeymap(frame, layout).Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// ColorValue converts the given color value to color. The value can
+// be either a name of a configured named color or an RGB value in
+// one of the supported formats.
+func ColorValue(v *viper.Viper, val string) (*ite8291.Color, error) {
+	return colorValueToColor(val, v)
+}
+
+// NamedColors returns sorted names of the configured named colors.
+func NamedColors(v *viper.Viper) []string {
+
+	var names []string
+	for name := range v.GetStringMap(NamedColorsProp) {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
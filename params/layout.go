@@ -0,0 +1,58 @@
+package params
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// layout properties and flags names.
+const (
+	// LayoutProp - name of layout flag and configuration property.
+	LayoutProp = "layout"
+)
+
+// AddLayout adds layout flag to the given cmd. The flag specifies a
+// file describing physical layout of the keyboard keys. If no layout
+// file is provided, every cell of the keyboard matrix is considered
+// to be a regular key. AddLayout returns function to retrieve the
+// layout.
+func AddLayout(cmd *cobra.Command, v *viper.Viper) (layout func() *ite8291.Layout) {
+
+	var l *ite8291.Layout
+
+	cmd.PersistentFlags().String(LayoutProp, "",
+		"File describing physical layout of the keyboard keys. "+configurationWarning)
+	bindAndValidate(cmd, v, LayoutProp, LayoutProp, func() (err error) {
+		l, err = Layout(v)
+		return err
+	})
+
+	return func() *ite8291.Layout { return l }
+}
+
+// Layout returns the configured keyboard layout or the default one
+// if no layout file is configured.
+func Layout(v *viper.Viper) (*ite8291.Layout, error) {
+
+	name := v.GetString(LayoutProp)
+	if len(name) == 0 {
+		return ite8291.DefaultLayout(), nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, name, "--"+LayoutProp, err)
+	}
+	defer f.Close()
+
+	l, err := ite8291.ReadLayout(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, name, "--"+LayoutProp, err)
+	}
+
+	return l, nil
+}
@@ -0,0 +1,49 @@
+package params
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// MapKeysWaitDefault - default value of map keys wait property.
+const MapKeysWaitDefault = 5 * time.Second
+
+// map keys properties and flags names.
+const (
+	// mapKeysWaitProp - name of map keys wait configuration property.
+	mapKeysWaitProp = "mapKeys.wait"
+	// MapKeysWaitFlag - name of map keys wait flag.
+	MapKeysWaitFlag = "wait"
+
+	// MapKeysOutputFlag - name of map keys output flag.
+	MapKeysOutputFlag = "output"
+)
+
+// AddMapKeys adds key mapping related flags to the given cmd. It
+// returns functions to retrieve time to wait for a key press before a
+// cell of the keyboard matrix is considered empty and the file to
+// write the produced layout to (empty for the command output).
+func AddMapKeys(cmd *cobra.Command, v *viper.Viper) (wait func() time.Duration, output func() string) {
+
+	var file string
+
+	cmd.PersistentFlags().Duration(MapKeysWaitFlag, MapKeysWaitDefault,
+		"Time to wait for a key press before the lit cell is considered to have no key. "+configurationWarning)
+	bindAndValidate(cmd, v, MapKeysWaitFlag, mapKeysWaitProp, func() error {
+
+		if v.GetDuration(mapKeysWaitProp) <= 0 {
+			return fmt.Errorf("%w %q for %q: wait time must be positive",
+				ErrInvalidOptVal, v.GetDuration(mapKeysWaitProp), "--"+MapKeysWaitFlag)
+		}
+
+		return nil
+	})
+
+	cmd.PersistentFlags().StringVarP(&file, MapKeysOutputFlag, "o", "",
+		"File to write the produced keyboard layout to. The layout is written to the standard output if it's not specified.")
+
+	return func() time.Duration { return v.GetDuration(mapKeysWaitProp) }, func() string { return file }
+}
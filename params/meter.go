@@ -0,0 +1,147 @@
+package params
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// default values of meter properties.
+const (
+	// MeterMinDefault - default value of meter min property.
+	MeterMinDefault = 0.0
+	// MeterMaxDefault - default value of meter max property.
+	MeterMaxDefault = 100.0
+	// MeterSmoothingDefault - default value of meter smoothing property.
+	MeterSmoothingDefault = 0.0
+	// MeterHysteresisDefault - default value of meter hysteresis property.
+	MeterHysteresisDefault = 0.0
+)
+
+// MeterColorsDefault - default colors of the meter ramp.
+var MeterColorsDefault = []string{"#00FF00", "#FFFF00", "#FF0000"}
+
+// meter properties and flags names.
+const (
+	// MeterProp - name of the meter configuration property.
+	MeterProp = "meter"
+
+	// meterMinProp - name of the meter min configuration property.
+	meterMinProp = MeterProp + ".min"
+	// MeterMinFlag - name of the meter min flag.
+	MeterMinFlag = "min"
+
+	// meterMaxProp - name of the meter max configuration property.
+	meterMaxProp = MeterProp + ".max"
+	// MeterMaxFlag - name of the meter max flag.
+	MeterMaxFlag = "max"
+
+	// meterColorsProp - name of the meter colors configuration property.
+	meterColorsProp = MeterProp + ".colors"
+	// MeterColorsFlag - name of the meter colors flag.
+	MeterColorsFlag = "colors"
+
+	// meterZoneProp - name of the meter zone configuration property.
+	meterZoneProp = MeterProp + ".zone"
+	// MeterZoneFlag - name of the meter zone flag.
+	MeterZoneFlag = "zone"
+
+	// meterSmoothingProp - name of the meter smoothing configuration property.
+	meterSmoothingProp = MeterProp + ".smoothing"
+	// MeterSmoothingFlag - name of the meter smoothing flag.
+	MeterSmoothingFlag = "smoothing"
+
+	// meterHysteresisProp - name of the meter hysteresis configuration property.
+	meterHysteresisProp = MeterProp + ".hysteresis"
+	// MeterHysteresisFlag - name of the meter hysteresis flag.
+	MeterHysteresisFlag = "hysteresis"
+)
+
+// AddMeter adds meter related flags to the given cmd. It returns
+// functions to create a new meter and to retrieve the cells of the
+// zone showing its color; the cells are nil for the whole keyboard.
+func AddMeter(cmd *cobra.Command, v *viper.Viper) (meter func() *ite8291.Meter, cells func() []ite8291.Cell) {
+
+	var zoneCells []ite8291.Cell
+
+	cmd.PersistentFlags().Float64(MeterMinFlag, MeterMinDefault,
+		"Value mapped to the first color of the ramp. "+configurationWarning)
+	bindAndValidate(cmd, v, MeterMinFlag, meterMinProp, nil)
+
+	cmd.PersistentFlags().Float64(MeterMaxFlag, MeterMaxDefault,
+		"Value mapped to the last color of the ramp; must be greater than the min value. "+configurationWarning)
+	bindAndValidate(cmd, v, MeterMaxFlag, meterMaxProp, func() error {
+
+		if v.GetFloat64(meterMaxProp) <= v.GetFloat64(meterMinProp) {
+			return fmt.Errorf("%w %v for %q; expected value greater than %v of %q", ErrInvalidOptVal,
+				v.GetFloat64(meterMaxProp), "--"+MeterMaxFlag, v.GetFloat64(meterMinProp), "--"+MeterMinFlag)
+		}
+
+		return nil
+	})
+
+	colors := addColorValues(cmd, v, MeterColorsFlag, meterColorsProp,
+		fmt.Sprintf("Colors of the ramp from the min to the max value; defaults to %q.", MeterColorsDefault))
+
+	cmd.PersistentFlags().String(MeterZoneFlag, "",
+		fmt.Sprintf("Name of the zone showing the color instead of the whole keyboard. One of zone names configured via %q property. %s",
+			ZonesProp, configurationWarning))
+	bindAndValidate(cmd, v, MeterZoneFlag, meterZoneProp, func() (err error) {
+
+		zoneCells = nil
+		if len(v.GetString(meterZoneProp)) > 0 {
+			zoneCells, err = Zone(v, v.GetString(meterZoneProp))
+		}
+
+		return err
+	})
+
+	cmd.PersistentFlags().Float64(MeterSmoothingFlag, MeterSmoothingDefault,
+		"Smoothing of the values in [0, 1); 0 shows values as they are. "+configurationWarning)
+	bindAndValidate(cmd, v, MeterSmoothingFlag, meterSmoothingProp, func() error {
+
+		if s := v.GetFloat64(meterSmoothingProp); s < 0 || s >= 1 {
+			return fmt.Errorf("%w %v for %q; expected value in [0, 1)", ErrInvalidOptVal, s, "--"+MeterSmoothingFlag)
+		}
+
+		return nil
+	})
+
+	cmd.PersistentFlags().Float64(MeterHysteresisFlag, MeterHysteresisDefault,
+		"Distance the smoothed value must move from the value shown last to change the color. "+configurationWarning)
+	bindAndValidate(cmd, v, MeterHysteresisFlag, meterHysteresisProp, func() error {
+
+		if h := v.GetFloat64(meterHysteresisProp); h < 0 {
+			return fmt.Errorf("%w %v for %q: hysteresis must not be negative", ErrInvalidOptVal, h, "--"+MeterHysteresisFlag)
+		}
+
+		return nil
+	})
+
+	return func() *ite8291.Meter {
+			return &ite8291.Meter{
+				Min:        v.GetFloat64(meterMinProp),
+				Max:        v.GetFloat64(meterMaxProp),
+				Ramp:       rampOrDefault(colors(), MeterColorsDefault),
+				Smoothing:  v.GetFloat64(meterSmoothingProp),
+				Hysteresis: v.GetFloat64(meterHysteresisProp),
+			}
+		},
+		func() []ite8291.Cell { return zoneCells }
+}
+
+// ParseMeterValue parses a value of the meter given as a decimal
+// number. It returns ErrInvalidOptVal if s is not a finite number.
+func ParseMeterValue(s string) (float64, error) {
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%w %q for meter value; expected number", ErrInvalidOptVal, s)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,153 @@
+package params
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// mqtt properties default values.
+const (
+	// MQTTBrokerDefault - default value of mqtt broker property.
+	MQTTBrokerDefault = "tcp://localhost:1883"
+	// MQTTIDDefault - default value of mqtt id property.
+	MQTTIDDefault = "keyboard"
+	// MQTTDiscoveryPrefixDefault - default value of mqtt discovery
+	// prefix property; it's the default prefix of Home Assistant.
+	MQTTDiscoveryPrefixDefault = "homeassistant"
+	// MQTTRefreshDefault - default value of mqtt refresh property.
+	MQTTRefreshDefault = 2 * time.Second
+)
+
+// mqtt properties and flags names.
+const (
+	// MQTTProp - name of the mqtt configuration property.
+	MQTTProp = "mqtt"
+
+	// mqttBrokerProp - name of the mqtt broker configuration property.
+	mqttBrokerProp = MQTTProp + ".broker"
+	// MQTTBrokerFlag - name of the mqtt broker flag.
+	MQTTBrokerFlag = "broker"
+
+	// mqttUsernameProp - name of the mqtt username configuration property.
+	mqttUsernameProp = MQTTProp + ".username"
+	// MQTTUsernameFlag - name of the mqtt username flag.
+	MQTTUsernameFlag = "username"
+
+	// mqttPasswordProp - name of the mqtt password configuration property.
+	mqttPasswordProp = MQTTProp + ".password"
+	// MQTTPasswordFlag - name of the mqtt password flag.
+	MQTTPasswordFlag = "password"
+
+	// mqttIDProp - name of the mqtt id configuration property.
+	mqttIDProp = MQTTProp + ".id"
+	// MQTTIDFlag - name of the mqtt id flag.
+	MQTTIDFlag = "id"
+
+	// mqttDiscoveryPrefixProp - name of the mqtt discovery prefix
+	// configuration property.
+	mqttDiscoveryPrefixProp = MQTTProp + ".discoveryPrefix"
+	// MQTTDiscoveryPrefixFlag - name of the mqtt discovery prefix flag.
+	MQTTDiscoveryPrefixFlag = "discovery-prefix"
+
+	// mqttRefreshProp - name of the mqtt refresh configuration property.
+	mqttRefreshProp = MQTTProp + ".refresh"
+	// MQTTRefreshFlag - name of the mqtt refresh flag.
+	MQTTRefreshFlag = "refresh"
+)
+
+// AddMQTT adds MQTT bridge related flags to the given cmd. It also
+// adds hook to bind them to the corresponding viper config properties
+// and to validate their values.
+func AddMQTT(cmd *cobra.Command, v *viper.Viper) {
+
+	cmd.PersistentFlags().String(MQTTBrokerFlag, MQTTBrokerDefault,
+		"URL of MQTT broker, e.g. tcp://host:1883 or ssl://host:8883. "+configurationWarning)
+	bindAndValidate(cmd, v, MQTTBrokerFlag, mqttBrokerProp, func() error {
+
+		broker := v.GetString(mqttBrokerProp)
+		u, err := url.Parse(broker)
+		if err == nil && (len(u.Scheme) == 0 || len(u.Host) == 0) {
+			err = errors.New("scheme and host are required")
+		}
+		if err != nil {
+			return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, broker, "--"+MQTTBrokerFlag, err)
+		}
+
+		return nil
+	})
+
+	cmd.PersistentFlags().String(MQTTUsernameFlag, "", "Username of MQTT broker. "+configurationWarning)
+	bindAndValidate(cmd, v, MQTTUsernameFlag, mqttUsernameProp, nil)
+
+	cmd.PersistentFlags().String(MQTTPasswordFlag, "", "Password of MQTT broker. "+configurationWarning)
+	bindAndValidate(cmd, v, MQTTPasswordFlag, mqttPasswordProp, nil)
+
+	cmd.PersistentFlags().String(MQTTIDFlag, MQTTIDDefault,
+		"Identifier of the light used in its topics and unique id. "+configurationWarning)
+	bindAndValidate(cmd, v, MQTTIDFlag, mqttIDProp, func() error {
+		return validateTopicLevel(v.GetString(mqttIDProp), MQTTIDFlag)
+	})
+
+	cmd.PersistentFlags().String(MQTTDiscoveryPrefixFlag, MQTTDiscoveryPrefixDefault,
+		"Prefix of Home Assistant discovery topics. "+configurationWarning)
+	bindAndValidate(cmd, v, MQTTDiscoveryPrefixFlag, mqttDiscoveryPrefixProp, func() error {
+		return validateTopicLevel(v.GetString(mqttDiscoveryPrefixProp), MQTTDiscoveryPrefixFlag)
+	})
+
+	cmd.PersistentFlags().Duration(MQTTRefreshFlag, MQTTRefreshDefault,
+		"Interval of reading the state changed by other programs; 0 reads it only after changes made via MQTT. "+
+			configurationWarning)
+	bindAndValidate(cmd, v, MQTTRefreshFlag, mqttRefreshProp, func() error {
+
+		if v.GetDuration(mqttRefreshProp) < 0 {
+			return fmt.Errorf("%w %q for %q: duration must not be negative",
+				ErrInvalidOptVal, v.GetDuration(mqttRefreshProp), "--"+MQTTRefreshFlag)
+		}
+
+		return nil
+	})
+}
+
+// validateTopicLevel returns error if the given value is not a valid
+// level of MQTT topic.
+func validateTopicLevel(val, flagName string) error {
+
+	if len(val) == 0 || strings.ContainsAny(val, "/+#") {
+		return fmt.Errorf("%w %q for %q: value must be non-empty and must not contain '/', '+' or '#'",
+			ErrInvalidOptVal, val, "--"+flagName)
+	}
+
+	return nil
+}
+
+// MQTTBroker returns URL of MQTT broker.
+func MQTTBroker(v *viper.Viper) string {
+	return v.GetString(mqttBrokerProp)
+}
+
+// MQTTCredentials returns username and password of MQTT broker.
+func MQTTCredentials(v *viper.Viper) (username, password string) {
+	return v.GetString(mqttUsernameProp), v.GetString(mqttPasswordProp)
+}
+
+// MQTTID returns identifier of the light exposed via MQTT.
+func MQTTID(v *viper.Viper) string {
+	return v.GetString(mqttIDProp)
+}
+
+// MQTTDiscoveryPrefix returns prefix of Home Assistant discovery topics.
+func MQTTDiscoveryPrefix(v *viper.Viper) string {
+	return v.GetString(mqttDiscoveryPrefixProp)
+}
+
+// MQTTRefresh returns interval of reading the state of the keyboard
+// backlight by MQTT bridge.
+func MQTTRefresh(v *viper.Viper) time.Duration {
+	return v.GetDuration(mqttRefreshProp)
+}
@@ -0,0 +1,147 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// notify properties default values.
+const (
+	// NotifyPatternDefault - default value of notify pattern property.
+	NotifyPatternDefault = ite8291.NotifyBlink
+	// NotifyCountDefault - default value of notify count property.
+	NotifyCountDefault = 3
+	// NotifyPeriodDefault - default value of notify period property.
+	NotifyPeriodDefault = 500 * time.Millisecond
+)
+
+// notify properties and flags names.
+const (
+	// NotifyProp - name of the notify configuration property.
+	NotifyProp = "notify"
+
+	// notifyPatternProp - name of the notify pattern configuration property.
+	notifyPatternProp = NotifyProp + ".pattern"
+	// NotifyPatternFlag - name of the notify pattern flag.
+	NotifyPatternFlag = "pattern"
+
+	// notifyCountProp - name of the notify count configuration property.
+	notifyCountProp = NotifyProp + ".count"
+	// NotifyCountFlag - name of the notify count flag.
+	NotifyCountFlag = "count"
+
+	// notifyPeriodProp - name of the notify period configuration property.
+	notifyPeriodProp = NotifyProp + ".period"
+	// NotifyPeriodFlag - name of the notify period flag.
+	NotifyPeriodFlag = "period"
+
+	// notifyTextProp - name of the notify text configuration property.
+	notifyTextProp = NotifyProp + ".text"
+	// NotifyTextFlag - name of the notify text flag.
+	NotifyTextFlag = "text"
+)
+
+// FlashPatternNames returns names of the notification patterns flashing
+// a color, i.e. all of them except morse, which needs a text.
+func FlashPatternNames() []string {
+	return slices.DeleteFunc(ite8291.NotifyPatternNames(),
+		func(name string) bool { return name == ite8291.NotifyMorse })
+}
+
+// AddNotify adds notification pattern related flags to the given cmd.
+// It returns function to retrieve the notification pattern lighting
+// the keys by the color provided by the given function. The color must
+// be available by the time the flags are validated.
+func AddNotify(cmd *cobra.Command, v *viper.Viper, color func() *ite8291.Color) (pattern func() ite8291.Animation) {
+
+	var anim ite8291.Animation
+
+	cmd.PersistentFlags().String(NotifyPatternFlag, NotifyPatternDefault,
+		fmt.Sprintf("Notification pattern %q. %s", ite8291.NotifyPatternNames(), configurationWarning))
+	bindAndValidate(cmd, v, NotifyPatternFlag, notifyPatternProp, func() error {
+
+		if slices.Contains(ite8291.NotifyPatternNames(), strings.ToLower(v.GetString(notifyPatternProp))) {
+			return nil
+		}
+
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, v.GetString(notifyPatternProp), "--"+NotifyPatternFlag, ite8291.NotifyPatternNames())
+	})
+
+	cmd.PersistentFlags().Int(NotifyCountFlag, NotifyCountDefault,
+		"Number of repetitions of the notification pattern. "+configurationWarning)
+	bindAndValidate(cmd, v, NotifyCountFlag, notifyCountProp, func() error {
+
+		if v.GetInt(notifyCountProp) <= 0 {
+			return fmt.Errorf("%w %d for %q: count must be positive",
+				ErrInvalidOptVal, v.GetInt(notifyCountProp), "--"+NotifyCountFlag)
+		}
+
+		return nil
+	})
+
+	cmd.PersistentFlags().String(NotifyTextFlag, "",
+		"Text sent in morse code by the morse pattern. "+configurationWarning)
+	bindAndValidate(cmd, v, NotifyTextFlag, notifyTextProp, nil)
+
+	cmd.PersistentFlags().Duration(NotifyPeriodFlag, NotifyPeriodDefault,
+		"Duration of one blink, pulse or sweep; a morse dot lasts a fifth of it. "+configurationWarning)
+	bindAndValidate(cmd, v, NotifyPeriodFlag, notifyPeriodProp, func() (err error) {
+
+		if v.GetDuration(notifyPeriodProp) <= 0 {
+			return fmt.Errorf("%w %q for %q: period must be positive",
+				ErrInvalidOptVal, v.GetDuration(notifyPeriodProp), "--"+NotifyPeriodFlag)
+		}
+
+		if anim, err = ite8291.NewNotifyAnimation(strings.ToLower(v.GetString(notifyPatternProp)),
+			ite8291.NotifyOptions{
+				Color:  color(),
+				Count:  v.GetInt(notifyCountProp),
+				Period: v.GetDuration(notifyPeriodProp),
+				Text:   v.GetString(notifyTextProp),
+			}); err != nil {
+			return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, v.GetString(notifyTextProp), "--"+NotifyTextFlag, err)
+		}
+
+		return nil
+	})
+
+	return func() ite8291.Animation { return anim }
+}
+
+// addFlashPattern adds flag with the given name, default value and
+// usage providing flash pattern of the color given by color function
+// to the given cmd. It also adds hook to bind it to the given viper
+// configuration property and to validate its value against the given
+// names. It returns function to retrieve the pattern; it is nil for
+// the names that aren't notification patterns e.g. solid.
+func addFlashPattern(cmd *cobra.Command, v *viper.Viper, flag, prop, defaultValue string, names []string,
+	color func() *ite8291.Color, usage string) (pattern func() ite8291.Animation) {
+
+	var anim ite8291.Animation
+
+	cmd.PersistentFlags().String(flag, defaultValue,
+		fmt.Sprintf("%s %q. %s", usage, names, configurationWarning))
+	bindAndValidate(cmd, v, flag, prop, func() (err error) {
+
+		name := strings.ToLower(v.GetString(prop))
+		if !slices.Contains(names, name) {
+			return fmt.Errorf("%w %q for %q; expected one of %q", ErrInvalidOptVal, v.GetString(prop), "--"+flag, names)
+		}
+
+		anim = nil
+		if slices.Contains(FlashPatternNames(), name) {
+			anim, err = ite8291.NewNotifyAnimation(name, ite8291.NotifyOptions{Color: color(), Count: NotifyCountDefault})
+		}
+
+		return err
+	})
+
+	return func() ite8291.Animation { return anim }
+}
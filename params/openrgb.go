@@ -0,0 +1,47 @@
+package params
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// OpenRGBAddressDefault - default value of openrgb address property;
+// the port is the default port of OpenRGB SDK servers.
+const OpenRGBAddressDefault = "127.0.0.1:6742"
+
+// openrgb properties and flags names.
+const (
+	// OpenRGBProp - name of the openrgb configuration property.
+	OpenRGBProp = "openrgb"
+
+	// openRGBAddressProp - name of the openrgb address configuration property.
+	openRGBAddressProp = OpenRGBProp + ".address"
+	// OpenRGBAddressFlag - name of the openrgb address flag.
+	OpenRGBAddressFlag = "address"
+)
+
+// AddOpenRGB adds OpenRGB server related flags to the given cmd. It
+// also adds hook to bind them to the corresponding viper config
+// properties and to validate their values.
+func AddOpenRGB(cmd *cobra.Command, v *viper.Viper) {
+
+	cmd.PersistentFlags().String(OpenRGBAddressFlag, OpenRGBAddressDefault,
+		"TCP address \"host:port\" the OpenRGB SDK server listens on. "+configurationWarning)
+	bindAndValidate(cmd, v, OpenRGBAddressFlag, openRGBAddressProp, func() error {
+
+		if _, _, err := net.SplitHostPort(v.GetString(openRGBAddressProp)); err != nil {
+			return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, v.GetString(openRGBAddressProp),
+				"--"+OpenRGBAddressFlag, err)
+		}
+
+		return nil
+	})
+}
+
+// OpenRGBAddress returns TCP address the OpenRGB SDK server listens on.
+func OpenRGBAddress(v *viper.Viper) string {
+	return v.GetString(openRGBAddressProp)
+}
@@ -0,0 +1,148 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// default values of palette cycle properties.
+const (
+	// PaletteCycleDefault - default value of palette cycle property.
+	PaletteCycleDefault = ite8291.PaletteRotate
+	// PalettePeriodDefault - default value of palette period property.
+	PalettePeriodDefault = 10 * time.Second
+)
+
+// palette cycle properties and flags names.
+const (
+	// PaletteProp - name of the palette cycle configuration property.
+	PaletteProp = "paletteCycle"
+
+	// paletteCycleProp - name of the palette cycle name configuration property.
+	paletteCycleProp = PaletteProp + ".cycle"
+	// PaletteCycleFlag - name of the palette cycle flag.
+	PaletteCycleFlag = "cycle"
+
+	// palettesProp - name of the palettes configuration property.
+	palettesProp = PaletteProp + ".palettes"
+	// PaletteFlag - name of the palette flag.
+	PaletteFlag = "palette"
+
+	// palettePeriodProp - name of the palette period configuration property.
+	palettePeriodProp = PaletteProp + ".period"
+	// PalettePeriodFlag - name of the palette period flag.
+	PalettePeriodFlag = "period"
+)
+
+// AddPaletteCycle adds palette cycle related flags to the given cmd.
+// It returns function to retrieve the palette cycle. The palettes
+// default to the single palette of the configured predefined colors.
+func AddPaletteCycle(cmd *cobra.Command, v *viper.Viper) (cycle func() ite8291.PaletteCycle) {
+
+	var c ite8291.PaletteCycle
+
+	cmd.PersistentFlags().String(PaletteCycleFlag, PaletteCycleDefault,
+		fmt.Sprintf("Palette cycle %q. %s", ite8291.PaletteCycleNames(), configurationWarning))
+	bindAndValidate(cmd, v, PaletteCycleFlag, paletteCycleProp, func() error {
+
+		if slices.Contains(ite8291.PaletteCycleNames(), strings.ToLower(v.GetString(paletteCycleProp))) {
+			return nil
+		}
+
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, v.GetString(paletteCycleProp), "--"+PaletteCycleFlag, ite8291.PaletteCycleNames())
+	})
+
+	cmd.PersistentFlags().StringArray(PaletteFlag, nil,
+		fmt.Sprintf("Comma separated colors of a palette; can be repeated. Colors are either names of the colors configured via %q property or RGB values in a one of the following formats %q. The palette defaults to the predefined colors. %s",
+			NamedColorsProp, ite8291.SupportedColorStringFormats, configurationWarning))
+	bindAndValidate(cmd, v, PaletteFlag, palettesProp, nil)
+
+	cmd.PersistentFlags().Duration(PalettePeriodFlag, PalettePeriodDefault,
+		"Duration of the palette cycle. "+configurationWarning)
+	bindAndValidate(cmd, v, PalettePeriodFlag, palettePeriodProp, func() error {
+
+		if v.GetDuration(palettePeriodProp) <= 0 {
+			return fmt.Errorf("%w %q for %q: period must be positive",
+				ErrInvalidOptVal, v.GetDuration(palettePeriodProp), "--"+PalettePeriodFlag)
+		}
+
+		palettes, err := palettes(v)
+		if err != nil {
+			return err
+		}
+
+		if c, err = ite8291.NewPaletteCycle(strings.ToLower(v.GetString(paletteCycleProp)), palettes,
+			v.GetDuration(palettePeriodProp)); err != nil {
+			return fmt.Errorf("%w for %q: %w", ErrInvalidOptVal, "--"+PaletteFlag, err)
+		}
+
+		return nil
+	})
+
+	return func() ite8291.PaletteCycle { return c }
+}
+
+// palettes returns palettes given by palettes property value. Every
+// palette is either a list of colors or a string of comma separated
+// colors. It returns the single palette of the predefined colors if
+// there are no palettes.
+func palettes(v *viper.Viper) ([][]*ite8291.Color, error) {
+
+	var vals [][]string
+	switch val := v.Get(palettesProp).(type) {
+	case nil:
+	case []string:
+		for _, p := range val {
+			vals = append(vals, strings.Split(p, ","))
+		}
+	case []any:
+		for _, p := range val {
+			switch p := p.(type) {
+			case string:
+				vals = append(vals, strings.Split(p, ","))
+			case []any:
+				var colors []string
+				for _, col := range p {
+					colors = append(colors, fmt.Sprint(col))
+				}
+				vals = append(vals, colors)
+			default:
+				return nil, fmt.Errorf("%w %v for %q: palette must be a list of colors", ErrInvalidOptVal, p, "--"+PaletteFlag)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w %v for %q: expected list of palettes", ErrInvalidOptVal, val, "--"+PaletteFlag)
+	}
+
+	if len(vals) == 0 {
+		palette := make([]*ite8291.Color, ite8291.PaletteSize)
+		for i := range palette {
+			var err error
+			if palette[i], err = PredefinedColor(v, i+1); err != nil {
+				return nil, err
+			}
+		}
+
+		return [][]*ite8291.Color{palette}, nil
+	}
+
+	palettes := make([][]*ite8291.Color, len(vals))
+	for i, p := range vals {
+		for _, val := range p {
+			col, err := colorValueToColor(strings.TrimSpace(val), v)
+			if err != nil {
+				return nil, fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, val, "--"+PaletteFlag, err)
+			}
+			palettes[i] = append(palettes[i], col)
+		}
+	}
+
+	return palettes, nil
+}
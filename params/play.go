@@ -0,0 +1,88 @@
+package params
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// PlayLoopDefault - default value of play loop property.
+const PlayLoopDefault = false
+
+// play properties and flags names.
+const (
+	// PlayProp - name of the play configuration property.
+	PlayProp = "play"
+
+	// playFileProp - name of the play file configuration property.
+	playFileProp = PlayProp + ".file"
+
+	// playLoopProp - name of the play loop configuration property.
+	playLoopProp = PlayProp + ".loop"
+	// PlayLoopFlag - name of the play loop flag.
+	PlayLoopFlag = "loop"
+)
+
+// AddPlayLoop adds play loop flag to the given cmd. It also adds hook
+// to bind it to the corresponding viper configuration property.
+func AddPlayLoop(cmd *cobra.Command, v *viper.Viper) {
+
+	cmd.PersistentFlags().Bool(PlayLoopFlag, PlayLoopDefault,
+		"Play the animation repeatedly until interrupted instead of once. "+configurationWarning)
+	bindAndValidate(cmd, v, PlayLoopFlag, playLoopProp, nil)
+}
+
+// PlayLoop returns play loop property value.
+func PlayLoop(v *viper.Viper) bool {
+	return v.GetBool(playLoopProp)
+}
+
+// PlayFile returns keyframe animation file given by the first of the
+// given command arguments or, if there are none, by play file
+// property value. It returns ErrInvalidOptVal if there is no file.
+func PlayFile(v *viper.Viper, args []string) (string, error) {
+
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	if file := v.GetString(playFileProp); len(file) > 0 {
+		return file, nil
+	}
+
+	return "", fmt.Errorf("%w animation file is missing (either configured via %q property or specified explicitly)",
+		ErrInvalidOptVal, playFileProp)
+}
+
+// KeyframeAnimation reads keyframe animation from the given file and resolves
+// it using the given layout. Colors of the animation are either names
+// of configured named colors or RGB values; zones are names of
+// configured zones; directions are gradient directions.
+func KeyframeAnimation(v *viper.Viper, file string, layout *ite8291.Layout, loop bool) (ite8291.Animation, error) {
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidOptVal, file, err)
+	}
+	defer f.Close()
+
+	k, err := ite8291.ReadKeyframes(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidOptVal, file, err)
+	}
+
+	anim, err := k.Animation(&ite8291.KeyframeResolver{
+		Layout:    layout,
+		Color:     func(val string) (*ite8291.Color, error) { return ColorValue(v, val) },
+		Zone:      func(name string) ([]ite8291.Cell, error) { return Zone(v, name) },
+		Direction: ParseGradientDirection,
+	}, loop)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidOptVal, file, err)
+	}
+
+	return anim, nil
+}
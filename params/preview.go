@@ -0,0 +1,49 @@
+package params
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/v4n6/itectl/pkg/preview"
+)
+
+// preview flags names.
+const (
+	// PreviewFlag - name of the preview flag.
+	PreviewFlag = "preview"
+	// PreviewFileFlag - name of the preview file flag.
+	PreviewFileFlag = "preview-file"
+)
+
+// AddPreview adds preview flags to the given cmd. They are not bound
+// to configuration properties. AddPreview returns function to
+// retrieve whether the keyboard backlight state should be rendered
+// instead of applied, the file to render it to (empty for the
+// terminal) and its format.
+func AddPreview(cmd *cobra.Command) (previewed func() (enabled bool, file string, format preview.Format)) {
+
+	var enabled bool
+	var file string
+	var format preview.Format
+
+	cmd.PersistentFlags().BoolVar(&enabled, PreviewFlag, false,
+		"Render the resulting keyboard backlight state in the terminal instead of applying it to the device.")
+	cmd.PersistentFlags().StringVar(&file, PreviewFileFlag, "",
+		fmt.Sprintf("Render the resulting keyboard backlight state to the given PNG, SVG or ANSI text file instead of applying it to the device; implies --%s.",
+			PreviewFlag))
+
+	addValidationHook(cmd, func() (err error) {
+
+		if len(file) == 0 {
+			return nil
+		}
+
+		if format, err = preview.FormatOf(file); err != nil {
+			return fmt.Errorf("%w %q for %q: %w", ErrInvalidOptVal, file, "--"+PreviewFileFlag, err)
+		}
+
+		return nil
+	})
+
+	return func() (bool, string, preview.Format) { return enabled || len(file) > 0, file, format }
+}
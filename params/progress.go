@@ -0,0 +1,156 @@
+package params
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// progress and timer properties default values.
+const (
+	// ProgressRowDefault - default value of progress and timer row properties.
+	ProgressRowDefault = 0
+	// ProgressFillDefault - default value of progress fill property.
+	ProgressFillDefault = "#00FF00"
+	// ProgressBackgroundDefault - default value of progress and timer background properties.
+	ProgressBackgroundDefault = "#000000"
+	// TimerFillDefault - default value of timer fill property.
+	TimerFillDefault = "#FF0000"
+	// TimerFinishDefault - default value of timer finish color property.
+	TimerFinishDefault = "#FFFFFF"
+	// TimerFinishPatternDefault - default value of timer finish pattern property.
+	TimerFinishPatternDefault = ite8291.NotifyBlink
+)
+
+// progress and timer properties and flags names.
+const (
+	// ProgressProp - name of the progress configuration property.
+	ProgressProp = "progress"
+	// TimerProp - name of the timer configuration property.
+	TimerProp = "timer"
+
+	// ProgressRowFlag - name of the progress bar row flag.
+	ProgressRowFlag = "row"
+	// ProgressZoneFlag - name of the progress bar zone flag.
+	ProgressZoneFlag = "zone"
+	// ProgressFillFlag - name of the progress bar fill color flag.
+	ProgressFillFlag = "fill"
+	// ProgressBackgroundFlag - name of the progress bar background color flag.
+	ProgressBackgroundFlag = "background"
+	// ProgressStdinFlag - name of the flag to read progress from the standard input.
+	ProgressStdinFlag = "stdin"
+
+	// timerFinishProp - name of the timer finish color configuration property.
+	timerFinishProp = TimerProp + ".finish.color"
+	// TimerFinishFlag - name of the timer finish color flag.
+	TimerFinishFlag = "finish"
+
+	// timerFinishPatternProp - name of the timer finish pattern configuration property.
+	timerFinishPatternProp = TimerProp + ".finish.pattern"
+	// TimerFinishPatternFlag - name of the timer finish pattern flag.
+	TimerFinishPatternFlag = "finish-pattern"
+)
+
+// AddProgress adds progress bar flags to the given cmd. It returns
+// functions to retrieve the cells of the bar, its fill and background
+// colors and whether the progress is read from the standard input.
+func AddProgress(cmd *cobra.Command, v *viper.Viper) (cells func() []ite8291.Cell,
+	fill, background func() *ite8291.Color, stdin func() bool) {
+
+	cells, fill, background = addBar(cmd, v, ProgressProp, ProgressFillDefault)
+
+	readStdin := cmd.PersistentFlags().Bool(ProgressStdinFlag, false,
+		"Read the progress from the standard input, a percentage per line, instead of the argument.")
+
+	return cells, fill, background, func() bool { return *readStdin }
+}
+
+// ParseProgress parses percentage given in "42", "42.5" or "42%"
+// format and returns it as the progress in [0, 1]. It returns
+// ErrInvalidOptVal if s is not a percentage in [0, 100].
+func ParseProgress(s string) (float64, error) {
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || math.IsNaN(percent) || percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("%w %q for progress; expected percentage [0,100]", ErrInvalidOptVal, s)
+	}
+
+	return percent / 100, nil
+}
+
+// ParseTimerDuration parses countdown duration given in "25m" or
+// "1h30m" format. It returns ErrInvalidOptVal if s is not a positive
+// duration.
+func ParseTimerDuration(s string) (time.Duration, error) {
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%w %q for countdown duration; expected positive duration e.g. 25m", ErrInvalidOptVal, s)
+	}
+
+	return d, nil
+}
+
+// AddTimer adds countdown bar and finish notification flags to the
+// given cmd. It returns functions to retrieve the cells of the bar,
+// its fill and background colors and the pattern flashed once the
+// countdown is over.
+func AddTimer(cmd *cobra.Command, v *viper.Viper) (cells func() []ite8291.Cell,
+	fill, background func() *ite8291.Color, finish func() ite8291.Animation) {
+
+	cells, fill, background = addBar(cmd, v, TimerProp, TimerFillDefault)
+
+	finishColor := addColorValue(cmd, v, TimerFinishFlag, timerFinishProp, TimerFinishDefault,
+		"Color flashed once the countdown is over.")
+	finish = addFlashPattern(cmd, v, TimerFinishPatternFlag, timerFinishPatternProp, TimerFinishPatternDefault,
+		FlashPatternNames(), finishColor, "Pattern flashed once the countdown is over")
+
+	return cells, fill, background, finish
+}
+
+// addBar adds flags of a bar over a row or a zone of the keyboard
+// matrix to the given cmd. Their configuration properties are nested
+// in the given property. The fill color defaults to the given value.
+// It returns functions to retrieve the cells of the bar, its fill and
+// background colors.
+func addBar(cmd *cobra.Command, v *viper.Viper, prop, fillDefault string) (cells func() []ite8291.Cell,
+	fill, background func() *ite8291.Color) {
+
+	var barCells []ite8291.Cell
+
+	rowProp, zoneProp := prop+"."+ProgressRowFlag, prop+"."+ProgressZoneFlag
+
+	cmd.PersistentFlags().Uint8(ProgressRowFlag, ProgressRowDefault,
+		fmt.Sprintf("Row of the keyboard matrix showing the bar; min value 0, max value %d. %s",
+			ite8291.RowsNumber-1, configurationWarning))
+	bindAndValidate(cmd, v, ProgressRowFlag, rowProp, func() error {
+		return validateMaxUint8Value("--"+ProgressRowFlag, byte(v.GetUint(rowProp)), ite8291.RowsNumber-1)
+	})
+
+	cmd.PersistentFlags().String(ProgressZoneFlag, "",
+		fmt.Sprintf("Name of the zone showing the bar instead of the row. One of zone names configured via %q property. %s",
+			ZonesProp, configurationWarning))
+	bindAndValidate(cmd, v, ProgressZoneFlag, zoneProp, func() (err error) {
+
+		if len(v.GetString(zoneProp)) == 0 {
+			barCells = ite8291.RowCells(v.GetInt(rowProp))
+			return nil
+		}
+
+		barCells, err = Zone(v, v.GetString(zoneProp))
+		return err
+	})
+
+	fill = addColorValue(cmd, v, ProgressFillFlag, prop+"."+ProgressFillFlag, fillDefault,
+		"Color of the filled part of the bar.")
+	background = addColorValue(cmd, v, ProgressBackgroundFlag, prop+"."+ProgressBackgroundFlag,
+		ProgressBackgroundDefault, "Color of the empty part of the bar.")
+
+	return func() []ite8291.Cell { return barCells }, fill, background
+}
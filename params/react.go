@@ -0,0 +1,89 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// default values of react properties.
+const (
+	// ReactBackgroundDefault - default value of react background property.
+	ReactBackgroundDefault = "#000000"
+	// ReactDecayDefault - default value of react decay property.
+	ReactDecayDefault = time.Second
+)
+
+// react properties and flags names.
+const (
+	// ReactProp - name of the react configuration property.
+	ReactProp = "react"
+
+	// reactEffectProp - name of the react effect configuration property.
+	reactEffectProp = ReactProp + ".effect"
+	// ReactEffectFlag - name of the react effect flag.
+	ReactEffectFlag = "effect"
+
+	// reactColorsProp - name of the react colors configuration property.
+	reactColorsProp = ReactProp + ".colors"
+	// ReactColorsFlag - name of the react colors flag.
+	ReactColorsFlag = "colors"
+
+	// reactBackgroundProp - name of the react background configuration property.
+	reactBackgroundProp = ReactProp + ".background"
+	// ReactBackgroundFlag - name of the react background flag.
+	ReactBackgroundFlag = "background"
+
+	// reactDecayProp - name of the react decay configuration property.
+	reactDecayProp = ReactProp + ".decay"
+	// ReactDecayFlag - name of the react decay flag.
+	ReactDecayFlag = "decay"
+)
+
+// AddReact adds reactive software effect related flags to the given
+// cmd. It returns function to retrieve options of the effect with its
+// name. The effect must be provided either via the flag or via
+// configuration.
+func AddReact(cmd *cobra.Command, v *viper.Viper) (effect func() string, options func() ite8291.ReactiveOptions) {
+
+	cmd.PersistentFlags().String(ReactEffectFlag, "",
+		fmt.Sprintf("Reactive software effect to play %q. %s", ite8291.ReactiveAnimationNames(), configurationWarning))
+	bindAndValidate(cmd, v, ReactEffectFlag, reactEffectProp, func() error {
+
+		if slices.Contains(ite8291.ReactiveAnimationNames(), strings.ToLower(v.GetString(reactEffectProp))) {
+			return nil
+		}
+
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, v.GetString(reactEffectProp), "--"+ReactEffectFlag, ite8291.ReactiveAnimationNames())
+	})
+
+	colors := addColorValues(cmd, v, ReactColorsFlag, reactColorsProp,
+		"Color(s) of the pressed keys used one after another; the heat effect uses them from cold to hot keys.")
+	background := addColorValue(cmd, v, ReactBackgroundFlag, reactBackgroundProp, ReactBackgroundDefault,
+		"Color of the keys not lit by presses.")
+
+	cmd.PersistentFlags().Duration(ReactDecayFlag, ReactDecayDefault,
+		"Time a press fades out in; the heat of the keys halves in it. "+configurationWarning)
+	bindAndValidate(cmd, v, ReactDecayFlag, reactDecayProp, func() error {
+
+		if v.GetDuration(reactDecayProp) <= 0 {
+			return fmt.Errorf("%w %q for %q: decay must be positive",
+				ErrInvalidOptVal, v.GetDuration(reactDecayProp), "--"+ReactDecayFlag)
+		}
+
+		return nil
+	})
+
+	return func() string { return strings.ToLower(v.GetString(reactEffectProp)) },
+		func() ite8291.ReactiveOptions {
+			return ite8291.ReactiveOptions{
+				Colors: colors(), Background: background(), Decay: v.GetDuration(reactDecayProp),
+			}
+		}
+}
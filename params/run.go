@@ -0,0 +1,135 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// RunPatternSolid - name of the outcome pattern showing the color of
+// the outcome steadily.
+const RunPatternSolid = "solid"
+
+// run properties default values.
+const (
+	// RunBusyDefault - default value of run busy effect property.
+	RunBusyDefault = "wave"
+	// RunSuccessDefault - default value of run success color property.
+	RunSuccessDefault = "#00FF00"
+	// RunFailureDefault - default value of run failure color property.
+	RunFailureDefault = "#FF0000"
+	// RunPatternDefault - default value of run outcome pattern properties.
+	RunPatternDefault = RunPatternSolid
+	// RunRestoreDefault - default value of run restore property.
+	RunRestoreDefault = 0
+)
+
+// run properties and flags names.
+const (
+	// RunProp - name of the run configuration property.
+	RunProp = "run"
+
+	// runBusyProp - name of the run busy effect configuration property.
+	runBusyProp = RunProp + ".busy.effect"
+	// RunBusyFlag - name of the run busy effect flag.
+	RunBusyFlag = "busy"
+
+	// runBusyColorsProp - name of the run busy colors configuration property.
+	runBusyColorsProp = RunProp + ".busy.colors"
+	// RunBusyColorsFlag - name of the run busy colors flag.
+	RunBusyColorsFlag = "busy-colors"
+
+	// runSuccessProp - name of the run success color configuration property.
+	runSuccessProp = RunProp + ".success.color"
+	// RunSuccessFlag - name of the run success color flag.
+	RunSuccessFlag = "success"
+
+	// runSuccessPatternProp - name of the run success pattern configuration property.
+	runSuccessPatternProp = RunProp + ".success.pattern"
+	// RunSuccessPatternFlag - name of the run success pattern flag.
+	RunSuccessPatternFlag = "success-pattern"
+
+	// runFailureProp - name of the run failure color configuration property.
+	runFailureProp = RunProp + ".failure.color"
+	// RunFailureFlag - name of the run failure color flag.
+	RunFailureFlag = "failure"
+
+	// runFailurePatternProp - name of the run failure pattern configuration property.
+	runFailurePatternProp = RunProp + ".failure.pattern"
+	// RunFailurePatternFlag - name of the run failure pattern flag.
+	RunFailurePatternFlag = "failure-pattern"
+
+	// runRestoreProp - name of the run restore configuration property.
+	runRestoreProp = RunProp + ".restore"
+	// RunRestoreFlag - name of the run restore flag.
+	RunRestoreFlag = "restore"
+)
+
+// RunPatternNames returns names of the outcome patterns: solid and the
+// flash patterns.
+func RunPatternNames() []string {
+	return append([]string{RunPatternSolid}, FlashPatternNames()...)
+}
+
+// AddRun adds flags of the job status indicator to the given cmd. It
+// returns functions to retrieve the software effect shown while the
+// job runs and its colors, the color and the pattern of the job
+// outcome given by success and the delay to restore the previous
+// state after. The pattern is nil for the solid outcome. The delay is
+// 0 if the outcome is kept.
+func AddRun(cmd *cobra.Command, v *viper.Viper) (busy func() string, busyColors func() []*ite8291.Color,
+	outcome func(success bool) (*ite8291.Color, ite8291.Animation), restore func() time.Duration) {
+
+	cmd.PersistentFlags().String(RunBusyFlag, RunBusyDefault,
+		fmt.Sprintf("Software effect shown while the command runs %q. %s", ite8291.AnimationNames(), configurationWarning))
+	bindAndValidate(cmd, v, RunBusyFlag, runBusyProp, func() error {
+
+		if slices.Contains(ite8291.AnimationNames(), strings.ToLower(v.GetString(runBusyProp))) {
+			return nil
+		}
+
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, v.GetString(runBusyProp), "--"+RunBusyFlag, ite8291.AnimationNames())
+	})
+
+	busyColors = addColorValues(cmd, v, RunBusyColorsFlag, runBusyColorsProp,
+		"Color(s) of the software effect shown while the command runs; the effect uses its own colors if none are given.")
+
+	successColor := addColorValue(cmd, v, RunSuccessFlag, runSuccessProp, RunSuccessDefault,
+		"Color shown if the command succeeds.")
+	successPattern := addFlashPattern(cmd, v, RunSuccessPatternFlag, runSuccessPatternProp, RunPatternDefault,
+		RunPatternNames(), successColor, "Pattern of the color shown if the command succeeds; the color stays afterwards")
+
+	failureColor := addColorValue(cmd, v, RunFailureFlag, runFailureProp, RunFailureDefault,
+		"Color shown if the command fails.")
+	failurePattern := addFlashPattern(cmd, v, RunFailurePatternFlag, runFailurePatternProp, RunPatternDefault,
+		RunPatternNames(), failureColor, "Pattern of the color shown if the command fails; the color stays afterwards")
+
+	cmd.PersistentFlags().Duration(RunRestoreFlag, RunRestoreDefault,
+		"Delay to restore the previous state of the keyboard backlight after the command exits; 0 keeps the outcome. "+
+			configurationWarning)
+	bindAndValidate(cmd, v, RunRestoreFlag, runRestoreProp, func() error {
+
+		if v.GetDuration(runRestoreProp) < 0 {
+			return fmt.Errorf("%w %q for %q: delay must not be negative",
+				ErrInvalidOptVal, v.GetDuration(runRestoreProp), "--"+RunRestoreFlag)
+		}
+
+		return nil
+	})
+
+	return func() string { return strings.ToLower(v.GetString(runBusyProp)) },
+		busyColors,
+		func(success bool) (*ite8291.Color, ite8291.Animation) {
+			if success {
+				return successColor(), successPattern()
+			}
+			return failureColor(), failurePattern()
+		},
+		func() time.Duration { return v.GetDuration(runRestoreProp) }
+}
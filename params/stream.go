@@ -0,0 +1,44 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// StreamFormatDefault - default value of stream format property.
+const StreamFormatDefault = ite8291.StreamText
+
+// stream properties and flags names.
+const (
+	// StreamProp - name of the stream configuration property.
+	StreamProp = "stream"
+
+	// streamFormatProp - name of the stream format configuration property.
+	streamFormatProp = StreamProp + ".format"
+	// StreamFormatFlag - name of the stream format flag.
+	StreamFormatFlag = "format"
+)
+
+// AddStreamFormat adds stream format flag to the given cmd. It
+// returns function to retrieve format of the frame stream.
+func AddStreamFormat(cmd *cobra.Command, v *viper.Viper) (format func() string) {
+
+	cmd.PersistentFlags().String(StreamFormatFlag, StreamFormatDefault,
+		fmt.Sprintf("Format of the frame stream %q. %s", ite8291.StreamFormats(), configurationWarning))
+	bindAndValidate(cmd, v, StreamFormatFlag, streamFormatProp, func() error {
+
+		if slices.Contains(ite8291.StreamFormats(), strings.ToLower(v.GetString(streamFormatProp))) {
+			return nil
+		}
+
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, v.GetString(streamFormatProp), "--"+StreamFormatFlag, ite8291.StreamFormats())
+	})
+
+	return func() string { return strings.ToLower(v.GetString(streamFormatProp)) }
+}
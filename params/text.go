@@ -0,0 +1,97 @@
+package params
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// default values of text properties.
+const (
+	// TextColorDefault - default value of text color property.
+	TextColorDefault = "#FFFFFF"
+	// TextBackgroundDefault - default value of text background property.
+	TextBackgroundDefault = "#000000"
+	// TextOnceDefault - default value of text once property.
+	TextOnceDefault = false
+)
+
+// text properties and flags names.
+const (
+	// TextProp - name of the text configuration property.
+	TextProp = "text"
+
+	// textMessageProp - name of the text message configuration property.
+	textMessageProp = TextProp + ".message"
+	// TextMessageFlag - name of the text message flag.
+	TextMessageFlag = "text"
+
+	// textColorProp - name of the text color configuration property.
+	textColorProp = TextProp + ".color"
+	// TextColorFlag - name of the text color flag.
+	TextColorFlag = "color"
+
+	// textBackgroundProp - name of the text background configuration property.
+	textBackgroundProp = TextProp + ".background"
+	// TextBackgroundFlag - name of the text background flag.
+	TextBackgroundFlag = "background"
+
+	// textOnceProp - name of the text once configuration property.
+	textOnceProp = TextProp + ".once"
+	// TextOnceFlag - name of the text once flag.
+	TextOnceFlag = "once"
+)
+
+// boundaries of the delay between text scroll steps.
+const (
+	scrollDelayMax  = 400 * time.Millisecond
+	scrollDelayStep = 35 * time.Millisecond
+)
+
+// AddText adds text related flags to the given cmd. It returns
+// functions to retrieve the text to show and its foreground and
+// background colors. The text must be provided either via the flag or
+// via configuration.
+func AddText(cmd *cobra.Command, v *viper.Viper) (text func() string, color, background func() *ite8291.Color) {
+
+	cmd.PersistentFlags().String(TextMessageFlag, "", "Text to show on the keyboard. "+configurationWarning)
+	bindAndValidate(cmd, v, TextMessageFlag, textMessageProp, func() error {
+
+		if len(v.GetString(textMessageProp)) == 0 {
+			return fmt.Errorf("%w text is missing for %q (either configured or specified explicitly)",
+				ErrInvalidOptVal, "--"+TextMessageFlag)
+		}
+
+		return nil
+	})
+
+	color = addColorValue(cmd, v, TextColorFlag, textColorProp, TextColorDefault, "Color of the text.")
+	background = addColorValue(cmd, v, TextBackgroundFlag, textBackgroundProp, TextBackgroundDefault,
+		"Color of the background.")
+
+	return func() string { return v.GetString(textMessageProp) }, color, background
+}
+
+// AddTextOnce adds text once flag to the given cmd. It also adds hook
+// to bind it to the corresponding viper configuration property.
+func AddTextOnce(cmd *cobra.Command, v *viper.Viper) {
+
+	cmd.PersistentFlags().Bool(TextOnceFlag, TextOnceDefault,
+		"Scroll the text once instead of looping until interrupted. "+configurationWarning)
+	bindAndValidate(cmd, v, TextOnceFlag, textOnceProp, nil)
+}
+
+// TextOnce returns text once property value.
+func TextOnce(v *viper.Viper) bool {
+	return v.GetBool(textOnceProp)
+}
+
+// ScrollDelay returns delay between text scroll steps corresponding
+// to speed property value: 400ms for speed 0 down to 50ms for the
+// maximum speed.
+func ScrollDelay(v *viper.Viper) time.Duration {
+	return scrollDelayMax - time.Duration(v.GetUint(SpeedProp))*scrollDelayStep
+}
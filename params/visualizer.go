@@ -0,0 +1,143 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/audio"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// visualizer styles names.
+const (
+	// VisualizerSpectrum - name of the visualizer style showing spectrum.
+	VisualizerSpectrum = "spectrum"
+	// VisualizerVU - name of the visualizer style showing VU meter.
+	VisualizerVU = "vu"
+)
+
+// visualizerStyles - names of the visualizer styles.
+var visualizerStyles = []string{VisualizerSpectrum, VisualizerVU}
+
+// default values of visualizer properties.
+const (
+	// VisualizerStyleDefault - default value of visualizer style property.
+	VisualizerStyleDefault = VisualizerSpectrum
+	// VisualizerSmoothingDefault - default value of visualizer smoothing property.
+	VisualizerSmoothingDefault = 0.5
+)
+
+// VisualizerColorsDefault - default colors of the visualizer ramp.
+var VisualizerColorsDefault = []string{"#00FF00", "#FFFF00", "#FF0000"}
+
+// visualizer properties and flags names.
+const (
+	// VisualizerProp - name of the visualizer configuration property.
+	VisualizerProp = "visualizer"
+
+	// visualizerStyleProp - name of the visualizer style configuration property.
+	visualizerStyleProp = VisualizerProp + ".style"
+	// VisualizerStyleFlag - name of the visualizer style flag.
+	VisualizerStyleFlag = "style"
+
+	// visualizerColorsProp - name of the visualizer colors configuration property.
+	visualizerColorsProp = VisualizerProp + ".colors"
+	// VisualizerColorsFlag - name of the visualizer colors flag.
+	VisualizerColorsFlag = "colors"
+
+	// visualizerSmoothingProp - name of the visualizer smoothing configuration property.
+	visualizerSmoothingProp = VisualizerProp + ".smoothing"
+	// VisualizerSmoothingFlag - name of the visualizer smoothing flag.
+	VisualizerSmoothingFlag = "smoothing"
+
+	// visualizerFileProp - name of the visualizer file configuration property.
+	visualizerFileProp = VisualizerProp + ".file"
+	// VisualizerFileFlag - name of the visualizer file flag.
+	VisualizerFileFlag = "file"
+
+	// visualizerRateProp - name of the visualizer rate configuration property.
+	visualizerRateProp = VisualizerProp + ".rate"
+	// VisualizerRateFlag - name of the visualizer rate flag.
+	VisualizerRateFlag = "rate"
+
+	// visualizerChannelsProp - name of the visualizer channels configuration property.
+	visualizerChannelsProp = VisualizerProp + ".channels"
+	// VisualizerChannelsFlag - name of the visualizer channels flag.
+	VisualizerChannelsFlag = "channels"
+)
+
+// AddVisualizer adds audio visualizer related flags to the given cmd.
+// It returns functions to retrieve style of the visualizer, its color
+// ramp, smoothing of the levels, PCM file (empty for the standard
+// input) and format of the PCM audio.
+func AddVisualizer(cmd *cobra.Command, v *viper.Viper) (style func() string, ramp func() ite8291.Gradient,
+	smoothing func() float64, file func() string, format func() audio.Format) {
+
+	cmd.PersistentFlags().String(VisualizerStyleFlag, VisualizerStyleDefault,
+		fmt.Sprintf("Style of the visualizer %q. %s", visualizerStyles, configurationWarning))
+	bindAndValidate(cmd, v, VisualizerStyleFlag, visualizerStyleProp, func() error {
+
+		if slices.Contains(visualizerStyles, strings.ToLower(v.GetString(visualizerStyleProp))) {
+			return nil
+		}
+
+		return fmt.Errorf("%w %q for %q; expected one of %q",
+			ErrInvalidOptVal, v.GetString(visualizerStyleProp), "--"+VisualizerStyleFlag, visualizerStyles)
+	})
+
+	colors := addColorValues(cmd, v, VisualizerColorsFlag, visualizerColorsProp,
+		fmt.Sprintf("Colors of the ramp from low to high levels; defaults to %q.", VisualizerColorsDefault))
+
+	cmd.PersistentFlags().Float64(VisualizerSmoothingFlag, VisualizerSmoothingDefault,
+		"Smoothing of falling levels in [0, 1); 0 shows levels as they are. "+configurationWarning)
+	bindAndValidate(cmd, v, VisualizerSmoothingFlag, visualizerSmoothingProp, func() error {
+
+		if s := v.GetFloat64(visualizerSmoothingProp); s < 0 || s >= 1 {
+			return fmt.Errorf("%w %v for %q; expected value in [0, 1)", ErrInvalidOptVal, s, "--"+VisualizerSmoothingFlag)
+		}
+
+		return nil
+	})
+
+	cmd.PersistentFlags().String(VisualizerFileFlag, "",
+		"File or named pipe to read PCM audio from; the audio is read from the standard input if it's not specified or is '-'. "+
+			configurationWarning)
+	bindAndValidate(cmd, v, VisualizerFileFlag, visualizerFileProp, nil)
+
+	cmd.PersistentFlags().Int(VisualizerRateFlag, audio.RateDefault,
+		fmt.Sprintf("Number of PCM frames per second; min value %d, max value %d. %s",
+			audio.RateMinValue, audio.RateMaxValue, configurationWarning))
+	cmd.PersistentFlags().Int(VisualizerChannelsFlag, audio.ChannelsDefault,
+		fmt.Sprintf("Number of PCM channels; min value %d, max value %d. %s",
+			audio.ChannelsMinValue, audio.ChannelsMaxValue, configurationWarning))
+	bindAndValidate(cmd, v, VisualizerRateFlag, visualizerRateProp, nil)
+	bindAndValidate(cmd, v, VisualizerChannelsFlag, visualizerChannelsProp, func() error {
+
+		if err := visualizerFormat(v).Validate(); err != nil {
+			return fmt.Errorf("%w for %q and %q: %w", ErrInvalidOptVal,
+				"--"+VisualizerRateFlag, "--"+VisualizerChannelsFlag, err)
+		}
+
+		return nil
+	})
+
+	return func() string { return strings.ToLower(v.GetString(visualizerStyleProp)) },
+		func() ite8291.Gradient { return rampOrDefault(colors(), VisualizerColorsDefault) },
+		func() float64 { return v.GetFloat64(visualizerSmoothingProp) },
+		func() string {
+			if file := v.GetString(visualizerFileProp); file != "-" {
+				return file
+			}
+			return ""
+		},
+		func() audio.Format { return visualizerFormat(v) }
+}
+
+// visualizerFormat returns format of the PCM audio given by visualizer
+// rate and channels properties values.
+func visualizerFormat(v *viper.Viper) audio.Format {
+	return audio.Format{Rate: v.GetInt(visualizerRateProp), Channels: v.GetInt(visualizerChannelsProp)}
+}
@@ -0,0 +1,194 @@
+package params
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// ZoneBaseColorDefault - default value of zone base color property.
+const ZoneBaseColorDefault = "#000000"
+
+// zone properties and flags names.
+const (
+	// ZonesProp - name of the zones configuration property.
+	ZonesProp = "zones"
+	// ZoneFlag - name of the zone flag.
+	ZoneFlag = "zone"
+
+	// ZoneBaseColorProp - name of the zone base color configuration property.
+	ZoneBaseColorProp = "zoneBaseColor"
+	// ZoneBaseColorFlag - name of the zone base color flag.
+	ZoneBaseColorFlag = "base-color"
+)
+
+// zoneConfig type provides configuration of a named zone. Keys
+// contains keys given as "row,column" cells of the keyboard
+// matrix. Rects contains rectangles of the keyboard matrix given as
+// "row,column-row,column" pairs of their opposite corners.
+type zoneConfig struct {
+	Keys  []string `mapstructure:"keys"`
+	Rects []string `mapstructure:"rects"`
+}
+
+// parseCell parses a key of the keyboard matrix provided in
+// "row,column" format.
+func parseCell(s string) (cell ite8291.Cell, err error) {
+
+	row, column, found := strings.Cut(s, ",")
+	if !found {
+		return cell, fmt.Errorf("expected \"row,column\" was %q", s)
+	}
+
+	if cell.Row, err = strconv.Atoi(strings.TrimSpace(row)); err != nil {
+		return cell, fmt.Errorf("invalid row of %q: %w", s, err)
+	}
+
+	if cell.Column, err = strconv.Atoi(strings.TrimSpace(column)); err != nil {
+		return cell, fmt.Errorf("invalid column of %q: %w", s, err)
+	}
+
+	if !cell.Valid() {
+		return cell, fmt.Errorf("%q is outside of the keyboard matrix [0-%d,0-%d]", s,
+			ite8291.RowsNumber-1, ite8291.ColumnsNumber-1)
+	}
+
+	return cell, nil
+}
+
+// parseRect parses a rectangle of the keyboard matrix provided in
+// "row,column-row,column" format and returns all its cells.
+func parseRect(s string) ([]ite8291.Cell, error) {
+
+	from, to, found := strings.Cut(s, "-")
+	if !found {
+		return nil, fmt.Errorf("expected \"row,column-row,column\" was %q", s)
+	}
+
+	c1, err := parseCell(from)
+	if err != nil {
+		return nil, err
+	}
+
+	c2, err := parseCell(to)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := []ite8291.Cell{}
+	for i := min(c1.Row, c2.Row); i <= max(c1.Row, c2.Row); i++ {
+		for j := min(c1.Column, c2.Column); j <= max(c1.Column, c2.Column); j++ {
+			cells = append(cells, ite8291.Cell{Row: i, Column: j})
+		}
+	}
+
+	return cells, nil
+}
+
+// Zone returns cells of the keyboard matrix that belong to the zone
+// configured with the given name. It returns ErrInvalidOptVal if the
+// zone is not configured or its configuration is invalid.
+func Zone(v *viper.Viper, name string) ([]ite8291.Cell, error) {
+
+	prop := fmt.Sprintf("%s.%s", ZonesProp, name)
+	if !v.IsSet(prop) {
+		return nil, fmt.Errorf("%w %q for %q is an unknown zone; expected one of %q",
+			ErrInvalidOptVal, name, "--"+ZoneFlag, ZoneNames(v))
+	}
+
+	var zc zoneConfig
+	if err := v.UnmarshalKey(prop, &zc); err != nil {
+		return nil, fmt.Errorf("%w for zone %q: %w", ErrInvalidOptVal, name, err)
+	}
+
+	cells := []ite8291.Cell{}
+	for _, k := range zc.Keys {
+		cell, err := parseCell(k)
+		if err != nil {
+			return nil, fmt.Errorf("%w for key of zone %q: %w", ErrInvalidOptVal, name, err)
+		}
+		cells = append(cells, cell)
+	}
+
+	for _, r := range zc.Rects {
+		rect, err := parseRect(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w for rectangle of zone %q: %w", ErrInvalidOptVal, name, err)
+		}
+		cells = append(cells, rect...)
+	}
+
+	return cells, nil
+}
+
+// ZoneNames returns sorted names of all configured zones.
+func ZoneNames(v *viper.Viper) []string {
+
+	names := []string{}
+	for name := range v.GetStringMap(ZonesProp) {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// AddZones adds zone flag to the given cmd. The flag can be repeated
+// to select several zones. The returned function provides cells of
+// all selected zones.
+func AddZones(cmd *cobra.Command, v *viper.Viper) (cells func() []ite8291.Cell) {
+
+	var names []string
+	var zoneCells []ite8291.Cell
+
+	cmd.PersistentFlags().StringSliceVar(&names, ZoneFlag, nil,
+		fmt.Sprintf("Name(s) of the zone(s) to use. One of zone names configured via %q property in configuration file(s).",
+			ZonesProp))
+
+	if err := cmd.MarkPersistentFlagRequired(ZoneFlag); err != nil {
+		panic(err)
+	}
+
+	addValidationHook(cmd, func() error {
+
+		zoneCells = nil
+		for _, name := range names {
+			cells, err := Zone(v, name)
+			if err != nil {
+				return err
+			}
+			zoneCells = append(zoneCells, cells...)
+		}
+
+		return nil
+	})
+
+	return func() []ite8291.Cell { return zoneCells }
+}
+
+// AddZoneBaseColor adds base color flag to the given cmd. Base color
+// is the color of all keys that don't belong to selected zones.
+func AddZoneBaseColor(cmd *cobra.Command, v *viper.Viper) (color func() *ite8291.Color) {
+
+	var col *ite8291.Color
+
+	cmd.PersistentFlags().String(ZoneBaseColorFlag, ZoneBaseColorDefault,
+		fmt.Sprintf("Color of the keys outside of the zone(s). Either a name of the color configured via %q property or an RGB value in a one of the following formats %q. %s",
+			NamedColorsProp, ite8291.SupportedColorStringFormats, configurationWarning))
+	bindAndValidate(cmd, v, ZoneBaseColorFlag, ZoneBaseColorProp, func() (err error) {
+
+		col, err = colorValueToColor(v.GetString(ZoneBaseColorProp), v)
+		if err != nil {
+			return fmt.Errorf("%w for %q: %w", ErrInvalidOptVal, "--"+ZoneBaseColorFlag, err)
+		}
+
+		return nil
+	})
+
+	return func() *ite8291.Color { return col }
+}
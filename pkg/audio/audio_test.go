@@ -0,0 +1,107 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/cmplx"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// sine returns n samples of sine wave of the given frequency and
+// amplitude sampled at the given rate.
+func sine(freq, amplitude float64, rate, n int) []float64 {
+
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))
+	}
+
+	return samples
+}
+
+// pcm returns raw PCM of the given interleaved samples.
+func pcm(samples ...int16) []byte {
+
+	buf := &bytes.Buffer{}
+	Ω(binary.Write(buf, binary.LittleEndian, samples)).Should(Succeed())
+
+	return buf.Bytes()
+}
+
+var _ = Describe("Reader", func() {
+
+	It("reads channels of frames", func() {
+
+		r, err := NewReader(bytes.NewReader(pcm(0, -32768, 16384, 32767, 1, 2)), Format{Rate: 8000, Channels: 2})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		samples, err := r.Read(2)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(samples).Should(Equal([][]float64{{0, 0.5}, {-1, 32767.0 / 32768}}))
+		Ω(Mix(samples)).Should(Equal([]float64{-0.5, (0.5 + 32767.0/32768) / 2}))
+
+		_, err = r.Read(2)
+		Ω(err).Should(MatchError(io.EOF))
+	})
+
+	DescribeTable("fails on invalid format",
+		func(f Format) {
+			_, err := NewReader(bytes.NewReader(nil), f)
+			Ω(err).Should(MatchError(ErrInvalidFormat))
+		},
+		Entry("low rate", Format{Rate: RateMinValue - 1, Channels: 1}),
+		Entry("high rate", Format{Rate: RateMaxValue + 1, Channels: 1}),
+		Entry("no channels", Format{Rate: RateDefault, Channels: 0}),
+		Entry("too many channels", Format{Rate: RateDefault, Channels: ChannelsMaxValue + 1}),
+	)
+})
+
+var _ = Describe("Spectrum", func() {
+
+	It("transforms signals", func() {
+
+		x := []complex128{1, 2, 3, 4, 0, 0, 0, 0}
+		expected := make([]complex128, len(x))
+		for k := range expected {
+			for n, v := range x {
+				expected[k] += v * cmplx.Exp(complex(0, -2*math.Pi*float64(k*n)/float64(len(x))))
+			}
+		}
+
+		FFT(x)
+		for k := range x {
+			Ω(cmplx.Abs(x[k] - expected[k])).Should(BeNumerically("<", 1e-9))
+		}
+	})
+
+	It("finds the band of a sine wave", func() {
+
+		a := NewAnalyzer(RateDefault, 2000)
+		a.Write(sine(1000, 1, RateDefault, 4096))
+
+		levels := a.Bands(10, 100, 10000) // 1000 Hz is in the 6th band
+		Ω(levels[5]).Should(BeNumerically("~", 1, 0.05))
+		Ω(levels[0]).Should(BeNumerically("<", 0.2))
+		Ω(levels[9]).Should(BeNumerically("<", 0.2))
+	})
+
+	It("levels quiet signals lower", func() {
+
+		a := NewAnalyzer(RateDefault, 2048)
+		a.Write(sine(1000, 0.1, RateDefault, 2048))
+
+		Ω(a.Bands(10, 100, 10000)[5]).Should(BeNumerically("~", 1-20.0/DynamicRange, 0.05))
+	})
+
+	It("measures RMS level", func() {
+
+		Ω(RMSLevel(sine(1000, 1, RateDefault, 4410))).Should(BeNumerically("~", 1, 0.01))
+		Ω(RMSLevel(sine(1000, 0.001, RateDefault, 4410))).Should(BeNumerically("~", 1-60.0/DynamicRange, 0.01))
+		Ω(RMSLevel(make([]float64, 100))).Should(BeZero())
+		Ω(RMSLevel(nil)).Should(BeZero())
+	})
+})
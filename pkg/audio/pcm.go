@@ -0,0 +1,129 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidFormat error indicates that a PCM format is invalid.
+var ErrInvalidFormat = errors.New("invalid PCM format")
+
+// boundaries and default values of PCM format.
+const (
+	// RateDefault - default number of frames per second.
+	RateDefault = 44100
+	// RateMinValue - minimum number of frames per second.
+	RateMinValue = 8000
+	// RateMaxValue - maximum number of frames per second.
+	RateMaxValue = 192000
+
+	// ChannelsDefault - default number of channels.
+	ChannelsDefault = 2
+	// ChannelsMinValue - minimum number of channels.
+	ChannelsMinValue = 1
+	// ChannelsMaxValue - maximum number of channels.
+	ChannelsMaxValue = 8
+)
+
+// sampleSize - size in bytes of a sample.
+const sampleSize = 2
+
+// Format provides format of raw PCM audio: interleaved signed 16 bit
+// little endian samples, e.g. produced by 'parec --format=s16le' or
+// 'pw-cat --record --format=s16'.
+type Format struct {
+	// Rate is number of frames per second.
+	Rate int
+	// Channels is number of samples per frame.
+	Channels int
+}
+
+// Validate returns ErrInvalidFormat if rate or number of channels of
+// the format is out of range.
+func (f Format) Validate() error {
+
+	if f.Rate < RateMinValue || f.Rate > RateMaxValue {
+		return fmt.Errorf("%w: rate %d is out of range [%d, %d]", ErrInvalidFormat, f.Rate, RateMinValue, RateMaxValue)
+	}
+
+	if f.Channels < ChannelsMinValue || f.Channels > ChannelsMaxValue {
+		return fmt.Errorf("%w: number of channels %d is out of range [%d, %d]",
+			ErrInvalidFormat, f.Channels, ChannelsMinValue, ChannelsMaxValue)
+	}
+
+	return nil
+}
+
+// Reader reads raw PCM audio of the given format.
+type Reader struct {
+	r   io.Reader
+	f   Format
+	buf []byte
+}
+
+// NewReader returns reader of raw PCM audio of the given format read
+// from r. It returns ErrInvalidFormat if the format is invalid.
+func NewReader(r io.Reader, f Format) (*Reader, error) {
+
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Reader{r: r, f: f}, nil
+}
+
+// Format returns format of the audio.
+func (r *Reader) Format() Format {
+	return r.f
+}
+
+// Read reads n frames and returns samples of every channel in [-1, 1].
+// It returns io.EOF if the audio ends before n frames are read.
+func (r *Reader) Read(n int) ([][]float64, error) {
+
+	size := n * r.f.Channels * sampleSize
+	if cap(r.buf) < size {
+		r.buf = make([]byte, size)
+	}
+	buf := r.buf[:size]
+
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	samples := make([][]float64, r.f.Channels)
+	for c := range samples {
+		samples[c] = make([]float64, n)
+	}
+
+	for i := range n {
+		for c := range r.f.Channels {
+			s := int16(binary.LittleEndian.Uint16(buf[(i*r.f.Channels+c)*sampleSize:]))
+			samples[c][i] = float64(s) / 32768
+		}
+	}
+
+	return samples, nil
+}
+
+// Mix returns average of the samples of the given channels.
+func Mix(channels [][]float64) []float64 {
+
+	if len(channels) == 0 {
+		return nil
+	}
+
+	mixed := make([]float64, len(channels[0]))
+	for _, ch := range channels {
+		for i, s := range ch {
+			mixed[i] += s / float64(len(channels))
+		}
+	}
+
+	return mixed
+}
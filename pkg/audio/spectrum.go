@@ -0,0 +1,145 @@
+package audio
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// DynamicRange - range in dB of levels above silence; levels at or
+// below -DynamicRange dB relative to full scale are 0.
+const DynamicRange = 60.0
+
+// FFT computes discrete Fourier transform of x in place. Length of x
+// must be a power of two.
+func FFT(x []complex128) {
+
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+
+	// bit reversal permutation
+	shift := bits.UintSize - bits.TrailingZeros(uint(n))
+	for i := range n {
+		if j := int(bits.Reverse(uint(i)) >> shift); i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := range size / 2 {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// Analyzer provides spectrum of the most recent samples of a mono
+// audio.
+type Analyzer struct {
+	rate    int
+	window  []float64
+	samples []float64
+	buf     []complex128
+}
+
+// NewAnalyzer returns analyzer of the audio with the given rate using
+// the given number of the most recent samples. size is rounded up to
+// a power of two.
+func NewAnalyzer(rate, size int) *Analyzer {
+
+	size = 1 << bits.Len(uint(max(2, size)-1))
+
+	window := make([]float64, size) // Hann window
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size))
+	}
+
+	return &Analyzer{
+		rate: rate, window: window, samples: make([]float64, size), buf: make([]complex128, size),
+	}
+}
+
+// Write adds the given samples to the analyzed ones.
+func (a *Analyzer) Write(samples []float64) {
+
+	if len(samples) >= len(a.samples) {
+		copy(a.samples, samples[len(samples)-len(a.samples):])
+		return
+	}
+
+	copy(a.samples, a.samples[len(samples):])
+	copy(a.samples[len(a.samples)-len(samples):], samples)
+}
+
+// Bands returns levels in [0, 1] of n frequency bands spaced
+// logarithmically between minFreq and maxFreq. The level of a band is
+// the level of its strongest frequency; a full scale sine wave has
+// level 1.
+func (a *Analyzer) Bands(n int, minFreq, maxFreq float64) []float64 {
+
+	size := len(a.samples)
+	for i, s := range a.samples {
+		a.buf[i] = complex(s*a.window[i], 0)
+	}
+	FFT(a.buf)
+
+	binWidth := float64(a.rate) / float64(size)
+	maxFreq = min(maxFreq, float64(a.rate)/2)
+	ratio := math.Pow(maxFreq/minFreq, 1/float64(n))
+
+	levels := make([]float64, n)
+	for b := range n {
+		lo, hi := minFreq*math.Pow(ratio, float64(b)), minFreq*math.Pow(ratio, float64(b+1))
+
+		// bins of the band or the bin nearest to its center
+		first := max(1, int(math.Ceil(lo/binWidth)))
+		last := min(size/2, int(math.Floor(hi/binWidth)))
+		if first > last {
+			first = max(1, min(size/2, int(math.Round(math.Sqrt(lo*hi)/binWidth))))
+			last = first
+		}
+
+		peak := 0.0
+		for k := first; k <= last; k++ {
+			// full scale sine wave has magnitude size/4 due to the window
+			peak = max(peak, cmplx.Abs(a.buf[k])*4/float64(size))
+		}
+		levels[b] = Level(peak)
+	}
+
+	return levels
+}
+
+// Level returns level in [0, 1] of the given amplitude relative to
+// full scale. Amplitudes at or below -DynamicRange dB are 0.
+func Level(amplitude float64) float64 {
+
+	if amplitude <= 0 {
+		return 0
+	}
+
+	return max(0, min(1, 1+20*math.Log10(amplitude)/DynamicRange))
+}
+
+// RMSLevel returns level in [0, 1] of the root mean square of the
+// given samples. A full scale sine wave has level 1.
+func RMSLevel(samples []float64) float64 {
+
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, s := range samples {
+		sum += s * s
+	}
+
+	return Level(math.Sqrt(2 * sum / float64(len(samples))))
+}
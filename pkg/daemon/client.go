@@ -0,0 +1,277 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// ErrClosed error indicates that the connection to the daemon is closed.
+var ErrClosed = errors.New("daemon connection closed")
+
+// statesBufferSize - number of state changes buffered by the client.
+const statesBufferSize = 16
+
+// Client provides connection to the daemon. It implements
+// ite8291.Device, so the device served by the daemon can be controlled
+// by ite8291.Controller.
+type Client struct {
+	conn   net.Conn
+	states chan *State
+	done   chan struct{} // closed when the connection is closed
+	read   chan struct{} // closed when reading is done
+
+	exclusive sync.Mutex // held while the client has exclusive access to the device
+
+	mu      sync.Mutex // guards fields below
+	enc     *json.Encoder
+	nextID  uint64
+	pending map[uint64]chan *message
+	err     error // error closing the connection
+}
+
+// Dial connects to the daemon listening on the given Unix socket.
+func Dial(path string) (*Client, error) {
+
+	nc, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{conn: nc, states: make(chan *State, statesBufferSize), done: make(chan struct{}),
+		read: make(chan struct{}), enc: json.NewEncoder(nc), pending: make(map[uint64]chan *message)}
+	go c.receive()
+
+	return c, nil
+}
+
+// receive dispatches messages received from the daemon till the
+// connection is closed. It's the only sender to the states channel,
+// so it closes it when done.
+func (c *Client) receive() {
+
+	defer close(c.read)
+	defer close(c.states)
+
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxMessageSize)
+	for scanner.Scan() {
+
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+
+		if msg.Method == StateChangedMethod {
+			var state State
+			if json.Unmarshal(msg.Params, &state) == nil {
+				select {
+				case c.states <- &state:
+				default: // subscriber is behind; drop the change
+				}
+			}
+			continue
+		}
+
+		if msg.ID == nil {
+			if msg.Error != nil { // e.g. access denied
+				c.close(msg.Error)
+				return
+			}
+			continue
+		}
+
+		id, err := strconv.ParseUint(string(*msg.ID), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		ch := c.pending[id]
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		if ch != nil {
+			ch <- &msg
+		}
+	}
+
+	c.close(ErrClosed)
+}
+
+// close closes the connection reporting the given error to pending
+// and later calls.
+func (c *Client) close(err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		_ = c.conn.Close()
+		close(c.done)
+	}
+}
+
+// Call calls the given method with the given params and decodes its
+// result into result, if it's not nil. It returns *Error if the
+// method failed.
+func (c *Client) Call(method string, params, result any) error {
+
+	p, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan *message, 1)
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := json.RawMessage(strconv.FormatUint(c.nextID, 10))
+	c.pending[c.nextID] = ch
+	err = c.enc.Encode(&request{JSONRPC: jsonRPCVersion, ID: &id, Method: method, Params: p})
+	c.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			return json.Unmarshal(msg.Result, result)
+		}
+		return nil
+
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.err
+	}
+}
+
+// Apply executes itectl command given by args in the daemon and
+// returns its output.
+func (c *Client) Apply(args []string) (string, error) {
+
+	var result ApplyResult
+	err := c.Call(ApplyMethod, &ApplyParams{Args: args}, &result)
+
+	return result.Output, err
+}
+
+// SetBrightness sets brightness and returns the resulting state.
+func (c *Client) SetBrightness(brightness byte) (*State, error) {
+
+	var state State
+	if err := c.Call(SetBrightnessMethod, &BrightnessParams{Brightness: brightness}, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// SetColors sets predefined colors given by their numbers.
+func (c *Client) SetColors(colors map[byte]string) error {
+	return c.Call(SetColorsMethod, &ColorsParams{Colors: colors}, nil)
+}
+
+// PushFrame shows the given frame in 'user' effect.
+func (c *Client) PushFrame(frame *FrameParams) error {
+	return c.Call(PushFrameMethod, frame, nil)
+}
+
+// State returns the current state of the keyboard backlight.
+func (c *Client) State() (*State, error) {
+
+	var state State
+	if err := c.Call(StateMethod, nil, &state); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Subscribe subscribes the client to the state changes. It returns
+// the current state and the channel of the changed states. The
+// channel is closed when the connection is closed.
+func (c *Client) Subscribe() (*State, <-chan *State, error) {
+
+	var state State
+	if err := c.Call(SubscribeMethod, nil, &state); err != nil {
+		return nil, nil, err
+	}
+
+	return &state, c.states, nil
+}
+
+// ControlTransfer calls ControlTransfer of the device served by the
+// daemon and copies the data after the transfer into data.
+func (c *Client) ControlTransfer(requestType byte, request byte, value uint16, index uint16,
+	data []byte, length int, timeout int) (int, error) {
+
+	var result ControlResult
+	if err := c.Call(ControlMethod, &ControlParams{RequestType: requestType, Request: request, Value: value,
+		Index: index, Data: data, Length: length, Timeout: timeout}, &result); err != nil {
+		return 0, err
+	}
+
+	copy(data, result.Data)
+
+	return result.N, nil
+}
+
+// GetBulkWrite returns function writing bulk data to the device
+// served by the daemon.
+func (c *Client) GetBulkWrite() (ite8291.WriteFunc, error) {
+
+	return func(p []byte) (int, error) {
+		var result BulkResult
+		err := c.Call(BulkMethod, &BulkParams{Data: p}, &result)
+		return result.N, err
+	}, nil
+}
+
+// Exclusive gives the client exclusive access to the device served by
+// the daemon until release is called; transfers of the other clients
+// wait till then. The client itself is returned as the device, so it
+// should be shared by concurrently used controllers via
+// ite8291.SharedDevice.
+func (c *Client) Exclusive() (ite8291.Device, func(), error) {
+
+	c.exclusive.Lock()
+	if err := c.Call(AcquireMethod, nil, nil); err != nil {
+		c.exclusive.Unlock()
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			_ = c.Call(ReleaseMethod, nil, nil)
+			c.exclusive.Unlock()
+		})
+	}, nil
+}
+
+// Close closes the connection to the daemon and waits for its
+// messages to be dispatched.
+func (c *Client) Close() error {
+
+	c.close(ErrClosed)
+	<-c.read
+
+	return nil
+}
@@ -0,0 +1,348 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("daemon", func() {
+
+	var dev *ite8291.VirtualDevice
+	var server *Server
+	var socket string
+	var cancel context.CancelFunc
+	var served chan error
+
+	red := ite8291.NewColor(0xff, 0, 0)
+
+	// serve starts serving the virtual device.
+	serve := func() {
+		l, err := Listen(socket, 0o600, -1)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		served = make(chan error, 1)
+		go func() { served <- server.Serve(ctx, l) }()
+	}
+
+	// dial connects a new client to the daemon.
+	dial := func() *Client {
+		c, err := Dial(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		DeferCleanup(c.Close)
+		return c
+	}
+
+	BeforeEach(func() {
+		dev = ite8291.NewVirtualDevice()
+		server = NewServer(dev)
+		socket = filepath.Join(GinkgoT().TempDir(), "itectl.sock")
+	})
+
+	AfterEach(func() {
+		if cancel != nil {
+			cancel()
+			Eventually(served).Should(Receive(BeNil()))
+			cancel = nil
+		}
+	})
+
+	It("serves the device to controllers of clients", func() {
+		serve()
+
+		ctl := ite8291.NewController(dial())
+		frame := ite8291.NewFrame(red)
+		Ω(ctl.SetFrameMode(40, frame, false)).Should(Succeed())
+
+		e, err := ctl.Effect()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(e.Effect).Should(Equal(byte(ite8291.UserEffect)))
+		Ω(e.Brightness).Should(Equal(byte(40)))
+		Ω(dev.Frame()).Should(Equal(frame))
+	})
+
+	It("gives clients exclusive access to the device", func() {
+		serve()
+
+		exclusive, release, err := dial().Exclusive()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		done := make(chan error, 1)
+		go func() { done <- ite8291.NewController(dial()).SetBrightness(5) }()
+		Consistently(done).ShouldNot(Receive())
+
+		Ω(ite8291.NewController(exclusive).SetBrightness(9)).Should(Succeed())
+		Ω(dev.Effect().Brightness).Should(Equal(byte(9)))
+
+		release()
+		Eventually(done).Should(Receive(BeNil()))
+		Ω(dev.Effect().Brightness).Should(Equal(byte(5)))
+	})
+
+	It("releases exclusive access of closed clients", func() {
+		serve()
+
+		c, err := Dial(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, _, err = c.Exclusive()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		done := make(chan error, 1)
+		go func() { done <- ite8291.NewController(dial()).SetBrightness(5) }()
+		Consistently(done).ShouldNot(Receive())
+
+		Ω(c.Close()).Should(Succeed())
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("refuses methods of the device state to clients having exclusive access", func() {
+		serve()
+		c := dial()
+
+		_, release, err := c.Exclusive()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = c.State()
+		Ω(err).Should(MatchError(ContainSubstring("not allowed while the device is acquired")))
+		_, err = c.SetBrightness(5)
+		Ω(err).Should(HaveOccurred())
+		Ω(c.PushFrame(&FrameParams{})).ShouldNot(Succeed())
+		_, _, err = c.Subscribe()
+		Ω(err).Should(HaveOccurred())
+
+		release()
+		Ω(c.State()).Should(HaveField("Brightness", dev.Effect().Brightness)) // not set while acquired
+	})
+
+	It("stops acquiring the device for closed clients", func() {
+		serve()
+
+		_, _, err := dial().Exclusive()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		c, err := Dial(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := c.Exclusive()
+			done <- err
+		}()
+		Consistently(done).ShouldNot(Receive())
+
+		Ω(c.Close()).Should(Succeed())
+		Eventually(done).Should(Receive(HaveOccurred()))
+	})
+
+	It("sets brightness and colors and reports the state", func() {
+		serve()
+		c := dial()
+
+		state, err := c.SetBrightness(10)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(state).Should(Equal(&State{On: true, Control: ite8291.SetEffectOp, Effect: ite8291.UserEffect, Brightness: 10}))
+
+		Ω(c.SetColors(map[byte]string{3: "#F00"})).Should(Succeed())
+		Ω(dev.Color(3)).Should(Equal(red))
+
+		_, err = c.SetBrightness(51)
+		Ω(err).Should(MatchError(ContainSubstring("exceeds")))
+		Ω(c.SetColors(map[byte]string{8: "#F00"})).Should(MatchError(ContainSubstring("out of")))
+	})
+
+	It("pushes frames switching to 'user' effect", func() {
+		server.ParseColor = func(s string) (*ite8291.Color, error) {
+			if s == "red" {
+				return red, nil
+			}
+			return ite8291.ParseColor(s)
+		}
+		serve()
+		c := dial()
+
+		Ω(ite8291.NewController(c).SetWaveMode(1, 30, ite8291.DirectionLeft, false)).Should(Succeed())
+
+		var p FrameParams
+		for i := range p.Frame {
+			for j := range p.Frame[i] {
+				p.Frame[i][j] = "red"
+			}
+		}
+		p.Frame[0][0] = "#000"
+		Ω(c.PushFrame(&p)).Should(Succeed())
+
+		expected := ite8291.NewFrame(red)
+		expected[0][0] = *ite8291.NewColor(0, 0, 0)
+		Ω(dev.Frame()).Should(Equal(expected))
+		Ω(dev.Effect().Effect).Should(Equal(byte(ite8291.UserEffect)))
+		Ω(dev.Effect().Brightness).Should(Equal(byte(30)))
+
+		p.Frame[0][0] = "nocolor"
+		Ω(c.PushFrame(&p)).Should(MatchError(ContainSubstring("nocolor")))
+	})
+
+	It("notifies subscribers of state changes", func() {
+		serve()
+		c := dial()
+
+		state, changes, err := c.Subscribe()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(state.Brightness).Should(Equal(byte(ite8291.BrightnessMaxValue / 2)))
+
+		Ω(ite8291.NewController(dial()).SetOffMode()).Should(Succeed())
+		Eventually(changes).Should(Receive(HaveField("On", BeFalse())))
+
+		_, err = c.SetBrightness(5)
+		Ω(err).ShouldNot(HaveOccurred())
+		Eventually(changes).Should(Receive(HaveField("Brightness", Equal(byte(5)))))
+	})
+
+	It("disconnects subscribers not reading the changes", func() {
+		timeout := notifyTimeout
+		notifyTimeout = 100 * time.Millisecond
+		DeferCleanup(func() { notifyTimeout = timeout })
+		serve()
+
+		stalled, nc := net.Pipe() // unbuffered, so the notification blocks
+		DeferCleanup(stalled.Close)
+		go server.serveConn(context.Background(), nc)
+		r := bufio.NewReader(stalled)
+		_, err := fmt.Fprintln(stalled, `{"jsonrpc":"2.0","id":1,"method":"subscribe"}`)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(r.ReadBytes('\n')).Should(ContainSubstring(`"result"`))
+
+		c := dial()
+		_, err = c.SetBrightness(5)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, changes, err := c.Subscribe()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = c.SetBrightness(7)
+		Ω(err).ShouldNot(HaveOccurred())
+		Eventually(changes).Should(Receive(HaveField("Brightness", Equal(byte(7)))))
+
+		_, err = io.ReadAll(r) // notifications sent before the timeout
+		Ω(err).Should(Or(Not(HaveOccurred()), MatchError(io.ErrClosedPipe)))
+	})
+
+	It("closes the changes on close of the subscriber receiving them", func() {
+		serve()
+
+		c, err := Dial(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		_, changes, err := c.Subscribe()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		other := dial()
+		for b := range byte(statesBufferSize * 2) {
+			_, err = other.SetBrightness(b % ite8291.BrightnessMaxValue)
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+
+		Ω(c.Close()).Should(Succeed())
+		Eventually(changes).Should(BeClosed())
+	})
+
+	It("applies commands one at a time", func() {
+		started, finished := make(chan struct{}), make(chan struct{})
+		server.Apply = func(ctx context.Context, args []string, out io.Writer) error {
+			if args[0] == "wait" {
+				close(started)
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond) // e.g. restoring the state
+				close(finished)
+				return ctx.Err()
+			}
+			select {
+			case <-finished:
+			default:
+				return errors.New("applied before the previous command returned")
+			}
+			_, err := fmt.Fprint(out, strings.Join(args, " "))
+			return err
+		}
+		serve()
+
+		waited := make(chan error, 1)
+		go func() {
+			_, err := dial().Apply([]string{"wait"})
+			waited <- err
+		}()
+		Eventually(started).Should(BeClosed())
+
+		output, err := dial().Apply([]string{"wave-mode", "-s", "5"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(output).Should(Equal("wave-mode -s 5"))
+		Eventually(waited).Should(Receive(MatchError(ContainSubstring("canceled"))))
+	})
+
+	It("reports unknown methods", func() {
+		serve()
+
+		err := dial().Call("unknown", nil, nil)
+		var rpcErr *Error
+		Ω(errors.As(err, &rpcErr)).Should(BeTrue())
+		Ω(rpcErr.Code).Should(Equal(MethodNotFoundCode))
+	})
+
+	It("authorizes peers by their credentials", func() {
+		var cred *Credentials
+		server.Authorize = func(c *Credentials) error {
+			cred = c
+			return errors.New("not allowed")
+		}
+		serve()
+
+		_, err := dial().State()
+		Ω(err).Should(MatchError(ContainSubstring("access denied: not allowed")))
+		Ω(cred).Should(Equal(&Credentials{PID: int32(os.Getpid()), UID: uint32(os.Getuid()), GID: uint32(os.Getgid())}))
+	})
+
+	It("sets permissions of the socket", func() {
+		serve()
+
+		info, err := os.Stat(socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(info.Mode().Perm()).Should(Equal(os.FileMode(0o600)))
+	})
+
+	It("refuses to listen if the daemon is running", func() {
+		serve()
+
+		_, err := Listen(socket, 0o600, -1)
+		Ω(err).Should(MatchError(ErrRunning))
+	})
+
+	It("replaces stale socket", func() {
+		l, err := net.Listen("unix", socket)
+		Ω(err).ShouldNot(HaveOccurred())
+		l.(*net.UnixListener).SetUnlinkOnClose(false)
+		Ω(l.Close()).Should(Succeed())
+
+		serve()
+
+		_, err = dial().State()
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("keeps files other than sockets", func() {
+		Ω(os.WriteFile(socket, []byte("data"), 0o600)).Should(Succeed())
+
+		_, err := Listen(socket, 0o600, -1)
+		Ω(err).Should(MatchError(ContainSubstring("is not a socket")))
+		Ω(os.ReadFile(socket)).Should(Equal([]byte("data")))
+	})
+})
@@ -0,0 +1,174 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// jsonRPCVersion - version of JSON-RPC protocol.
+const jsonRPCVersion = "2.0"
+
+// methods served by the daemon.
+const (
+	// ApplyMethod - name of the method executing itectl command given
+	// by its arguments, e.g. to apply a mode.
+	ApplyMethod = "apply"
+	// SetBrightnessMethod - name of the method setting brightness.
+	SetBrightnessMethod = "setBrightness"
+	// SetColorsMethod - name of the method setting predefined colors.
+	SetColorsMethod = "setColors"
+	// PushFrameMethod - name of the method showing a frame in 'user'
+	// effect.
+	PushFrameMethod = "pushFrame"
+	// StateMethod - name of the method returning the current state.
+	StateMethod = "state"
+	// SubscribeMethod - name of the method subscribing the connection
+	// to state changes.
+	SubscribeMethod = "subscribe"
+	// StateChangedMethod - name of the notification sent to subscribed
+	// connections when the state changes.
+	StateChangedMethod = "stateChanged"
+	// ControlMethod - name of the method calling ControlTransfer of the
+	// device.
+	ControlMethod = "device.control"
+	// BulkMethod - name of the method writing bulk data to the device.
+	BulkMethod = "device.bulk"
+	// AcquireMethod - name of the method giving the connection
+	// exclusive access to the device till ReleaseMethod is called or
+	// the connection is closed. Transfers of the other connections wait
+	// till then; the connection itself may only make transfers.
+	AcquireMethod = "device.acquire"
+	// ReleaseMethod - name of the method releasing exclusive access to
+	// the device.
+	ReleaseMethod = "device.release"
+)
+
+// JSON-RPC error codes.
+const (
+	// ParseErrorCode - code of the error reported for invalid JSON.
+	ParseErrorCode = -32700
+	// MethodNotFoundCode - code of the error reported for unknown methods.
+	MethodNotFoundCode = -32601
+	// InvalidParamsCode - code of the error reported for invalid params.
+	InvalidParamsCode = -32602
+	// FailedCode - code of the error reported if the method failed.
+	FailedCode = -32000
+)
+
+// request provides JSON-RPC request or notification, if ID is not set.
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// response provides JSON-RPC response.
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *Error           `json:"error,omitempty"`
+}
+
+// message provides any JSON-RPC message received by the client.
+type message struct {
+	ID     *json.RawMessage `json:"id,omitempty"`
+	Method string           `json:"method,omitempty"`
+	Params json.RawMessage  `json:"params,omitempty"`
+	Result json.RawMessage  `json:"result,omitempty"`
+	Error  *Error           `json:"error,omitempty"`
+}
+
+// Error provides JSON-RPC error returned by the daemon.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error returns message of the error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("daemon: %s (%d)", e.Message, e.Code)
+}
+
+// ApplyParams provides params of apply method.
+type ApplyParams struct {
+	// Args are the arguments of itectl command, e.g. ["wave-mode", "-s", "5"].
+	Args []string `json:"args"`
+}
+
+// ApplyResult provides result of apply method.
+type ApplyResult struct {
+	// Output is the output of the command.
+	Output string `json:"output"`
+}
+
+// BrightnessParams provides params of setBrightness method.
+type BrightnessParams struct {
+	Brightness byte `json:"brightness"`
+}
+
+// ColorsParams provides params of setColors method.
+type ColorsParams struct {
+	// Colors maps predefined color numbers to their colors.
+	Colors map[byte]string `json:"colors"`
+}
+
+// FrameParams provides params of pushFrame method.
+type FrameParams struct {
+	// Frame provides colors of the keys by rows.
+	Frame [ite8291.RowsNumber][ite8291.ColumnsNumber]string `json:"frame"`
+	// Brightness is used if the keyboard backlight is not in 'user'
+	// effect yet; the current brightness is kept if it's nil.
+	Brightness *byte `json:"brightness,omitempty"`
+}
+
+// State provides state of the keyboard backlight.
+type State struct {
+	On         bool `json:"on"`
+	Control    byte `json:"control"`
+	Effect     byte `json:"effect"`
+	Speed      byte `json:"speed"`
+	Brightness byte `json:"brightness"`
+	ColorNum   byte `json:"colorNum"`
+	ReactOrDiv byte `json:"reactOrDiv"`
+}
+
+// newState returns state of the given effect.
+func newState(e *ite8291.EffectState) *State {
+	return &State{On: e.Control != ite8291.OffState, Control: e.Control, Effect: e.Effect, Speed: e.Speed,
+		Brightness: e.Brightness, ColorNum: e.ColorNum, ReactOrDiv: e.ReactOrDiv}
+}
+
+// ControlParams provides params of device.control method. They
+// correspond to the arguments of ite8291.Device ControlTransfer.
+type ControlParams struct {
+	RequestType byte   `json:"requestType"`
+	Request     byte   `json:"request"`
+	Value       uint16 `json:"value"`
+	Index       uint16 `json:"index"`
+	Data        []byte `json:"data"`
+	Length      int    `json:"length"`
+	Timeout     int    `json:"timeout"`
+}
+
+// ControlResult provides result of device.control method.
+type ControlResult struct {
+	// N is the number of transferred bytes.
+	N int `json:"n"`
+	// Data is the data after the transfer, i.e. received data.
+	Data []byte `json:"data"`
+}
+
+// BulkParams provides params of device.bulk method.
+type BulkParams struct {
+	Data []byte `json:"data"`
+}
+
+// BulkResult provides result of device.bulk method.
+type BulkResult struct {
+	// N is the number of written bytes.
+	N int `json:"n"`
+}
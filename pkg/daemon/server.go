@@ -0,0 +1,611 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// maxMessageSize - maximum size of a message received by the daemon.
+const maxMessageSize = 1 << 20
+
+// notifyTimeout - maximum time to send a notification to a
+// subscriber. The subscriber not reading in time is disconnected.
+var notifyTimeout = 5 * time.Second
+
+// Server serves ite8291r3 device to the clients connected to its
+// listener. Requests of all clients are applied to the same device.
+type Server struct {
+
+	// Apply, if set, executes itectl command given by args and writes
+	// its output to out. It's called by apply method. The command is
+	// applied until it's done, ctx is done or another command is
+	// applied.
+	Apply func(ctx context.Context, args []string, out io.Writer) error
+
+	// Authorize, if set, returns error if the process with the given
+	// credentials must not access the device. Its connection is closed
+	// then. All processes are allowed if it's nil.
+	Authorize func(cred *Credentials) error
+
+	// ParseColor, if set, parses colors given to setColors and
+	// pushFrame methods. ite8291.ParseColor is used if it's nil.
+	ParseColor func(s string) (*ite8291.Color, error)
+
+	dev *ite8291.SharedDevice
+	ctl *ite8291.Controller
+
+	opMu    sync.Mutex    // serializes operations consisting of several transfers
+	changes chan struct{} // signals possible change of the state
+
+	mu          sync.Mutex // guards fields below
+	subscribers map[*conn]struct{}
+	notified    *State // state notified last
+	cancelApply context.CancelFunc
+	applied     chan struct{} // closed when the command applied last returns
+}
+
+// NewServer creates a new server of the given device.
+func NewServer(dev ite8291.Device) *Server {
+
+	s := &Server{changes: make(chan struct{}, 1), subscribers: make(map[*conn]struct{})}
+	s.dev = ite8291.NewSharedDevice(dev)
+	s.dev.OnChange = s.changed
+	s.ctl = ite8291.NewController(s.dev)
+
+	return s
+}
+
+// Device returns the device served by s. It can be used concurrently
+// with the clients of s; its Close does nothing.
+func (s *Server) Device() ite8291.Device {
+	return s.dev
+}
+
+// Serve accepts connections of l and serves their requests till ctx
+// is done. It closes l and all connections and waits for their
+// requests to finish before returning.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := context.AfterFunc(ctx, func() { _ = l.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.notifyChanges(ctx)
+	}()
+
+	var err error
+	for {
+		var nc net.Conn
+		if nc, err = l.Accept(); err != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, nc)
+		}()
+	}
+
+	if ctx.Err() != nil {
+		err = nil // closed on purpose
+	}
+
+	cancel()
+	wg.Wait()
+
+	return err
+}
+
+// serveConn serves requests received over the given connection.
+func (s *Server) serveConn(ctx context.Context, nc net.Conn) {
+
+	defer nc.Close()
+	stop := context.AfterFunc(ctx, func() { _ = nc.Close() })
+	defer stop()
+
+	c := &conn{nc: nc, enc: json.NewEncoder(nc)}
+	c.ctx, c.close = context.WithCancel(ctx)
+	defer c.close()
+
+	if s.Authorize != nil {
+		cred, err := PeerCredentials(nc)
+		if err == nil {
+			err = s.Authorize(cred)
+		}
+
+		if err != nil {
+			_ = c.send(&response{JSONRPC: jsonRPCVersion, Error: &Error{Code: FailedCode,
+				Message: fmt.Sprintf("access denied: %v", err)}})
+			return
+		}
+	}
+
+	defer s.unsubscribe(c)
+	defer func() {
+		if c.exclusive != nil {
+			_ = s.release(c)
+		}
+	}()
+
+	// requests are read by another goroutine, so closing of the
+	// connection is noticed while a request waits, e.g. for exclusive
+	// access to the device
+	lines := make(chan []byte)
+	go func() {
+		defer close(lines)
+		defer c.close()
+
+		scanner := bufio.NewScanner(nc)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxMessageSize)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			select {
+			case lines <- bytes.Clone(line):
+			case <-c.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for line := range lines {
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if c.send(&response{JSONRPC: jsonRPCVersion, Error: &Error{Code: ParseErrorCode, Message: err.Error()}}) != nil {
+				return
+			}
+			continue
+		}
+
+		result, err := s.handle(ctx, c, &req)
+		if req.ID == nil {
+			continue // notifications are not answered
+		}
+
+		resp := &response{JSONRPC: jsonRPCVersion, ID: req.ID}
+		if err == nil {
+			resp.Result, err = json.Marshal(result)
+		}
+		if err != nil {
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &Error{Code: FailedCode, Message: err.Error()}
+			}
+			resp.Error, resp.Result = rpcErr, nil
+		}
+
+		if c.send(resp) != nil {
+			return
+		}
+	}
+}
+
+// handle calls the method requested by req and returns its result.
+//
+//nolint:cyclop
+func (s *Server) handle(ctx context.Context, c *conn, req *request) (any, error) {
+
+	if c.exclusive != nil && !slices.Contains(exclusiveMethods, req.Method) {
+		// it would wait for the device held by the connection itself
+		return nil, &Error{Code: FailedCode,
+			Message: fmt.Sprintf("method %q is not allowed while the device is acquired", req.Method)}
+	}
+
+	switch req.Method {
+	case ApplyMethod:
+		var p ApplyParams
+		if err := decode(req.Params, &p); err != nil {
+			return nil, err
+		}
+		return s.apply(ctx, p.Args)
+
+	case SetBrightnessMethod:
+		var p BrightnessParams
+		if err := decode(req.Params, &p); err != nil {
+			return nil, err
+		}
+		return s.setBrightness(p.Brightness)
+
+	case SetColorsMethod:
+		var p ColorsParams
+		if err := decode(req.Params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.setColors(p.Colors)
+
+	case PushFrameMethod:
+		var p FrameParams
+		if err := decode(req.Params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.pushFrame(&p)
+
+	case StateMethod:
+		return s.state()
+
+	case SubscribeMethod:
+		return s.subscribe(c)
+
+	case ControlMethod:
+		var p ControlParams
+		if err := decode(req.Params, &p); err != nil {
+			return nil, err
+		}
+		data := p.Data
+		if len(data) < p.Length {
+			data = append(data, make([]byte, p.Length-len(data))...)
+		}
+		n, err := c.device(s.dev).ControlTransfer(p.RequestType, p.Request, p.Value, p.Index, data, p.Length,
+			p.Timeout)
+		return &ControlResult{N: n, Data: data}, err
+
+	case BulkMethod:
+		var p BulkParams
+		if err := decode(req.Params, &p); err != nil {
+			return nil, err
+		}
+		write, err := c.device(s.dev).GetBulkWrite()
+		if err != nil {
+			return nil, err
+		}
+		n, err := write(p.Data)
+		return &BulkResult{N: n}, err
+
+	case AcquireMethod:
+		return nil, s.acquire(c)
+
+	case ReleaseMethod:
+		return nil, s.release(c)
+	}
+
+	return nil, &Error{Code: MethodNotFoundCode, Message: fmt.Sprintf("unknown method %q", req.Method)}
+}
+
+// exclusiveMethods - methods allowed to the connection having
+// exclusive access to the device.
+var exclusiveMethods = []string{ControlMethod, BulkMethod, AcquireMethod, ReleaseMethod}
+
+// decode decodes the given params into v.
+func decode(params json.RawMessage, v any) error {
+
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: InvalidParamsCode, Message: err.Error()}
+	}
+
+	return nil
+}
+
+// apply executes the command given by args after the command applied
+// before is done.
+func (s *Server) apply(ctx context.Context, args []string) (*ApplyResult, error) {
+
+	if s.Apply == nil {
+		return nil, &Error{Code: MethodNotFoundCode, Message: fmt.Sprintf("method %q is not supported", ApplyMethod)}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	s.mu.Lock()
+	if s.cancelApply != nil {
+		s.cancelApply() // the command applied before gives way
+	}
+	prev := s.applied
+	s.cancelApply, s.applied = cancel, done
+	s.mu.Unlock()
+
+	if prev != nil {
+		<-prev
+	}
+	if err := ctx.Err(); err != nil { // replaced while waiting
+		return nil, err
+	}
+
+	var out strings.Builder
+	err := s.Apply(ctx, args, &out)
+
+	return &ApplyResult{Output: out.String()}, err
+}
+
+// acquire gives the given connection exclusive access to the device.
+// It stops waiting for the access when the connection is closed.
+func (s *Server) acquire(c *conn) error {
+
+	if c.exclusive != nil {
+		return &Error{Code: FailedCode, Message: "device is already acquired"}
+	}
+
+	dev, release, err := s.dev.ExclusiveContext(c.ctx)
+	if err != nil {
+		return err
+	}
+	c.exclusive, c.release = dev, release
+
+	return nil
+}
+
+// release releases exclusive access of the given connection to the
+// device.
+func (s *Server) release(c *conn) error {
+
+	if c.exclusive == nil {
+		return &Error{Code: FailedCode, Message: "device is not acquired"}
+	}
+
+	c.release()
+	c.exclusive, c.release = nil, nil
+
+	return nil
+}
+
+// setBrightness sets brightness and returns the resulting state.
+func (s *Server) setBrightness(brightness byte) (*State, error) {
+
+	if brightness > ite8291.BrightnessMaxValue {
+		return nil, &Error{Code: InvalidParamsCode,
+			Message: fmt.Sprintf("brightness %d exceeds %d", brightness, ite8291.BrightnessMaxValue)}
+	}
+
+	if err := s.ctl.SetBrightness(brightness); err != nil {
+		return nil, err
+	}
+
+	return s.state()
+}
+
+// setColors sets the given predefined colors.
+func (s *Server) setColors(colors map[byte]string) error {
+
+	parsed := make(map[byte]*ite8291.Color, len(colors))
+	for num, val := range colors {
+		if num < ite8291.CustomColorNumMinValue || num > ite8291.CustomColorNumMaxValue {
+			return &Error{Code: InvalidParamsCode, Message: fmt.Sprintf("color number %d is out of [%d,%d]",
+				num, ite8291.CustomColorNumMinValue, ite8291.CustomColorNumMaxValue)}
+		}
+
+		color, err := s.parseColor(val)
+		if err != nil {
+			return err
+		}
+		parsed[num] = color
+	}
+
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
+	for num, color := range parsed {
+		if err := s.ctl.SetColor(num, color); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pushFrame shows the given frame switching to 'user' effect if needed.
+func (s *Server) pushFrame(p *FrameParams) error {
+
+	var frame ite8291.Frame
+	for i, row := range p.Frame {
+		for j, val := range row {
+			color, err := s.parseColor(val)
+			if err != nil {
+				return err
+			}
+			frame[i][j] = *color
+		}
+	}
+
+	if p.Brightness != nil && *p.Brightness > ite8291.BrightnessMaxValue {
+		return &Error{Code: InvalidParamsCode,
+			Message: fmt.Sprintf("brightness %d exceeds %d", *p.Brightness, ite8291.BrightnessMaxValue)}
+	}
+
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
+	e, err := s.ctl.Effect()
+	if err != nil {
+		return err
+	}
+
+	if e.Control == ite8291.OffState || e.Effect != ite8291.UserEffect {
+		brightness := e.Brightness
+		if p.Brightness != nil {
+			brightness = *p.Brightness
+		}
+
+		if err := s.ctl.SetUserMode(brightness, false); err != nil {
+			return err
+		}
+	}
+
+	return s.ctl.WriteFrame(&frame)
+}
+
+// parseColor parses the given color value.
+func (s *Server) parseColor(val string) (*ite8291.Color, error) {
+
+	parse := ite8291.ParseColor
+	if s.ParseColor != nil {
+		parse = s.ParseColor
+	}
+
+	color, err := parse(val)
+	if err != nil {
+		return nil, &Error{Code: InvalidParamsCode, Message: err.Error()}
+	}
+
+	return color, nil
+}
+
+// state returns the current state of the keyboard backlight.
+func (s *Server) state() (*State, error) {
+
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
+	e, err := s.ctl.Effect()
+	if err != nil {
+		return nil, err
+	}
+
+	return newState(e), nil
+}
+
+// subscribe subscribes the given connection to the state changes and
+// returns the current state.
+func (s *Server) subscribe(c *conn) (*State, error) {
+
+	state, err := s.state()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribers[c] = struct{}{}
+	s.notified = state
+
+	return state, nil
+}
+
+// unsubscribe removes the given connection from the subscribers.
+func (s *Server) unsubscribe(c *conn) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscribers, c)
+}
+
+// changed signals that the state might have changed.
+func (s *Server) changed() {
+
+	select {
+	case s.changes <- struct{}{}:
+	default: // already signaled
+	}
+}
+
+// notifyChanges sends the changed state to the subscribers till ctx
+// is done.
+func (s *Server) notifyChanges(ctx context.Context) {
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.changes:
+		}
+
+		s.mu.Lock()
+		subscribed := len(s.subscribers) > 0
+		s.mu.Unlock()
+		if !subscribed {
+			continue
+		}
+
+		state, err := s.state()
+		if err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		var subscribers []*conn
+		if s.notified == nil || *s.notified != *state {
+			s.notified = state
+			for c := range s.subscribers {
+				subscribers = append(subscribers, c)
+			}
+		}
+		s.mu.Unlock()
+
+		params, _ := json.Marshal(state)
+		for _, c := range subscribers {
+			c.notify(&request{JSONRPC: jsonRPCVersion, Method: StateChangedMethod, Params: params})
+		}
+	}
+}
+
+// conn provides connection of a client.
+type conn struct {
+	nc  net.Conn
+	mu  sync.Mutex // guards enc
+	enc *json.Encoder
+
+	// ctx is done when the client closes the connection or the server
+	// is closed; it doesn't cancel applied commands, as the client may
+	// only close its side of the connection and wait for the result
+	ctx   context.Context
+	close context.CancelFunc
+
+	// exclusive access to the device acquired by the client; used
+	// only by the goroutine serving the connection
+	exclusive ite8291.Device
+	release   func()
+}
+
+// device returns the device transfers of the client are made to: the
+// device exclusively accessed by the client, if acquired, or the given
+// shared device.
+func (c *conn) device(shared ite8291.Device) ite8291.Device {
+
+	if c.exclusive != nil {
+		return c.exclusive
+	}
+
+	return shared
+}
+
+// notify sends the given notification to the client. The connection
+// is closed if the client doesn't read it within notifyTimeout.
+func (c *conn) notify(msg any) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.nc.SetWriteDeadline(time.Now().Add(notifyTimeout))
+	if err := c.enc.Encode(msg); err != nil {
+		_ = c.nc.Close()
+		return
+	}
+	_ = c.nc.SetWriteDeadline(time.Time{})
+}
+
+// send sends the given message to the client.
+func (c *conn) send(msg any) error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.enc.Encode(msg)
+}
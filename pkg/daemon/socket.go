@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// ErrRunning error indicates that a daemon already listens on the socket.
+var ErrRunning = errors.New("daemon is already running")
+
+// Credentials provides credentials of the process connected to the
+// daemon.
+type Credentials struct {
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+// PeerCredentials returns credentials of the process connected to the
+// other end of the given Unix socket connection.
+func PeerCredentials(conn net.Conn) (*Credentials, error) {
+
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, fmt.Errorf("no credentials of %s connection", conn.LocalAddr().Network())
+	}
+
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var ucred *syscall.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		ucred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return nil, err
+	}
+	if credErr != nil {
+		return nil, credErr
+	}
+
+	return &Credentials{PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid}, nil
+}
+
+// Listen creates Unix socket at the given path with the given
+// permissions and group, if gid is not negative. A socket left by a
+// daemon that is no longer running, i.e. refusing connections, is
+// replaced; any other file at the path is kept and an error is
+// returned. It returns ErrRunning if another daemon listens on the
+// socket.
+func Listen(path string, perm os.FileMode, gid int) (net.Listener, error) {
+
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("%q is not a socket", path)
+		}
+
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%w at %q", ErrRunning, path)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, err
+		}
+
+		if err := os.Remove(path); err != nil { // stale socket
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if gid >= 0 {
+		if err := os.Chown(path, -1, gid); err != nil {
+			_ = l.Close()
+			return nil, err
+		}
+	}
+
+	if err := os.Chmod(path, perm); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
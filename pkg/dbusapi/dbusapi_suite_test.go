@@ -0,0 +1,60 @@
+package dbusapi
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDbusapi(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dbusapi Suite")
+}
+
+// busConfig - configuration of the private bus used by the tests.
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// busAddress - address of the private bus started for the tests.
+var busAddress string
+
+var _ = BeforeSuite(func() {
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		Skip("dbus-daemon is not available")
+	}
+
+	dir := GinkgoT().TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	Ω(os.WriteFile(config, []byte(strings.Replace(busConfig, "%s", dir, 1)), 0o600)).Should(Succeed())
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+	out, err := cmd.StdoutPipe()
+	Ω(err).ShouldNot(HaveOccurred())
+	Ω(cmd.Start()).Should(Succeed())
+	DeferCleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	busAddress, err = bufio.NewReader(out).ReadString('\n')
+	Ω(err).ShouldNot(HaveOccurred())
+	busAddress = strings.TrimSpace(busAddress)
+})
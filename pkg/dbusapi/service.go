@@ -0,0 +1,541 @@
+package dbusapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// D-Bus names of the service.
+const (
+	// BusName - well-known bus name of the service.
+	BusName = "io.github.v4n6.Itectl"
+	// ObjectPath - path of the keyboard backlight object.
+	ObjectPath dbus.ObjectPath = "/io/github/v4n6/Itectl"
+	// Interface - name of the keyboard backlight interface.
+	Interface = "io.github.v4n6.Itectl.Keyboard"
+)
+
+// properties of the keyboard backlight interface.
+const (
+	// OnProp - whether the keyboard backlight is on.
+	OnProp = "On"
+	// ModeProp - name of the mode of the keyboard backlight.
+	ModeProp = "Mode"
+	// BrightnessProp - brightness of the keyboard backlight.
+	BrightnessProp = "Brightness"
+	// SpeedProp - speed of the effect of the keyboard backlight.
+	SpeedProp = "Speed"
+	// ColorsProp - predefined colors of the controller.
+	ColorsProp = "Colors"
+	// FrameProp - colors of the keys of 'user' effect by rows.
+	FrameProp = "Frame"
+)
+
+// invalidArgsError - name of the error reported for invalid arguments.
+const invalidArgsError = "org.freedesktop.DBus.Error.InvalidArgs"
+
+// ErrNameTaken error indicates that the bus name of the service is
+// owned by another connection.
+var ErrNameTaken = errors.New("D-Bus name is already taken")
+
+// Service provides the keyboard backlight object.
+type Service struct {
+
+	// Apply, if set, executes itectl command given by args and writes
+	// its output to out. It applies modes set via the service. The
+	// command is applied until it's done, ctx is done or another mode
+	// is set.
+	Apply func(ctx context.Context, args []string, out io.Writer) error
+
+	// Modes are the names of the modes that can be set, e.g. "wave";
+	// the mode is applied by "<name>-mode" command.
+	Modes []string
+
+	// ModeName, if set, returns name of the mode of the given state.
+	ModeName func(state *ite8291.EffectState) string
+
+	// ParseColor, if set, parses colors given to the service.
+	// ite8291.ParseColor is used if it's nil.
+	ParseColor func(s string) (*ite8291.Color, error)
+
+	// Colors are the predefined colors reported until they are set via
+	// the service.
+	Colors []*ite8291.Color
+
+	// Frame is the frame reported until it is set via the service.
+	Frame *ite8291.Frame
+
+	dev   *ite8291.SharedDevice
+	ctl   *ite8291.Controller
+	props *prop.Properties
+
+	opMu    sync.Mutex    // serializes operations consisting of several transfers
+	changes chan struct{} // signals possible change of the state
+
+	mu          sync.Mutex // guards fields below
+	ctx         context.Context
+	cancelApply context.CancelFunc
+	applied     chan struct{} // closed when the mode applied last returns
+	applying    sync.WaitGroup
+}
+
+// NewService creates a new service of the given device.
+func NewService(dev ite8291.Device) *Service {
+
+	s := &Service{changes: make(chan struct{}, 1)}
+	s.dev = ite8291.NewSharedDevice(dev)
+	s.dev.OnChange = s.changed
+	s.ctl = ite8291.NewController(s.dev)
+
+	return s
+}
+
+// Device returns the device of the service. It can be used
+// concurrently with the service; its Close does nothing.
+func (s *Service) Device() ite8291.Device {
+	return s.dev
+}
+
+// Serve exports the keyboard backlight object on the given connection
+// and requests the bus name of the service. It refreshes the
+// properties after every change made via the service and every
+// refresh interval, if it's positive, till ctx is done or the
+// connection is closed. It returns ErrNameTaken if the bus name is
+// owned by another connection.
+func (s *Service) Serve(ctx context.Context, conn *dbus.Conn, refresh time.Duration) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	if err := s.export(conn); err != nil {
+		return err
+	}
+	defer s.unexport(conn)
+
+	reply, err := conn.RequestName(BusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("%w: %s", ErrNameTaken, BusName)
+	}
+	defer func() { _, _ = conn.ReleaseName(BusName) }()
+
+	var tick <-chan time.Time
+	if refresh > 0 {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for err == nil {
+		select {
+		case <-ctx.Done():
+		case <-conn.Context().Done():
+			err = conn.Context().Err()
+		case <-s.changes:
+			err = s.refresh()
+		case <-tick:
+			err = s.refresh()
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	cancel() // the applied mode is stopped
+	s.mu.Unlock()
+	s.applying.Wait()
+
+	return err
+}
+
+// export exports the object on the given connection.
+func (s *Service) export(conn *dbus.Conn) error {
+
+	state, err := s.state()
+	if err != nil {
+		return err
+	}
+
+	colors := make([]string, len(s.Colors))
+	for i, color := range s.Colors {
+		colors[i] = color.String()
+	}
+
+	frame := [][]string{}
+	if s.Frame != nil {
+		frame = frameStrings(s.Frame)
+	}
+
+	s.props, err = prop.Export(conn, ObjectPath, prop.Map{Interface: {
+		OnProp:   {Value: state.Control != ite8291.OffState, Emit: prop.EmitTrue},
+		ModeProp: {Value: s.modeName(state), Writable: true, Emit: prop.EmitTrue, Callback: s.onModeSet},
+		BrightnessProp: {Value: state.Brightness, Writable: true, Emit: prop.EmitTrue,
+			Callback: s.onBrightnessSet},
+		SpeedProp:  {Value: state.Speed, Emit: prop.EmitTrue},
+		ColorsProp: {Value: colors, Writable: true, Emit: prop.EmitTrue, Callback: s.onColorsSet},
+		FrameProp:  {Value: frame, Writable: true, Emit: prop.EmitTrue, Callback: s.onFrameSet},
+	}})
+	if err != nil {
+		return err
+	}
+
+	methods := &keyboard{s: s}
+	if err := conn.Export(methods, ObjectPath, Interface); err != nil {
+		return err
+	}
+
+	node := &introspect.Node{
+		Name: string(ObjectPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{Name: Interface, Methods: introspect.Methods(methods), Properties: s.props.Introspection(Interface)},
+		},
+	}
+
+	return conn.Export(introspect.NewIntrospectable(node), ObjectPath, "org.freedesktop.DBus.Introspectable")
+}
+
+// unexport removes the object from the given connection.
+func (s *Service) unexport(conn *dbus.Conn) {
+
+	for _, iface := range []string{Interface, "org.freedesktop.DBus.Properties", "org.freedesktop.DBus.Introspectable"} {
+		_ = conn.Export(nil, ObjectPath, iface)
+	}
+}
+
+// state returns the current state of the keyboard backlight.
+func (s *Service) state() (*ite8291.EffectState, error) {
+
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
+	return s.ctl.Effect()
+}
+
+// modeName returns name of the mode of the given state.
+func (s *Service) modeName(state *ite8291.EffectState) string {
+
+	if s.ModeName != nil {
+		return s.ModeName(state)
+	}
+
+	return fmt.Sprintf("effect %#x", state.Effect)
+}
+
+// changed signals that the state might have changed.
+func (s *Service) changed() {
+
+	select {
+	case s.changes <- struct{}{}:
+	default: // already signaled
+	}
+}
+
+// refresh updates the properties reflecting the state.
+func (s *Service) refresh() error {
+
+	state, err := s.state()
+	if err != nil {
+		return err
+	}
+
+	s.update(OnProp, state.Control != ite8291.OffState)
+	s.update(ModeProp, s.modeName(state))
+	s.update(BrightnessProp, state.Brightness)
+	s.update(SpeedProp, state.Speed)
+
+	return nil
+}
+
+// update sets the given property if its value differs.
+func (s *Service) update(name string, value any) {
+
+	if !reflect.DeepEqual(s.props.GetMust(Interface, name), value) {
+		s.props.SetMust(Interface, name, value)
+	}
+}
+
+// setMode applies the mode of the given name with the given
+// arguments and returns its output.
+func (s *Service) setMode(mode string, args []string) (string, error) {
+
+	if s.Apply == nil || !slices.Contains(s.Modes, mode) {
+		return "", invalidArgs("unknown mode %q; expected one of %q", mode, s.Modes)
+	}
+
+	s.mu.Lock()
+	if s.ctx == nil || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return "", dbus.MakeFailedError(errors.New("service is stopped"))
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	if s.cancelApply != nil {
+		s.cancelApply() // the mode applied before gives way
+	}
+	done := make(chan struct{})
+	prev := s.applied
+	s.cancelApply, s.applied = cancel, done
+	s.applying.Add(1)
+	s.mu.Unlock()
+
+	defer s.applying.Done()
+	defer close(done)
+	defer cancel()
+
+	if prev != nil {
+		<-prev
+	}
+	if err := ctx.Err(); err != nil { // replaced while waiting
+		return "", err
+	}
+
+	var out strings.Builder
+	err := s.Apply(ctx, slices.Concat([]string{mode + "-mode"}, args), &out)
+	s.changed()
+
+	return out.String(), err
+}
+
+// stop stops the mode applied last.
+func (s *Service) stop() {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancelApply != nil {
+		s.cancelApply()
+	}
+}
+
+// setColor sets predefined color of the given number.
+func (s *Service) setColor(num byte, val string) (*ite8291.Color, error) {
+
+	if num < ite8291.CustomColorNumMinValue || num > ite8291.CustomColorNumMaxValue {
+		return nil, invalidArgs("color number %d is out of [%d,%d]",
+			num, ite8291.CustomColorNumMinValue, ite8291.CustomColorNumMaxValue)
+	}
+
+	color, err := s.parseColor(val)
+	if err != nil {
+		return nil, err
+	}
+
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
+	return color, s.ctl.SetColor(num, color)
+}
+
+// setFrame shows the given frame switching to 'user' effect if needed.
+func (s *Service) setFrame(rows [][]string) (*ite8291.Frame, error) {
+
+	if len(rows) != ite8291.RowsNumber {
+		return nil, invalidArgs("frame has %d rows; expected %d", len(rows), ite8291.RowsNumber)
+	}
+
+	var frame ite8291.Frame
+	for i, row := range rows {
+		if len(row) != ite8291.ColumnsNumber {
+			return nil, invalidArgs("row %d has %d colors; expected %d", i, len(row), ite8291.ColumnsNumber)
+		}
+
+		for j, val := range row {
+			color, err := s.parseColor(val)
+			if err != nil {
+				return nil, err
+			}
+			frame[i][j] = *color
+		}
+	}
+
+	s.opMu.Lock()
+	defer s.opMu.Unlock()
+
+	state, err := s.ctl.Effect()
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Control == ite8291.OffState || state.Effect != ite8291.UserEffect {
+		if err := s.ctl.SetUserMode(state.Brightness, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return &frame, s.ctl.WriteFrame(&frame)
+}
+
+// parseColor parses the given color value.
+func (s *Service) parseColor(val string) (*ite8291.Color, error) {
+
+	parse := ite8291.ParseColor
+	if s.ParseColor != nil {
+		parse = s.ParseColor
+	}
+
+	color, err := parse(val)
+	if err != nil {
+		return nil, invalidArgs("%v", err)
+	}
+
+	return color, nil
+}
+
+// onModeSet applies the mode set via Mode property. The mode is
+// applied in background since it can run until another mode is set.
+func (s *Service) onModeSet(c *prop.Change) *dbus.Error {
+
+	mode := c.Value.(string) //nolint:forcetypeassert // checked by signature
+	if s.Apply == nil || !slices.Contains(s.Modes, mode) {
+		return invalidArgs("unknown mode %q; expected one of %q", mode, s.Modes)
+	}
+
+	go func() { _, _ = s.setMode(mode, nil) }()
+
+	return nil
+}
+
+// onBrightnessSet sets brightness set via Brightness property.
+func (s *Service) onBrightnessSet(c *prop.Change) *dbus.Error {
+
+	brightness := c.Value.(byte) //nolint:forcetypeassert // checked by signature
+	if brightness > ite8291.BrightnessMaxValue {
+		return invalidArgs("brightness %d exceeds %d", brightness, ite8291.BrightnessMaxValue)
+	}
+
+	return toError(s.ctl.SetBrightness(brightness))
+}
+
+// onColorsSet sets predefined colors set via Colors property.
+func (s *Service) onColorsSet(c *prop.Change) *dbus.Error {
+
+	colors := c.Value.([]string) //nolint:forcetypeassert // checked by signature
+	if len(colors) != ite8291.CustomColorNumMaxValue {
+		return invalidArgs("%d colors given; expected %d", len(colors), ite8291.CustomColorNumMaxValue)
+	}
+
+	for i, val := range colors {
+		if _, err := s.setColor(byte(i+ite8291.CustomColorNumMinValue), val); err != nil {
+			return toError(err)
+		}
+	}
+
+	return nil
+}
+
+// onFrameSet shows the frame set via Frame property.
+func (s *Service) onFrameSet(c *prop.Change) *dbus.Error {
+
+	_, err := s.setFrame(c.Value.([][]string)) //nolint:forcetypeassert // checked by signature
+
+	return toError(err)
+}
+
+// keyboard provides methods of the keyboard backlight interface.
+type keyboard struct {
+	s *Service
+}
+
+// SetMode applies the mode of the given name with the given
+// arguments of its command and returns its output. It returns when
+// the command is done; commands running until interrupted are done
+// when another mode is set or Stop is called.
+func (k *keyboard) SetMode(mode string, args []string) (string, *dbus.Error) {
+
+	out, err := k.s.setMode(mode, args)
+
+	return out, toError(err)
+}
+
+// Stop stops the mode set last if it's still running.
+func (k *keyboard) Stop() *dbus.Error {
+
+	k.s.stop()
+
+	return nil
+}
+
+// SetColor sets predefined color of the given number.
+func (k *keyboard) SetColor(num byte, color string) *dbus.Error {
+
+	col, err := k.s.setColor(num, color)
+	if err != nil {
+		return toError(err)
+	}
+
+	colors := slices.Clone(k.s.props.GetMust(Interface, ColorsProp).([]string)) //nolint:forcetypeassert
+	if int(num) <= len(colors) {
+		colors[num-ite8291.CustomColorNumMinValue] = col.String()
+		k.s.update(ColorsProp, colors)
+	}
+
+	return nil
+}
+
+// SetFrame shows the given colors of the keys by rows in 'user'
+// effect.
+func (k *keyboard) SetFrame(frame [][]string) *dbus.Error {
+
+	shown, err := k.s.setFrame(frame)
+	if err != nil {
+		return toError(err)
+	}
+
+	k.s.update(FrameProp, frameStrings(shown))
+
+	return nil
+}
+
+// frameStrings returns colors of the given frame by rows.
+func frameStrings(frame *ite8291.Frame) [][]string {
+
+	rows := make([][]string, len(frame))
+	for i, row := range frame {
+		rows[i] = make([]string, len(row))
+		for j := range row {
+			rows[i][j] = row[j].String()
+		}
+	}
+
+	return rows
+}
+
+// invalidArgs returns D-Bus error reporting invalid arguments.
+func invalidArgs(format string, a ...any) *dbus.Error {
+	return dbus.NewError(invalidArgsError, []any{fmt.Sprintf(format, a...)})
+}
+
+// toError converts the given error to D-Bus error.
+func toError(err error) *dbus.Error {
+
+	var dbusErr *dbus.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &dbusErr):
+		return dbusErr
+	}
+
+	return dbus.MakeFailedError(err)
+}
@@ -0,0 +1,233 @@
+package dbusapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("D-Bus service", func() {
+
+	var dev *ite8291.VirtualDevice
+	var service *Service
+	var client *dbus.Conn
+	var obj dbus.BusObject
+	var cancel context.CancelFunc
+	var served chan error
+
+	red := ite8291.NewColor(0xff, 0, 0)
+
+	// connect connects to the private bus.
+	connect := func() *dbus.Conn {
+		conn, err := dbus.Connect(busAddress)
+		Ω(err).ShouldNot(HaveOccurred())
+		DeferCleanup(conn.Close)
+		return conn
+	}
+
+	// serve starts serving the virtual device.
+	serve := func() {
+		conn := connect()
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		served = make(chan error, 1)
+		go func() { served <- service.Serve(ctx, conn, 0) }()
+
+		Eventually(func() (bool, error) {
+			var owned bool
+			err := client.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, BusName).Store(&owned)
+			return owned, err
+		}).Should(BeTrue())
+	}
+
+	// get returns value of the given property.
+	get := func(name string) any {
+		v, err := obj.GetProperty(Interface + "." + name)
+		Ω(err).ShouldNot(HaveOccurred())
+		return v.Value()
+	}
+
+	BeforeEach(func() {
+		dev = ite8291.NewVirtualDevice()
+		service = NewService(dev)
+		service.Modes = []string{"user", "wave"}
+		service.ModeName = func(state *ite8291.EffectState) string {
+			if state.Effect == ite8291.WaveEffect {
+				return "wave"
+			}
+			return "user"
+		}
+		service.Colors = []*ite8291.Color{red, red, red, red, red, red, red}
+		client = connect()
+		obj = client.Object(BusName, ObjectPath)
+	})
+
+	AfterEach(func() {
+		if cancel != nil {
+			cancel()
+			Eventually(served).Should(Receive(BeNil()))
+			cancel = nil
+		}
+	})
+
+	It("exports properties reflecting the state", func() {
+		Ω(ite8291.NewController(dev).SetBrightness(20)).Should(Succeed())
+		serve()
+
+		Ω(get(OnProp)).Should(BeTrue())
+		Ω(get(ModeProp)).Should(Equal("user"))
+		Ω(get(BrightnessProp)).Should(Equal(byte(20)))
+		Ω(get(ColorsProp)).Should(HaveLen(7))
+		Ω(get(ColorsProp)).Should(ContainElement(red.String()))
+		Ω(get(FrameProp)).Should(BeEmpty())
+
+		var xml string
+		Ω(obj.Call("org.freedesktop.DBus.Introspectable.Introspect", 0).Store(&xml)).Should(Succeed())
+		Ω(xml).Should(ContainSubstring(Interface))
+		Ω(xml).Should(ContainSubstring("SetFrame"))
+	})
+
+	It("sets brightness and signals changes of properties", func() {
+		serve()
+
+		Ω(client.AddMatchSignal(dbus.WithMatchObjectPath(ObjectPath),
+			dbus.WithMatchInterface("org.freedesktop.DBus.Properties"))).Should(Succeed())
+		signals := make(chan *dbus.Signal, 10)
+		client.Signal(signals)
+
+		Ω(obj.SetProperty(Interface+"."+BrightnessProp, dbus.MakeVariant(byte(10)))).Should(Succeed())
+		Ω(dev.Effect().Brightness).Should(Equal(byte(10)))
+
+		var signal *dbus.Signal
+		Eventually(signals).Should(Receive(&signal))
+		Ω(signal.Name).Should(Equal("org.freedesktop.DBus.Properties.PropertiesChanged"))
+		Ω(signal.Body[1]).Should(HaveKeyWithValue(BrightnessProp, dbus.MakeVariant(byte(10))))
+
+		// changes made by other clients of the device are signaled after refresh
+		Ω(ite8291.NewController(service.Device()).SetBrightness(30)).Should(Succeed())
+		Eventually(func() any { return get(BrightnessProp) }).Should(Equal(byte(30)))
+
+		Ω(obj.SetProperty(Interface+"."+BrightnessProp, dbus.MakeVariant(byte(51)))).
+			Should(MatchError(ContainSubstring("exceeds")))
+	})
+
+	It("sets predefined colors", func() {
+		serve()
+
+		Ω(obj.Call(Interface+".SetColor", 0, byte(2), "#00FF00").Err).Should(Succeed())
+		Ω(dev.Color(2)).Should(Equal(ite8291.NewColor(0, 0xff, 0)))
+		Ω(get(ColorsProp).([]string)[1]).Should(Equal(ite8291.NewColor(0, 0xff, 0).String()))
+
+		colors := repeat("#0000FF", 7)
+		Ω(obj.SetProperty(Interface+"."+ColorsProp, dbus.MakeVariant(colors))).Should(Succeed())
+		Ω(dev.Color(7)).Should(Equal(ite8291.NewColor(0, 0, 0xff)))
+
+		Ω(obj.Call(Interface+".SetColor", 0, byte(8), "#00FF00").Err).Should(MatchError(ContainSubstring("out of")))
+		Ω(obj.Call(Interface+".SetColor", 0, byte(1), "nocolor").Err).Should(MatchError(ContainSubstring("nocolor")))
+		Ω(obj.SetProperty(Interface+"."+ColorsProp, dbus.MakeVariant(colors[1:]))).
+			Should(MatchError(ContainSubstring("expected 7")))
+	})
+
+	It("shows frames switching to 'user' effect", func() {
+		Ω(ite8291.NewController(dev).SetWaveMode(1, 30, ite8291.DirectionLeft, false)).Should(Succeed())
+		serve()
+
+		frame := make([][]string, ite8291.RowsNumber)
+		for i := range frame {
+			frame[i] = repeat("#FF0000", ite8291.ColumnsNumber)
+		}
+		frame[0][0] = "#000000"
+
+		Ω(obj.Call(Interface+".SetFrame", 0, frame).Err).Should(Succeed())
+
+		expected := ite8291.NewFrame(red)
+		expected[0][0] = *ite8291.NewColor(0, 0, 0)
+		Ω(dev.Frame()).Should(Equal(expected))
+		Ω(dev.Effect().Effect).Should(Equal(byte(ite8291.UserEffect)))
+		Ω(dev.Effect().Brightness).Should(Equal(byte(30)))
+		Ω(get(FrameProp)).Should(Equal(frameStrings(expected)))
+		Eventually(func() any { return get(ModeProp) }).Should(Equal("user"))
+
+		Ω(obj.Call(Interface+".SetFrame", 0, frame[1:]).Err).Should(MatchError(ContainSubstring("rows")))
+		frame[0] = frame[0][1:]
+		Ω(obj.SetProperty(Interface+"."+FrameProp, dbus.MakeVariant(frame))).
+			Should(MatchError(ContainSubstring("colors")))
+	})
+
+	It("applies modes", func() {
+		applied := make(chan []string, 2)
+		service.Apply = func(ctx context.Context, args []string, out io.Writer) error {
+			applied <- args
+			if args[0] == "wave-mode" {
+				<-ctx.Done() // runs until interrupted
+			}
+			fmt.Fprint(out, "done")
+			return nil
+		}
+		serve()
+
+		var out string
+		Ω(obj.Call(Interface+".SetMode", 0, "user", []string{"-b", "10"}).Store(&out)).Should(Succeed())
+		Ω(out).Should(Equal("done"))
+		Ω(applied).Should(Receive(Equal([]string{"user-mode", "-b", "10"})))
+
+		Ω(obj.SetProperty(Interface+"."+ModeProp, dbus.MakeVariant("wave"))).Should(Succeed())
+		Eventually(applied).Should(Receive(Equal([]string{"wave-mode"})))
+		Ω(obj.Call(Interface+".Stop", 0).Err).Should(Succeed())
+
+		Ω(obj.Call(Interface+".SetMode", 0, "rainbow", []string{}).Err).Should(MatchError(ContainSubstring("unknown mode")))
+		Ω(obj.SetProperty(Interface+"."+ModeProp, dbus.MakeVariant("rainbow"))).
+			Should(MatchError(ContainSubstring("unknown mode")))
+	})
+
+	It("applies modes one at a time", func() {
+		started, finished := make(chan struct{}), make(chan struct{})
+		service.Apply = func(ctx context.Context, args []string, out io.Writer) error {
+			if args[0] == "wave-mode" {
+				close(started)
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond) // e.g. restoring the state
+				close(finished)
+				return nil
+			}
+			select {
+			case <-finished:
+			default:
+				return errors.New("applied before the previous mode returned")
+			}
+			fmt.Fprint(out, "done")
+			return nil
+		}
+		serve()
+
+		Ω(obj.SetProperty(Interface+"."+ModeProp, dbus.MakeVariant("wave"))).Should(Succeed())
+		Eventually(started).Should(BeClosed())
+
+		var out string
+		Ω(obj.Call(Interface+".SetMode", 0, "user", []string{}).Store(&out)).Should(Succeed())
+		Ω(out).Should(Equal("done"))
+	})
+
+	It("refuses to take the name owned by another connection", func() {
+		serve()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		Ω(NewService(dev).Serve(ctx, connect(), 0)).Should(MatchError(ErrNameTaken))
+	})
+})
+
+// repeat returns slice of n copies of the given value.
+func repeat(val string, n int) []string {
+	return strings.Split(strings.Repeat(val+" ", n-1)+val, " ")
+}
@@ -0,0 +1,111 @@
+package editor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// keyUnit - width of a regular key with its gap in terminal columns.
+const keyUnit = 4
+
+// cell provides a terminal cell of the keyboard drawing.
+type cell struct {
+	color *ite8291.Color // nil outside of keys
+	char  rune
+}
+
+// swatch returns a sample of the given color.
+func swatch(col *ite8291.Color) string {
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm  \x1b[0m", col.Red, col.Green, col.Blue)
+}
+
+// contrast returns sequence setting foreground color readable on the
+// given background color.
+func contrast(col *ite8291.Color) string {
+
+	if 0.299*float64(col.Red)+0.587*float64(col.Green)+0.114*float64(col.Blue) > 128 {
+		return "30"
+	}
+
+	return "97"
+}
+
+// keyboard returns lines of the keyboard drawing. The cursor key is
+// framed by brackets; keys to paint are marked by a dot.
+func (e *Editor) keyboard() []string {
+
+	w, h := e.layout.Bounds()
+	grid := make([][]cell, int(math.Ceil(h)))
+	for i := range grid {
+		grid[i] = make([]cell, int(math.Round(w*keyUnit)))
+		for j := range grid[i] {
+			grid[i][j].char = ' '
+		}
+	}
+
+	marked := map[int]bool{}
+	for _, i := range e.chosen() {
+		marked[i] = true
+	}
+
+	for i := range e.layout.Keys {
+		k := &e.layout.Keys[i]
+		col := e.frame.Get(k.Cell())
+
+		x0, x1 := int(math.Round(k.X*keyUnit)), int(math.Round((k.X+k.Width)*keyUnit))-1
+		for y := int(math.Floor(k.Y)); y < int(math.Ceil(k.Y+k.Height)) && y < len(grid); y++ {
+			for x := x0; x < x1 && x < len(grid[y]); x++ {
+				grid[y][x] = cell{color: col, char: ' '}
+			}
+		}
+
+		y, mid := int(math.Floor(k.Y+k.Height/2)), (x0+x1-1)/2
+		if y >= len(grid) || x1 > len(grid[y]) || x1 <= x0 {
+			continue
+		}
+		if i == e.cursor {
+			grid[y][x0].char, grid[y][x1-1].char = '[', ']'
+		}
+		if marked[i] {
+			grid[y][mid].char = '•'
+		}
+	}
+
+	lines := make([]string, len(grid))
+	for i, row := range grid {
+
+		var sb strings.Builder
+		var last string
+		for _, c := range row {
+			code := "\x1b[0m"
+			if c.color != nil {
+				code = fmt.Sprintf("\x1b[0;%s;48;2;%d;%d;%dm", contrast(c.color), c.color.Red, c.color.Green,
+					c.color.Blue)
+			}
+			if code != last {
+				sb.WriteString(code)
+				last = code
+			}
+			sb.WriteRune(c.char)
+		}
+		sb.WriteString("\x1b[0m")
+
+		lines[i] = sb.String()
+	}
+
+	return lines
+}
+
+// screen returns the whole editor screen. Lines are terminated by
+// CRLF since the terminal is in raw mode.
+func (e *Editor) screen() string {
+
+	lines := append([]string{e.Title, ""}, e.keyboard()...)
+	lines = append(lines, "")
+	lines = append(lines, e.status()...)
+
+	return "\x1b[H\x1b[2J" + strings.Join(lines, "\x1b[K\r\n")
+}
@@ -0,0 +1,453 @@
+package editor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// mode represents editor input mode.
+type mode int
+
+// editor input modes.
+const (
+	// normalMode - keys move cursor, select keys and paint them.
+	normalMode mode = iota
+	// colorInputMode - keys edit text of the current color.
+	colorInputMode
+	// hsvMode - keys adjust HSV sliders of the current color.
+	hsvMode
+)
+
+// steps of HSV sliders.
+const (
+	hueStep   = 5
+	valueStep = 0.05
+)
+
+// hsvSliders - names of HSV sliders.
+var hsvSliders = []string{"hue", "saturation", "value"}
+
+// Editor provides interactive terminal editor of colors of the
+// keyboard backlight keys. Keys are shown and navigated using the
+// keyboard layout.
+type Editor struct {
+
+	// Title is shown at the top of the editor screen.
+	Title string
+
+	// ParseColor, if set, converts text entered by user to a color.
+	// It defaults to ite8291.ParseColor.
+	ParseColor func(text string) (*ite8291.Color, error)
+
+	// NamedColors provides names of the colors accepted by
+	// ParseColor. They are cycled by 'n' key.
+	NamedColors []string
+
+	// Apply, if set, is called with the edited frame after every
+	// change, e.g. to show it on the device.
+	Apply func(frame *ite8291.Frame) error
+
+	// Save, if set, is called with the edited frame when user
+	// requests to save it.
+	Save func(frame *ite8291.Frame) error
+
+	layout *ite8291.Layout
+	frame  *ite8291.Frame
+
+	cursor   int          // index of the key under cursor
+	selected map[int]bool // indexes of selected keys
+	anchor   int          // index of the key anchoring region selection or -1
+
+	color ite8291.Color // current color
+	hsv   ite8291.HSV   // current color in HSV color space
+	named int           // index of the last picked named color
+
+	mode    mode
+	input   string // text of the color being entered
+	slider  int    // index of the active HSV slider
+	message string // message shown to the user
+	quit    bool
+}
+
+// New creates an editor of the given frame using the given layout.
+// The frame is edited in place.
+func New(layout *ite8291.Layout, frame *ite8291.Frame) *Editor {
+
+	e := &Editor{layout: layout, frame: frame, selected: map[int]bool{}, anchor: -1, named: -1}
+	e.setColor(ite8291.NewColor(0xff, 0xff, 0xff))
+
+	return e
+}
+
+// Frame returns the edited frame.
+func (e *Editor) Frame() *ite8291.Frame {
+	return e.frame
+}
+
+// Run shows the editor on out and handles keys read from in until
+// user quits, in is exhausted or ctx is done. in is expected to be a
+// terminal in raw mode. The edited frame is applied before the
+// first key is handled.
+func (e *Editor) Run(ctx context.Context, in io.Reader, out io.Writer) (err error) {
+
+	if len(e.layout.Keys) == 0 {
+		return fmt.Errorf("%w: layout %q has no keys", ite8291.ErrInvalidLayout, e.layout.Name)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// use alternate screen and hide cursor
+	if _, err := io.WriteString(out, "\x1b[?1049h\x1b[?25l"); err != nil {
+		return err
+	}
+	defer func() {
+		if _, werr := io.WriteString(out, "\x1b[?25h\x1b[?1049l"); err == nil {
+			err = werr
+		}
+	}()
+
+	e.apply()
+	keys := readKeys(ctx, in)
+
+	for !e.quit {
+
+		if _, err := io.WriteString(out, e.screen()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			e.handle(k)
+		}
+	}
+
+	return nil
+}
+
+// handle handles the given key according to the current mode.
+func (e *Editor) handle(k rune) {
+
+	e.message = ""
+
+	if k == keyInterrupt {
+		e.quit = true
+		return
+	}
+
+	switch e.mode {
+	case normalMode:
+		e.handleNormal(k)
+	case colorInputMode:
+		e.handleColorInput(k)
+	case hsvMode:
+		e.handleHSV(k)
+	}
+}
+
+// handleNormal handles keys of normal mode.
+//
+//nolint:cyclop
+func (e *Editor) handleNormal(k rune) {
+
+	switch k {
+	case keyUp, 'k':
+		e.move(0, -1)
+	case keyDown, 'j':
+		e.move(0, 1)
+	case keyLeft, 'h':
+		e.move(-1, 0)
+	case keyRight, 'l':
+		e.move(1, 0)
+	case ' ':
+		e.selected[e.cursor] = !e.selected[e.cursor]
+	case 'v':
+		if e.anchor < 0 {
+			e.anchor = e.cursor
+		} else {
+			for _, i := range e.region() {
+				e.selected[i] = true
+			}
+			e.anchor = -1
+		}
+	case 'a':
+		for i := range e.layout.Keys {
+			e.selected[i] = true
+		}
+	case keyEsc:
+		clear(e.selected)
+		e.anchor = -1
+	case keyEnter, 'p':
+		e.paint()
+	case 'g':
+		e.setColor(e.frame.Get(e.layout.Keys[e.cursor].Cell()))
+	case 'n':
+		e.nextNamedColor()
+	case '#':
+		e.mode, e.input = colorInputMode, "#"
+	case 'c':
+		e.mode = hsvMode
+	case 's':
+		e.save()
+	case 'q':
+		e.quit = true
+	}
+}
+
+// handleColorInput handles keys of color input mode.
+func (e *Editor) handleColorInput(k rune) {
+
+	switch {
+	case k == keyEsc:
+		e.mode = normalMode
+	case k == keyEnter:
+		col, err := e.parseColor(e.input)
+		if err != nil {
+			e.message = err.Error()
+			return
+		}
+		e.setColor(col)
+		e.mode = normalMode
+	case k == keyBackspace:
+		if len(e.input) > 0 {
+			_, n := lastRune(e.input)
+			e.input = e.input[:len(e.input)-n]
+		}
+	case k >= ' ':
+		e.input += string(k)
+	}
+}
+
+// handleHSV handles keys of HSV sliders mode.
+func (e *Editor) handleHSV(k rune) {
+
+	step := 0.0
+	switch k {
+	case keyEsc, keyEnter, 'c':
+		e.mode = normalMode
+	case keyUp, 'k':
+		e.slider = (e.slider + len(hsvSliders) - 1) % len(hsvSliders)
+	case keyDown, 'j':
+		e.slider = (e.slider + 1) % len(hsvSliders)
+	case keyLeft, 'h':
+		step = -1
+	case keyRight, 'l':
+		step = 1
+	}
+
+	if step == 0 {
+		return
+	}
+
+	switch e.slider {
+	case 0:
+		e.hsv.H = math.Mod(e.hsv.H+step*hueStep+360, 360)
+	case 1:
+		e.hsv.S = max(0, min(1, e.hsv.S+step*valueStep))
+	case 2:
+		e.hsv.V = max(0, min(1, e.hsv.V+step*valueStep))
+	}
+	e.color = *e.hsv.Color()
+}
+
+// setColor sets the current color.
+func (e *Editor) setColor(col *ite8291.Color) {
+	e.color, e.hsv = *col, col.HSV()
+}
+
+// parseColor converts the given text to a color.
+func (e *Editor) parseColor(text string) (*ite8291.Color, error) {
+
+	if e.ParseColor != nil {
+		return e.ParseColor(text)
+	}
+
+	return ite8291.ParseColor(text)
+}
+
+// nextNamedColor sets the current color to the next named color.
+func (e *Editor) nextNamedColor() {
+
+	if len(e.NamedColors) == 0 {
+		e.message = "no named colors configured"
+		return
+	}
+
+	e.named = (e.named + 1) % len(e.NamedColors)
+	name := e.NamedColors[e.named]
+
+	col, err := e.parseColor(name)
+	if err != nil {
+		e.message = err.Error()
+		return
+	}
+
+	e.setColor(col)
+	e.message = "color " + name
+}
+
+// move moves cursor to the nearest key in the given direction.
+// Distance along the direction is preferred to the distance across
+// it.
+func (e *Editor) move(dx, dy float64) {
+
+	cx, cy := e.layout.Keys[e.cursor].Center()
+
+	best, bestDist := -1, math.Inf(1)
+	for i := range e.layout.Keys {
+		x, y := e.layout.Keys[i].Center()
+
+		along := (x-cx)*dx + (y-cy)*dy
+		if along <= 0.01 {
+			continue
+		}
+
+		across := math.Abs((x-cx)*dy) + math.Abs((y-cy)*dx)
+		if dist := along + 2*across; dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+
+	if best >= 0 {
+		e.cursor = best
+	}
+}
+
+// region returns indexes of the keys inside the rectangle of the
+// keyboard matrix between the anchor and the cursor keys.
+func (e *Editor) region() []int {
+
+	if e.anchor < 0 {
+		return nil
+	}
+
+	a, c := e.layout.Keys[e.anchor].Cell(), e.layout.Keys[e.cursor].Cell()
+	minRow, maxRow := min(a.Row, c.Row), max(a.Row, c.Row)
+	minCol, maxCol := min(a.Column, c.Column), max(a.Column, c.Column)
+
+	var keys []int
+	for i := range e.layout.Keys {
+		cell := e.layout.Keys[i].Cell()
+		if cell.Row >= minRow && cell.Row <= maxRow && cell.Column >= minCol && cell.Column <= maxCol {
+			keys = append(keys, i)
+		}
+	}
+
+	return keys
+}
+
+// chosen returns indexes of the selected keys and of the keys of the
+// region being selected.
+func (e *Editor) chosen() []int {
+
+	var keys []int
+	for i := range e.layout.Keys {
+		if e.selected[i] {
+			keys = append(keys, i)
+		}
+	}
+
+	return append(keys, e.region()...)
+}
+
+// targets returns indexes of the keys to paint: the chosen keys or the
+// cursor key if there are none.
+func (e *Editor) targets() []int {
+
+	if keys := e.chosen(); len(keys) > 0 {
+		return keys
+	}
+
+	return []int{e.cursor}
+}
+
+// paint sets the target keys to the current color and applies the
+// frame.
+func (e *Editor) paint() {
+
+	for _, i := range e.targets() {
+		e.frame.Set(e.layout.Keys[i].Cell(), &e.color)
+	}
+
+	e.apply()
+}
+
+// apply applies the edited frame.
+func (e *Editor) apply() {
+
+	if e.Apply == nil {
+		return
+	}
+
+	if err := e.Apply(e.frame); err != nil {
+		e.message = "error applying colors: " + err.Error()
+	}
+}
+
+// save saves the edited frame.
+func (e *Editor) save() {
+
+	if e.Save == nil {
+		return
+	}
+
+	if err := e.Save(e.frame); err != nil {
+		e.message = "error saving: " + err.Error()
+		return
+	}
+
+	e.message = "saved"
+}
+
+// lastRune returns the last rune of s and its length in bytes.
+func lastRune(s string) (rune, int) {
+
+	r := []rune(s)
+	last := r[len(r)-1]
+
+	return last, len(string(last))
+}
+
+// status returns lines describing the editor state.
+func (e *Editor) status() []string {
+
+	k := &e.layout.Keys[e.cursor]
+	lines := []string{
+		fmt.Sprintf("Key: %s (row %d, column %d) %s   Selected: %d",
+			k.Name, k.Row, k.Column, e.frame.Get(k.Cell()), len(e.targets())),
+		fmt.Sprintf("Color: %s %s   H %3.0f  S %.2f  V %.2f",
+			swatch(&e.color), e.color.String(), e.hsv.H, e.hsv.S, e.hsv.V),
+	}
+
+	switch e.mode {
+	case colorInputMode:
+		lines = append(lines, "Color (hex or name): "+e.input+"▏",
+			"[enter] set  [esc] cancel")
+	case hsvMode:
+		var sliders []string
+		for i, name := range hsvSliders {
+			if i == e.slider {
+				name = "[" + strings.ToUpper(name) + "]"
+			}
+			sliders = append(sliders, name)
+		}
+		lines = append(lines, "HSV: "+strings.Join(sliders, " "),
+			"[↑↓] slider  [←→] adjust  [enter/esc] done")
+	default:
+		lines = append(lines, "",
+			"[←↑↓→/hjkl] move  [space] select  [v] region  [a] all  [esc] clear  [enter/p] paint  "+
+				"[g] grab  [#] enter color  [n] named color  [c] HSV  [s] save  [q] quit")
+	}
+
+	return append(lines, e.message)
+}
@@ -0,0 +1,168 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("Editor", func() {
+
+	var ed *Editor
+	var applied, saved []*ite8291.Frame
+	var out *gbytes.Buffer
+	black, red, green := ite8291.NewColor(0, 0, 0), ite8291.NewColor(0xff, 0, 0), ite8291.NewColor(0, 0xff, 0)
+
+	// run runs the editor with the given keys.
+	run := func(keys string) {
+		GinkgoHelper()
+		Ω(ed.Run(context.Background(), strings.NewReader(keys), out)).Should(Succeed())
+	}
+
+	// cells returns cells of the given row between the given columns.
+	cells := func(row, from, to int) []ite8291.Cell {
+		var c []ite8291.Cell
+		for j := from; j <= to; j++ {
+			c = append(c, ite8291.Cell{Row: row, Column: j})
+		}
+		return c
+	}
+
+	BeforeEach(func() {
+		applied, saved = nil, nil
+		out = gbytes.NewBuffer()
+
+		ed = New(ite8291.DefaultLayout(), ite8291.NewFrame(black))
+		ed.Title = "editing test"
+		ed.NamedColors = []string{"green", "red"}
+		ed.ParseColor = func(s string) (*ite8291.Color, error) {
+			switch s {
+			case "red":
+				return red, nil
+			case "green":
+				return green, nil
+			}
+			return ite8291.ParseColor(s)
+		}
+		ed.Apply = func(f *ite8291.Frame) error {
+			c := *f
+			applied = append(applied, &c)
+			return nil
+		}
+		ed.Save = func(f *ite8291.Frame) error {
+			c := *f
+			saved = append(saved, &c)
+			return nil
+		}
+	})
+
+	It("shows the keyboard on alternate screen and applies initial frame", func() {
+		run("q")
+
+		Ω(out).Should(gbytes.Say(`\x1b\[\?1049h`))
+		Ω(out).Should(gbytes.Say("editing test"))
+		Ω(out).Should(gbytes.Say(`Key: r0c0 \(row 0, column 0\)`))
+		Ω(out).Should(gbytes.Say(`\x1b\[\?1049l`))
+		Ω(applied).Should(Equal([]*ite8291.Frame{ite8291.NewFrame(black)}))
+	})
+
+	It("paints the key under cursor with entered color", func() {
+		run("ll\x1b[Bj#F00\rp")
+
+		expected := ite8291.NewFrame(black)
+		expected.Set(ite8291.Cell{Row: 2, Column: 2}, red)
+		Ω(ed.Frame()).Should(Equal(expected))
+		Ω(applied).Should(HaveLen(2))
+		Ω(applied[1]).Should(Equal(expected))
+	})
+
+	It("paints selected keys and region with named color", func() {
+		run(" lll vll\x1b[Bvnp")
+
+		expected := ite8291.NewFrame(black)
+		expected.SetCells([]ite8291.Cell{{Row: 0, Column: 0}}, green)
+		expected.SetCells(cells(0, 3, 5), green)
+		expected.SetCells(cells(1, 3, 5), green)
+		Ω(ed.Frame()).Should(Equal(expected))
+	})
+
+	It("clears selection on escape", func() {
+		run("a\x1bn\r")
+
+		expected := ite8291.NewFrame(black)
+		expected.Set(ite8291.Cell{Row: 0, Column: 0}, green)
+		Ω(ed.Frame()).Should(Equal(expected))
+	})
+
+	It("adjusts current color by HSV sliders", func() {
+		// red -> hue 120 (green) -> saturation 0 (white)
+		run("#\x7fred\rc" + strings.Repeat("l", 24) + "j" + strings.Repeat("h", 20) + "\rp")
+
+		Ω(ed.Frame().Get(ite8291.Cell{Row: 0, Column: 0})).Should(Equal(ite8291.NewColor(0xff, 0xff, 0xff)))
+	})
+
+	It("adjusts hue by HSV slider", func() {
+		run("#\x7fred\rc" + strings.Repeat("l", 24) + "\x1b\rp")
+
+		Ω(ed.Frame().Get(ite8291.Cell{Row: 0, Column: 0})).Should(Equal(green))
+	})
+
+	It("grabs color of the key under cursor", func() {
+		ed.Frame().Set(ite8291.Cell{Row: 3, Column: 3}, red)
+		run("jjjlllgkp")
+
+		Ω(ed.Frame().Get(ite8291.Cell{Row: 2, Column: 3})).Should(Equal(red))
+	})
+
+	It("reports invalid color and keeps the input", func() {
+		run("#nocolor\r")
+
+		Ω(out).Should(gbytes.Say("Color \\(hex or name\\): #nocolor"))
+		Ω(out).Should(gbytes.Say("invalid color format"))
+	})
+
+	It("saves the edited frame", func() {
+		run("#0f0\rps")
+
+		expected := ite8291.NewFrame(black)
+		expected.Set(ite8291.Cell{Row: 0, Column: 0}, green)
+		Ω(saved).Should(Equal([]*ite8291.Frame{expected}))
+		Ω(out).Should(gbytes.Say("saved"))
+	})
+
+	It("reports save errors", func() {
+		ed.Save = func(*ite8291.Frame) error { return errors.New("disk full") }
+		run("s")
+
+		Ω(out).Should(gbytes.Say("error saving: disk full"))
+	})
+
+	It("stops on interrupt", func() {
+		run("\x03p")
+
+		Ω(applied).Should(HaveLen(1))
+	})
+
+	It("fails with empty layout", func() {
+		ed = New(&ite8291.Layout{Name: "empty"}, ite8291.NewFrame(black))
+		Ω(ed.Run(context.Background(), strings.NewReader(""), out)).Should(MatchError(ite8291.ErrInvalidLayout))
+	})
+
+	DescribeTable("parses terminal input",
+		func(data string, keys []rune, rest string) {
+			k, r := parseKeys([]byte(data))
+			Ω(k).Should(Equal(keys))
+			Ω(string(r)).Should(Equal(rest))
+		},
+		Entry("arrows", "\x1b[A\x1b[B\x1bOC\x1b[D", []rune{keyUp, keyDown, keyRight, keyLeft}, ""),
+		Entry("escape", "\x1bq", []rune{keyEsc, 'q'}, ""),
+		Entry("control keys", "\r\n\x7f\x08\t\x03\x01", []rune{keyEnter, keyEnter, keyBackspace, keyBackspace,
+			keyTab, keyInterrupt}, ""),
+		Entry("unicode", "ж\xd0", []rune{'ж'}, "\xd0"),
+	)
+})
@@ -0,0 +1,94 @@
+package editor
+
+import (
+	"context"
+	"io"
+	"unicode/utf8"
+)
+
+// special keys reported by input.
+const (
+	keyUp rune = -(iota + 1)
+	keyDown
+	keyRight
+	keyLeft
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyTab
+	keyInterrupt
+)
+
+// arrows maps final bytes of ANSI cursor key sequences to keys.
+var arrows = map[byte]rune{'A': keyUp, 'B': keyDown, 'C': keyRight, 'D': keyLeft}
+
+// parseKeys converts bytes read from a terminal in raw mode to keys.
+// Special keys are reported by negative values. Incomplete UTF-8
+// sequence at the end of data is returned as rest.
+func parseKeys(data []byte) (keys []rune, rest []byte) {
+
+	for len(data) > 0 {
+
+		switch b := data[0]; {
+		case b == 0x1b && len(data) >= 3 && (data[1] == '[' || data[1] == 'O') && arrows[data[2]] != 0:
+			keys, data = append(keys, arrows[data[2]]), data[3:]
+			continue
+		case b == 0x1b:
+			keys = append(keys, keyEsc)
+		case b == '\r' || b == '\n':
+			keys = append(keys, keyEnter)
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, keyBackspace)
+		case b == '\t':
+			keys = append(keys, keyTab)
+		case b == 0x03 || b == 0x04:
+			keys = append(keys, keyInterrupt)
+		case b < 0x20:
+			// ignore other control characters
+		default:
+			if !utf8.FullRune(data) {
+				return keys, data
+			}
+			r, n := utf8.DecodeRune(data)
+			keys, data = append(keys, r), data[n:]
+			continue
+		}
+
+		data = data[1:]
+	}
+
+	return keys, nil
+}
+
+// readKeys reads keys from r and sends them to the returned channel.
+// The channel is closed when r is exhausted, fails or ctx is done.
+func readKeys(ctx context.Context, r io.Reader) <-chan rune {
+
+	keys := make(chan rune)
+
+	go func() {
+		defer close(keys)
+
+		buf := make([]byte, 256)
+		var rest []byte
+		for {
+			n, err := r.Read(buf)
+
+			var parsed []rune
+			parsed, rest = parseKeys(append(rest, buf[:n]...))
+			for _, k := range parsed {
+				select {
+				case keys <- k:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return keys
+}
@@ -0,0 +1,301 @@
+package evdev
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrNoKeyboardFound error indicates that no keyboard input device
+// was found.
+var ErrNoKeyboardFound = errors.New("no keyboard input device found")
+
+// input event types and key event values.
+const (
+	// EvKey - type of key events.
+	EvKey = 0x1
+
+	// KeyRelease - value of key release events.
+	KeyRelease = 0
+	// KeyPress - value of key press events.
+	KeyPress = 1
+	// KeyRepeat - value of key autorepeat events.
+	KeyRepeat = 2
+)
+
+// inputEvent mirrors struct input_event of the platform, so its size
+// follows the size of the platform's long in the time fields.
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// evRep - bit of key autorepeat events in EV bitmap of input device.
+const evRep = 0x14
+
+// devicesFile - file listing input devices.
+const devicesFile = "/proc/bus/input/devices"
+
+// Event provides an input event.
+type Event struct {
+	Time  time.Time
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// Pressed reports whether the event is a key press.
+func (e *Event) Pressed() bool {
+	return e.Type == EvKey && e.Value == KeyPress
+}
+
+// EventSource interface abstracts a source of input events.
+type EventSource interface {
+
+	// ReadEvent blocks until the next event is available and returns
+	// it. It returns io.EOF once the source is closed.
+	ReadEvent() (Event, error)
+
+	// Close closes the source.
+	Close() error
+}
+
+// Device provides an input device e.g. /dev/input/event3.
+type Device struct {
+	f *os.File
+}
+
+// Open opens the input device with the given path.
+func Open(path string) (*Device, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Device{f: f}, nil
+}
+
+// ReadEvent reads the next event from the device.
+func (d *Device) ReadEvent() (Event, error) {
+
+	var ev inputEvent
+	if err := binary.Read(d.f, binary.NativeEndian, &ev); err != nil {
+		if errors.Is(err, os.ErrClosed) {
+			return Event{}, io.EOF
+		}
+		return Event{}, err
+	}
+
+	return Event{
+		Time:  time.Unix(ev.Time.Unix()),
+		Type:  ev.Type,
+		Code:  ev.Code,
+		Value: ev.Value,
+	}, nil
+}
+
+// Close closes the device.
+func (d *Device) Close() error {
+	return d.f.Close()
+}
+
+// Keyboards returns paths of the input devices that are keyboards,
+// i.e. devices handled by keyboard handler that support key
+// autorepeat.
+func Keyboards() ([]string, error) {
+
+	f, err := os.Open(devicesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseKeyboards(f)
+}
+
+// parseKeyboards returns paths of the keyboard devices listed in the
+// given content of /proc/bus/input/devices.
+func parseKeyboards(r io.Reader) ([]string, error) {
+
+	var paths []string
+	var event string
+	var kbd, rep bool
+
+	flush := func() {
+		if kbd && rep && len(event) > 0 {
+			paths = append(paths, "/dev/input/"+event)
+		}
+		event, kbd, rep = "", false, false
+	}
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+
+		switch {
+		case len(line) == 0:
+			flush()
+		case strings.HasPrefix(line, "H: Handlers="):
+			for _, h := range strings.Fields(strings.TrimPrefix(line, "H: Handlers=")) {
+				if h == "kbd" {
+					kbd = true
+				} else if strings.HasPrefix(h, "event") {
+					event = h
+				}
+			}
+		case strings.HasPrefix(line, "B: EV="):
+			ev, err := strconv.ParseUint(strings.TrimPrefix(line, "B: EV="), 16, 64)
+			rep = err == nil && ev&(1<<EvKey) != 0 && ev&(1<<evRep) != 0
+		}
+	}
+	flush()
+
+	return paths, s.Err()
+}
+
+// OpenKeyboards opens the input devices with the given paths or, if
+// none are given, all keyboards (see Keyboards) and returns source
+// merging their events.
+func OpenKeyboards(paths []string) (EventSource, error) {
+
+	if len(paths) == 0 {
+		var err error
+		if paths, err = Keyboards(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNoKeyboardFound, err)
+		}
+		if len(paths) == 0 {
+			return nil, ErrNoKeyboardFound
+		}
+	}
+
+	sources := make([]EventSource, 0, len(paths))
+	for _, p := range paths {
+		d, err := Open(p)
+		if err != nil {
+			for _, s := range sources {
+				_ = s.Close()
+			}
+			return nil, err
+		}
+		sources = append(sources, d)
+	}
+
+	return Merge(sources...), nil
+}
+
+// merged provides events of several sources.
+type merged struct {
+	sources []EventSource
+	events  chan Event
+	errs    chan error
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Merge returns source providing events of all given sources. Reading
+// from the returned source fails with the first error of any of the
+// sources. Closing it closes all sources.
+func Merge(sources ...EventSource) EventSource {
+
+	m := &merged{sources: sources, events: make(chan Event), errs: make(chan error, len(sources)),
+		done: make(chan struct{})}
+
+	for _, s := range sources {
+		go func() {
+			for {
+				ev, err := s.ReadEvent()
+				if err != nil {
+					m.errs <- err
+					return
+				}
+
+				select {
+				case m.events <- ev:
+				case <-m.done:
+					return
+				}
+			}
+		}()
+	}
+
+	return m
+}
+
+// ReadEvent returns the next event of any of the merged sources.
+func (m *merged) ReadEvent() (Event, error) {
+
+	select {
+	case ev := <-m.events:
+		return ev, nil
+	case err := <-m.errs:
+		return Event{}, err
+	case <-m.done:
+		return Event{}, io.EOF
+	}
+}
+
+// Close closes all merged sources.
+func (m *merged) Close() (err error) {
+
+	m.once.Do(func() {
+		close(m.done)
+		for _, s := range m.sources {
+			err = errors.Join(err, s.Close())
+		}
+	})
+
+	return err
+}
+
+// KeyPresses reads events of the given source and sends codes of the
+// pressed keys to the returned keys channel. When reading fails or ctx
+// is done, the cause is sent to the returned errs channel and the keys
+// channel is closed. The source is closed once ctx is done to
+// interrupt pending read.
+func KeyPresses(ctx context.Context, src EventSource) (keys <-chan uint16, errs <-chan error) {
+
+	keyc, errc := make(chan uint16), make(chan error, 1)
+
+	stop := context.AfterFunc(ctx, func() { _ = src.Close() })
+
+	go func() {
+		defer stop()
+		defer close(keyc)
+
+		for {
+			ev, err := src.ReadEvent()
+			if err != nil {
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				errc <- err
+				return
+			}
+
+			if !ev.Pressed() {
+				continue
+			}
+
+			select {
+			case keyc <- ev.Code:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return keyc, errc
+}
@@ -0,0 +1,13 @@
+package evdev
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEvdev(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Evdev Suite")
+}
@@ -0,0 +1,226 @@
+package evdev
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// devices - sample content of /proc/bus/input/devices.
+const devices = `I: Bus=0019 Vendor=0000 Product=0001 Version=0000
+N: Name="Power Button"
+H: Handlers=kbd event0
+B: EV=3
+B: KEY=10000000000000 0
+
+I: Bus=0011 Vendor=0001 Product=0001 Version=ab83
+N: Name="AT Translated Set 2 keyboard"
+H: Handlers=sysrq kbd leds event3
+B: EV=120013
+B: KEY=402000000 3803078f800d001 feffffdfffefffff fffffffffffffffe
+
+I: Bus=0018 Vendor=06cb Product=ce26 Version=0100
+N: Name="Touchpad"
+H: Handlers=mouse0 event5
+B: EV=1b
+
+I: Bus=0003 Vendor=046d Product=c52b Version=0111
+N: Name="USB Keyboard"
+H: Handlers=sysrq kbd leds event12
+B: EV=120013
+`
+
+// scriptT type provides event source returning scripted events.
+type scriptT struct {
+	events []Event
+	err    error
+	closed bool
+}
+
+func (s *scriptT) ReadEvent() (Event, error) {
+
+	if len(s.events) == 0 {
+		return Event{}, s.err
+	}
+
+	ev := s.events[0]
+	s.events = s.events[1:]
+
+	return ev, nil
+}
+
+func (s *scriptT) Close() error {
+	s.closed = true
+	return nil
+}
+
+// blockingT type provides event source blocking reads until it is
+// closed.
+type blockingT struct {
+	closed chan struct{}
+}
+
+func (s *blockingT) ReadEvent() (Event, error) {
+	<-s.closed
+	return Event{}, io.EOF
+}
+
+func (s *blockingT) Close() error {
+	close(s.closed)
+	return nil
+}
+
+// eventBytes returns the given events encoded as struct input_event.
+func eventBytes(events ...Event) []byte {
+
+	var data bytes.Buffer
+	for _, ev := range events {
+		_ = binary.Write(&data, binary.NativeEndian, inputEvent{
+			Time: syscall.NsecToTimeval(ev.Time.UnixNano()), Type: ev.Type, Code: ev.Code, Value: ev.Value})
+	}
+
+	return data.Bytes()
+}
+
+var _ = Describe("Evdev", func() {
+
+	press := func(code uint16) Event { return Event{Type: EvKey, Code: code, Value: KeyPress} }
+
+	It("finds keyboards", func() {
+		Ω(parseKeyboards(strings.NewReader(devices))).Should(
+			Equal([]string{"/dev/input/event3", "/dev/input/event12"}))
+	})
+
+	It("reads events of device", func() {
+
+		t := time.Unix(1700000000, 123000)
+		events := []Event{
+			{Time: t, Type: EvKey, Code: 30, Value: KeyPress},
+			{Time: t, Type: 0, Code: 0, Value: 0},
+			{Time: t, Type: EvKey, Code: 30, Value: KeyRelease},
+		}
+
+		path := filepath.Join(GinkgoT().TempDir(), "event0")
+		Ω(os.WriteFile(path, eventBytes(events...), 0o600)).Should(Succeed())
+
+		dev, err := Open(path)
+		Ω(err).ShouldNot(HaveOccurred())
+		defer dev.Close()
+
+		for _, ev := range events {
+			read, err := dev.ReadEvent()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(read.Time.Equal(ev.Time)).Should(BeTrue())
+			Ω(read.Type).Should(Equal(ev.Type))
+			Ω(read.Code).Should(Equal(ev.Code))
+			Ω(read.Value).Should(Equal(ev.Value))
+		}
+		Ω(dev.ReadEvent()).Error().Should(MatchError(io.EOF))
+	})
+
+	It("fails to open missing device", func() {
+		Ω(OpenKeyboards([]string{filepath.Join(GinkgoT().TempDir(), "missing")})).Error().
+			Should(MatchError(os.ErrNotExist))
+	})
+
+	It("merges sources", func() {
+
+		s1 := &scriptT{events: []Event{press(1), press(2)}, err: errors.New("s1 failed")}
+		s2 := &scriptT{events: []Event{press(3)}, err: io.EOF}
+		src := Merge(s1, s2)
+
+		var codes []uint16
+		var errs []error
+		for len(errs) < 2 {
+			ev, err := src.ReadEvent()
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			codes = append(codes, ev.Code)
+		}
+
+		Ω(codes).Should(ConsistOf(uint16(1), uint16(2), uint16(3)))
+		Ω(errs).Should(ConsistOf(MatchError("s1 failed"), MatchError(io.EOF)))
+
+		Ω(src.Close()).Should(Succeed())
+		Ω(s1.closed).Should(BeTrue())
+		Ω(s2.closed).Should(BeTrue())
+		Ω(src.ReadEvent()).Error().Should(MatchError(io.EOF))
+	})
+
+	It("reports key presses", func() {
+
+		src := &scriptT{events: []Event{
+			press(30), {Type: EvKey, Code: 30, Value: KeyRepeat}, {Type: EvKey, Code: 30, Value: KeyRelease},
+			{Type: 0x4, Code: 0x4, Value: 30}, press(57),
+		}, err: io.EOF}
+
+		keys, errs := KeyPresses(context.Background(), src)
+
+		var codes []uint16
+		for code := range keys {
+			codes = append(codes, code)
+		}
+
+		Ω(codes).Should(Equal([]uint16{30, 57}))
+		Ω(<-errs).Should(MatchError(io.EOF))
+	})
+
+	It("stops reporting key presses when context is done", func() {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		keys, errs := KeyPresses(ctx, &scriptT{events: []Event{press(30), press(31)}, err: io.EOF})
+
+		Ω(<-keys).Should(Equal(uint16(30)))
+		cancel()
+
+		Ω(<-errs).Should(MatchError(context.Canceled))
+		Eventually(keys).Should(BeClosed())
+	})
+
+	It("interrupts pending read when context is done", func() {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		src := &blockingT{closed: make(chan struct{})}
+		keys, errs := KeyPresses(ctx, src)
+
+		cancel()
+
+		Eventually(src.closed).Should(BeClosed())
+		Ω(<-errs).Should(MatchError(context.Canceled))
+		Eventually(keys).Should(BeClosed())
+	})
+
+	DescribeTable("names keys",
+		func(code uint16, name string) {
+			Ω(KeyName(code)).Should(Equal(name))
+
+			found, ok := KeyCode(name)
+			Ω(ok).Should(BeTrue())
+			Ω(found).Should(Equal(code))
+		},
+		Entry(nil, uint16(1), "esc"),
+		Entry(nil, uint16(30), "a"),
+		Entry(nil, uint16(57), "space"),
+		Entry(nil, uint16(0x1d0), "fn"),
+		Entry(nil, uint16(999), "key999"),
+	)
+
+	It("does not find codes of unknown key names", func() {
+		for _, name := range []string{"r0c0", "key", "keyboard", "key70000"} {
+			_, ok := KeyCode(name)
+			Ω(ok).Should(BeFalse(), name)
+		}
+	})
+})
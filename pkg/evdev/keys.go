@@ -0,0 +1,74 @@
+package evdev
+
+import (
+	"strconv"
+	"strings"
+)
+
+// keyNames maps Linux input key codes to names of the keys. Names are
+// the lowercase names of the kernel KEY_* constants without the
+// prefix.
+var keyNames = map[uint16]string{
+	1: "esc", 2: "1", 3: "2", 4: "3", 5: "4", 6: "5", 7: "6", 8: "7", 9: "8", 10: "9", 11: "0",
+	12: "minus", 13: "equal", 14: "backspace", 15: "tab",
+	16: "q", 17: "w", 18: "e", 19: "r", 20: "t", 21: "y", 22: "u", 23: "i", 24: "o", 25: "p",
+	26: "leftbrace", 27: "rightbrace", 28: "enter", 29: "leftctrl",
+	30: "a", 31: "s", 32: "d", 33: "f", 34: "g", 35: "h", 36: "j", 37: "k", 38: "l",
+	39: "semicolon", 40: "apostrophe", 41: "grave", 42: "leftshift", 43: "backslash",
+	44: "z", 45: "x", 46: "c", 47: "v", 48: "b", 49: "n", 50: "m",
+	51: "comma", 52: "dot", 53: "slash", 54: "rightshift", 55: "kpasterisk", 56: "leftalt", 57: "space",
+	58: "capslock", 59: "f1", 60: "f2", 61: "f3", 62: "f4", 63: "f5", 64: "f6", 65: "f7", 66: "f8",
+	67: "f9", 68: "f10", 69: "numlock", 70: "scrolllock",
+	71: "kp7", 72: "kp8", 73: "kp9", 74: "kpminus", 75: "kp4", 76: "kp5", 77: "kp6", 78: "kpplus",
+	79: "kp1", 80: "kp2", 81: "kp3", 82: "kp0", 83: "kpdot",
+	85: "zenkakuhankaku", 86: "102nd", 87: "f11", 88: "f12", 89: "ro", 90: "katakana", 91: "hiragana",
+	92: "henkan", 93: "katakanahiragana", 94: "muhenkan", 95: "kpjpcomma",
+	96: "kpenter", 97: "rightctrl", 98: "kpslash", 99: "sysrq", 100: "rightalt", 101: "linefeed",
+	102: "home", 103: "up", 104: "pageup", 105: "left", 106: "right", 107: "end", 108: "down",
+	109: "pagedown", 110: "insert", 111: "delete", 112: "macro", 113: "mute", 114: "volumedown",
+	115: "volumeup", 116: "power", 117: "kpequal", 118: "kpplusminus", 119: "pause", 120: "scale",
+	121: "kpcomma", 122: "hangeul", 123: "hanja", 124: "yen", 125: "leftmeta", 126: "rightmeta",
+	127: "compose",
+	128: "stop", 129: "again", 130: "props", 131: "undo", 132: "front", 133: "copy", 134: "open",
+	135: "paste", 136: "find", 137: "cut", 138: "help", 139: "menu", 140: "calc", 142: "sleep",
+	143: "wakeup", 148: "prog1", 149: "prog2", 150: "www", 152: "screenlock", 155: "mail",
+	156: "bookmarks", 157: "computer", 158: "back", 159: "forward", 163: "nextsong",
+	164: "playpause", 165: "previoussong", 166: "stopcd", 171: "config", 172: "homepage",
+	173: "refresh", 183: "f13", 184: "f14", 185: "f15", 186: "f16", 187: "f17", 188: "f18",
+	189: "f19", 190: "f20", 191: "f21", 192: "f22", 193: "f23", 194: "f24", 200: "playcd",
+	201: "pausecd", 202: "prog3", 203: "prog4", 210: "print", 212: "camera", 217: "search",
+	224: "brightnessdown", 225: "brightnessup", 227: "switchvideomode", 228: "kbdillumtoggle",
+	229: "kbdillumdown", 230: "kbdillumup", 236: "battery", 237: "bluetooth", 238: "wlan",
+	240: "unknown", 246: "wwan", 247: "rfkill", 248: "micmute",
+	0x1d0: "fn", 0x1d1: "fn_esc", 0x21a: "touchpad_toggle", 0x279: "selective_screenshot",
+}
+
+// KeyName returns name of the key with the given code e.g. "esc" for
+// 1. Keys unknown to KeyName are named "key" followed by their code.
+func KeyName(code uint16) string {
+
+	if name, found := keyNames[code]; found {
+		return name
+	}
+
+	return "key" + strconv.Itoa(int(code))
+}
+
+// KeyCode returns code of the key with the given name as named by
+// KeyName. It returns false if the name is not a name of a key.
+func KeyCode(name string) (uint16, bool) {
+
+	for code, n := range keyNames {
+		if n == name {
+			return code, true
+		}
+	}
+
+	if num, found := strings.CutPrefix(name, "key"); found {
+		if code, err := strconv.ParseUint(num, 10, 16); err == nil {
+			return uint16(code), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,97 @@
+package idle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPeriod error indicates that the period of the day cannot
+// be parsed.
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// day - duration of a day.
+const day = 24 * time.Hour
+
+// timeOfDayLayout - layout of the start and the end of a period.
+const timeOfDayLayout = "15:04"
+
+// Period provides a period of the day, e.g. from 22:00 to 07:00. The
+// period wraps around midnight if its end precedes its start.
+type Period struct {
+	From time.Duration // start since midnight
+	To   time.Duration // end since midnight
+}
+
+// ParsePeriod parses period of the day given as "HH:MM-HH:MM".
+func ParsePeriod(s string) (Period, error) {
+
+	from, to, found := strings.Cut(s, "-")
+	if !found {
+		return Period{}, fmt.Errorf("%w %q; expected \"HH:MM-HH:MM\"", ErrInvalidPeriod, s)
+	}
+
+	var p Period
+	var err error
+	if p.From, err = parseTimeOfDay(from); err != nil {
+		return Period{}, fmt.Errorf("%w %q: %w", ErrInvalidPeriod, s, err)
+	}
+	if p.To, err = parseTimeOfDay(to); err != nil {
+		return Period{}, fmt.Errorf("%w %q: %w", ErrInvalidPeriod, s, err)
+	}
+
+	if p.From == p.To {
+		return Period{}, fmt.Errorf("%w %q: period is empty", ErrInvalidPeriod, s)
+	}
+
+	return p, nil
+}
+
+// parseTimeOfDay parses time of the day given as "HH:MM" and returns
+// its duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+
+	t, err := time.Parse(timeOfDayLayout, strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+
+	return sinceMidnight(t), nil
+}
+
+// sinceMidnight returns duration of the given time since its midnight.
+func sinceMidnight(t time.Time) time.Duration {
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// Contains reports whether the given time is within the period.
+func (p Period) Contains(t time.Time) bool {
+
+	d := sinceMidnight(t)
+	if p.From < p.To {
+		return d >= p.From && d < p.To
+	}
+
+	return d >= p.From || d < p.To
+}
+
+// Remaining returns duration from the given time till the end of the
+// period; it's 0 if the time is not within the period.
+func (p Period) Remaining(t time.Time) time.Duration {
+
+	if !p.Contains(t) {
+		return 0
+	}
+
+	return (p.To - sinceMidnight(t) + day) % day
+}
+
+// String returns the period as "HH:MM-HH:MM".
+func (p Period) String() string {
+
+	midnight := time.Time{}
+	return midnight.Add(p.From).Format(timeOfDayLayout) + "-" + midnight.Add(p.To).Format(timeOfDayLayout)
+}
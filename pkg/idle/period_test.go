@@ -0,0 +1,51 @@
+package idle
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Period", func() {
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 5, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	DescribeTable("parses valid periods",
+		func(s string, expected Period) {
+			p, err := ParsePeriod(s)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p).Should(Equal(expected))
+			Ω(p.String()).Should(Equal(s))
+		},
+		Entry("within the day", "09:30-17:00", Period{From: 9*time.Hour + 30*time.Minute, To: 17 * time.Hour}),
+		Entry("over midnight", "22:00-07:00", Period{From: 22 * time.Hour, To: 7 * time.Hour}),
+	)
+
+	DescribeTable("fails on invalid periods",
+		func(s string) {
+			_, err := ParsePeriod(s)
+			Ω(err).Should(MatchError(ErrInvalidPeriod))
+		},
+		Entry("no end", "22:00"),
+		Entry("invalid time", "22:00-25:00"),
+		Entry("empty", "08:00-08:00"),
+	)
+
+	DescribeTable("contains the times within it",
+		func(s string, t time.Time, contains bool, remaining time.Duration) {
+			p, err := ParsePeriod(s)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p.Contains(t)).Should(Equal(contains))
+			Ω(p.Remaining(t)).Should(Equal(remaining))
+		},
+		Entry("start", "09:00-17:00", at(9, 0), true, 8*time.Hour),
+		Entry("end", "09:00-17:00", at(17, 0), false, time.Duration(0)),
+		Entry("before", "09:00-17:00", at(8, 59), false, time.Duration(0)),
+		Entry("before midnight", "22:00-07:00", at(23, 30), true, 7*time.Hour+30*time.Minute),
+		Entry("after midnight", "22:00-07:00", at(6, 0), true, time.Hour),
+		Entry("outside of wrapped", "22:00-07:00", at(12, 0), false, time.Duration(0)),
+	)
+})
@@ -0,0 +1,147 @@
+package idle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/v4n6/itectl/pkg/evdev"
+)
+
+// Policy runs an action after a period without key presses and
+// reverts it on the next key press.
+type Policy struct {
+
+	// Timeout is the period without key presses after which the
+	// keyboard is idle.
+	Timeout time.Duration
+
+	// Excluded are the periods of the day when the keyboard does not
+	// become idle.
+	Excluded []Period
+
+	// Idle is called when the keyboard becomes idle. Its ctx is done
+	// on the next key press; Idle may return before, e.g. after
+	// dimming the keyboard.
+	Idle func(ctx context.Context) error
+
+	// Wake is called after Idle returned to revert its action on the
+	// next key press or when the policy stops.
+	Wake func() error
+
+	// Now, if set, returns the current time the excluded periods are
+	// checked against. time.Now is used if it's nil.
+	Now func() time.Time
+}
+
+// Run applies the policy to the key presses read from src till ctx is
+// done or reading fails. The action of the idle keyboard is reverted
+// before Run returns.
+func (p *Policy) Run(ctx context.Context, src evdev.EventSource) error {
+
+	if p.Timeout <= 0 {
+		return fmt.Errorf("idle timeout %s must be positive", p.Timeout)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys, errs := evdev.KeyPresses(ctx, src)
+
+	var idle bool
+	var idleDone chan error // receives result of Idle while it runs
+	cancelIdle := func() {}
+
+	// wake reverts the action of the idle keyboard.
+	wake := func() error {
+		if !idle {
+			return nil
+		}
+		idle = false
+
+		cancelIdle()
+		var err error
+		if idleDone != nil {
+			err = <-idleDone
+			idleDone = nil
+		}
+		if errors.Is(err, context.Canceled) {
+			err = nil // stopped by the key press
+		}
+
+		return errors.Join(err, p.Wake())
+	}
+
+	lastPress := time.Now()
+	for {
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		if !idle {
+			wait := time.Until(lastPress.Add(p.Timeout))
+			if wait <= 0 {
+				wait = p.excluded()
+			}
+
+			if wait <= 0 {
+				idle = true
+				idleCtx, stop := context.WithCancel(ctx)
+				cancelIdle = stop
+				idleDone = make(chan error, 1)
+				go func() { idleDone <- p.Idle(idleCtx) }()
+				continue
+			}
+
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+
+		select {
+		case <-timeout:
+
+		case <-ctx.Done():
+			return wake()
+
+		case err := <-idleDone:
+			idleDone = nil
+			if err != nil && ctx.Err() == nil {
+				return errors.Join(err, wake())
+			}
+
+		case _, ok := <-keys:
+			if !ok {
+				if err := <-errs; ctx.Err() == nil {
+					return errors.Join(fmt.Errorf("reading key events: %w", err), wake())
+				}
+				return wake() // stopped on purpose
+			}
+
+			lastPress = time.Now()
+			if err := wake(); err != nil {
+				return err
+			}
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// excluded returns duration till the end of the excluded period
+// containing the current time; it's 0 if there is none.
+func (p *Policy) excluded() time.Duration {
+
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+
+	t := now()
+	var remaining time.Duration
+	for _, period := range p.Excluded {
+		remaining = max(remaining, period.Remaining(t))
+	}
+
+	return remaining
+}
@@ -0,0 +1,160 @@
+package idle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/v4n6/itectl/pkg/evdev"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// sourceT type provides event source of the events sent to its channel.
+type sourceT struct {
+	events chan evdev.Event
+	once   sync.Once
+	closed chan struct{}
+}
+
+func newSource() *sourceT {
+	return &sourceT{events: make(chan evdev.Event), closed: make(chan struct{})}
+}
+
+func (s *sourceT) ReadEvent() (evdev.Event, error) {
+
+	select {
+	case ev := <-s.events:
+		return ev, nil
+	case <-s.closed:
+		return evdev.Event{}, io.EOF
+	}
+}
+
+func (s *sourceT) Close() error {
+
+	s.once.Do(func() { close(s.closed) })
+
+	return nil
+}
+
+// press sends press of a key.
+func (s *sourceT) press() {
+	s.events <- evdev.Event{Type: evdev.EvKey, Code: 30, Value: evdev.KeyPress}
+}
+
+var _ = Describe("Policy", func() {
+
+	var src *sourceT
+	var policy *Policy
+	var calls chan string
+	var cancel context.CancelFunc
+	var done chan error
+
+	run := func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan error, 1)
+		go func() { done <- policy.Run(ctx, src) }()
+		DeferCleanup(src.Close)
+	}
+
+	BeforeEach(func() {
+		src = newSource()
+		calls = make(chan string, 10)
+		policy = &Policy{
+			Timeout: 50 * time.Millisecond,
+			Idle: func(context.Context) error {
+				calls <- "idle"
+				return nil
+			},
+			Wake: func() error {
+				calls <- "wake"
+				return nil
+			},
+		}
+	})
+
+	It("runs the action after the timeout and reverts it on key press", func() {
+		run()
+
+		Eventually(calls).Should(Receive(Equal("idle")))
+		Consistently(calls, 100*time.Millisecond).ShouldNot(Receive())
+
+		src.press()
+		Eventually(calls).Should(Receive(Equal("wake")))
+		Eventually(calls).Should(Receive(Equal("idle")))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Ω(calls).Should(Receive(Equal("wake")))
+	})
+
+	It("postpones the action while keys are pressed", func() {
+		run()
+
+		for range 5 {
+			src.press()
+			time.Sleep(20 * time.Millisecond)
+		}
+		Ω(calls).ShouldNot(Receive())
+
+		Eventually(calls).Should(Receive(Equal("idle")))
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("stops running action on key press", func() {
+		policy.Idle = func(ctx context.Context) error {
+			calls <- "idle"
+			<-ctx.Done()
+			calls <- "stopped"
+			return ctx.Err()
+		}
+		run()
+
+		Eventually(calls).Should(Receive(Equal("idle")))
+		src.press()
+		Eventually(calls).Should(Receive(Equal("stopped")))
+		Ω(calls).Should(Receive(Equal("wake")))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("does not run the action in the excluded periods", func() {
+		policy.Excluded = []Period{{From: 22 * time.Hour, To: 7 * time.Hour}}
+		policy.Now = func() time.Time { return time.Date(2024, 5, 1, 23, 0, 0, 0, time.Local) }
+		run()
+
+		Consistently(calls, 200*time.Millisecond).ShouldNot(Receive())
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		Ω(calls).ShouldNot(Receive())
+	})
+
+	It("returns errors of the action", func() {
+		policy.Idle = func(context.Context) error { return errors.New("failed") }
+		run()
+
+		Eventually(done).Should(Receive(MatchError("failed")))
+		cancel()
+	})
+
+	It("fails on reading error", func() {
+		run()
+		Ω(src.Close()).Should(Succeed())
+
+		Eventually(done).Should(Receive(MatchError(io.EOF)))
+		cancel()
+	})
+
+	It("fails on non-positive timeout", func() {
+		policy.Timeout = 0
+		Ω(policy.Run(context.Background(), src)).Should(MatchError(ContainSubstring("must be positive")))
+	})
+})
@@ -0,0 +1,102 @@
+package ite8291
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// boundaries and default value of animation frame rate.
+const (
+	// FPSMinValue - minimum number of animation frames per second.
+	FPSMinValue = 1
+	// FPSMaxValue - maximum number of animation frames per second.
+	FPSMaxValue = 60
+	// FPSDefault - default number of animation frames per second.
+	FPSDefault = 30
+)
+
+// Animation interface abstracts a software effect rendered frame by
+// frame.
+type Animation interface {
+
+	// Render renders the animation at the given time elapsed since its
+	// start into frame. frame holds the previously rendered frame.
+	// Render returns false once the animation is over; the frame
+	// rendered last is shown afterwards.
+	Render(frame *Frame, elapsed time.Duration) bool
+}
+
+// AnimationFunc type is an adapter to use ordinary functions as
+// animations.
+type AnimationFunc func(frame *Frame, elapsed time.Duration) bool
+
+// Render calls f(frame, elapsed).
+func (f AnimationFunc) Render(frame *Frame, elapsed time.Duration) bool {
+	return f(frame, elapsed)
+}
+
+// Animate renders the animation fps times a second and writes the
+// rendered frames to w. Frames equal to the frame written last are
+// skipped. If writing takes longer than a frame, frames are dropped
+// to keep up with the time. fps is clipped to [FPSMinValue,
+// FPSMaxValue]. Animate returns nil once the animation is over or
+// ctx is done.
+func Animate(ctx context.Context, w FrameWriter, a Animation, fps int) error {
+
+	fps = max(FPSMinValue, min(FPSMaxValue, fps))
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+
+	var frame, shown Frame
+	written := false
+	start := time.Now()
+
+	for {
+		more := a.Render(&frame, time.Since(start))
+
+		if !written || frame != shown {
+			out := frame
+			if err := w.WriteFrame(&out); err != nil {
+				return err
+			}
+			shown, written = frame, true
+		}
+
+		if !more {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// WithUserMode sets the keyboard backlight to 'user' effect with the
+// given brightness, calls f and restores the previous effect
+// afterwards, even if f fails. If the previous effect is 'user' effect,
+// its frame is restored too if it is known (see LastFrame); otherwise
+// the keys keep the colors written by f. The 'user' effect is not
+// saved.
+func (c *Controller) WithUserMode(brightness byte, f func() error) error {
+
+	last := c.LastFrame()
+	state, err := c.Effect()
+	if err != nil {
+		return err
+	}
+
+	if err := c.SetUserMode(brightness, false); err != nil {
+		return err
+	}
+
+	err = f()
+	if last != nil && state.Control != OffState && state.Effect == UserEffect {
+		err = errors.Join(err, c.WriteFrame(last))
+	}
+
+	return errors.Join(err, c.RestoreEffect(state))
+}
@@ -0,0 +1,212 @@
+package ite8291
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Animate", func() {
+
+	var w *frameWriterStub
+	red, green := NewColor(0xff, 0, 0), NewColor(0, 0xff, 0)
+
+	// steps returns animation showing the given colors one per frame
+	steps := func(colors ...*Color) Animation {
+		n := 0
+		return AnimationFunc(func(frame *Frame, _ time.Duration) bool {
+			frame.Fill(colors[n])
+			n++
+			return n < len(colors)
+		})
+	}
+
+	BeforeEach(func() {
+		w = &frameWriterStub{}
+	})
+
+	It("writes rendered frames skipping repeated ones until animation is over", func() {
+		Ω(Animate(context.Background(), w, steps(red, red, green, red), FPSMaxValue)).Should(Succeed())
+		Ω(w.frames).Should(Equal([]*Frame{NewFrame(red), NewFrame(green), NewFrame(red)}))
+	})
+
+	It("renders frames with the given frame rate until context is done", func() {
+
+		var times []time.Duration
+		anim := AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+			times = append(times, elapsed)
+			return true
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+		defer cancel()
+
+		Ω(Animate(ctx, w, anim, 20)).Should(Succeed())
+		Ω(len(times)).Should(BeNumerically("~", 6, 1))
+		Ω(times[0]).Should(BeNumerically("<", 10*time.Millisecond))
+		Ω(times[len(times)-1]).Should(BeNumerically("~", 250*time.Millisecond, 60*time.Millisecond))
+		Ω(w.frames).Should(HaveLen(1))
+	})
+
+	It("stops on write error", func() {
+		w.err = errors.New("write error") //nolint:err113
+		Ω(Animate(context.Background(), w, steps(red, green), FPSMaxValue)).Should(MatchError(w.err))
+		Ω(w.frames).Should(HaveLen(1))
+	})
+
+	It("runs in 'user' effect and restores the previous effect", func() {
+
+		dev := NewVirtualDevice()
+		ctl := NewController(dev)
+		Ω(ctl.SetWaveMode(3, 40, DirectionUp, false)).Should(Succeed())
+
+		err := ctl.WithUserMode(10, func() error {
+			Ω(dev.Effect()).Should(Equal(&EffectState{Control: SetEffectOp, Effect: UserEffect, Brightness: 10}))
+			return Animate(context.Background(), ctl, steps(red), FPSDefault)
+		})
+
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(dev.Frame()).Should(Equal(NewFrame(red)))
+		Ω(dev.Effect()).Should(Equal(&EffectState{Control: SetEffectOp, Effect: WaveEffect, Speed: 3,
+			Brightness: 40, ReactOrDiv: byte(DirectionUp)}))
+	})
+
+	It("restores the previous effect if running fails", func() {
+
+		dev := NewVirtualDevice()
+		ctl := NewController(dev)
+		Ω(ctl.SetOffMode()).Should(Succeed())
+
+		failed := errors.New("failed") //nolint:err113
+		Ω(ctl.WithUserMode(10, func() error { return failed })).Should(MatchError(failed))
+		Ω(ctl.State()).Should(BeFalse())
+	})
+
+	It("restores the known frame of the previous 'user' effect", func() {
+
+		dev := NewVirtualDevice()
+		ctl := NewController(dev)
+		shown := NewFrame(NewColor(1, 2, 3))
+		Ω(ctl.SetFrameMode(20, shown, false)).Should(Succeed())
+
+		Ω(ctl.WithUserMode(10, func() error {
+			return Animate(context.Background(), ctl, steps(red), FPSDefault)
+		})).Should(Succeed())
+
+		Ω(dev.Frame()).Should(Equal(shown))
+		Ω(dev.Effect()).Should(Equal(&EffectState{Control: SetEffectOp, Effect: UserEffect, Brightness: 20}))
+	})
+
+	It("keeps the frame written last if the previous frame is not known", func() {
+
+		dev := NewVirtualDevice()
+		Ω(NewController(dev).SetFrameMode(20, NewFrame(NewColor(1, 2, 3)), false)).Should(Succeed())
+		ctl := NewController(dev)
+
+		Ω(ctl.WithUserMode(10, func() error {
+			return Animate(context.Background(), ctl, steps(red), FPSDefault)
+		})).Should(Succeed())
+
+		Ω(dev.Frame()).Should(Equal(NewFrame(red)))
+	})
+})
+
+var _ = Describe("Animations", func() {
+
+	black := Frame{}
+
+	// render renders the named animation at the given times.
+	render := func(name string, opts AnimationOptions, times ...time.Duration) []Frame {
+		GinkgoHelper()
+
+		opts.Rand = rand.New(rand.NewPCG(1, 2)) //nolint:gosec
+		anim, err := NewAnimation(name, opts)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		var frames []Frame
+		var frame Frame
+		for _, t := range times {
+			Ω(anim.Render(&frame, t)).Should(BeTrue())
+			frames = append(frames, frame)
+		}
+
+		return frames
+	}
+
+	It("are listed by name", func() {
+		Ω(AnimationNames()).Should(Equal([]string{"breathing", "fire", "matrix", "spectrum", "starfield", "wave"}))
+	})
+
+	It("fails on unknown name", func() {
+		Ω(NewAnimation("disco", AnimationOptions{})).Error().Should(MatchError(ErrUnknownAnimation))
+	})
+
+	DescribeTable("change over time",
+		func(name string) {
+			frames := render(name, AnimationOptions{Period: time.Second},
+				0, 100*time.Millisecond, 300*time.Millisecond, 700*time.Millisecond)
+
+			Ω(frames).Should(ContainElement(Not(Equal(black))))
+			Ω(frames[1]).ShouldNot(Equal(frames[2]))
+			Ω(frames[2]).ShouldNot(Equal(frames[3]))
+		},
+		Entry(nil, "spectrum"),
+		Entry(nil, "wave"),
+		Entry(nil, "breathing"),
+		Entry(nil, "starfield"),
+		Entry(nil, "fire"),
+		Entry(nil, "matrix"),
+	)
+
+	It("cycle spectrum of the given colors", func() {
+		blue := NewColor(0, 0, 0xff)
+		frames := render("spectrum", AnimationOptions{Colors: []*Color{NewColor(0xff, 0, 0), blue}, Period: time.Second},
+			0, 500*time.Millisecond, time.Second)
+
+		Ω(frames[0]).Should(Equal(*NewFrame(NewColor(0xff, 0, 0))))
+		Ω(frames[1]).Should(Equal(*NewFrame(blue)))
+		Ω(frames[2]).Should(Equal(frames[0]))
+	})
+
+	It("move wave over the keys of the layout", func() {
+		layout := &Layout{Keys: []Key{
+			{Name: "a", Row: 1, Column: 1, X: 0, Y: 0, Width: 1, Height: 1},
+			{Name: "b", Row: 1, Column: 2, X: 1, Y: 0, Width: 1, Height: 1},
+		}}
+		frames := render("wave", AnimationOptions{Layout: layout, Period: time.Second}, 0, 500*time.Millisecond)
+
+		Ω(frames[0][0][0]).Should(Equal(Color{}))
+		Ω(frames[0][1][1]).ShouldNot(Equal(frames[0][1][2]))
+		Ω(frames[1][1][2]).Should(Equal(frames[0][1][1])) // the wave moved by a half of the layout width
+	})
+
+	It("burn hotter at the bottom of the keyboard", func() {
+		frame := render("fire", AnimationOptions{Colors: []*Color{NewColor(0, 0, 0), NewColor(0xff, 0xff, 0xff)},
+			Period: time.Second}, 2*time.Second)[0]
+
+		sum := func(row int) (s int) {
+			for _, c := range frame[row] {
+				s += int(c.Red)
+			}
+			return s
+		}
+		Ω(sum(RowsNumber - 1)).Should(BeNumerically(">", sum(0)))
+	})
+
+	It("drop rain of the given color", func() {
+		red := NewColor(0xff, 0, 0)
+		for _, frame := range render("matrix", AnimationOptions{Colors: []*Color{red}},
+			0, time.Second, 2*time.Second) {
+			for _, row := range frame {
+				for _, c := range row {
+					Ω(c.Red).Should(BeNumerically(">=", c.Green))
+					Ω(c.Red).Should(BeNumerically(">=", c.Blue))
+				}
+			}
+		}
+	})
+})
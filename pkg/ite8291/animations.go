@@ -0,0 +1,353 @@
+package ite8291
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// ErrUnknownAnimation error indicates that a software effect with the
+// given name does not exist.
+var ErrUnknownAnimation = errors.New("unknown animation")
+
+// AnimationOptions provides parameters of the software effects.
+type AnimationOptions struct {
+
+	// Colors used by the effect. Effects use their own colors if none
+	// are given.
+	Colors []*Color
+
+	// Period is duration of one cycle of the effect. It defaults to 5
+	// seconds.
+	Period time.Duration
+
+	// Layout provides positions of the keys. It defaults to
+	// DefaultLayout.
+	Layout *Layout
+
+	// Rand is the source of randomness of the effect. It defaults to
+	// a randomly seeded one.
+	Rand *rand.Rand
+}
+
+// animationPeriodDefault - default duration of one cycle of the software effects.
+const animationPeriodDefault = 5 * time.Second
+
+// animations maps names of the software effects to their constructors.
+var animations = map[string]func(o *AnimationOptions) Animation{
+	"spectrum":  spectrumAnimation,
+	"wave":      waveAnimation,
+	"breathing": breathingAnimation,
+	"starfield": starfieldAnimation,
+	"fire":      fireAnimation,
+	"matrix":    matrixAnimation,
+}
+
+// AnimationNames returns sorted names of the software effects.
+func AnimationNames() []string {
+
+	names := make([]string, 0, len(animations))
+	for name := range animations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// NewAnimation creates the software effect with the given name. It
+// returns ErrUnknownAnimation if there is no such effect. Options
+// missing from opts take their default values.
+//
+// The following effects are supported:
+//   - spectrum - all keys cycle through the colors;
+//   - wave - the colors flow over the keys from the left to the right;
+//   - breathing - every key breathes in its own color and rhythm;
+//   - starfield - stars of the colors twinkle at random keys;
+//   - fire - flames of the colors rise from the bottom row;
+//   - matrix - drops of the color fall down the columns.
+func NewAnimation(name string, opts AnimationOptions) (Animation, error) {
+
+	create, found := animations[name]
+	if !found {
+		return nil, fmt.Errorf("%w %q; expected one of %q", ErrUnknownAnimation, name, AnimationNames())
+	}
+
+	if opts.Period <= 0 {
+		opts.Period = animationPeriodDefault
+	}
+	if opts.Layout == nil {
+		opts.Layout = DefaultLayout()
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) //nolint:gosec
+	}
+
+	return create(&opts), nil
+}
+
+// phase returns position of elapsed time within the period in [0, 1).
+func phase(elapsed, period time.Duration) float64 {
+	return float64(elapsed%period) / float64(period)
+}
+
+// dim returns color with its light scaled by level in [0, 1].
+func dim(c *Color, level float64) Color {
+
+	level = max(0, min(1, level))
+
+	return Color{
+		Red:   linearToSrgb(srgbToLinear(c.Red) * level),
+		Green: linearToSrgb(srgbToLinear(c.Green) * level),
+		Blue:  linearToSrgb(srgbToLinear(c.Blue) * level),
+	}
+}
+
+// cycle returns function providing color at the given position in
+// [0, 1) of the cycle through the given colors. The cycle runs
+// through all hues if no colors are given.
+func cycle(colors []*Color) func(pos float64) *Color {
+
+	switch len(colors) {
+	case 0:
+		return func(pos float64) *Color { return HSV{H: 360 * pos, S: 1, V: 1}.Color() }
+	case 1:
+		return func(float64) *Color { return colors[0] }
+	}
+
+	g := append(Gradient(slices.Clone(colors)), colors[0])
+
+	return func(pos float64) *Color { return g.At(pos - math.Floor(pos)) }
+}
+
+// pick returns function providing randomly chosen color of the given
+// colors or, if there are none, a random fully saturated one.
+func pick(o *AnimationOptions) func() *Color {
+
+	if len(o.Colors) == 0 {
+		return func() *Color { return HSV{H: 360 * o.Rand.Float64(), S: 1, V: 1}.Color() }
+	}
+
+	return func() *Color { return o.Colors[o.Rand.IntN(len(o.Colors))] }
+}
+
+// spectrumAnimation returns effect setting all keys to the same color
+// cycling through the colors once per period.
+func spectrumAnimation(o *AnimationOptions) Animation {
+
+	color := cycle(o.Colors)
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+		frame.Fill(color(phase(elapsed, o.Period)))
+		return true
+	})
+}
+
+// waveAnimation returns effect moving the cycle of the colors from the
+// left to the right edge of the layout once per period. Cells without
+// a key are black.
+func waveAnimation(o *AnimationOptions) Animation {
+
+	color := cycle(o.Colors)
+	w, _ := o.Layout.Bounds()
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+
+		*frame = Frame{}
+		p := phase(elapsed, o.Period)
+		for i := range o.Layout.Keys {
+			x, _ := o.Layout.Keys[i].Center()
+			frame.Set(o.Layout.Keys[i].Cell(), color(1+x/w-p))
+		}
+
+		return true
+	})
+}
+
+// breathingAnimation returns effect fading every key in and out once
+// per period. Keys get random colors of the colors and random phases.
+func breathingAnimation(o *AnimationOptions) Animation {
+
+	var colors [RowsNumber][ColumnsNumber]*Color
+	var phases [RowsNumber][ColumnsNumber]float64
+
+	next := pick(o)
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			colors[i][j], phases[i][j] = next(), o.Rand.Float64()
+		}
+	}
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+
+		p := phase(elapsed, o.Period)
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				frame[i][j] = dim(colors[i][j], (1-math.Cos(2*math.Pi*(p+phases[i][j])))/2)
+			}
+		}
+
+		return true
+	})
+}
+
+// starLife - part of the cycle of a key the star is lit.
+const starLife = 0.2
+
+// starfieldAnimation returns effect lighting stars at random keys.
+// Every key lights a star of a random color of the colors once per
+// its own cycle lasting from one to four periods.
+func starfieldAnimation(o *AnimationOptions) Animation {
+
+	var colors [RowsNumber][ColumnsNumber]*Color
+	var cycles [RowsNumber][ColumnsNumber]time.Duration
+	var offsets [RowsNumber][ColumnsNumber]time.Duration
+
+	next := pick(o)
+	if len(o.Colors) == 0 {
+		white := NewColor(0xff, 0xff, 0xff)
+		next = func() *Color { return white }
+	}
+
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			colors[i][j] = next()
+			cycles[i][j] = time.Duration(float64(o.Period) * (1 + 3*o.Rand.Float64()))
+			offsets[i][j] = time.Duration(float64(cycles[i][j]) * o.Rand.Float64())
+		}
+	}
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				level := 0.0
+				if p := phase(elapsed+offsets[i][j], cycles[i][j]); p < starLife {
+					level = math.Sin(math.Pi * p / starLife)
+				}
+				frame[i][j] = dim(colors[i][j], level)
+			}
+		}
+
+		return true
+	})
+}
+
+// fire parameters.
+const (
+	// fireSteps - number of fire simulation steps per period.
+	fireSteps = 50
+	// fireCooling - maximum part of heat lost by rising flames per step.
+	fireCooling = 0.35
+)
+
+// firePalette - default colors of the flames from the coldest to the hottest one.
+var firePalette = Gradient{
+	NewColor(0, 0, 0), NewColor(0x80, 0, 0), NewColor(0xff, 0x30, 0), NewColor(0xff, 0xa0, 0),
+	NewColor(0xff, 0xff, 0x80),
+}
+
+// fireAnimation returns effect of flames rising from the bottom row of
+// the keyboard matrix. Heat of the flames is shown by the gradient of
+// the colors from the coldest to the hottest one; a single color is
+// shown fading from black.
+func fireAnimation(o *AnimationOptions) Animation {
+
+	palette := firePalette
+	switch len(o.Colors) {
+	case 0:
+	case 1:
+		palette = Gradient{NewColor(0, 0, 0), o.Colors[0]}
+	default:
+		palette = Gradient(o.Colors)
+	}
+
+	// heat of the cells; the extra bottom row is the source of the flames
+	var heat [RowsNumber + 1][ColumnsNumber]float64
+	steps := 0
+	stepDuration := o.Period / fireSteps
+
+	step := func() {
+		for j := range ColumnsNumber {
+			heat[RowsNumber][j] = 0.6 + 0.4*o.Rand.Float64()
+		}
+
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				sum, n := 0.0, 0.0
+				for k := max(0, j-1); k <= min(ColumnsNumber-1, j+1); k++ {
+					sum, n = sum+heat[i+1][k], n+1
+				}
+				heat[i][j] = sum / n * (1 - fireCooling*o.Rand.Float64())
+			}
+		}
+	}
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+
+		// catch up with the time, but don't simulate a long pause
+		target := int(elapsed / stepDuration)
+		for steps = max(steps, target-2*RowsNumber); steps < target; steps++ {
+			step()
+		}
+
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				frame[i][j] = *palette.At(heat[i][j])
+			}
+		}
+
+		return true
+	})
+}
+
+// matrix rain parameters.
+const (
+	// matrixTrail - length of the trail of a drop in keys.
+	matrixTrail = 3
+	// matrixGap - maximum number of keys between consecutive drops.
+	matrixGap = 6
+)
+
+// matrixAnimation returns effect of drops falling down the columns of
+// the keyboard matrix with fading trails. A drop passes the keyboard
+// rows within a half to a whole period. The drops have the first of
+// the colors or green.
+func matrixAnimation(o *AnimationOptions) Animation {
+
+	color := NewColor(0, 0xff, 0x41)
+	if len(o.Colors) > 0 {
+		color = o.Colors[0]
+	}
+	head := *color.Mix(NewColor(0xff, 0xff, 0xff), 0.6)
+
+	var speeds, offsets, lengths [ColumnsNumber]float64
+	for j := range ColumnsNumber {
+		speeds[j] = 1 + o.Rand.Float64()
+		lengths[j] = RowsNumber + matrixTrail + float64(o.Rand.IntN(matrixGap+1))
+		offsets[j] = o.Rand.Float64() * lengths[j]
+	}
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+
+		t := float64(elapsed) / float64(o.Period) * RowsNumber
+		for j := range ColumnsNumber {
+			pos := math.Mod(t*speeds[j]+offsets[j], lengths[j])
+			for i := range RowsNumber {
+				switch d := pos - float64(i); {
+				case d >= 0 && d < 1:
+					frame[i][j] = head
+				case d >= 1 && d < matrixTrail+1:
+					frame[i][j] = dim(color, 1-(d-1)/matrixTrail)
+				default:
+					frame[i][j] = Color{}
+				}
+			}
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,47 @@
+package ite8291
+
+// BarsFrame returns frame of vertical bars rising from the bottom row
+// of the keyboard matrix. levels in [0, 1] give heights of the bars
+// of the columns from the left; the columns without levels are black.
+// The bars are colored by ramp from the bottom to the top row. The top
+// key of a bar is dimmed in proportion to its part covered by the bar.
+func BarsFrame(levels []float64, ramp Gradient) *Frame {
+
+	frame := &Frame{}
+	for j := range min(len(levels), ColumnsNumber) {
+		height := levels[j] * RowsNumber
+		for k := range RowsNumber { // rows from the bottom
+			if fill := height - float64(k); fill > 0 {
+				frame[RowsNumber-1-k][j] = dim(ramp.At(float64(k)/(RowsNumber-1)), fill)
+			}
+		}
+	}
+
+	return frame
+}
+
+// MeterFrame returns frame of horizontal bars growing from the left
+// column of the keyboard matrix. levels in [0, 1] give widths of the
+// bars; the rows of the keyboard matrix are split evenly between the
+// bars from the top. The bars are colored by ramp from the left to the
+// right column. The last key of a bar is dimmed in proportion to its
+// part covered by the bar.
+func MeterFrame(levels []float64, ramp Gradient) *Frame {
+
+	frame := &Frame{}
+	if len(levels) == 0 {
+		return frame
+	}
+
+	n := min(len(levels), RowsNumber)
+	for i := range RowsNumber {
+		width := levels[i*n/RowsNumber] * ColumnsNumber
+		for j := range ColumnsNumber {
+			if fill := width - float64(j); fill > 0 {
+				frame[i][j] = dim(ramp.At(float64(j)/(ColumnsNumber-1)), fill)
+			}
+		}
+	}
+
+	return frame
+}
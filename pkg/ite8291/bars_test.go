@@ -0,0 +1,53 @@
+package ite8291
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bars", func() {
+
+	black, red, green := NewColor(0, 0, 0), NewColor(0xff, 0, 0), NewColor(0, 0xff, 0)
+	ramp := Gradient{green, red}
+
+	It("renders vertical bars", func() {
+
+		frame := BarsFrame([]float64{1, 0.5, 0.25, 0}, ramp)
+
+		Ω(frame.Get(Cell{Row: 0, Column: 0})).Should(Equal(red))
+		Ω(frame.Get(Cell{Row: RowsNumber - 1, Column: 0})).Should(Equal(green))
+
+		Ω(frame.Get(Cell{Row: 3, Column: 1})).ShouldNot(Equal(black))
+		Ω(frame.Get(Cell{Row: 2, Column: 1})).Should(Equal(black))
+
+		// 1.5 keys high bar
+		Ω(frame.Get(Cell{Row: 5, Column: 2})).Should(Equal(green))
+		partial := frame.Get(Cell{Row: 4, Column: 2})
+		Ω(partial.Red).Should(BeNumerically(">", 0))
+		Ω(partial).ShouldNot(Equal(ramp.At(0.2)))
+		Ω(frame.Get(Cell{Row: 3, Column: 2})).Should(Equal(black))
+
+		for i := range RowsNumber {
+			Ω(frame.Get(Cell{Row: i, Column: 3})).Should(Equal(black))
+			Ω(frame.Get(Cell{Row: i, Column: ColumnsNumber - 1})).Should(Equal(black))
+		}
+	})
+
+	It("renders horizontal bars", func() {
+
+		frame := MeterFrame([]float64{1, 0.5}, ramp)
+
+		for i := range RowsNumber / 2 {
+			Ω(frame.Get(Cell{Row: i, Column: 0})).Should(Equal(green))
+			Ω(frame.Get(Cell{Row: i, Column: ColumnsNumber - 1})).Should(Equal(red))
+		}
+		for i := RowsNumber / 2; i < RowsNumber; i++ {
+			Ω(frame.Get(Cell{Row: i, Column: 0})).Should(Equal(green))
+			Ω(frame.Get(Cell{Row: i, Column: 10})).ShouldNot(Equal(black))
+			Ω(frame.Get(Cell{Row: i, Column: 11})).Should(Equal(black))
+		}
+
+		Ω(MeterFrame([]float64{0.5}, ramp)).Should(Equal(MeterFrame([]float64{0.5, 0.5}, ramp)))
+		Ω(MeterFrame(nil, ramp)).Should(Equal(&Frame{}))
+	})
+})
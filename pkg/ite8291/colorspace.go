@@ -0,0 +1,143 @@
+package ite8291
+
+import "math"
+
+// Oklab provides color in Oklab perceptual color space.
+type Oklab struct {
+	L float64
+	A float64
+	B float64
+}
+
+// srgbToLinear converts sRGB color component to linear light.
+func srgbToLinear(c uint8) float64 {
+
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSrgb converts linear light color component to sRGB.
+func linearToSrgb(v float64) uint8 {
+
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+
+	return uint8(math.Round(max(0, min(1, v)) * 255))
+}
+
+// Oklab converts color to Oklab color space.
+func (c *Color) Oklab() Oklab {
+
+	r, g, b := srgbToLinear(c.Red), srgbToLinear(c.Green), srgbToLinear(c.Blue)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return Oklab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// Color converts Oklab color to RGB Color. Colors outside of sRGB
+// gamut are clipped.
+func (o Oklab) Color() *Color {
+
+	l := o.L + 0.3963377774*o.A + 0.2158037573*o.B
+	m := o.L - 0.1055613458*o.A - 0.0638541728*o.B
+	s := o.L - 0.0894841775*o.A - 1.2914855480*o.B
+
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return NewColor(
+		linearToSrgb(4.0767416621*l-3.3077115913*m+0.2309699292*s),
+		linearToSrgb(-1.2684380046*l+2.6097574011*m-0.3413193965*s),
+		linearToSrgb(-0.0041960863*l-0.7034186147*m+1.7076147010*s))
+}
+
+// Mix returns color between c and to. t is a position between the
+// colors: 0 is c, 1 is to. The colors are interpolated in Oklab
+// color space.
+func (c *Color) Mix(to *Color, t float64) *Color {
+
+	t = max(0, min(1, t))
+	a, b := c.Oklab(), to.Oklab()
+
+	return Oklab{
+		L: a.L + (b.L-a.L)*t,
+		A: a.A + (b.A-a.A)*t,
+		B: a.B + (b.B-a.B)*t,
+	}.Color()
+}
+
+// HSV provides color in HSV color space. Hue is given in degrees
+// [0, 360), saturation and value in [0, 1].
+type HSV struct {
+	H float64
+	S float64
+	V float64
+}
+
+// HSV converts color to HSV color space.
+func (c *Color) HSV() HSV {
+
+	r, g, b := float64(c.Red)/255, float64(c.Green)/255, float64(c.Blue)/255
+	hi, lo := max(r, g, b), min(r, g, b)
+	d := hi - lo
+
+	hsv := HSV{V: hi}
+	if hi > 0 {
+		hsv.S = d / hi
+	}
+
+	switch {
+	case d == 0:
+	case hi == r:
+		hsv.H = 60 * math.Mod((g-b)/d+6, 6)
+	case hi == g:
+		hsv.H = 60 * ((b-r)/d + 2)
+	default:
+		hsv.H = 60 * ((r-g)/d + 4)
+	}
+
+	return hsv
+}
+
+// Color converts HSV color to RGB Color. Hue is taken modulo 360,
+// saturation and value are clipped to [0, 1].
+func (h HSV) Color() *Color {
+
+	hue := math.Mod(math.Mod(h.H, 360)+360, 360) / 60
+	s, v := max(0, min(1, h.S)), max(0, min(1, h.V))
+
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(hue, 2)-1))
+
+	var r, g, b float64
+	switch int(hue) {
+	case 0:
+		r, g = c, x
+	case 1:
+		r, g = x, c
+	case 2:
+		g, b = c, x
+	case 3:
+		g, b = x, c
+	case 4:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+
+	m := v - c
+	return NewColor(uint8(math.Round((r+m)*255)), uint8(math.Round((g+m)*255)), uint8(math.Round((b+m)*255)))
+}
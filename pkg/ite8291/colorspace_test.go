@@ -0,0 +1,30 @@
+package ite8291
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HSV", func() {
+
+	DescribeTable("converts colors",
+		func(c *Color, hsv HSV) {
+			Ω(c.HSV().H).Should(BeNumerically("~", hsv.H, 0.5))
+			Ω(c.HSV().S).Should(BeNumerically("~", hsv.S, 0.01))
+			Ω(c.HSV().V).Should(BeNumerically("~", hsv.V, 0.01))
+			Ω(hsv.Color()).Should(Equal(c))
+		},
+		Entry(nil, NewColor(0, 0, 0), HSV{}),
+		Entry(nil, NewColor(0xff, 0xff, 0xff), HSV{V: 1}),
+		Entry(nil, NewColor(0xff, 0, 0), HSV{H: 0, S: 1, V: 1}),
+		Entry(nil, NewColor(0, 0xff, 0), HSV{H: 120, S: 1, V: 1}),
+		Entry(nil, NewColor(0, 0, 0xff), HSV{H: 240, S: 1, V: 1}),
+		Entry(nil, NewColor(0xff, 0, 0xff), HSV{H: 300, S: 1, V: 1}),
+		Entry(nil, NewColor(0x80, 0x40, 0x40), HSV{H: 0, S: 0.5, V: 0.502}),
+	)
+
+	It("normalizes hue and clips saturation and value", func() {
+		Ω(HSV{H: -120, S: 2, V: 1}.Color()).Should(Equal(NewColor(0, 0, 0xff)))
+		Ω(HSV{H: 480, S: 1, V: -1}.Color()).Should(Equal(NewColor(0, 0, 0)))
+	})
+})
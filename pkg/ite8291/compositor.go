@@ -0,0 +1,320 @@
+package ite8291
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownBlendMode error indicates that a blend mode with the
+// given name does not exist.
+var ErrUnknownBlendMode = errors.New("unknown blend mode")
+
+// BlendMode identifies how colors of a layer are combined with the
+// colors of the layers below it.
+type BlendMode string
+
+// blend modes.
+const (
+	// BlendNormal - colors of the layer cover the colors below.
+	BlendNormal BlendMode = "normal"
+	// BlendAdd - colors of the layer are added to the colors below.
+	BlendAdd BlendMode = "add"
+	// BlendMultiply - colors below are multiplied by the colors of
+	// the layer, so the layer darkens them.
+	BlendMultiply BlendMode = "multiply"
+	// BlendScreen - inverted colors below are multiplied by the
+	// inverted colors of the layer, so the layer lightens them.
+	BlendScreen BlendMode = "screen"
+)
+
+// blends maps blend modes to functions combining linear light color
+// component below dst with color component of the layer src.
+var blends = map[BlendMode]func(dst, src float64) float64{
+	BlendNormal:   func(_, src float64) float64 { return src },
+	BlendAdd:      func(dst, src float64) float64 { return min(1, dst+src) },
+	BlendMultiply: func(dst, src float64) float64 { return dst * src },
+	BlendScreen:   func(dst, src float64) float64 { return 1 - (1-dst)*(1-src) },
+}
+
+// BlendModeNames returns sorted names of the blend modes.
+func BlendModeNames() []string {
+
+	names := make([]string, 0, len(blends))
+	for mode := range blends {
+		names = append(names, string(mode))
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// ParseBlendMode returns blend mode with the given case insensitive
+// name. It returns ErrUnknownBlendMode if there is no such mode.
+func ParseBlendMode(name string) (BlendMode, error) {
+
+	mode := BlendMode(strings.ToLower(name))
+	if _, found := blends[mode]; found {
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("%w %q; expected one of %q", ErrUnknownBlendMode, name, BlendModeNames())
+}
+
+// Layer provides colors of the keys with their opacity composed by
+// Compositor. A layer is identified by its name and the name of its
+// source, i.e. of the owner of the layer.
+type Layer struct {
+	Source string
+	Name   string
+
+	// Z is position of the layer in the stack. Layers with higher Z
+	// are above layers with lower one; layers with the same Z are
+	// stacked in the order they are added.
+	Z int
+
+	// Blend is blend mode of the layer. It defaults to BlendNormal.
+	Blend BlendMode
+
+	// Colors of the keys.
+	Colors Frame
+
+	// Alpha is opacity of the keys in [0, 1]; keys with 0 opacity
+	// are transparent.
+	Alpha [RowsNumber][ColumnsNumber]float64
+
+	// Mask, if not nil, restricts the layer to the given cells, e.g.
+	// to the cells of a zone.
+	Mask []Cell
+}
+
+// NewLayer creates transparent layer with the given source and name.
+func NewLayer(source, name string) *Layer {
+	return &Layer{Source: source, Name: name, Blend: BlendNormal}
+}
+
+// Set sets color and opacity of the key identified by the given
+// cell. Cells outside the keyboard matrix are ignored.
+func (l *Layer) Set(cell Cell, color *Color, alpha float64) {
+
+	if cell.Valid() {
+		l.Colors[cell.Row][cell.Column] = *color
+		l.Alpha[cell.Row][cell.Column] = alpha
+	}
+}
+
+// SetCells sets color and opacity of all keys identified by the given
+// cells.
+func (l *Layer) SetCells(cells []Cell, color *Color, alpha float64) {
+
+	for _, cell := range cells {
+		l.Set(cell, color, alpha)
+	}
+}
+
+// Fill sets all keys of the layer to the given color and opacity.
+func (l *Layer) Fill(color *Color, alpha float64) {
+	l.SetFrame(NewFrame(color), alpha)
+}
+
+// SetFrame sets keys of the layer to the colors of the given frame
+// and to the given opacity.
+func (l *Layer) SetFrame(frame *Frame, alpha float64) {
+
+	l.Colors = *frame
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			l.Alpha[i][j] = alpha
+		}
+	}
+}
+
+// Compositor composes stack of layers into the frame shown by the
+// keyboard backlight. Layers are composed over black keys. Layers
+// can be added and removed at any time, including from different
+// goroutines. Compositor is an Animation, so the composed frame can
+// be shown live by Animate.
+type Compositor struct {
+	mu     sync.Mutex
+	layers []*Layer
+}
+
+// NewCompositor creates compositor without layers.
+func NewCompositor() *Compositor {
+	return &Compositor{}
+}
+
+// Set adds copy of the given layer to the compositor or replaces the
+// layer with the same source and name keeping its position among the
+// layers with the same Z. It returns ErrUnknownBlendMode if the blend
+// mode of the layer is unknown.
+func (c *Compositor) Set(layer *Layer) error {
+
+	l := *layer
+	l.Mask = slices.Clone(layer.Mask)
+	if len(l.Blend) == 0 {
+		l.Blend = BlendNormal
+	}
+	if _, found := blends[l.Blend]; !found {
+		return fmt.Errorf("%w %q of layer %q of %q; expected one of %q",
+			ErrUnknownBlendMode, l.Blend, l.Name, l.Source, BlendModeNames())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i := c.index(l.Source, l.Name); i >= 0 {
+		c.layers[i] = &l
+	} else {
+		c.layers = append(c.layers, &l)
+	}
+	slices.SortStableFunc(c.layers, func(a, b *Layer) int { return a.Z - b.Z })
+
+	return nil
+}
+
+// Remove removes the layer with the given source and name. It returns
+// false if there is no such layer.
+func (c *Compositor) Remove(source, name string) bool {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.index(source, name)
+	if i < 0 {
+		return false
+	}
+	c.layers = slices.Delete(c.layers, i, i+1)
+
+	return true
+}
+
+// RemoveSource removes all layers of the given source and returns
+// number of the removed layers.
+func (c *Compositor) RemoveSource(source string) int {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.layers)
+	c.layers = slices.DeleteFunc(c.layers, func(l *Layer) bool { return l.Source == source })
+
+	return n - len(c.layers)
+}
+
+// Layers returns copies of the layers from the bottom to the top.
+func (c *Compositor) Layers() []Layer {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	layers := make([]Layer, len(c.layers))
+	for i, l := range c.layers {
+		layers[i] = *l
+		layers[i].Mask = slices.Clone(l.Mask)
+	}
+
+	return layers
+}
+
+// index returns index of the layer with the given source and name or
+// -1 if there is no such layer.
+func (c *Compositor) index(source, name string) int {
+	return slices.IndexFunc(c.layers, func(l *Layer) bool { return l.Source == source && l.Name == name })
+}
+
+// Compose returns frame composed of the layers. Colors are blended in
+// linear light.
+func (c *Compositor) Compose() *Frame {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var light [RowsNumber][ColumnsNumber][3]float64
+
+	for _, l := range c.layers {
+
+		var masked *[RowsNumber][ColumnsNumber]bool
+		if l.Mask != nil {
+			masked = &[RowsNumber][ColumnsNumber]bool{}
+			for _, cell := range l.Mask {
+				if cell.Valid() {
+					masked[cell.Row][cell.Column] = true
+				}
+			}
+		}
+
+		blend := blends[l.Blend]
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+
+				a := max(0, min(1, l.Alpha[i][j]))
+				if a == 0 || (masked != nil && !masked[i][j]) {
+					continue
+				}
+
+				col := &l.Colors[i][j]
+				for k, src := range [3]float64{
+					srgbToLinear(col.Red), srgbToLinear(col.Green), srgbToLinear(col.Blue),
+				} {
+					dst := light[i][j][k]
+					light[i][j][k] = dst + (blend(dst, src)-dst)*a
+				}
+			}
+		}
+	}
+
+	frame := &Frame{}
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			frame[i][j] = Color{
+				Red:   linearToSrgb(light[i][j][0]),
+				Green: linearToSrgb(light[i][j][1]),
+				Blue:  linearToSrgb(light[i][j][2]),
+			}
+		}
+	}
+
+	return frame
+}
+
+// Overlay returns animation rendering anim into the colors of copy of
+// the given layer, setting the layer to the compositor and rendering
+// the frame composed of all layers. The layer is set first when the
+// animation renders its first frame. The keys of the layer are opaque
+// unless they are masked out. The returned animation is over once anim
+// is over. It returns ErrUnknownBlendMode if the blend mode of the
+// layer is unknown.
+func (c *Compositor) Overlay(layer *Layer, anim Animation) (Animation, error) {
+
+	l := *layer
+	l.SetFrame(&l.Colors, 1)
+	if len(l.Blend) == 0 {
+		l.Blend = BlendNormal
+	}
+	if _, found := blends[l.Blend]; !found {
+		return nil, fmt.Errorf("%w %q of layer %q of %q; expected one of %q",
+			ErrUnknownBlendMode, l.Blend, l.Name, l.Source, BlendModeNames())
+	}
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+
+		more := anim.Render(&l.Colors, elapsed)
+		_ = c.Set(&l) // the blend mode is known
+
+		*frame = *c.Compose()
+
+		return more
+	}), nil
+}
+
+// Render renders the composed frame into frame. It never ends.
+func (c *Compositor) Render(frame *Frame, _ time.Duration) bool {
+
+	*frame = *c.Compose()
+
+	return true
+}
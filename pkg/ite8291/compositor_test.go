@@ -0,0 +1,178 @@
+package ite8291
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compositor", func() {
+
+	var c *Compositor
+	black, white := NewColor(0, 0, 0), NewColor(0xff, 0xff, 0xff)
+	red, blue := NewColor(0xff, 0, 0), NewColor(0, 0, 0xff)
+	corner := Cell{Row: 0, Column: 0}
+
+	// layer returns layer of the given source and name filled with
+	// the given color.
+	layer := func(source, name string, color *Color, z int) *Layer {
+		l := NewLayer(source, name)
+		l.Fill(color, 1)
+		l.Z = z
+		return l
+	}
+
+	// names returns source/name of the layers from the bottom to the top.
+	names := func() []string {
+		var names []string
+		for _, l := range c.Layers() {
+			names = append(names, l.Source+"/"+l.Name)
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		c = NewCompositor()
+	})
+
+	It("composes black frame without layers", func() {
+		Ω(c.Compose()).Should(Equal(NewFrame(black)))
+	})
+
+	It("stacks layers by Z and the order they are added", func() {
+
+		Ω(c.Set(layer("s1", "top", blue, 1))).Should(Succeed())
+		Ω(c.Set(layer("s1", "bottom", red, 0))).Should(Succeed())
+		Ω(c.Set(layer("s2", "middle", white, 0))).Should(Succeed())
+
+		Ω(names()).Should(Equal([]string{"s1/bottom", "s2/middle", "s1/top"}))
+		Ω(c.Compose()).Should(Equal(NewFrame(blue)))
+	})
+
+	It("replaces layers keeping their position", func() {
+
+		Ω(c.Set(layer("s", "a", red, 0))).Should(Succeed())
+		Ω(c.Set(layer("s", "b", blue, 0))).Should(Succeed())
+		Ω(c.Set(layer("s", "a", white, 0))).Should(Succeed())
+
+		Ω(names()).Should(Equal([]string{"s/a", "s/b"}))
+		Ω(c.Compose()).Should(Equal(NewFrame(blue)))
+	})
+
+	It("removes layers", func() {
+
+		Ω(c.Set(layer("s1", "a", red, 0))).Should(Succeed())
+		Ω(c.Set(layer("s1", "b", red, 0))).Should(Succeed())
+		Ω(c.Set(layer("s2", "a", blue, 0))).Should(Succeed())
+
+		Ω(c.Remove("s2", "b")).Should(BeFalse())
+		Ω(c.Remove("s2", "a")).Should(BeTrue())
+		Ω(c.Compose()).Should(Equal(NewFrame(red)))
+
+		Ω(c.RemoveSource("s1")).Should(Equal(2))
+		Ω(c.Layers()).Should(BeEmpty())
+	})
+
+	It("keeps copies of the layers", func() {
+
+		l := layer("s", "a", red, 0)
+		Ω(c.Set(l)).Should(Succeed())
+		l.Fill(blue, 1)
+
+		Ω(c.Compose()).Should(Equal(NewFrame(red)))
+	})
+
+	It("composes transparent keys and masks", func() {
+
+		Ω(c.Set(layer("s", "base", red, 0))).Should(Succeed())
+
+		l := NewLayer("s", "key")
+		l.Set(corner, blue, 1)
+		l.Set(Cell{Row: 5, Column: 20}, blue, 1)
+		l.Mask = []Cell{corner}
+		Ω(c.Set(l)).Should(Succeed())
+
+		expected := NewFrame(red)
+		expected.Set(corner, blue)
+		Ω(c.Compose()).Should(Equal(expected))
+	})
+
+	DescribeTable("blends colors",
+		func(mode BlendMode, alpha float64, below, above, expected *Color) {
+
+			Ω(c.Set(layer("s", "below", below, 0))).Should(Succeed())
+
+			l := layer("s", "above", above, 1)
+			l.Fill(above, alpha)
+			l.Blend = mode
+			Ω(c.Set(l)).Should(Succeed())
+
+			Ω(c.Compose().Get(corner)).Should(Equal(expected))
+		},
+		Entry("normal", BlendNormal, 1.0, red, blue, blue),
+		Entry("normal half opaque", BlendNormal, 0.5, black, white, NewColor(0xbc, 0xbc, 0xbc)),
+		Entry("add", BlendAdd, 1.0, red, blue, NewColor(0xff, 0, 0xff)),
+		Entry("multiply", BlendMultiply, 1.0, white, red, red),
+		Entry("multiply darkens", BlendMultiply, 1.0, blue, red, black),
+		Entry("screen", BlendScreen, 1.0, red, blue, NewColor(0xff, 0, 0xff)),
+		Entry("default", BlendMode(""), 1.0, red, blue, blue),
+	)
+
+	It("fails on unknown blend mode", func() {
+
+		l := layer("s", "a", red, 0)
+		l.Blend = "overlay"
+		Ω(c.Set(l)).Should(MatchError(ErrUnknownBlendMode))
+		Ω(c.Layers()).Should(BeEmpty())
+	})
+
+	It("parses blend modes", func() {
+
+		Ω(ParseBlendMode("Screen")).Should(Equal(BlendScreen))
+		_, err := ParseBlendMode("overlay")
+		Ω(err).Should(MatchError(ErrUnknownBlendMode))
+		Ω(BlendModeNames()).Should(Equal([]string{"add", "multiply", "normal", "screen"}))
+	})
+
+	It("renders the composed frame", func() {
+
+		Ω(c.Set(layer("s", "a", red, 0))).Should(Succeed())
+
+		var frame Frame
+		Ω(c.Render(&frame, 0)).Should(BeTrue())
+		Ω(&frame).Should(Equal(NewFrame(red)))
+	})
+
+	It("overlays animation as a layer", func() {
+
+		base := layer("keyboard", "shown", red, 0)
+		Ω(c.Set(base)).Should(Succeed())
+
+		bar := NewLayer("timer", "bar")
+		bar.Z, bar.Mask = 1, []Cell{corner}
+		anim, err := c.Overlay(bar, AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+			frame.Fill(blue)
+			return elapsed < time.Second
+		}))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(names()).Should(Equal([]string{"keyboard/shown"}))
+
+		var frame Frame
+		Ω(anim.Render(&frame, 0)).Should(BeTrue())
+		expected := NewFrame(red)
+		expected.Set(corner, blue)
+		Ω(&frame).Should(Equal(expected))
+		Ω(names()).Should(Equal([]string{"keyboard/shown", "timer/bar"}))
+
+		Ω(anim.Render(&frame, time.Second)).Should(BeFalse())
+	})
+
+	It("fails to overlay layer of unknown blend mode", func() {
+
+		l := NewLayer("s", "a")
+		l.Blend = "overlay"
+		Ω(c.Overlay(l, AnimationFunc(func(*Frame, time.Duration) bool { return false }))).
+			Error().Should(MatchError(ErrUnknownBlendMode))
+	})
+})
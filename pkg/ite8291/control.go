@@ -102,9 +102,23 @@ type Device interface {
 	Close() error
 }
 
+// Exclusive interface is implemented by devices shared by several
+// controllers. The controller uses it for the operations consisting of
+// several transfers, e.g. reading the effect, so their transfers are
+// not interleaved with the transfers of the other controllers.
+type Exclusive interface {
+
+	// Exclusive returns device giving exclusive access to the shared
+	// device until release is called. The other users of the shared
+	// device wait till then.
+	Exclusive() (dev Device, release func(), err error)
+}
+
 // Controller provides ite8291r3 controller functionality.
 type Controller struct {
-	dev Device
+	dev   Device
+	frame *Frame // frame shown in 'user' effect if known
+	hint  *Frame // frame likely shown in 'user' effect
 }
 
 // NewController creates a new controller backed by provided ite8291r3
@@ -119,10 +133,33 @@ func (c *Controller) Close() error {
 	return c.dev.Close()
 }
 
+// exclusive calls f with the device giving exclusive access to it if
+// it's shared (see Exclusive) or with the device itself otherwise.
+func (c *Controller) exclusive(f func(dev Device) error) error {
+
+	x, ok := c.dev.(Exclusive)
+	if !ok {
+		return f(c.dev)
+	}
+
+	dev, release, err := x.Exclusive()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return f(dev)
+}
+
 // ControlSend sends data to ite8291r3 controller.
 func (c *Controller) ControlSend(data []byte) error {
+	return controlSend(c.dev, data)
+}
+
+// controlSend sends data to ite8291r3 controller of the given device.
+func controlSend(dev Device, data []byte) error {
 
-	_, err := c.dev.ControlTransfer(SendControlRequestType,
+	_, err := dev.ControlTransfer(SendControlRequestType,
 		0x009, // bRequest (HID set_report)
 		0x300, // wValue (HID feature)
 		0x001, // wIndex
@@ -133,9 +170,10 @@ func (c *Controller) ControlSend(data []byte) error {
 	return err
 }
 
-// ControlSend receives data from ite8291r3 controller.
-func (c *Controller) controlReceive(data []byte) error {
-	_, err := c.dev.ControlTransfer(ReceiveControlRequestType,
+// controlReceive receives data from ite8291r3 controller of the given
+// device.
+func controlReceive(dev Device, data []byte) error {
+	_, err := dev.ControlTransfer(ReceiveControlRequestType,
 		0x001, // bRequest (HID set_report)
 		0x300, // wValue (HID feature)
 		0x001, // wIndex
@@ -165,20 +203,71 @@ func (c *Controller) SetOffMode() error {
 	return c.SetEffect(SetOffOp, 0, 0, 0, 0, 0, false)
 }
 
+// EffectState provides ite8291r3 keyboard backlight effect and its
+// attributes as reported by the controller. Colors of the keys of
+// 'user' effect cannot be read back from the controller.
+type EffectState struct {
+	Control    byte
+	Effect     byte
+	Speed      byte
+	Brightness byte
+	ColorNum   byte
+	ReactOrDiv byte
+}
+
+// query sends the given command to ite8291r3 controller and receives
+// its reply.
+func (c *Controller) query(command byte) ([]byte, error) {
+
+	out := []byte{8, 0, 0, 0, 0, 0, 0, 0}
+	err := c.exclusive(func(dev Device) error {
+		if err := controlSend(dev, []byte{command}); err != nil {
+			return err
+		}
+
+		return controlReceive(dev, out)
+	})
+
+	return out, err
+}
+
+// Effect retrieves current ite8291r3 keyboard backlight effect and
+// its attributes.
+func (c *Controller) Effect() (*EffectState, error) {
+
+	out, err := c.query(GetEffectCommand)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EffectState{Control: out[1], Effect: out[2], Speed: out[3], Brightness: out[4],
+		ColorNum: out[5], ReactOrDiv: out[6]}, nil
+}
+
+// RestoreEffect sets ite8291r3 keyboard backlight effect and its
+// attributes to the given state retrieved by Effect. The state is not
+// saved. Colors of the keys of 'user' effect remain as they were set
+// last.
+func (c *Controller) RestoreEffect(state *EffectState) error {
+
+	if state.Control == OffState {
+		return c.SetOffMode()
+	}
+
+	return c.SetEffect(state.Control, state.Effect, state.Speed, state.Brightness, state.ColorNum,
+		state.ReactOrDiv, false)
+}
+
 // State retrieves ite8291r3 keyboard backlight state: whether it's On
 // (true) or Off (false).
 func (c *Controller) State() (state bool, err error) {
 
-	if err := c.ControlSend([]byte{GetEffectCommand}); err != nil {
-		return false, err
-	}
-
-	out := []byte{8, 0, 0, 0, 0, 0, 0, 0}
-	if err := c.controlReceive(out); err != nil {
+	e, err := c.Effect()
+	if err != nil {
 		return false, err
 	}
 
-	return out[1] != OffState, nil
+	return e.Control != OffState, nil
 }
 
 // SetBrightness sets brightness of ite8291r3 keyboard backlight. The
@@ -192,16 +281,12 @@ func (c *Controller) SetBrightness(brightness byte) error {
 // maximum value is specified by BrightnessMaxValue.
 func (c *Controller) Brightness() (brightness byte, err error) {
 
-	if err = c.ControlSend([]byte{GetEffectCommand}); err != nil {
-		return 0, err
-	}
-
-	out := []byte{8, 0, 0, 0, 0, 0, 0, 0}
-	if err := c.controlReceive(out); err != nil {
+	e, err := c.Effect()
+	if err != nil {
 		return 0, err
 	}
 
-	return out[4], nil
+	return e.Brightness, nil
 }
 
 // SetAuroraMode sets ite8291r3 keyboard backlight controller to
@@ -264,49 +349,118 @@ func (c *Controller) SetWaveMode(speed, brightness byte, direction Direction, sa
 	return c.SetEffect(SetEffectOp, WaveEffect, speed, brightness, 0, byte(direction), save)
 }
 
-// SetUserMode sets ite8291r3 keyboard backlight to 'user' effect.
-func (c *Controller) setUserMode(brightness byte, save bool) error {
+// SetUserMode sets ite8291r3 keyboard backlight to 'user' effect. In
+// 'user' effect colors of the keys are set via WriteFrame.
+func (c *Controller) SetUserMode(brightness byte, save bool) error {
 
 	return c.setEffectWithReactive(UserEffect, 0, brightness, 0, false, save)
 }
 
-// setRowIndex sets current keyboard row of 'user' effect to the
-// specified value.
-func (c *Controller) setRowIndex(idx byte) error {
+// setRowIndex sets current keyboard row of 'user' effect of the given
+// device to the specified value.
+func setRowIndex(dev Device, idx byte) error {
 
-	return c.ControlSend([]byte{SetRowIndexCommand, 0, idx})
+	return controlSend(dev, []byte{SetRowIndexCommand, 0, idx})
 }
 
-// SetSingleColorMode sets color of all keyboard backlight key to the specified color.
-func (c *Controller) SetSingleColorMode(brightness byte, color *Color, save bool) error {
+// WriteFrame sets colors of all keyboard backlight keys to the colors
+// provided by the given frame. The keyboard backlight must be in
+// 'user' effect (see SetUserMode).
+func (c *Controller) WriteFrame(frame *Frame) error {
+
+	if err := c.exclusive(func(dev Device) error {
+		write, err := dev.GetBulkWrite()
+		if err != nil {
+			return err
+		}
 
-	if err := c.setUserMode(brightness, save); err != nil {
+		rowBuffer := make([]byte, rowBufferLength)
+		for i := range RowsNumber {
+			if err := setRowIndex(dev, byte(i)); err != nil {
+				return err
+			}
+
+			for j := range ColumnsNumber {
+				rowBuffer[j+rowBlueOffset] = frame[i][j].Blue
+				rowBuffer[j+rowGreenOffset] = frame[i][j].Green
+				rowBuffer[j+rowRedOffset] = frame[i][j].Red
+			}
+
+			if _, err := write(rowBuffer); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
 		return err
 	}
 
-	write, err := c.dev.GetBulkWrite()
-	if err != nil {
-		return err
+	shown := *frame
+	c.frame = &shown
+
+	return nil
+}
+
+// LastFrame returns the frame written last by WriteFrame. It returns
+// nil if the frame is not known. The frame is shown by the keyboard
+// backlight in 'user' effect unless it was changed by other means.
+func (c *Controller) LastFrame() *Frame {
+
+	if c.frame == nil {
+		return nil
 	}
+	frame := *c.frame
 
-	rowBuffer := make([]byte, rowBufferLength)
-	for i := range RowsNumber {
-		if err := c.setRowIndex(byte(i)); err != nil {
-			return err
-		}
+	return &frame
+}
 
-		for j := range ColumnsNumber {
-			rowBuffer[j+rowBlueOffset] = color.Blue
-			rowBuffer[j+rowGreenOffset] = color.Green
-			rowBuffer[j+rowRedOffset] = color.Red
-		}
+// FrameHint returns the frame likely shown by the keyboard backlight
+// in 'user' effect: the frame returned by LastFrame if it is known or
+// the frame given to SetFrameHint. It returns nil if neither is
+// set. Unlike LastFrame, the hint may be stale, so it is only good to
+// crossfade from it, not to restore it.
+func (c *Controller) FrameHint() *Frame {
 
-		if _, err := write(rowBuffer); err != nil {
-			return err
-		}
+	if c.frame != nil {
+		return c.LastFrame()
 	}
+	if c.hint == nil {
+		return nil
+	}
+	frame := *c.hint
 
-	return nil
+	return &frame
+}
+
+// SetFrameHint sets the frame returned by FrameHint while LastFrame is
+// not known, e.g. to the frame written last by another process. nil
+// clears the hint.
+func (c *Controller) SetFrameHint(frame *Frame) {
+
+	c.hint = nil
+	if frame != nil {
+		hint := *frame
+		c.hint = &hint
+	}
+}
+
+// SetFrameMode sets ite8291r3 keyboard backlight to 'user' effect
+// and sets colors of all keys to the colors provided by the given
+// frame.
+func (c *Controller) SetFrameMode(brightness byte, frame *Frame, save bool) error {
+
+	if err := c.SetUserMode(brightness, save); err != nil {
+		return err
+	}
+
+	return c.WriteFrame(frame)
+}
+
+// SetSingleColorMode sets color of all keyboard backlight key to the specified color.
+func (c *Controller) SetSingleColorMode(brightness byte, color *Color, save bool) error {
+
+	return c.SetFrameMode(brightness, NewFrame(color), save)
 }
 
 // SetColor sets predefined color specified by its colorNum to the
@@ -333,12 +487,7 @@ func (c *Controller) SetColors(colors []*Color) error {
 // as string.
 func (c *Controller) FirmwareVersion() (string, error) {
 
-	if err := c.ControlSend([]byte{GetFirmwareVersionCommand}); err != nil {
-		return "", err
-	}
-
-	out := []byte{8, 0, 0, 0, 0, 0, 0, 0}
-	err := c.controlReceive(out)
+	out, err := c.query(GetFirmwareVersionCommand)
 	if err != nil {
 		return "", err
 	}
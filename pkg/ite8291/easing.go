@@ -0,0 +1,63 @@
+package ite8291
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownEasing error indicates that an easing function with the
+// given name does not exist.
+var ErrUnknownEasing = errors.New("unknown easing")
+
+// Easing provides progress of a transition in [0, 1] at the given
+// time t in [0, 1] of the transition.
+type Easing func(t float64) float64
+
+// easing functions names.
+const (
+	// EasingNone - name of the easing keeping the start of the
+	// transition until its end.
+	EasingNone = "none"
+	// EasingLinear - name of the easing progressing uniformly.
+	EasingLinear = "linear"
+	// EasingIn - name of the easing starting slowly.
+	EasingIn = "ease-in"
+	// EasingOut - name of the easing ending slowly.
+	EasingOut = "ease-out"
+	// EasingInOut - name of the easing starting and ending slowly.
+	EasingInOut = "ease-in-out"
+)
+
+// easings maps names of the easing functions to the functions.
+var easings = map[string]Easing{
+	EasingNone:   func(float64) float64 { return 0 },
+	EasingLinear: func(t float64) float64 { return t },
+	EasingIn:     func(t float64) float64 { return t * t * t },
+	EasingOut:    func(t float64) float64 { return 1 - (1-t)*(1-t)*(1-t) },
+	EasingInOut:  func(t float64) float64 { return t * t * (3 - 2*t) },
+}
+
+// EasingNames returns sorted names of the easing functions.
+func EasingNames() []string {
+
+	names := make([]string, 0, len(easings))
+	for name := range easings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// ParseEasing returns easing function with the given case insensitive
+// name. It returns ErrUnknownEasing if there is no such function.
+func ParseEasing(name string) (Easing, error) {
+
+	if e, found := easings[strings.ToLower(name)]; found {
+		return e, nil
+	}
+
+	return nil, fmt.Errorf("%w %q; expected one of %q", ErrUnknownEasing, name, EasingNames())
+}
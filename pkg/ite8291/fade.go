@@ -0,0 +1,110 @@
+package ite8291
+
+import (
+	"context"
+	"time"
+)
+
+// Transition provides parameters of a smooth transition between
+// states of the keyboard backlight.
+type Transition struct {
+
+	// Duration of the transition. The transition is instant if it is
+	// not positive.
+	Duration time.Duration
+
+	// Easing provides progress of the transition. It defaults to
+	// linear progress.
+	Easing Easing
+
+	// FPS is number of steps of the transition per second. It is
+	// clipped to [FPSMinValue, FPSMaxValue].
+	FPS int
+}
+
+// Run calls step with progress of the transition in [0, 1] FPS times
+// a second for the duration of the transition. The last call is
+// always step(1). If ctx is done, the transition jumps to its end.
+// Run stops and returns the error of the first failed step.
+func (t Transition) Run(ctx context.Context, step func(progress float64) error) error {
+
+	if t.Duration <= 0 {
+		return step(1)
+	}
+
+	easing := t.Easing
+	if easing == nil {
+		easing = easings[EasingLinear]
+	}
+
+	fps := max(FPSMinValue, min(FPSMaxValue, t.FPS))
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+
+	start := time.Now()
+
+	for {
+		elapsed := time.Since(start)
+		if elapsed >= t.Duration {
+			return step(1)
+		}
+
+		if err := step(easing(float64(elapsed) / float64(t.Duration))); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return step(1)
+		case <-ticker.C:
+		}
+	}
+}
+
+// BrightnessWriter interface abstracts a keyboard backlight with
+// adjustable brightness. It is implemented by Controller.
+type BrightnessWriter interface {
+
+	// SetBrightness sets brightness of the keyboard backlight.
+	SetBrightness(brightness byte) error
+}
+
+// FadeBrightness ramps brightness of the keyboard backlight from the
+// given brightness to the other one during the transition. Every
+// brightness level is set once.
+func FadeBrightness(ctx context.Context, w BrightnessWriter, from, to byte, t Transition) error {
+
+	shown := from
+	set := false
+
+	return t.Run(ctx, func(progress float64) error {
+
+		b := byte(float64(from) + (float64(to)-float64(from))*progress + 0.5)
+		if set && b == shown {
+			return nil
+		}
+
+		shown, set = b, true
+		return w.SetBrightness(b)
+	})
+}
+
+// Crossfade writes to w frames mixing the given frames during the
+// transition, starting with the first one and ending with the other
+// one. Frames equal to the frame written last are skipped. See
+// Frame.Mix.
+func Crossfade(ctx context.Context, w FrameWriter, from, to *Frame, t Transition) error {
+
+	var shown *Frame
+
+	return t.Run(ctx, func(progress float64) error {
+
+		frame := from.Mix(to, progress)
+		if shown != nil && *frame == *shown {
+			return nil
+		}
+
+		shown = frame
+		return w.WriteFrame(frame)
+	})
+}
@@ -0,0 +1,145 @@
+package ite8291
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// brightnessWriterStub collects set brightness levels.
+type brightnessWriterStub struct {
+	levels []byte
+}
+
+// SetBrightness collects the given brightness.
+func (w *brightnessWriterStub) SetBrightness(brightness byte) error {
+	w.levels = append(w.levels, brightness)
+	return nil
+}
+
+var _ = Describe("Transitions", func() {
+
+	t := Transition{Duration: 50 * time.Millisecond, FPS: FPSMaxValue}
+
+	It("runs transition from start to end", func() {
+
+		var progress []float64
+		Ω(t.Run(context.Background(), func(p float64) error {
+			progress = append(progress, p)
+			return nil
+		})).Should(Succeed())
+
+		Ω(len(progress)).Should(BeNumerically(">", 1))
+		Ω(progress[0]).Should(BeNumerically("<", 0.5))
+		Ω(progress[len(progress)-1]).Should(Equal(1.0))
+		for i := 1; i < len(progress); i++ {
+			Ω(progress[i]).Should(BeNumerically(">=", progress[i-1]))
+		}
+	})
+
+	It("applies easing", func() {
+
+		easing, err := ParseEasing(EasingNone)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		var progress []float64
+		Ω(Transition{Duration: t.Duration, Easing: easing, FPS: t.FPS}.Run(context.Background(),
+			func(p float64) error {
+				progress = append(progress, p)
+				return nil
+			})).Should(Succeed())
+
+		Ω(progress[:len(progress)-1]).Should(HaveEach(0.0))
+		Ω(progress[len(progress)-1]).Should(Equal(1.0))
+	})
+
+	DescribeTable("jumps to the end",
+		func(t Transition, cancelled bool) {
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if cancelled {
+				cancel()
+			}
+			defer cancel()
+
+			var progress []float64
+			Ω(t.Run(ctx, func(p float64) error {
+				progress = append(progress, p)
+				return nil
+			})).Should(Succeed())
+
+			Ω(progress[len(progress)-1]).Should(Equal(1.0))
+			Ω(len(progress)).Should(BeNumerically("<=", 2))
+		},
+		Entry("of instant transition", Transition{}, false),
+		Entry("once ctx is done", Transition{Duration: time.Hour}, true),
+	)
+
+	It("stops on step error", func() {
+
+		errStep := errors.New("step error")
+		calls := 0
+		Ω(t.Run(context.Background(), func(float64) error {
+			calls++
+			return errStep
+		})).Should(MatchError(errStep))
+		Ω(calls).Should(Equal(1))
+	})
+
+	It("ramps brightness setting every level once", func() {
+
+		w := &brightnessWriterStub{}
+		Ω(FadeBrightness(context.Background(), w, 40, 10, t)).Should(Succeed())
+
+		Ω(w.levels[len(w.levels)-1]).Should(Equal(byte(10)))
+		for i := 1; i < len(w.levels); i++ {
+			Ω(w.levels[i]).Should(BeNumerically("<", w.levels[i-1]))
+		}
+	})
+
+	It("crossfades frames", func() {
+
+		from, to := NewFrame(NewColor(0xff, 0, 0)), NewFrame(NewColor(0, 0, 0xff))
+
+		w := &frameWriterStub{}
+		Ω(Crossfade(context.Background(), w, from, to, t)).Should(Succeed())
+
+		Ω(len(w.frames)).Should(BeNumerically(">", 2))
+		Ω(w.frames[len(w.frames)-1]).Should(Equal(to))
+		Ω(w.frames[len(w.frames)/2]).ShouldNot(Or(Equal(from), Equal(to)))
+	})
+})
+
+var _ = Describe("Controller last frame", func() {
+
+	It("keeps the frame written last", func() {
+
+		ctl := NewController(NewVirtualDevice())
+		Ω(ctl.LastFrame()).Should(BeNil())
+
+		written := NewFrame(NewColor(4, 5, 6))
+		Ω(ctl.SetFrameMode(10, written, false)).Should(Succeed())
+		written.Fill(NewColor(0, 0, 0))
+		Ω(ctl.LastFrame()).Should(Equal(NewFrame(NewColor(4, 5, 6))))
+	})
+	It("hints the frame to crossfade from until the frame is known", func() {
+
+		ctl := NewController(NewVirtualDevice())
+		Ω(ctl.FrameHint()).Should(BeNil())
+
+		hint := NewFrame(NewColor(1, 2, 3))
+		ctl.SetFrameHint(hint)
+		Ω(ctl.FrameHint()).Should(Equal(hint))
+		Ω(ctl.LastFrame()).Should(BeNil())
+
+		written := NewFrame(NewColor(4, 5, 6))
+		Ω(ctl.WriteFrame(written)).Should(Succeed())
+		Ω(ctl.FrameHint()).Should(Equal(written))
+
+		ctl.SetFrameHint(nil)
+		Ω(ctl.FrameHint()).Should(Equal(written))
+	})
+})
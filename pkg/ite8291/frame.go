@@ -0,0 +1,88 @@
+package ite8291
+
+// Cell identifies a key of ite8291r3 keyboard backlight by its row
+// and column in the keyboard matrix.
+type Cell struct {
+	Row    int `yaml:"row"`
+	Column int `yaml:"column"`
+}
+
+// Valid returns true if cell is inside the keyboard matrix.
+func (c Cell) Valid() bool {
+	return c.Row >= 0 && c.Row < RowsNumber && c.Column >= 0 && c.Column < ColumnsNumber
+}
+
+// Frame provides colors of all keys of ite8291r3 keyboard backlight.
+type Frame [RowsNumber][ColumnsNumber]Color
+
+// NewFrame creates Frame with all keys set to the given color.
+func NewFrame(color *Color) *Frame {
+
+	f := &Frame{}
+	f.Fill(color)
+
+	return f
+}
+
+// Fill sets all keys of the frame to the given color.
+func (f *Frame) Fill(color *Color) {
+
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			f[i][j] = *color
+		}
+	}
+}
+
+// Set sets color of the key identified by the given cell. Cells
+// outside the keyboard matrix are ignored.
+func (f *Frame) Set(cell Cell, color *Color) {
+
+	if cell.Valid() {
+		f[cell.Row][cell.Column] = *color
+	}
+}
+
+// SetCells sets color of all keys identified by the given cells.
+func (f *Frame) SetCells(cells []Cell, color *Color) {
+
+	for _, cell := range cells {
+		f.Set(cell, color)
+	}
+}
+
+// Get returns color of the key identified by the given cell.
+func (f *Frame) Get(cell Cell) *Color {
+
+	color := f[cell.Row][cell.Column]
+
+	return &color
+}
+
+// Mix returns frame with colors of the keys between the colors of the
+// keys of f and to. t is a position between the frames: 0 is f, 1 is
+// to. See Color.Mix.
+func (f *Frame) Mix(to *Frame, t float64) *Frame {
+
+	switch {
+	case t <= 0:
+		mixed := *f
+		return &mixed
+	case t >= 1:
+		mixed := *to
+		return &mixed
+	}
+
+	mixed := &Frame{}
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			if f[i][j] == to[i][j] {
+				mixed[i][j] = f[i][j]
+			} else {
+				mixed[i][j] = *f[i][j].Mix(&to[i][j], t)
+			}
+		}
+	}
+
+	return mixed
+}
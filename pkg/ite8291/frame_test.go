@@ -0,0 +1,62 @@
+package ite8291
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Frame", func() {
+
+	var frame *Frame
+	red, blue := NewColor(0xff, 0, 0), NewColor(0, 0, 0xff)
+
+	BeforeEach(func() {
+		frame = NewFrame(red)
+	})
+
+	It("is filled with the given color", func() {
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				Ω(frame.Get(Cell{Row: i, Column: j})).Should(Equal(red))
+			}
+		}
+	})
+
+	It("sets colors of valid cells only", func() {
+		frame.SetCells([]Cell{{Row: 0, Column: 0}, {Row: 5, Column: 20}, {Row: 6, Column: 0}, {Row: 0, Column: -1}},
+			blue)
+
+		Ω(frame.Get(Cell{Row: 0, Column: 0})).Should(Equal(blue))
+		Ω(frame.Get(Cell{Row: 5, Column: 20})).Should(Equal(blue))
+		Ω(frame.Get(Cell{Row: 0, Column: 1})).Should(Equal(red))
+	})
+
+	It("returns a copy of the key color", func() {
+		frame.Get(Cell{Row: 1, Column: 1}).Red = 0
+
+		Ω(frame.Get(Cell{Row: 1, Column: 1})).Should(Equal(red))
+	})
+
+	DescribeTable("cell validity",
+		func(cell Cell, valid bool) {
+			Ω(cell.Valid()).Should(Equal(valid))
+		},
+		Entry(nil, Cell{Row: 0, Column: 0}, true),
+		Entry(nil, Cell{Row: RowsNumber - 1, Column: ColumnsNumber - 1}, true),
+		Entry(nil, Cell{Row: RowsNumber, Column: 0}, false),
+		Entry(nil, Cell{Row: 0, Column: ColumnsNumber}, false),
+		Entry(nil, Cell{Row: -1, Column: 0}, false),
+	)
+
+	It("mixes colors of the keys", func() {
+		from, to := NewFrame(NewColor(0, 0, 0)), NewFrame(NewColor(0, 0, 0))
+		to.Set(Cell{Row: 1, Column: 2}, NewColor(0xff, 0xff, 0xff))
+
+		Ω(from.Mix(to, -1)).Should(Equal(from))
+		Ω(from.Mix(to, 2)).Should(Equal(to))
+
+		mixed := from.Mix(to, 0.5)
+		Ω(mixed.Get(Cell{Row: 1, Column: 2})).Should(Equal(NewColor(0, 0, 0).Mix(NewColor(0xff, 0xff, 0xff), 0.5)))
+		Ω(mixed.Get(Cell{Row: 0, Column: 0})).Should(Equal(NewColor(0, 0, 0)))
+	})
+})
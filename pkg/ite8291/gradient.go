@@ -0,0 +1,98 @@
+package ite8291
+
+import "math"
+
+// Gradient provides colors smoothly changing between its evenly
+// spaced stops.
+type Gradient []*Color
+
+// At returns color of the gradient at position t; 0 is the first
+// stop, 1 is the last one; NaN is treated as 0. Colors between stops
+// are interpolated in Oklab color space.
+func (g Gradient) At(t float64) *Color {
+
+	switch len(g) {
+	case 0:
+		return NewColor(0, 0, 0)
+	case 1:
+		return NewColor(g[0].Red, g[0].Green, g[0].Blue)
+	}
+
+	if math.IsNaN(t) {
+		t = 0
+	}
+
+	t = max(0, min(1, t)) * float64(len(g)-1)
+	i := min(int(t), len(g)-2)
+
+	return g[i].Mix(g[i+1], t-float64(i))
+}
+
+// GradientDirection represents direction of a gradient. Linear
+// gradients change along X, Y vector; radial gradients change from
+// the center of the keyboard to its edges.
+type GradientDirection struct {
+	X      float64
+	Y      float64
+	Radial bool
+}
+
+// RadialGradient - direction of radial gradients.
+var RadialGradient = GradientDirection{Radial: true}
+
+// LinearGradient returns direction of linear gradient changing along
+// the sum of the given directions, e.g. DirectionUp and DirectionRight
+// provide diagonal gradient.
+func LinearGradient(dirs ...Direction) GradientDirection {
+
+	var d GradientDirection
+	for _, dir := range dirs {
+		switch dir {
+		case DirectionRight:
+			d.X++
+		case DirectionLeft:
+			d.X--
+		case DirectionUp:
+			d.Y--
+		case DirectionDown:
+			d.Y++
+		}
+	}
+
+	return d
+}
+
+// GradientFrame renders the gradient in the given direction over the
+// keys of the given layout. Cells of the keyboard matrix without a
+// key are set to black.
+func GradientFrame(g Gradient, layout *Layout, dir GradientDirection) *Frame {
+
+	frame := &Frame{}
+	if len(layout.Keys) == 0 {
+		return frame
+	}
+
+	w, h := layout.Bounds()
+	pos := make([]float64, len(layout.Keys))
+	lo, hi := math.Inf(1), math.Inf(-1)
+
+	for i := range layout.Keys {
+		x, y := layout.Keys[i].Center()
+		if dir.Radial {
+			pos[i] = math.Hypot(x-w/2, y-h/2)
+		} else {
+			pos[i] = x*dir.X + y*dir.Y
+		}
+		lo, hi = min(lo, pos[i]), max(hi, pos[i])
+	}
+
+	for i := range layout.Keys {
+		t := 0.0
+		if hi > lo {
+			t = (pos[i] - lo) / (hi - lo)
+		}
+		frame.Set(layout.Keys[i].Cell(), g.At(t))
+	}
+
+	return frame
+}
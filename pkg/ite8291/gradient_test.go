@@ -0,0 +1,62 @@
+package ite8291
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Gradient", func() {
+
+	red, white, blue := NewColor(0xff, 0, 0), NewColor(0xff, 0xff, 0xff), NewColor(0, 0, 0xff)
+
+	DescribeTable("Oklab conversion round trip",
+		func(c *Color) {
+			Ω(c.Oklab().Color()).Should(Equal(c))
+		},
+		Entry(nil, NewColor(0, 0, 0)),
+		Entry(nil, white),
+		Entry(nil, red),
+		Entry(nil, NewColor(0x12, 0x34, 0x56)),
+		Entry(nil, NewColor(0xAB, 0xCD, 0xEF)),
+	)
+
+	It("mixes colors perceptually", func() {
+		Ω(red.Mix(blue, 0)).Should(Equal(red))
+		Ω(red.Mix(blue, 1)).Should(Equal(blue))
+		Ω(red.Mix(blue, 2)).Should(Equal(blue))
+
+		// perceptual middle gray differs from the average of sRGB components
+		gray := NewColor(0, 0, 0).Mix(white, 0.5)
+		Ω(gray.Red).Should(BeNumerically("~", 0x63, 1))
+		Ω(gray.Green).Should(Equal(gray.Red))
+		Ω(gray.Blue).Should(Equal(gray.Red))
+	})
+
+	It("returns colors of its stops", func() {
+		g := Gradient{red, white, blue}
+
+		Ω(g.At(0)).Should(Equal(red))
+		Ω(g.At(0.5)).Should(Equal(white))
+		Ω(g.At(1)).Should(Equal(blue))
+		Ω(Gradient{white}.At(0.3)).Should(Equal(white))
+		Ω(g.At(math.NaN())).Should(Equal(red))
+	})
+
+	DescribeTable("renders frame",
+		func(dir GradientDirection, first, last Cell) {
+			frame := GradientFrame(Gradient{red, blue}, DefaultLayout(), dir)
+
+			Ω(frame.Get(first)).Should(Equal(red))
+			Ω(frame.Get(last)).Should(Equal(blue))
+		},
+		Entry("right", LinearGradient(DirectionRight), Cell{Row: 3, Column: 0}, Cell{Row: 2, Column: 20}),
+		Entry("left", LinearGradient(DirectionLeft), Cell{Row: 3, Column: 20}, Cell{Row: 2, Column: 0}),
+		Entry("up", LinearGradient(DirectionUp), Cell{Row: 5, Column: 3}, Cell{Row: 0, Column: 7}),
+		Entry("down", LinearGradient(DirectionDown), Cell{Row: 0, Column: 3}, Cell{Row: 5, Column: 7}),
+		Entry("up-right", LinearGradient(DirectionUp, DirectionRight), Cell{Row: 5, Column: 0},
+			Cell{Row: 0, Column: 20}),
+		Entry("radial", RadialGradient, Cell{Row: 2, Column: 10}, Cell{Row: 0, Column: 0}),
+	)
+})
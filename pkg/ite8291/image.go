@@ -0,0 +1,154 @@
+package ite8291
+
+import (
+	"image"
+	"image/draw"
+	"image/gif"
+	"math"
+	"time"
+)
+
+// Sampling represents method used to scale an image to the keyboard.
+type Sampling int
+
+// supported image sampling methods.
+const (
+	// NearestSampling uses color of the image pixel at the center of
+	// every cell of the keyboard matrix.
+	NearestSampling Sampling = iota
+	// AverageSampling uses average color of the image pixels covered
+	// by every cell of the keyboard matrix.
+	AverageSampling
+	// LayoutSampling uses average color of the image pixels covered
+	// by every key of the keyboard layout.
+	LayoutSampling
+)
+
+// gifDefaultDelay - delay used for GIF frames without delay.
+const gifDefaultDelay = 100 * time.Millisecond
+
+// ImageFrame scales the given image to the keyboard using the given
+// sampling method and returns the resulting frame. Transparent parts
+// of the image are rendered over black. layout is used by
+// LayoutSampling only; cells of the keyboard matrix without a key
+// are set to black.
+func ImageFrame(img image.Image, layout *Layout, sampling Sampling) *Frame {
+
+	frame := &Frame{}
+	b := img.Bounds()
+	if b.Empty() {
+		return frame
+	}
+
+	sx, sy := float64(b.Dx())/ColumnsNumber, float64(b.Dy())/RowsNumber
+
+	switch sampling {
+	case NearestSampling:
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				frame[i][j] = *averageColor(img, image.Rect(
+					b.Min.X+int((float64(j)+0.5)*sx), b.Min.Y+int((float64(i)+0.5)*sy),
+					b.Min.X+int((float64(j)+0.5)*sx)+1, b.Min.Y+int((float64(i)+0.5)*sy)+1))
+			}
+		}
+
+	case AverageSampling:
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				frame[i][j] = *averageColor(img, scaledRect(b, float64(j)*sx, float64(i)*sy,
+					float64(j+1)*sx, float64(i+1)*sy))
+			}
+		}
+
+	case LayoutSampling:
+		w, h := layout.Bounds()
+		if w <= 0 || h <= 0 {
+			return frame
+		}
+
+		kx, ky := float64(b.Dx())/w, float64(b.Dy())/h
+		for _, k := range layout.Keys {
+			frame.Set(k.Cell(), averageColor(img, scaledRect(b, k.X*kx, k.Y*ky,
+				(k.X+k.Width)*kx, (k.Y+k.Height)*ky)))
+		}
+	}
+
+	return frame
+}
+
+// scaledRect returns rectangle of the image pixels given by its
+// coordinates relative to the image bounds. The rectangle contains
+// at least one pixel.
+func scaledRect(b image.Rectangle, x0, y0, x1, y1 float64) image.Rectangle {
+
+	r := image.Rect(b.Min.X+int(math.Floor(x0)), b.Min.Y+int(math.Floor(y0)),
+		b.Min.X+int(math.Ceil(x1)), b.Min.Y+int(math.Ceil(y1))).Intersect(b)
+
+	if r.Empty() {
+		x, y := min(b.Min.X+int(x0), b.Max.X-1), min(b.Min.Y+int(y0), b.Max.Y-1)
+		r = image.Rect(x, y, x+1, y+1)
+	}
+
+	return r
+}
+
+// averageColor returns average color of the image pixels in the
+// given rectangle rendered over black.
+func averageColor(img image.Image, r image.Rectangle) *Color {
+
+	var red, green, blue, n uint64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cr, cg, cb, _ := img.At(x, y).RGBA() // alpha premultiplied i.e. over black
+			red, green, blue, n = red+uint64(cr), green+uint64(cg), blue+uint64(cb), n+1
+		}
+	}
+
+	if n == 0 {
+		return NewColor(0, 0, 0)
+	}
+
+	return NewColor(uint8(red/n>>8), uint8(green/n>>8), uint8(blue/n>>8))
+}
+
+// GIFFrames renders all frames of the given GIF image to the keyboard
+// frames with their corresponding delays. Frames without delay are
+// shown for 100ms.
+func GIFFrames(g *gif.GIF, layout *Layout, sampling Sampling) []TimedFrame {
+
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	if canvas.Rect.Empty() && len(g.Image) > 0 {
+		canvas = image.NewRGBA(g.Image[0].Bounds())
+	}
+
+	frames := make([]TimedFrame, 0, len(g.Image))
+	for i, img := range g.Image {
+
+		var previous *image.RGBA
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(canvas.Rect)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Over)
+
+		delay := gifDefaultDelay
+		if i < len(g.Delay) && g.Delay[i] > 0 {
+			delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		frames = append(frames, TimedFrame{Frame: ImageFrame(canvas, layout, sampling), Delay: delay})
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return frames
+}
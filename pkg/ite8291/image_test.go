@@ -0,0 +1,88 @@
+package ite8291
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Image", func() {
+
+	// newImage creates an image of the given size; left half is red, right half is blue.
+	newImage := func(w, h int) *image.RGBA {
+		img := image.NewRGBA(image.Rect(10, 10, 10+w, 10+h))
+		for y := range h {
+			for x := range w {
+				c := color.RGBA{R: 0xff, A: 0xff}
+				if x >= w/2 {
+					c = color.RGBA{B: 0xff, A: 0xff}
+				}
+				img.Set(10+x, 10+y, c)
+			}
+		}
+		return img
+	}
+
+	Describe("ImageFrame", func() {
+
+		It("samples nearest pixels", func() {
+			frame := ImageFrame(newImage(42, 12), DefaultLayout(), NearestSampling)
+
+			Ω(frame.Get(Cell{Row: 0, Column: 0})).Should(Equal(NewColor(0xff, 0, 0)))
+			Ω(frame.Get(Cell{Row: 5, Column: 9})).Should(Equal(NewColor(0xff, 0, 0)))
+			Ω(frame.Get(Cell{Row: 3, Column: 10})).Should(Equal(NewColor(0, 0, 0xff)))
+		})
+
+		It("averages pixels covered by the matrix cells", func() {
+			frame := ImageFrame(newImage(21, 6), DefaultLayout(), AverageSampling)
+
+			Ω(frame.Get(Cell{Row: 0, Column: 9})).Should(Equal(NewColor(0xff, 0, 0)))
+			Ω(frame.Get(Cell{Row: 0, Column: 10})).Should(Equal(NewColor(0, 0, 0xff)))
+
+			frame = ImageFrame(newImage(2, 1), DefaultLayout(), AverageSampling)
+			Ω(frame.Get(Cell{Row: 2, Column: 0})).Should(Equal(NewColor(0xff, 0, 0)))
+			Ω(frame.Get(Cell{Row: 2, Column: 20})).Should(Equal(NewColor(0, 0, 0xff)))
+		})
+
+		It("averages pixels covered by the layout keys", func() {
+			layout := &Layout{Keys: []Key{
+				{Name: "space", Row: 1, Column: 3, X: 0, Y: 0, Width: 4, Height: 1},
+				{Name: "right", Row: 1, Column: 4, X: 3, Y: 0, Width: 1, Height: 1},
+			}}
+			frame := ImageFrame(newImage(4, 1), layout, LayoutSampling)
+
+			Ω(frame.Get(Cell{Row: 1, Column: 3})).Should(Equal(NewColor(0x7f, 0, 0x7f)))
+			Ω(frame.Get(Cell{Row: 1, Column: 4})).Should(Equal(NewColor(0, 0, 0xff)))
+			Ω(frame.Get(Cell{Row: 0, Column: 0})).Should(Equal(NewColor(0, 0, 0)))
+		})
+	})
+
+	Describe("GIFFrames", func() {
+
+		It("renders every frame with its delay", func() {
+			palette := color.Palette{color.RGBA{A: 0xff}, color.RGBA{G: 0xff, A: 0xff}, color.Transparent}
+			first := image.NewPaletted(image.Rect(0, 0, 2, 2), palette)
+			second := image.NewPaletted(image.Rect(1, 0, 2, 2), palette)
+			for y := range 2 {
+				second.SetColorIndex(1, y, 1)
+			}
+
+			frames := GIFFrames(&gif.GIF{
+				Image:  []*image.Paletted{first, second},
+				Delay:  []int{50, 0},
+				Config: image.Config{Width: 2, Height: 2},
+			}, DefaultLayout(), NearestSampling)
+
+			Ω(frames).Should(HaveLen(2))
+			Ω(frames[0].Delay).Should(Equal(500 * time.Millisecond))
+			Ω(frames[1].Delay).Should(Equal(100 * time.Millisecond))
+			Ω(frames[0].Frame.Get(Cell{Row: 0, Column: 20})).Should(Equal(NewColor(0, 0, 0)))
+			Ω(frames[1].Frame.Get(Cell{Row: 0, Column: 20})).Should(Equal(NewColor(0, 0xff, 0)))
+			Ω(frames[1].Frame.Get(Cell{Row: 0, Column: 0})).Should(Equal(NewColor(0, 0, 0)))
+		})
+	})
+})
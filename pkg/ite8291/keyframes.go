@@ -0,0 +1,220 @@
+package ite8291
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidKeyframes error indicates that a keyframe animation is
+// invalid.
+var ErrInvalidKeyframes = errors.New("invalid keyframe animation")
+
+// Keyframes provides keyframe animation. Every frame is shown for its
+// duration and, unless its easing is "none", transitions into the
+// next frame meanwhile. The easing of the frames defaults to the
+// easing of the animation or "none". Colors, zones and directions are
+// kept as text and converted by KeyframeResolver.
+type Keyframes struct {
+	Easing string     `yaml:"easing,omitempty"`
+	Frames []Keyframe `yaml:"frames"`
+}
+
+// Keyframe provides a frame of keyframe animation. Colors of the keys
+// are given by layers applied in the following order: the base color,
+// the gradient, the colors of the zones given by their names and the
+// colors of the keys given by their names in the keyboard layout.
+// Keys not colored by any layer are black.
+type Keyframe struct {
+	Duration time.Duration     `yaml:"duration"`
+	Easing   string            `yaml:"easing,omitempty"`
+	Base     string            `yaml:"base,omitempty"`
+	Gradient *KeyframeGradient `yaml:"gradient,omitempty"`
+	Zones    map[string]string `yaml:"zones,omitempty"`
+	Keys     map[string]string `yaml:"keys,omitempty"`
+}
+
+// KeyframeGradient provides gradient layer of a keyframe.
+type KeyframeGradient struct {
+	Colors    []string `yaml:"colors"`
+	Direction string   `yaml:"direction,omitempty"`
+}
+
+// KeyframeResolver converts text values of keyframes.
+type KeyframeResolver struct {
+
+	// Layout provides the keys given by names and positions of the
+	// keys used by gradients.
+	Layout *Layout
+
+	// Color converts text to a color.
+	Color func(val string) (*Color, error)
+
+	// Zone, if set, returns cells of the zone with the given name.
+	Zone func(name string) ([]Cell, error)
+
+	// Direction, if set, converts text to a gradient direction.
+	// Gradients run to the right if their direction is not given.
+	Direction func(val string) (GradientDirection, error)
+}
+
+// ReadKeyframes reads keyframe animation in YAML or JSON format from
+// the given reader. It returns ErrInvalidKeyframes if the animation
+// has no frames or a frame has no duration.
+func ReadKeyframes(r io.Reader) (*Keyframes, error) {
+
+	k := &Keyframes{}
+	if err := yaml.NewDecoder(r).Decode(k); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeyframes, err)
+	}
+
+	if len(k.Frames) == 0 {
+		return nil, fmt.Errorf("%w: no frames", ErrInvalidKeyframes)
+	}
+
+	for i := range k.Frames {
+		if k.Frames[i].Duration <= 0 {
+			return nil, fmt.Errorf("%w: frame %d must have positive duration", ErrInvalidKeyframes, i+1)
+		}
+	}
+
+	return k, nil
+}
+
+// frame renders the keyframe using the given resolver.
+//
+//nolint:cyclop
+func (f *Keyframe) frame(res *KeyframeResolver) (*Frame, error) {
+
+	frame := &Frame{}
+
+	if len(f.Base) > 0 {
+		base, err := res.Color(f.Base)
+		if err != nil {
+			return nil, fmt.Errorf("base color: %w", err)
+		}
+		frame.Fill(base)
+	}
+
+	if f.Gradient != nil {
+		g := make(Gradient, len(f.Gradient.Colors))
+		for i, val := range f.Gradient.Colors {
+			var err error
+			if g[i], err = res.Color(val); err != nil {
+				return nil, fmt.Errorf("gradient color: %w", err)
+			}
+		}
+
+		dir := LinearGradient(DirectionRight)
+		if len(f.Gradient.Direction) > 0 {
+			if res.Direction == nil {
+				return nil, fmt.Errorf("gradient direction %q is not supported", f.Gradient.Direction)
+			}
+
+			var err error
+			if dir, err = res.Direction(f.Gradient.Direction); err != nil {
+				return nil, fmt.Errorf("gradient direction: %w", err)
+			}
+		}
+
+		gf := GradientFrame(g, res.Layout, dir)
+		for i := range res.Layout.Keys {
+			frame.Set(res.Layout.Keys[i].Cell(), gf.Get(res.Layout.Keys[i].Cell()))
+		}
+	}
+
+	for name, val := range f.Zones {
+		if res.Zone == nil {
+			return nil, fmt.Errorf("zone %q is not supported", name)
+		}
+
+		cells, err := res.Zone(name)
+		if err != nil {
+			return nil, err
+		}
+
+		col, err := res.Color(val)
+		if err != nil {
+			return nil, fmt.Errorf("color of zone %q: %w", name, err)
+		}
+		frame.SetCells(cells, col)
+	}
+
+	for name, val := range f.Keys {
+		k, found := res.Layout.Key(name)
+		if !found {
+			return nil, fmt.Errorf("key %q is missing from layout %q", name, res.Layout.Name)
+		}
+
+		col, err := res.Color(val)
+		if err != nil {
+			return nil, fmt.Errorf("color of key %q: %w", name, err)
+		}
+		frame.Set(k.Cell(), col)
+	}
+
+	return frame, nil
+}
+
+// Animation returns animation playing the keyframes once or, if loop
+// is true, repeatedly. Looped animation transitions from the last
+// frame into the first one. It returns ErrInvalidKeyframes if a
+// keyframe cannot be resolved.
+func (k *Keyframes) Animation(res *KeyframeResolver, loop bool) (Animation, error) {
+
+	n := len(k.Frames)
+	frames := make([]*Frame, n)
+	eases := make([]Easing, n)
+	starts := make([]time.Duration, n+1) // start times of the frames and the total duration
+
+	for i := range k.Frames {
+		f := &k.Frames[i]
+
+		var err error
+		if frames[i], err = f.frame(res); err != nil {
+			return nil, fmt.Errorf("%w: frame %d: %w", ErrInvalidKeyframes, i+1, err)
+		}
+
+		easing := EasingNone
+		if len(f.Easing) > 0 {
+			easing = f.Easing
+		} else if len(k.Easing) > 0 {
+			easing = k.Easing
+		}
+
+		if eases[i], err = ParseEasing(easing); err != nil {
+			return nil, fmt.Errorf("%w: frame %d: %w", ErrInvalidKeyframes, i+1, err)
+		}
+
+		starts[i+1] = starts[i] + f.Duration
+	}
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+
+		total := starts[n]
+		if !loop && elapsed >= total {
+			*frame = *frames[n-1]
+			return false
+		}
+		elapsed %= total
+
+		i := 0
+		for elapsed >= starts[i+1] {
+			i++
+		}
+
+		next := (i + 1) % n
+		if !loop && next == 0 {
+			*frame = *frames[i]
+			return true
+		}
+
+		t := float64(elapsed-starts[i]) / float64(starts[i+1]-starts[i])
+		*frame = *frames[i].Mix(frames[next], eases[i](t))
+
+		return true
+	}), nil
+}
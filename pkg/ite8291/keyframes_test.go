@@ -0,0 +1,146 @@
+package ite8291
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Keyframes", func() {
+
+	black, white := NewColor(0, 0, 0), NewColor(0xff, 0xff, 0xff)
+	red, blue := NewColor(0xff, 0, 0), NewColor(0, 0, 0xff)
+	layout := &Layout{Name: "test", Keys: []Key{
+		{Name: "esc", Row: 0, Column: 0, X: 0, Y: 0, Width: 1, Height: 1},
+		{Name: "f1", Row: 0, Column: 1, X: 1, Y: 0, Width: 1, Height: 1},
+	}}
+	colors := map[string]*Color{"black": black, "white": white, "red": red, "blue": blue}
+
+	var res *KeyframeResolver
+
+	BeforeEach(func() {
+		res = &KeyframeResolver{
+			Layout: layout,
+			Color: func(val string) (*Color, error) {
+				if c, found := colors[val]; found {
+					return c, nil
+				}
+				return ParseColor(val)
+			},
+			Zone: func(name string) ([]Cell, error) {
+				if name == "corner" {
+					return []Cell{{Row: 5, Column: 20}}, nil
+				}
+				return nil, ErrInvalidColorFormat
+			},
+		}
+	})
+
+	// animation reads keyframes from the given text and returns their animation.
+	animation := func(text string, loop bool) Animation {
+		GinkgoHelper()
+
+		k, err := ReadKeyframes(strings.NewReader(text))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		anim, err := k.Animation(res, loop)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		return anim
+	}
+
+	// render renders the animation at the given time. It also returns
+	// whether the animation continues.
+	render := func(anim Animation, elapsed time.Duration) (*Frame, bool) {
+		frame := &Frame{}
+		more := anim.Render(frame, elapsed)
+		return frame, more
+	}
+
+	// frameAt returns frame of the continuing animation at the given time.
+	frameAt := func(anim Animation, elapsed time.Duration) *Frame {
+		GinkgoHelper()
+
+		frame, more := render(anim, elapsed)
+		Ω(more).Should(BeTrue())
+
+		return frame
+	}
+
+	It("layers base color, gradient, zones and keys", func() {
+		anim := animation(`{"frames": [{"duration": "1s", "base": "white",
+			"gradient": {"colors": ["red", "blue"]}, "zones": {"corner": "red"}, "keys": {"f1": "#0F0"}}]}`, false)
+
+		frame := frameAt(anim, 0)
+		Ω(frame.Get(Cell{Row: 0, Column: 0})).Should(Equal(red))
+		Ω(frame.Get(Cell{Row: 0, Column: 1})).Should(Equal(NewColor(0, 0xff, 0)))
+		Ω(frame.Get(Cell{Row: 5, Column: 20})).Should(Equal(red))
+		Ω(frame.Get(Cell{Row: 3, Column: 3})).Should(Equal(white))
+	})
+
+	It("holds frames without easing and stops after the last one", func() {
+		anim := animation(`
+frames:
+  - {duration: 100ms, base: red}
+  - {duration: 200ms, base: blue}
+`, false)
+
+		Ω(frameAt(anim, 50*time.Millisecond)).Should(Equal(NewFrame(red)))
+		Ω(frameAt(anim, 150*time.Millisecond)).Should(Equal(NewFrame(blue)))
+		Ω(frameAt(anim, 299*time.Millisecond)).Should(Equal(NewFrame(blue)))
+
+		frame, more := render(anim, time.Second)
+		Ω(frame).Should(Equal(NewFrame(blue)))
+		Ω(more).Should(BeFalse())
+	})
+
+	It("interpolates frames with easing and loops", func() {
+		anim := animation(`
+easing: linear
+frames:
+  - {duration: 100ms, base: black}
+  - {duration: 100ms, base: white, easing: ease-in}
+`, true)
+
+		Ω(frameAt(anim, 50*time.Millisecond)).Should(Equal(NewFrame(black).Mix(NewFrame(white), 0.5)))
+		Ω(frameAt(anim, 150*time.Millisecond)).Should(Equal(NewFrame(white).Mix(NewFrame(black), 0.125)))
+
+		Ω(frameAt(anim, 250*time.Millisecond)).Should(Equal(NewFrame(black).Mix(NewFrame(white), 0.5)))
+	})
+
+	DescribeTable("invalid keyframes",
+		func(text string) {
+			k, err := ReadKeyframes(strings.NewReader(text))
+			if err == nil {
+				_, err = k.Animation(res, false)
+			}
+			Ω(err).Should(MatchError(ErrInvalidKeyframes))
+		},
+		Entry("not keyframes", "frames: 1\n"),
+		Entry("no frames", "frames: []\n"),
+		Entry("no duration", "frames: [{base: red}]\n"),
+		Entry("invalid color", "frames: [{duration: 1s, base: nocolor}]\n"),
+		Entry("invalid gradient color", "frames: [{duration: 1s, gradient: {colors: [red, nocolor]}}]\n"),
+		Entry("unsupported direction", "frames: [{duration: 1s, gradient: {colors: [red], direction: up}}]\n"),
+		Entry("unknown zone", "frames: [{duration: 1s, zones: {middle: red}}]\n"),
+		Entry("unknown key", "frames: [{duration: 1s, keys: {space: red}}]\n"),
+		Entry("unknown easing", "easing: bounce\nframes: [{duration: 1s}]\n"),
+	)
+})
+
+var _ = DescribeTable("Easing",
+	func(name string, at map[float64]float64) {
+		e, err := ParseEasing(name)
+		Ω(err).ShouldNot(HaveOccurred())
+		for t, p := range at {
+			Ω(e(t)).Should(BeNumerically("~", p, 1e-9))
+		}
+	},
+	Entry(nil, "none", map[float64]float64{0: 0, 0.5: 0, 1: 0}),
+	Entry(nil, "Linear", map[float64]float64{0: 0, 0.3: 0.3, 1: 1}),
+	Entry(nil, "ease-in", map[float64]float64{0: 0, 0.5: 0.125, 1: 1}),
+	Entry(nil, "ease-out", map[float64]float64{0: 0, 0.5: 0.875, 1: 1}),
+	Entry(nil, "ease-in-out", map[float64]float64{0: 0, 0.5: 0.5, 1: 1}),
+)
@@ -0,0 +1,91 @@
+package ite8291
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidKeymap error indicates that a keymap is invalid.
+var ErrInvalidKeymap = errors.New("invalid keymap")
+
+// Keymap provides colors of the keys given by their names in a
+// keyboard layout. Keys missing from the keymap have the base color.
+// Colors are kept as text e.g. "#FF0000" or a name of a configured
+// color.
+type Keymap struct {
+	Base string            `yaml:"base,omitempty"`
+	Keys map[string]string `yaml:"keys"`
+}
+
+// NewKeymap creates keymap with colors of all keys of the given
+// layout taken from the given frame.
+func NewKeymap(frame *Frame, layout *Layout) *Keymap {
+
+	m := &Keymap{Keys: make(map[string]string, len(layout.Keys))}
+	for i := range layout.Keys {
+		m.Keys[layout.Keys[i].Name] = frame.Get(layout.Keys[i].Cell()).String()
+	}
+
+	return m
+}
+
+// ReadKeymap reads keymap in YAML format from the given reader.
+func ReadKeymap(r io.Reader) (*Keymap, error) {
+
+	m := &Keymap{}
+	if err := yaml.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeymap, err)
+	}
+
+	return m, nil
+}
+
+// Write writes keymap in YAML format to the given writer.
+func (m *Keymap) Write(w io.Writer) error {
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// Frame returns frame with colors of the keymap keys. Key names are
+// looked up in the given layout; colors are converted by the given
+// parse function. Cells without a key in the keymap are black unless
+// the keymap has a base color. Frame returns ErrInvalidKeymap if a
+// key is missing from the layout or its color is invalid.
+func (m *Keymap) Frame(layout *Layout, parse func(color string) (*Color, error)) (*Frame, error) {
+
+	frame := NewFrame(NewColor(0, 0, 0))
+	if len(m.Base) > 0 {
+		base, err := parse(m.Base)
+		if err != nil {
+			return nil, fmt.Errorf("%w: base color: %w", ErrInvalidKeymap, err)
+		}
+		frame.Fill(base)
+	}
+
+	for name, val := range m.Keys {
+
+		k, found := layout.Key(name)
+		if !found {
+			return nil, fmt.Errorf("%w: key %q is missing from layout %q", ErrInvalidKeymap, name, layout.Name)
+		}
+
+		col, err := parse(val)
+		if err != nil {
+			return nil, fmt.Errorf("%w: color of key %q: %w", ErrInvalidKeymap, name, err)
+		}
+
+		frame.Set(k.Cell(), col)
+	}
+
+	return frame, nil
+}
@@ -0,0 +1,53 @@
+package ite8291
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Keymap", func() {
+
+	red, blue := NewColor(0xff, 0, 0), NewColor(0, 0, 0xff)
+
+	It("reads keymap with base color", func() {
+		m, err := ReadKeymap(strings.NewReader("base: \"#0000FF\"\nkeys:\n  r1c2: \"#F00\"\n"))
+		Ω(err).Should(Succeed())
+
+		frame, err := m.Frame(DefaultLayout(), ParseColor)
+		Ω(err).Should(Succeed())
+
+		expected := NewFrame(blue)
+		expected.Set(Cell{Row: 1, Column: 2}, red)
+		Ω(frame).Should(Equal(expected))
+	})
+
+	It("writes and reads back keymap of a frame", func() {
+		frame := NewFrame(blue)
+		frame.Set(Cell{Row: 5, Column: 20}, red)
+
+		var buf bytes.Buffer
+		Ω(NewKeymap(frame, DefaultLayout()).Write(&buf)).Should(Succeed())
+		Ω(buf.String()).Should(ContainSubstring(`r5c20: '#FF0000'`))
+
+		m, err := ReadKeymap(&buf)
+		Ω(err).Should(Succeed())
+		Ω(m.Frame(DefaultLayout(), ParseColor)).Should(Equal(frame))
+	})
+
+	DescribeTable("invalid keymap",
+		func(data, msg string) {
+			m, err := ReadKeymap(strings.NewReader(data))
+			if err == nil {
+				_, err = m.Frame(DefaultLayout(), ParseColor)
+			}
+			Ω(err).Should(SatisfyAll(MatchError(ErrInvalidKeymap), MatchError(ContainSubstring(msg))))
+		},
+		Entry("unknown key", "keys:\n  esc: red\n", `key "esc" is missing`),
+		Entry("invalid color", "keys:\n  r0c0: redish\n", `color of key "r0c0"`),
+		Entry("invalid base", "base: nocolor\n", "base color"),
+		Entry("invalid yaml", "keys: [\n", "invalid keymap"),
+	)
+})
@@ -0,0 +1,131 @@
+package ite8291
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidLayout error indicates that a keyboard layout is invalid.
+var ErrInvalidLayout = errors.New("invalid keyboard layout")
+
+// Key provides a physical key of the keyboard backlight: its name,
+// input key code, cell of the keyboard matrix and geometry. X, Y,
+// Width and Height are given in key units, i.e. width and height of
+// a regular key are 1.
+type Key struct {
+	Name   string  `yaml:"name"`
+	Code   uint16  `yaml:"code,omitempty"`
+	Row    int     `yaml:"row"`
+	Column int     `yaml:"column"`
+	X      float64 `yaml:"x"`
+	Y      float64 `yaml:"y"`
+	Width  float64 `yaml:"width"`
+	Height float64 `yaml:"height"`
+}
+
+// Cell returns cell of the keyboard matrix the key belongs to.
+func (k *Key) Cell() Cell {
+	return Cell{Row: k.Row, Column: k.Column}
+}
+
+// Center returns coordinates of the key center.
+func (k *Key) Center() (x, y float64) {
+	return k.X + k.Width/2, k.Y + k.Height/2
+}
+
+// Layout provides physical layout of the keyboard backlight keys.
+// Cells of the keyboard matrix without a key are either not listed
+// or listed as Empty.
+type Layout struct {
+	Name  string `yaml:"name"`
+	Keys  []Key  `yaml:"keys"`
+	Empty []Cell `yaml:"empty,omitempty"`
+}
+
+// DefaultLayout returns layout where every cell of the keyboard
+// matrix is a regular key placed at the cell position. The keys are
+// named "rNcM" where N and M are row and column of the cell.
+func DefaultLayout() *Layout {
+
+	l := &Layout{Name: "matrix"}
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			l.Keys = append(l.Keys, Key{
+				Name: fmt.Sprintf("r%dc%d", i, j), Row: i, Column: j,
+				X: float64(j), Y: float64(i), Width: 1, Height: 1,
+			})
+		}
+	}
+
+	return l
+}
+
+// ReadLayout reads layout in YAML format from the given reader and
+// validates it. It returns ErrInvalidLayout if a key or an empty cell
+// is outside of the keyboard matrix or a key has no size.
+func ReadLayout(r io.Reader) (*Layout, error) {
+
+	l := &Layout{}
+	if err := yaml.NewDecoder(r).Decode(l); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLayout, err)
+	}
+
+	for i := range l.Keys {
+		k := &l.Keys[i]
+		if !k.Cell().Valid() {
+			return nil, fmt.Errorf("%w: key %q is outside of the keyboard matrix", ErrInvalidLayout, k.Name)
+		}
+
+		if k.Width <= 0 || k.Height <= 0 {
+			return nil, fmt.Errorf("%w: key %q must have positive width and height", ErrInvalidLayout, k.Name)
+		}
+	}
+
+	for _, c := range l.Empty {
+		if !c.Valid() {
+			return nil, fmt.Errorf("%w: empty cell %d,%d is outside of the keyboard matrix", ErrInvalidLayout,
+				c.Row, c.Column)
+		}
+	}
+
+	return l, nil
+}
+
+// Write writes layout in YAML format to the given writer.
+func (l *Layout) Write(w io.Writer) error {
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(l); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// Key returns key with the given name.
+func (l *Layout) Key(name string) (*Key, bool) {
+
+	for i := range l.Keys {
+		if l.Keys[i].Name == name {
+			return &l.Keys[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// Bounds returns width and height of the rectangle containing all
+// keys of the layout.
+func (l *Layout) Bounds() (width, height float64) {
+
+	for _, k := range l.Keys {
+		width, height = max(width, k.X+k.Width), max(height, k.Y+k.Height)
+	}
+
+	return width, height
+}
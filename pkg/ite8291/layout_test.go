@@ -0,0 +1,50 @@
+package ite8291
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Layout", func() {
+
+	It("default layout covers the keyboard matrix", func() {
+		l := DefaultLayout()
+
+		Ω(l.Keys).Should(HaveLen(RowsNumber * ColumnsNumber))
+		w, h := l.Bounds()
+		Ω(w).Should(BeNumerically("==", ColumnsNumber))
+		Ω(h).Should(BeNumerically("==", RowsNumber))
+
+		k, found := l.Key("r2c3")
+		Ω(found).Should(BeTrue())
+		Ω(k.Cell()).Should(Equal(Cell{Row: 2, Column: 3}))
+	})
+
+	It("is written and read back", func() {
+		l := &Layout{Name: "test", Keys: []Key{
+			{Name: "esc", Code: 1, Row: 0, Column: 0, X: 0, Y: 0, Width: 1, Height: 1},
+			{Name: "space", Code: 57, Row: 5, Column: 8, X: 4, Y: 5, Width: 6.25, Height: 1},
+		}, Empty: []Cell{{Row: 5, Column: 0}}}
+
+		buf := &bytes.Buffer{}
+		Ω(l.Write(buf)).Should(Succeed())
+
+		read, err := ReadLayout(buf)
+		Ω(err).Should(Succeed())
+		Ω(read).Should(Equal(l))
+	})
+
+	DescribeTable("invalid layouts",
+		func(s string) {
+			_, err := ReadLayout(strings.NewReader(s))
+			Ω(err).Should(MatchError(ErrInvalidLayout))
+		},
+		Entry("outside of matrix", "keys:\n  - {name: a, row: 6, column: 0, width: 1, height: 1}\n"),
+		Entry("without size", "keys:\n  - {name: a, row: 0, column: 0}\n"),
+		Entry("not a layout", "keys: 12\n"),
+		Entry("empty cell outside of matrix", "keys: []\nempty:\n  - {row: 0, column: 21}\n"),
+	)
+})
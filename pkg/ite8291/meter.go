@@ -0,0 +1,57 @@
+package ite8291
+
+import "math"
+
+// Meter maps a stream of values to colors of a ramp. Values are
+// smoothed and the shown color changes only if the smoothed value
+// moves far enough from the value shown last.
+type Meter struct {
+
+	// Min and Max give the range of the values mapped to the ramp from
+	// its first to its last color. Values out of the range are clipped.
+	Min, Max float64
+
+	// Ramp provides the colors of the values.
+	Ramp Gradient
+
+	// Smoothing in [0, 1) is the weight of the smoothed value when the
+	// next value is added; 0 shows values as they are.
+	Smoothing float64
+
+	// Hysteresis is the distance the smoothed value must move from the
+	// value shown last to change the color.
+	Hysteresis float64
+
+	level, shown float64
+	started      bool
+}
+
+// Update adds the given value and returns the color of the smoothed
+// value. changed is false if the color shown last is kept.
+func (m *Meter) Update(value float64) (color *Color, changed bool) {
+
+	value = max(m.Min, min(m.Max, value))
+
+	if !m.started {
+		m.level, m.shown, m.started = value, value, true
+		return m.color(), true
+	}
+
+	m.level = m.Smoothing*m.level + (1-m.Smoothing)*value
+	if m.level == m.shown || math.Abs(m.level-m.shown) < m.Hysteresis {
+		return m.color(), false
+	}
+	m.shown = m.level
+
+	return m.color(), true
+}
+
+// color returns color of the value shown last.
+func (m *Meter) color() *Color {
+
+	if m.Max <= m.Min {
+		return m.Ramp.At(0)
+	}
+
+	return m.Ramp.At((m.shown - m.Min) / (m.Max - m.Min))
+}
@@ -0,0 +1,67 @@
+package ite8291
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Meter", func() {
+
+	green, red := NewColor(0, 0xff, 0), NewColor(0xff, 0, 0)
+	ramp := Gradient{green, red}
+
+	It("maps values to the ramp", func() {
+
+		m := &Meter{Min: 10, Max: 20, Ramp: ramp}
+
+		color, changed := m.Update(10)
+		Ω(changed).Should(BeTrue())
+		Ω(color).Should(Equal(green))
+
+		color, changed = m.Update(15)
+		Ω(changed).Should(BeTrue())
+		Ω(color).Should(Equal(ramp.At(0.5)))
+
+		color, changed = m.Update(15)
+		Ω(changed).Should(BeFalse())
+		Ω(color).Should(Equal(ramp.At(0.5)))
+	})
+
+	It("clips values", func() {
+
+		m := &Meter{Min: 0, Max: 1, Ramp: ramp}
+
+		color, _ := m.Update(-5)
+		Ω(color).Should(Equal(green))
+
+		color, _ = m.Update(5)
+		Ω(color).Should(Equal(red))
+	})
+
+	It("keeps the color within hysteresis", func() {
+
+		m := &Meter{Min: 0, Max: 100, Ramp: ramp, Hysteresis: 5}
+		m.Update(50)
+
+		color, changed := m.Update(54)
+		Ω(changed).Should(BeFalse())
+		Ω(color).Should(Equal(ramp.At(0.5)))
+
+		color, changed = m.Update(45)
+		Ω(changed).Should(BeTrue())
+		Ω(color).Should(Equal(ramp.At(0.45)))
+	})
+
+	It("smooths values", func() {
+
+		m := &Meter{Min: 0, Max: 100, Ramp: ramp, Smoothing: 0.5}
+		m.Update(0)
+
+		color, changed := m.Update(100)
+		Ω(changed).Should(BeTrue())
+		Ω(color).Should(Equal(ramp.At(0.5)))
+
+		color, _ = m.Update(100)
+		Ω(color).Should(Equal(ramp.At(0.75)))
+	})
+})
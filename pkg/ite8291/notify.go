@@ -0,0 +1,226 @@
+package ite8291
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrUnknownNotifyPattern error indicates that a notification pattern
+// with the given name does not exist.
+var ErrUnknownNotifyPattern = errors.New("unknown notification pattern")
+
+// ErrInvalidMorseText error indicates that a text cannot be sent in
+// morse code.
+var ErrInvalidMorseText = errors.New("invalid morse text")
+
+// notification patterns names.
+const (
+	// NotifyBlink - name of the pattern turning all keys on and off.
+	NotifyBlink = "blink"
+	// NotifyPulse - name of the pattern smoothly lighting and dimming
+	// all keys.
+	NotifyPulse = "pulse"
+	// NotifySweep - name of the pattern sweeping a bar over the keys
+	// from the left to the right.
+	NotifySweep = "sweep"
+	// NotifyMorse - name of the pattern sending a text in morse code.
+	NotifyMorse = "morse"
+)
+
+// NotifyPatternNames returns sorted names of the notification patterns.
+func NotifyPatternNames() []string {
+	return []string{NotifyBlink, NotifyMorse, NotifyPulse, NotifySweep}
+}
+
+// NotifyOptions provides parameters of the notification patterns.
+type NotifyOptions struct {
+
+	// Color of the lit keys. It defaults to white.
+	Color *Color
+
+	// Count is number of repetitions of the pattern. It defaults to 1.
+	Count int
+
+	// Period is duration of one blink, pulse or sweep. A morse dot
+	// lasts a fifth of it. It defaults to 500 milliseconds.
+	Period time.Duration
+
+	// Text is sent by the morse pattern.
+	Text string
+}
+
+// notify patterns parameters.
+const (
+	// notifyPeriodDefault - default duration of one repetition of the notification patterns.
+	notifyPeriodDefault = 500 * time.Millisecond
+	// morseUnits - number of morse dots in the period.
+	morseUnits = 5
+	// sweepWidth - half width in columns of the swept bar.
+	sweepWidth = 2.0
+)
+
+// morseCodes maps characters to their morse codes.
+var morseCodes = map[rune]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.", 'G': "--.", 'H': "....",
+	'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..", 'M': "--", 'N': "-.", 'O': "---", 'P': ".--.",
+	'Q': "--.-", 'R': ".-.", 'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-",
+	'Y': "-.--", 'Z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-", '5': ".....", '6': "-....",
+	'7': "--...", '8': "---..", '9': "----.",
+	'.': ".-.-.-", ',': "--..--", '?': "..--..", '!': "-.-.--", '/': "-..-.", '-': "-....-",
+	'=': "-...-", '+': ".-.-.", '@': ".--.-.", ':': "---...", '\'': ".----.", '"': ".-..-.",
+}
+
+// morseTimeline returns durations in morse dots of the alternating
+// lit and dark parts sending the text, starting with a lit part. The
+// text is followed by a word gap.
+func morseTimeline(text string) ([]int, error) {
+
+	var parts []int
+	words := strings.Fields(strings.ToUpper(text))
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%w: no text to send", ErrInvalidMorseText)
+	}
+
+	for _, word := range words {
+		chars := []rune(word)
+		for j, r := range chars {
+			code, found := morseCodes[r]
+			if !found {
+				return nil, fmt.Errorf("%w: unsupported character %q", ErrInvalidMorseText, r)
+			}
+
+			for k, symbol := range code {
+				lit, dark := 1, 1 // dot and gap between the symbols of a character
+				if symbol == '-' {
+					lit = 3
+				}
+				switch {
+				case k < len(code)-1:
+				case j < len(chars)-1:
+					dark = 3 // gap between the characters of a word
+				default:
+					dark = 7 // gap between the words
+				}
+
+				parts = append(parts, lit, dark)
+			}
+		}
+	}
+
+	return parts, nil
+}
+
+// NewNotifyAnimation creates the notification pattern with the given
+// name. The animation is over once the pattern is repeated the given
+// number of times; its last frame is black. It returns
+// ErrUnknownNotifyPattern if there is no such pattern and
+// ErrInvalidMorseText if the text of the morse pattern cannot be sent.
+// Options missing from opts take their default values.
+//
+// The following patterns are supported:
+//   - blink - all keys are lit for the first half of the period;
+//   - pulse - all keys are smoothly lit and dimmed;
+//   - sweep - a bar sweeps over the keys from the left to the right;
+//   - morse - all keys send the text in morse code.
+func NewNotifyAnimation(name string, opts NotifyOptions) (Animation, error) {
+
+	if opts.Color == nil {
+		opts.Color = NewColor(0xff, 0xff, 0xff)
+	}
+	if opts.Count <= 0 {
+		opts.Count = 1
+	}
+	if opts.Period <= 0 {
+		opts.Period = notifyPeriodDefault
+	}
+
+	black := NewColor(0, 0, 0)
+	total := opts.Period * time.Duration(opts.Count)
+
+	switch name {
+	case NotifyBlink:
+		return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+			if elapsed >= total {
+				frame.Fill(black)
+				return false
+			}
+
+			if phase(elapsed, opts.Period) < 0.5 {
+				frame.Fill(opts.Color)
+			} else {
+				frame.Fill(black)
+			}
+			return true
+		}), nil
+
+	case NotifyPulse:
+		return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+			if elapsed >= total {
+				frame.Fill(black)
+				return false
+			}
+
+			c := dim(opts.Color, math.Pow(math.Sin(math.Pi*phase(elapsed, opts.Period)), 2))
+			frame.Fill(&c)
+			return true
+		}), nil
+
+	case NotifySweep:
+		return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+			frame.Fill(black)
+			if elapsed >= total {
+				return false
+			}
+
+			// the bar enters from the left and leaves to the right
+			pos := phase(elapsed, opts.Period)*(ColumnsNumber+2*sweepWidth) - sweepWidth
+			for j := range ColumnsNumber {
+				if level := 1 - math.Abs(float64(j)-pos)/sweepWidth; level > 0 {
+					c := dim(opts.Color, level)
+					for i := range RowsNumber {
+						frame[i][j] = c
+					}
+				}
+			}
+			return true
+		}), nil
+
+	case NotifyMorse:
+		parts, err := morseTimeline(opts.Text)
+		if err != nil {
+			return nil, err
+		}
+
+		dot := opts.Period / morseUnits
+		var units int
+		for _, p := range parts {
+			units += p
+		}
+		total = dot * time.Duration(units*opts.Count)
+
+		return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+			frame.Fill(black)
+			if elapsed >= total {
+				return false
+			}
+
+			unit := int(elapsed/dot) % units
+			for i, p := range parts {
+				if unit < p {
+					if i%2 == 0 {
+						frame.Fill(opts.Color)
+					}
+					break
+				}
+				unit -= p
+			}
+			return true
+		}), nil
+	}
+
+	return nil, fmt.Errorf("%w %q; expected one of %q", ErrUnknownNotifyPattern, name, NotifyPatternNames())
+}
@@ -0,0 +1,105 @@
+package ite8291
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notification patterns", func() {
+
+	red, black := NewColor(0xff, 0, 0), NewColor(0, 0, 0)
+	period := time.Second
+
+	// render renders the pattern at the given time.
+	render := func(a Animation, elapsed time.Duration) *Frame {
+		frame := &Frame{}
+		a.Render(frame, elapsed)
+		return frame
+	}
+
+	// newPattern creates the pattern with the given name and options.
+	newPattern := func(name string, opts NotifyOptions) Animation {
+		a, err := NewNotifyAnimation(name, opts)
+		Ω(err).ShouldNot(HaveOccurred())
+		return a
+	}
+
+	It("blinks the given number of times", func() {
+
+		a := newPattern(NotifyBlink, NotifyOptions{Color: red, Count: 2, Period: period})
+
+		for _, at := range []time.Duration{0, 400 * time.Millisecond, 1100 * time.Millisecond} {
+			Ω(render(a, at)).Should(Equal(NewFrame(red)))
+		}
+		for _, at := range []time.Duration{600 * time.Millisecond, 1600 * time.Millisecond} {
+			Ω(render(a, at)).Should(Equal(NewFrame(black)))
+		}
+
+		frame := &Frame{}
+		Ω(a.Render(frame, 2*period)).Should(BeFalse())
+		Ω(frame).Should(Equal(NewFrame(black)))
+	})
+
+	It("pulses smoothly", func() {
+
+		a := newPattern(NotifyPulse, NotifyOptions{Color: red, Period: period})
+
+		Ω(render(a, 0)).Should(Equal(NewFrame(black)))
+		Ω(render(a, period/2)).Should(Equal(NewFrame(red)))
+
+		frame := render(a, period/4)
+		Ω(frame[0][0].Red).Should(And(BeNumerically(">", 0), BeNumerically("<", 0xff)))
+	})
+
+	It("sweeps a bar from the left to the right", func() {
+
+		a := newPattern(NotifySweep, NotifyOptions{Color: red, Period: period})
+
+		lit := func(frame *Frame) []int {
+			var cols []int
+			for j := range ColumnsNumber {
+				if frame[RowsNumber-1][j] != *black {
+					cols = append(cols, j)
+				}
+			}
+			return cols
+		}
+
+		early, late := render(a, period/4), render(a, 3*period/4)
+		Ω(lit(early)).ShouldNot(BeEmpty())
+		Ω(lit(late)).ShouldNot(BeEmpty())
+		Ω(lit(late)[0]).Should(BeNumerically(">", lit(early)[len(lit(early))-1]))
+	})
+
+	It("sends text in morse code", func() {
+
+		// E is a dot followed by a word gap: 1 + 7 dots
+		dot := period / morseUnits
+		a := newPattern(NotifyMorse, NotifyOptions{Color: red, Period: period, Text: "e", Count: 2})
+
+		Ω(render(a, dot/2)).Should(Equal(NewFrame(red)))
+		Ω(render(a, dot*3/2)).Should(Equal(NewFrame(black)))
+		Ω(render(a, 8*dot+dot/2)).Should(Equal(NewFrame(red)))
+
+		Ω(a.Render(&Frame{}, 16*dot)).Should(BeFalse())
+	})
+
+	It("times morse code", func() {
+
+		parts, err := morseTimeline("at e")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(parts).Should(Equal([]int{1, 1, 3, 3, 3, 7, 1, 7}))
+	})
+
+	DescribeTable("fails",
+		func(name, text string, expected error) {
+			_, err := NewNotifyAnimation(name, NotifyOptions{Text: text})
+			Ω(err).Should(MatchError(expected))
+		},
+		Entry("on unknown pattern", "flash", "", ErrUnknownNotifyPattern),
+		Entry("on empty morse text", NotifyMorse, " ", ErrInvalidMorseText),
+		Entry("on unsupported morse character", NotifyMorse, "S#S", ErrInvalidMorseText),
+	)
+})
@@ -0,0 +1,158 @@
+package ite8291
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrUnknownPaletteCycle error indicates that a palette cycle with the
+// given name does not exist.
+var ErrUnknownPaletteCycle = errors.New("unknown palette cycle")
+
+// ErrInvalidPalette error indicates that a palette has no colors.
+var ErrInvalidPalette = errors.New("invalid palette")
+
+// PaletteSize - number of the predefined colors.
+const PaletteSize = CustomColorNumMaxValue - CustomColorNumMinValue + 1
+
+// palette cycles names.
+const (
+	// PaletteRotate - name of the cycle rotating the colors of the
+	// first palette over the predefined colors.
+	PaletteRotate = "rotate"
+	// PaletteHue - name of the cycle shifting hues of the colors of
+	// the first palette.
+	PaletteHue = "hue"
+	// PaletteStep - name of the cycle stepping through the palettes.
+	PaletteStep = "step"
+)
+
+// PaletteCycleNames returns names of the palette cycles.
+func PaletteCycleNames() []string {
+	return []string{PaletteHue, PaletteRotate, PaletteStep}
+}
+
+// PaletteCycle provides the predefined colors at the given time
+// elapsed since the start of the cycle.
+type PaletteCycle func(elapsed time.Duration) [PaletteSize]Color
+
+// NewPaletteCycle creates palette cycle with the given name repeating
+// every period. Palettes are lists of colors filling the predefined
+// colors repeatedly. The colors change smoothly. It returns
+// ErrUnknownPaletteCycle if there is no such cycle and
+// ErrInvalidPalette if there are no palettes or a palette has no
+// colors.
+//
+// The following cycles are supported:
+//   - rotate - colors of the first palette move by one predefined
+//     color at a time, passing all of them in the period;
+//   - hue - hues of the colors of the first palette shift around the
+//     color wheel once in the period;
+//   - step - the palettes follow each other, passing all of them in
+//     the period.
+func NewPaletteCycle(name string, palettes [][]*Color, period time.Duration) (PaletteCycle, error) {
+
+	if len(palettes) == 0 {
+		return nil, fmt.Errorf("%w: no palettes", ErrInvalidPalette)
+	}
+
+	filled := make([][PaletteSize]Color, len(palettes))
+	for i, p := range palettes {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("%w: palette %d has no colors", ErrInvalidPalette, i+1)
+		}
+		for j := range PaletteSize {
+			filled[i][j] = *p[j%len(p)]
+		}
+	}
+
+	switch name {
+	case PaletteRotate:
+		return func(elapsed time.Duration) [PaletteSize]Color {
+			pos := phase(elapsed, period) * PaletteSize
+			k, t := int(pos), pos-math.Floor(pos)
+
+			var colors [PaletteSize]Color
+			for i := range PaletteSize {
+				from, to := &filled[0][(i+k)%PaletteSize], &filled[0][(i+k+1)%PaletteSize]
+				colors[i] = fade(from, to, t)
+			}
+			return colors
+		}, nil
+
+	case PaletteHue:
+		return func(elapsed time.Duration) [PaletteSize]Color {
+			shift := 360 * phase(elapsed, period)
+
+			var colors [PaletteSize]Color
+			for i := range PaletteSize {
+				hsv := filled[0][i].HSV()
+				hsv.H += shift
+				colors[i] = *hsv.Color()
+			}
+			return colors
+		}, nil
+
+	case PaletteStep:
+		return func(elapsed time.Duration) [PaletteSize]Color {
+			pos := phase(elapsed, period) * float64(len(filled))
+			k, t := int(pos), pos-math.Floor(pos)
+
+			var colors [PaletteSize]Color
+			for i := range PaletteSize {
+				colors[i] = fade(&filled[k][i], &filled[(k+1)%len(filled)][i], t)
+			}
+			return colors
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w %q; expected one of %q", ErrUnknownPaletteCycle, name, PaletteCycleNames())
+}
+
+// PaletteWriter interface abstracts a keyboard backlight with
+// predefined colors. It is implemented by Controller.
+type PaletteWriter interface {
+
+	// SetColor sets predefined color specified by its colorNum to the
+	// given color.
+	SetColor(colorNum byte, color *Color) error
+}
+
+// CyclePalette sets the predefined colors to the colors of the cycle
+// fps times a second until ctx is done. Only the changed colors are
+// set. fps is clipped to [FPSMinValue, FPSMaxValue]. CyclePalette
+// returns nil once ctx is done.
+func CyclePalette(ctx context.Context, w PaletteWriter, cycle PaletteCycle, fps int) error {
+
+	fps = max(FPSMinValue, min(FPSMaxValue, fps))
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+
+	var shown [PaletteSize]Color
+	written := false
+	start := time.Now()
+
+	for {
+		colors := cycle(time.Since(start))
+		for i := range PaletteSize {
+			if written && colors[i] == shown[i] {
+				continue
+			}
+
+			if err := w.SetColor(byte(i+CustomColorNumMinValue), &colors[i]); err != nil {
+				return err
+			}
+			shown[i] = colors[i]
+		}
+		written = true
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
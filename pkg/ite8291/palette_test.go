@@ -0,0 +1,111 @@
+package ite8291
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// paletteWriterStub collects set predefined colors.
+type paletteWriterStub struct {
+	nums   []byte
+	colors []Color
+	err    error
+}
+
+// SetColor collects the given predefined color.
+func (w *paletteWriterStub) SetColor(colorNum byte, color *Color) error {
+	w.nums = append(w.nums, colorNum)
+	w.colors = append(w.colors, *color)
+	return w.err
+}
+
+var _ = Describe("Palette cycles", func() {
+
+	red, green, blue := NewColor(0xff, 0, 0), NewColor(0, 0xff, 0), NewColor(0, 0, 0xff)
+	period := 7 * time.Second
+
+	// palette returns predefined colors filled by the given colors.
+	palette := func(colors ...*Color) [PaletteSize]Color {
+		var p [PaletteSize]Color
+		for i := range PaletteSize {
+			p[i] = *colors[i%len(colors)]
+		}
+		return p
+	}
+
+	// newCycle creates the cycle with the given name and palettes.
+	newCycle := func(name string, palettes ...[]*Color) PaletteCycle {
+		c, err := NewPaletteCycle(name, palettes, period)
+		Ω(err).ShouldNot(HaveOccurred())
+		return c
+	}
+
+	It("rotates the colors of the first palette", func() {
+
+		c := newCycle(PaletteRotate, []*Color{red, green, blue, red, green, blue, NewColor(0, 0, 0)})
+
+		Ω(c(0)).Should(Equal(palette(red, green, blue, red, green, blue, NewColor(0, 0, 0))))
+		Ω(c(time.Second)).Should(Equal(palette(green, blue, red, green, blue, NewColor(0, 0, 0), red)))
+		Ω(c(period)).Should(Equal(c(0)))
+
+		half := c(500 * time.Millisecond)
+		Ω(half[0].Red).Should(And(BeNumerically(">", 0), BeNumerically("<", 0xff)))
+		Ω(half[0].Green).Should(And(BeNumerically(">", 0), BeNumerically("<", 0xff)))
+	})
+
+	It("shifts hues of the colors of the first palette", func() {
+
+		c := newCycle(PaletteHue, []*Color{red, green}, []*Color{blue})
+
+		Ω(c(0)).Should(Equal(palette(red, green)))
+		Ω(c(period / 3)).Should(Equal(palette(green, blue)))
+	})
+
+	It("steps through the palettes", func() {
+
+		c := newCycle(PaletteStep, []*Color{red}, []*Color{green, blue})
+
+		Ω(c(0)).Should(Equal(palette(red)))
+		Ω(c(period / 2)).Should(Equal(palette(green, blue)))
+		Ω(c(period / 4)[1]).Should(Equal(fade(red, blue, 0.5)))
+	})
+
+	DescribeTable("fails on invalid cycle",
+		func(name string, palettes [][]*Color, expected error) {
+			_, err := NewPaletteCycle(name, palettes, period)
+			Ω(err).Should(MatchError(expected))
+		},
+		Entry("unknown cycle", "shuffle", [][]*Color{{red}}, ErrUnknownPaletteCycle),
+		Entry("no palettes", PaletteStep, nil, ErrInvalidPalette),
+		Entry("empty palette", PaletteStep, [][]*Color{{red}, {}}, ErrInvalidPalette),
+	)
+
+	It("sets changed predefined colors until context is done", func() {
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		w := &paletteWriterStub{}
+		c := func(elapsed time.Duration) [PaletteSize]Color {
+			if elapsed < 20*time.Millisecond {
+				return palette(red)
+			}
+			return palette(red, green)
+		}
+
+		Ω(CyclePalette(ctx, w, c, FPSMaxValue)).Should(Succeed())
+		Ω(w.nums).Should(Equal([]byte{1, 2, 3, 4, 5, 6, 7, 2, 4, 6}))
+		Ω(w.colors[PaletteSize:]).Should(Equal([]Color{*green, *green, *green}))
+	})
+
+	It("stops on write error", func() {
+
+		w := &paletteWriterStub{err: errors.New("write error")} //nolint:err113
+		Ω(CyclePalette(context.Background(), w, newCycle(PaletteHue, []*Color{red}), FPSMaxValue)).Should(MatchError(w.err))
+		Ω(w.nums).Should(HaveLen(1))
+	})
+})
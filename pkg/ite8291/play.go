@@ -0,0 +1,55 @@
+package ite8291
+
+import (
+	"context"
+	"time"
+)
+
+// FrameWriter interface abstracts a keyboard backlight that can show
+// frames. It is implemented by Controller.
+type FrameWriter interface {
+
+	// WriteFrame sets colors of all keys to the colors of the given
+	// frame.
+	WriteFrame(frame *Frame) error
+}
+
+// TimedFrame provides a frame together with duration of time it is
+// shown.
+type TimedFrame struct {
+	Frame *Frame
+	Delay time.Duration
+}
+
+// PlayFrames writes the given frames one after another to w, waiting
+// for the delay of each frame before writing the next one. The
+// frames are played loops times; if loops is negative they are
+// played until ctx is done. PlayFrames returns nil if ctx is done.
+func PlayFrames(ctx context.Context, w FrameWriter, frames []TimedFrame, loops int) error {
+
+	if len(frames) == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for n := 0; loops < 0 || n < loops; n++ {
+		for _, f := range frames {
+
+			if err := w.WriteFrame(f.Frame); err != nil {
+				return err
+			}
+
+			timer.Reset(f.Delay)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-timer.C:
+			}
+		}
+	}
+
+	return nil
+}
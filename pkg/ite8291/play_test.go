@@ -0,0 +1,52 @@
+package ite8291
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// frameWriterStub collects written frames.
+type frameWriterStub struct {
+	frames []*Frame
+	err    error
+}
+
+// WriteFrame collects the given frame.
+func (w *frameWriterStub) WriteFrame(frame *Frame) error {
+	w.frames = append(w.frames, frame)
+	return w.err
+}
+
+var _ = Describe("PlayFrames", func() {
+
+	var w *frameWriterStub
+	f1, f2 := NewFrame(NewColor(1, 1, 1)), NewFrame(NewColor(2, 2, 2))
+	frames := []TimedFrame{{Frame: f1, Delay: time.Millisecond}, {Frame: f2, Delay: time.Millisecond}}
+
+	BeforeEach(func() {
+		w = &frameWriterStub{}
+	})
+
+	It("plays frames given number of times", func() {
+		Ω(PlayFrames(context.Background(), w, frames, 2)).Should(Succeed())
+		Ω(w.frames).Should(Equal([]*Frame{f1, f2, f1, f2}))
+	})
+
+	It("plays frames until context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		Ω(PlayFrames(ctx, w, frames, -1)).Should(Succeed())
+		Ω(len(w.frames)).Should(BeNumerically(">", 2))
+	})
+
+	It("stops on write error", func() {
+		w.err = errors.New("write error") //nolint:err113
+		Ω(PlayFrames(context.Background(), w, frames, 2)).Should(MatchError(w.err))
+		Ω(w.frames).Should(HaveLen(1))
+	})
+})
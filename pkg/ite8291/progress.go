@@ -0,0 +1,63 @@
+package ite8291
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// RowCells returns cells of the given row of the keyboard matrix from
+// the left to the right.
+func RowCells(row int) []Cell {
+
+	cells := make([]Cell, ColumnsNumber)
+	for j := range ColumnsNumber {
+		cells[j] = Cell{Row: row, Column: j}
+	}
+
+	return cells
+}
+
+// SetProgress draws a progress bar over the given cells. The bar grows
+// from the left to the right; cells of the same column fill from the
+// top. progress in [0, 1] gives the part of the cells lit by fill, the
+// rest is set to background. The last cell of the bar is mixed from
+// both colors in proportion to its part covered by the bar.
+func (f *Frame) SetProgress(cells []Cell, progress float64, fill, background *Color) {
+
+	cells = slices.Clone(cells)
+	slices.SortStableFunc(cells, func(a, b Cell) int {
+		return cmp.Or(cmp.Compare(a.Column, b.Column), cmp.Compare(a.Row, b.Row))
+	})
+
+	filled := max(0, min(1, progress)) * float64(len(cells))
+	for k, cell := range cells {
+		switch part := filled - float64(k); {
+		case part >= 1:
+			f.Set(cell, fill)
+		case part > 0:
+			f.Set(cell, background.Mix(fill, part))
+		default:
+			f.Set(cell, background)
+		}
+	}
+}
+
+// NewCountdownAnimation returns animation counting the given duration
+// down by a progress bar over the given cells of base frame. The bar
+// is full at the start and shrinks to the empty one by the end of the
+// duration, when the animation is over. See Frame.SetProgress.
+func NewCountdownAnimation(base *Frame, cells []Cell, d time.Duration, fill, background *Color) Animation {
+
+	return AnimationFunc(func(frame *Frame, elapsed time.Duration) bool {
+
+		*frame = *base
+		if elapsed >= d {
+			frame.SetProgress(cells, 0, fill, background)
+			return false
+		}
+
+		frame.SetProgress(cells, 1-float64(elapsed)/float64(d), fill, background)
+		return true
+	})
+}
@@ -0,0 +1,81 @@
+package ite8291
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Progress", func() {
+
+	blue, green, black := NewColor(0, 0, 0xff), NewColor(0, 0xff, 0), NewColor(0, 0, 0)
+
+	It("returns cells of a row", func() {
+
+		cells := RowCells(2)
+
+		Ω(cells).Should(HaveLen(ColumnsNumber))
+		Ω(cells[0]).Should(Equal(Cell{Row: 2, Column: 0}))
+		Ω(cells[ColumnsNumber-1]).Should(Equal(Cell{Row: 2, Column: ColumnsNumber - 1}))
+	})
+
+	It("draws a progress bar over a row", func() {
+
+		frame := NewFrame(blue)
+		frame.SetProgress(RowCells(1), 0.5, green, black)
+
+		for j := range 10 {
+			Ω(frame.Get(Cell{Row: 1, Column: j})).Should(Equal(green))
+		}
+		partial := frame.Get(Cell{Row: 1, Column: 10})
+		Ω(partial).ShouldNot(Equal(green))
+		Ω(partial).ShouldNot(Equal(black))
+		for j := 11; j < ColumnsNumber; j++ {
+			Ω(frame.Get(Cell{Row: 1, Column: j})).Should(Equal(black))
+		}
+		Ω(frame.Get(Cell{Row: 0, Column: 0})).Should(Equal(blue))
+	})
+
+	It("fills cells of a zone by columns", func() {
+
+		cells := []Cell{{Row: 1, Column: 1}, {Row: 0, Column: 1}, {Row: 3, Column: 0}, {Row: 2, Column: 0}}
+
+		frame := NewFrame(blue)
+		frame.SetProgress(cells, 0.75, green, black)
+
+		Ω(frame.Get(Cell{Row: 2, Column: 0})).Should(Equal(green))
+		Ω(frame.Get(Cell{Row: 3, Column: 0})).Should(Equal(green))
+		Ω(frame.Get(Cell{Row: 0, Column: 1})).Should(Equal(green))
+		Ω(frame.Get(Cell{Row: 1, Column: 1})).Should(Equal(black))
+		Ω(cells[0]).Should(Equal(Cell{Row: 1, Column: 1})) // cells are not reordered
+	})
+
+	It("clips progress", func() {
+
+		full, empty := NewFrame(blue), NewFrame(blue)
+		full.SetProgress(RowCells(0), 1.5, green, black)
+		empty.SetProgress(RowCells(0), -1, green, black)
+
+		Ω(full.Get(Cell{Row: 0, Column: ColumnsNumber - 1})).Should(Equal(green))
+		Ω(empty.Get(Cell{Row: 0, Column: 0})).Should(Equal(black))
+	})
+
+	It("counts down", func() {
+
+		a := NewCountdownAnimation(NewFrame(blue), RowCells(0), time.Minute, green, black)
+
+		frame := &Frame{}
+		Ω(a.Render(frame, 0)).Should(BeTrue())
+		Ω(frame.Get(Cell{Row: 0, Column: ColumnsNumber - 1})).Should(Equal(green))
+		Ω(frame.Get(Cell{Row: 1, Column: 0})).Should(Equal(blue))
+
+		Ω(a.Render(frame, 30*time.Second)).Should(BeTrue())
+		Ω(frame.Get(Cell{Row: 0, Column: 9})).Should(Equal(green))
+		Ω(frame.Get(Cell{Row: 0, Column: 11})).Should(Equal(black))
+
+		Ω(a.Render(frame, time.Minute)).Should(BeFalse())
+		Ω(frame.Get(Cell{Row: 0, Column: 0})).Should(Equal(black))
+		Ω(frame.Get(Cell{Row: 1, Column: 0})).Should(Equal(blue))
+	})
+})
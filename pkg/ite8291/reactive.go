@@ -0,0 +1,261 @@
+package ite8291
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrUnknownReactiveAnimation error indicates that a reactive software
+// effect with the given name does not exist.
+var ErrUnknownReactiveAnimation = errors.New("unknown reactive animation")
+
+// ReactiveOptions provides parameters of the reactive software
+// effects.
+type ReactiveOptions struct {
+
+	// Colors of the presses used one after another. Presses get
+	// distinct hues if no colors are given. The heat effect uses the
+	// colors as the gradient from cold to hot keys.
+	Colors []*Color
+
+	// Background is color of the keys not lit by presses. It
+	// defaults to black.
+	Background *Color
+
+	// Decay is duration a press fades out in. It defaults to 1
+	// second.
+	Decay time.Duration
+
+	// Layout provides positions of the keys. It defaults to
+	// DefaultLayout.
+	Layout *Layout
+}
+
+// reactiveDecayDefault - default duration a press fades out in.
+const reactiveDecayDefault = time.Second
+
+// reactive effects parameters.
+const (
+	// splashRadius - distance in keys a splash reaches.
+	splashRadius = 2.0
+	// rippleWidth - width in keys of a ripple ring.
+	rippleWidth = 1.0
+	// heatStep - heat added to a key by a press.
+	heatStep = 0.35
+	// goldenAngle - hue difference of consecutive presses without colors.
+	goldenAngle = 137.50776
+)
+
+// heatPalette - default colors of the heat effect from cold to hot keys.
+var heatPalette = []*Color{NewColor(0, 0, 0xff), NewColor(0xff, 0, 0), NewColor(0xff, 0xff, 0), NewColor(0xff, 0xff, 0xff)}
+
+// reactiveLevels maps names of the reactive effects to functions
+// returning level in [0, 1] a key at the given distance in keys from
+// the pressed key is lit by the press at the given part of the decay
+// in [0, 1). size is the larger of the layout dimensions.
+var reactiveLevels = map[string]func(dist, age, size float64) float64{
+	"trail": func(dist, age, _ float64) float64 {
+		if dist > 0 {
+			return 0
+		}
+		return 1 - age
+	},
+	"splash": func(dist, age, _ float64) float64 {
+		return max(0, 1-dist/splashRadius) * (1 - age)
+	},
+	"ripple": func(dist, age, size float64) float64 {
+		return max(0, 1-math.Abs(dist-age*size)/rippleWidth) * (1 - age)
+	},
+	"heat": nil, // accumulates presses, see ReactiveAnimation.renderHeat
+}
+
+// ReactiveAnimationNames returns sorted names of the reactive software
+// effects.
+func ReactiveAnimationNames() []string {
+
+	names := make([]string, 0, len(reactiveLevels))
+	for name := range reactiveLevels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// keyPress provides a press of keys.
+type keyPress struct {
+	keys  []Key
+	color *Color
+	at    time.Duration // time of the press since the start of the animation
+}
+
+// ReactiveAnimation provides software effect lighting keys pressed by
+// user. Presses reported by Press are rendered by the next Render
+// call, so Press can be called from any goroutine.
+type ReactiveAnimation struct {
+	mu      sync.Mutex
+	o       ReactiveOptions
+	level   func(dist, age, size float64) float64
+	size    float64
+	count   int
+	pending []keyPress
+	presses []keyPress
+
+	heat     [RowsNumber][ColumnsNumber]float64
+	rendered time.Duration
+}
+
+// NewReactiveAnimation creates the reactive software effect with the
+// given name. It returns ErrUnknownReactiveAnimation if there is no
+// such effect. Options missing from opts take their default values.
+//
+// The following effects are supported:
+//   - trail - pressed keys light up and fade out leaving a trail;
+//   - splash - pressed keys splash their color over the keys around;
+//   - ripple - rings of the color spread from pressed keys;
+//   - heat - keys warm up with every press and cool down over time.
+func NewReactiveAnimation(name string, opts ReactiveOptions) (*ReactiveAnimation, error) {
+
+	level, found := reactiveLevels[name]
+	if !found {
+		return nil, fmt.Errorf("%w %q; expected one of %q",
+			ErrUnknownReactiveAnimation, name, ReactiveAnimationNames())
+	}
+
+	if opts.Background == nil {
+		opts.Background = NewColor(0, 0, 0)
+	}
+	if opts.Decay <= 0 {
+		opts.Decay = reactiveDecayDefault
+	}
+	if opts.Layout == nil {
+		opts.Layout = DefaultLayout()
+	}
+
+	w, h := opts.Layout.Bounds()
+
+	return &ReactiveAnimation{o: opts, level: level, size: max(w, h)}, nil
+}
+
+// Press reports press of the given keys. Keys lighting several cells
+// of the keyboard matrix can be pressed together.
+func (r *ReactiveAnimation) Press(keys ...*Key) {
+
+	if len(keys) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	color := HSV{H: math.Mod(goldenAngle*float64(r.count), 360), S: 1, V: 1}.Color()
+	if len(r.o.Colors) > 0 {
+		color = r.o.Colors[r.count%len(r.o.Colors)]
+	}
+	r.count++
+
+	p := keyPress{color: color}
+	for _, k := range keys {
+		p.keys = append(p.keys, *k)
+	}
+	r.pending = append(r.pending, p)
+}
+
+// Render renders the keys lit by the presses. It never ends.
+func (r *ReactiveAnimation) Render(frame *Frame, elapsed time.Duration) bool {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pressed := r.pending
+	r.pending = nil
+	frame.Fill(r.o.Background)
+
+	if r.level == nil {
+		r.renderHeat(frame, elapsed, pressed)
+		return true
+	}
+
+	for i := range pressed {
+		pressed[i].at = elapsed
+	}
+	r.presses = append(r.presses, pressed...)
+	r.presses = slices.DeleteFunc(r.presses, func(p keyPress) bool { return elapsed-p.at >= r.o.Decay })
+
+	for i := range r.o.Layout.Keys {
+		k := &r.o.Layout.Keys[i]
+		x, y := k.Center()
+
+		level, color := 0.0, r.o.Background
+		for _, p := range r.presses {
+			age := float64(elapsed-p.at) / float64(r.o.Decay)
+			for j := range p.keys {
+				px, py := p.keys[j].Center()
+				if l := r.level(math.Hypot(x-px, y-py), age, r.size); l > level {
+					level, color = l, p.color
+				}
+			}
+		}
+
+		if level > 0 {
+			c := fade(r.o.Background, color, level)
+			frame.Set(k.Cell(), &c)
+		}
+	}
+
+	return true
+}
+
+// renderHeat cools the keys down since the previous call, warms the
+// keys of the given presses up and renders their heat. Heat halves
+// every decay.
+func (r *ReactiveAnimation) renderHeat(frame *Frame, elapsed time.Duration, pressed []keyPress) {
+
+	cooling := math.Pow(0.5, float64(elapsed-r.rendered)/float64(r.o.Decay))
+	r.rendered = elapsed
+
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			r.heat[i][j] *= cooling
+		}
+	}
+
+	for _, p := range pressed {
+		for _, k := range p.keys {
+			if c := k.Cell(); c.Valid() {
+				r.heat[c.Row][c.Column] = min(1, r.heat[c.Row][c.Column]+heatStep)
+			}
+		}
+	}
+
+	colors := heatPalette
+	if len(r.o.Colors) > 0 {
+		colors = r.o.Colors
+	}
+	palette := append(Gradient{r.o.Background}, colors...)
+
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			if r.heat[i][j] > 0 {
+				frame[i][j] = *palette.At(r.heat[i][j])
+			}
+		}
+	}
+}
+
+// fade returns color between from and to at the given level in [0, 1]
+// mixed in linear light.
+func fade(from, to *Color, level float64) Color {
+
+	level = max(0, min(1, level))
+	mix := func(a, b uint8) uint8 {
+		la := srgbToLinear(a)
+		return linearToSrgb(la + (srgbToLinear(b)-la)*level)
+	}
+
+	return Color{Red: mix(from.Red, to.Red), Green: mix(from.Green, to.Green), Blue: mix(from.Blue, to.Blue)}
+}
@@ -0,0 +1,113 @@
+package ite8291
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reactive animations", func() {
+
+	black, red, blue := NewColor(0, 0, 0), NewColor(0xff, 0, 0), NewColor(0, 0, 0xff)
+	layout := DefaultLayout()
+	pressed, _ := layout.Key("r2c10")
+	near, _ := layout.Key("r2c11")
+	far, _ := layout.Key("r2c14")
+
+	// render returns frame rendered by the effect at the given time.
+	render := func(a Animation, elapsed time.Duration) *Frame {
+		frame := &Frame{}
+		Ω(a.Render(frame, elapsed)).Should(BeTrue())
+		return frame
+	}
+
+	// newReactive creates the effect with the given name and colors.
+	newReactive := func(name string, colors ...*Color) *ReactiveAnimation {
+		a, err := NewReactiveAnimation(name, ReactiveOptions{Colors: colors, Decay: time.Second, Layout: layout})
+		Ω(err).ShouldNot(HaveOccurred())
+		return a
+	}
+
+	It("fails on unknown effect", func() {
+		_, err := NewReactiveAnimation("unknown", ReactiveOptions{})
+		Ω(err).Should(MatchError(ErrUnknownReactiveAnimation))
+		Ω(ReactiveAnimationNames()).Should(Equal([]string{"heat", "ripple", "splash", "trail"}))
+	})
+
+	It("renders background without presses", func() {
+		a, err := NewReactiveAnimation("splash", ReactiveOptions{Background: blue})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(render(a, 0)).Should(Equal(NewFrame(blue)))
+	})
+
+	It("fades pressed keys out leaving a trail", func() {
+
+		a := newReactive("trail", red, blue)
+		render(a, 0)
+		a.Press(pressed)
+		frame := render(a, 100*time.Millisecond)
+
+		Ω(frame.Get(pressed.Cell())).Should(Equal(red))
+		Ω(frame.Get(near.Cell())).Should(Equal(black))
+
+		a.Press(near)
+		frame = render(a, 600*time.Millisecond)
+		Ω(frame.Get(near.Cell())).Should(Equal(blue))
+		dimmed := frame.Get(pressed.Cell())
+		Ω(dimmed.Red).Should(And(BeNumerically(">", 0), BeNumerically("<", 0xff)))
+
+		frame = render(a, 1100*time.Millisecond)
+		Ω(frame.Get(pressed.Cell())).Should(Equal(black))
+	})
+
+	It("splashes color over the keys around", func() {
+
+		a := newReactive("splash", red)
+		a.Press(pressed)
+		frame := render(a, 0)
+
+		Ω(frame.Get(pressed.Cell())).Should(Equal(red))
+		Ω(frame.Get(near.Cell()).Red).Should(And(BeNumerically(">", 0), BeNumerically("<", 0xff)))
+		Ω(frame.Get(far.Cell())).Should(Equal(black))
+	})
+
+	It("spreads ripples from the pressed keys", func() {
+
+		a := newReactive("ripple", red)
+		a.Press(pressed)
+		render(a, 0)
+
+		// the ring reaches the layout width in the decay
+		frame := render(a, time.Second*4/ColumnsNumber)
+		Ω(frame.Get(far.Cell()).Red).Should(BeNumerically(">", frame.Get(pressed.Cell()).Red))
+		Ω(frame.Get(pressed.Cell())).Should(Equal(black))
+	})
+
+	It("warms pressed keys up and cools them down", func() {
+
+		a := newReactive("heat", red, blue)
+		render(a, 0)
+
+		a.Press(pressed)
+		a.Press(pressed)
+		frame := render(a, 0)
+		hot := frame.Get(pressed.Cell())
+		Ω(hot).Should(Equal(Gradient{black, red, blue}.At(2 * heatStep)))
+		Ω(frame.Get(near.Cell())).Should(Equal(black))
+
+		frame = render(a, time.Second)
+		Ω(frame.Get(pressed.Cell())).Should(Equal(Gradient{black, red, blue}.At(heatStep)))
+	})
+
+	It("gives presses distinct hues without colors", func() {
+
+		a := newReactive("trail")
+		a.Press(pressed)
+		a.Press(near)
+		frame := render(a, 0)
+
+		Ω(frame.Get(pressed.Cell())).Should(Equal(red))
+		Ω(frame.Get(near.Cell())).ShouldNot(Equal(red))
+	})
+})
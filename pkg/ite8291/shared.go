@@ -0,0 +1,164 @@
+package ite8291
+
+import (
+	"context"
+	"sync"
+)
+
+// SharedDevice provides device shared by concurrently used
+// controllers. Transfers are serialized and Close does nothing; the
+// device is closed by its owner. It implements Exclusive, so the
+// operations of the controllers consisting of several transfers are
+// serialized too.
+type SharedDevice struct {
+
+	// OnChange, if set, is called after effect or brightness was set.
+	OnChange func()
+
+	op chan struct{} // held during a transfer or an exclusive access
+
+	mu    sync.Mutex // guards fields below
+	dev   Device
+	write WriteFunc
+}
+
+// NewSharedDevice creates a new shared device backed by the given
+// device.
+func NewSharedDevice(dev Device) *SharedDevice {
+	return &SharedDevice{dev: dev, op: make(chan struct{}, 1)}
+}
+
+// ControlTransfer calls ControlTransfer of the device.
+func (d *SharedDevice) ControlTransfer(requestType byte, request byte, value uint16, index uint16,
+	data []byte, length int, timeout int) (int, error) {
+
+	d.op <- struct{}{}
+	n, err := d.dev.ControlTransfer(requestType, request, value, index, data, length, timeout)
+	<-d.op
+
+	d.changed(requestType, data, err)
+
+	return n, err
+}
+
+// GetBulkWrite returns function writing bulk data to the device.
+func (d *SharedDevice) GetBulkWrite() (WriteFunc, error) {
+
+	write, err := d.bulkWrite()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(p []byte) (int, error) {
+		d.op <- struct{}{}
+		defer func() { <-d.op }()
+
+		return write(p)
+	}, nil
+}
+
+// Exclusive returns device giving exclusive access to the device
+// until release is called. The exclusive access to the device is
+// acquired as well if it's shared too.
+func (d *SharedDevice) Exclusive() (Device, func(), error) {
+	return d.ExclusiveContext(context.Background())
+}
+
+// ExclusiveContext is like Exclusive, but stops waiting for the
+// exclusive access and returns the context error when the context is
+// done.
+func (d *SharedDevice) ExclusiveContext(ctx context.Context) (Device, func(), error) {
+
+	select {
+	case d.op <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	var dev Device
+	release := func() {}
+	if x, ok := d.dev.(Exclusive); ok {
+		var err error
+		if dev, release, err = x.Exclusive(); err != nil {
+			<-d.op
+			return nil, nil, err
+		}
+	}
+
+	var once sync.Once
+	return &exclusiveDevice{shared: d, dev: dev}, func() {
+		once.Do(func() {
+			release()
+			<-d.op
+		})
+	}, nil
+}
+
+// bulkWrite returns write function of the device obtained from it
+// once.
+func (d *SharedDevice) bulkWrite() (WriteFunc, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.write == nil {
+		write, err := d.dev.GetBulkWrite()
+		if err != nil {
+			return nil, err
+		}
+		d.write = write
+	}
+
+	return d.write, nil
+}
+
+// changed calls OnChange if the given transfer set effect or
+// brightness.
+func (d *SharedDevice) changed(requestType byte, data []byte, err error) {
+
+	if err == nil && d.OnChange != nil && requestType == SendControlRequestType && len(data) > 0 &&
+		(data[0] == SetEffectCommand || data[0] == SetBrightnessCommand) {
+		d.OnChange()
+	}
+}
+
+// Close does nothing; the device is closed by its owner.
+func (d *SharedDevice) Close() error {
+	return nil
+}
+
+// exclusiveDevice provides exclusive access to a shared device.
+type exclusiveDevice struct {
+	shared *SharedDevice
+	dev    Device // exclusive access to the device if it's shared too
+}
+
+// ControlTransfer calls ControlTransfer of the device.
+func (d *exclusiveDevice) ControlTransfer(requestType byte, request byte, value uint16, index uint16,
+	data []byte, length int, timeout int) (int, error) {
+
+	dev := d.dev
+	if dev == nil {
+		dev = d.shared.dev
+	}
+
+	n, err := dev.ControlTransfer(requestType, request, value, index, data, length, timeout)
+	d.shared.changed(requestType, data, err)
+
+	return n, err
+}
+
+// GetBulkWrite returns function writing bulk data to the device.
+func (d *exclusiveDevice) GetBulkWrite() (WriteFunc, error) {
+
+	if d.dev != nil {
+		return d.dev.GetBulkWrite()
+	}
+
+	return d.shared.bulkWrite()
+}
+
+// Close does nothing; the device is closed by its owner.
+func (d *exclusiveDevice) Close() error {
+	return nil
+}
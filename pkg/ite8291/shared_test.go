@@ -0,0 +1,121 @@
+package ite8291
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// yieldingDevice provides device yielding the processor on every
+// control transfer, so concurrent transfers are likely interleaved.
+type yieldingDevice struct {
+	Device
+}
+
+func (d yieldingDevice) ControlTransfer(requestType byte, request byte, value uint16, index uint16,
+	data []byte, length int, timeout int) (int, error) {
+
+	runtime.Gosched()
+	return d.Device.ControlTransfer(requestType, request, value, index, data, length, timeout)
+}
+
+var _ = Describe("SharedDevice", func() {
+
+	var dev *VirtualDevice
+	var shared *SharedDevice
+	var changes int
+
+	BeforeEach(func() {
+		dev = NewVirtualDevice()
+		shared = NewSharedDevice(dev)
+		changes = 0
+		shared.OnChange = func() { changes++ }
+	})
+
+	It("reports changes of effect and brightness", func() {
+		ctl := NewController(shared)
+
+		Ω(ctl.SetWaveMode(3, 40, DirectionUp, false)).Should(Succeed())
+		Ω(ctl.SetBrightness(7)).Should(Succeed())
+		Ω(changes).Should(Equal(2))
+
+		Ω(ctl.Brightness()).Should(BeEquivalentTo(7))
+		Ω(ctl.SetColor(2, NewColor(1, 2, 3))).Should(Succeed())
+		Ω(changes).Should(Equal(2))
+	})
+
+	It("does not interleave frames written concurrently", func() {
+		var mixed []*Frame
+		dev.OnFrame = func(frame *Frame) {
+			if *frame != *NewFrame(&frame[0][0]) {
+				mixed = append(mixed, frame)
+			}
+		}
+
+		shared = NewSharedDevice(yieldingDevice{dev})
+
+		var wg sync.WaitGroup
+		for _, color := range []*Color{NewColor(0xff, 0, 0), NewColor(0, 0, 0xff)} {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				ctl := NewController(shared)
+				for range 100 {
+					Ω(ctl.WriteFrame(NewFrame(color))).Should(Succeed())
+					Ω(ctl.Effect()).Should(HaveField("Effect", BeEquivalentTo(UserEffect)))
+				}
+			}()
+		}
+		wg.Wait()
+
+		Ω(mixed).Should(BeEmpty())
+	})
+
+	It("gives exclusive access till it's released", func() {
+		exclusive, release, err := shared.Exclusive()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		done := make(chan error, 1)
+		go func() { done <- NewController(shared).SetBrightness(5) }()
+		Consistently(done).ShouldNot(Receive())
+
+		Ω(NewController(exclusive).SetBrightness(9)).Should(Succeed())
+		Ω(changes).Should(Equal(1))
+
+		release()
+		release() // released once
+		Eventually(done).Should(Receive(BeNil()))
+		Ω(dev.Effect().Brightness).Should(BeEquivalentTo(5))
+	})
+
+	It("stops waiting for exclusive access when the context is done", func() {
+		_, release, err := shared.Exclusive()
+		Ω(err).ShouldNot(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := shared.ExclusiveContext(ctx)
+			done <- err
+		}()
+		Consistently(done).ShouldNot(Receive())
+
+		cancel()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+
+		release()
+		_, release, err = shared.ExclusiveContext(context.Background())
+		Ω(err).ShouldNot(HaveOccurred())
+		release()
+	})
+
+	It("is not closed by controllers", func() {
+		Ω(NewController(shared).Close()).Should(Succeed())
+		Ω(NewController(shared).SetBrightness(5)).Should(Succeed())
+	})
+})
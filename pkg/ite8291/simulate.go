@@ -0,0 +1,86 @@
+package ite8291
+
+// rainbowGradient - gradient used to simulate multicolor effects.
+var rainbowGradient = Gradient{
+	NewColor(0xff, 0, 0), NewColor(0xff, 0xff, 0), NewColor(0, 0xff, 0), NewColor(0, 0xff, 0xff),
+	NewColor(0, 0, 0xff), NewColor(0xff, 0, 0xff),
+}
+
+// scatteredKey reports whether the given cell is lit by simulation of
+// effects lighting random keys.
+func scatteredKey(row, column int) bool {
+	return (row*7+column*3)%5 == 0
+}
+
+// SimulateEffect returns frame approximating a snapshot of the given
+// built-in effect. colors returns predefined colors by their numbers;
+// effects using random colors are shown in rainbow colors. Positions
+// of the keys are given by layout. Keys of a turned off keyboard
+// backlight and of 'user' effect are black.
+//
+//nolint:cyclop
+func SimulateEffect(state *EffectState, colors func(colorNum byte) *Color, layout *Layout) *Frame {
+
+	black := NewColor(0, 0, 0)
+	if state.Control == OffState {
+		return NewFrame(black)
+	}
+
+	rainbow := GradientFrame(rainbowGradient, layout, LinearGradient(DirectionRight))
+	color := colors(state.ColorNum)
+
+	// colored returns frame with keys lit by the effect color
+	colored := func(lit func(row, column int) bool) *Frame {
+		frame := NewFrame(black)
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				if !lit(i, j) {
+					continue
+				}
+				if color != nil {
+					frame[i][j] = *color
+				} else {
+					frame[i][j] = rainbow[i][j]
+				}
+			}
+		}
+		return frame
+	}
+
+	switch state.Effect {
+	case WaveEffect:
+		dir := Direction(state.ReactOrDiv)
+		if dir == DirectionNone || dir > DirectionDown {
+			dir = DirectionRight
+		}
+		return GradientFrame(rainbowGradient, layout, LinearGradient(dir))
+
+	case RainbowEffect:
+		return GradientFrame(rainbowGradient, layout, LinearGradient(DirectionUp, DirectionRight))
+
+	case BreathingEffect:
+		return colored(func(int, int) bool { return true })
+
+	case MarqueeEffect:
+		frame := NewFrame(black)
+		for i := range RowsNumber {
+			for j := range ColumnsNumber {
+				if i == 0 || i == RowsNumber-1 || j == 0 || j == ColumnsNumber-1 {
+					frame[i][j] = Color{Red: 0xff, Green: 0xff, Blue: 0xff}
+				}
+			}
+		}
+		return frame
+
+	case AuroraEffect, RippleEffect:
+		if color == nil {
+			return GradientFrame(rainbowGradient, layout, RadialGradient)
+		}
+		return GradientFrame(Gradient{color, black}, layout, RadialGradient)
+
+	case RandomEffect, RaindropEffect, FireworksEffect:
+		return colored(scatteredKey)
+	}
+
+	return NewFrame(black)
+}
@@ -0,0 +1,250 @@
+package ite8291
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidStream error indicates that a frame stream is invalid.
+var ErrInvalidStream = errors.New("invalid frame stream")
+
+// frame stream formats names.
+const (
+	// StreamText - name of the line based text format of frame
+	// streams. Every line of the stream updates the frame shown
+	// last and shows it. A line is one of
+	//   - "ROW COLUMN COLOR [ROW COLUMN COLOR ...]" - sets the keys
+	//     given by their rows and columns to the colors;
+	//   - "fill COLOR" - sets all keys to the color;
+	//   - "frame COLOR..." - sets all keys to the RowsNumber x
+	//     ColumnsNumber colors listed row by row.
+	// Empty lines are ignored.
+	StreamText = "text"
+	// StreamRaw - name of the binary format of frame streams. The
+	// stream consists of frames of RawFrameSize bytes each holding
+	// red, green and blue bytes of the keys row by row.
+	StreamRaw = "raw"
+)
+
+// RawFrameSize - size in bytes of a frame of the raw frame stream.
+const RawFrameSize = RowsNumber * ColumnsNumber * 3
+
+// StreamFormats returns sorted names of the frame stream formats.
+func StreamFormats() []string {
+	return []string{StreamRaw, StreamText}
+}
+
+// FrameReader interface abstracts a stream of frames.
+type FrameReader interface {
+
+	// ReadFrame reads the next frame of the stream into frame. frame
+	// holds the frame read previously. ReadFrame returns io.EOF at
+	// the end of the stream.
+	ReadFrame(frame *Frame) error
+}
+
+// NewFrameReader returns reader of the frame stream in the given
+// format read from r. color converts colors of the text format to
+// colors. It returns ErrInvalidStream if the format is not
+// supported.
+func NewFrameReader(r io.Reader, format string, color func(val string) (*Color, error)) (FrameReader, error) {
+
+	switch strings.ToLower(format) {
+	case StreamText:
+		return &textFrameReader{scanner: bufio.NewScanner(r), color: color}, nil
+	case StreamRaw:
+		return &rawFrameReader{r: r}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown format %q; expected one of %q", ErrInvalidStream, format, StreamFormats())
+}
+
+// rawFrameReader reads frames of the raw frame stream.
+type rawFrameReader struct {
+	r   io.Reader
+	buf [RawFrameSize]byte
+}
+
+// ReadFrame reads the next raw frame into frame.
+func (r *rawFrameReader) ReadFrame(frame *Frame) error {
+
+	if _, err := io.ReadFull(r.r, r.buf[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: incomplete frame", ErrInvalidStream)
+		}
+		return err
+	}
+
+	for i := range RowsNumber {
+		for j := range ColumnsNumber {
+			k := (i*ColumnsNumber + j) * 3
+			frame[i][j] = Color{Red: r.buf[k], Green: r.buf[k+1], Blue: r.buf[k+2]}
+		}
+	}
+
+	return nil
+}
+
+// textFrameReader reads frames of the text frame stream.
+type textFrameReader struct {
+	scanner *bufio.Scanner
+	color   func(val string) (*Color, error)
+	line    int
+}
+
+// ReadFrame applies the next non-empty line of the stream to frame.
+func (r *textFrameReader) ReadFrame(frame *Frame) error {
+
+	for r.scanner.Scan() {
+		r.line++
+
+		fields := strings.Fields(r.scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if err := r.apply(frame, fields); err != nil {
+			return fmt.Errorf("%w: line %d: %w", ErrInvalidStream, r.line, err)
+		}
+
+		return nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
+
+// apply applies the given fields of a line to frame. The frame is
+// left intact if the line is invalid.
+func (r *textFrameReader) apply(frame *Frame, fields []string) error {
+
+	updated := *frame
+
+	switch strings.ToLower(fields[0]) {
+	case "fill":
+		if len(fields) != 2 {
+			return errors.New("fill expects one color")
+		}
+
+		c, err := r.color(fields[1])
+		if err != nil {
+			return err
+		}
+		updated.Fill(c)
+
+	case "frame":
+		if len(fields) != RowsNumber*ColumnsNumber+1 {
+			return fmt.Errorf("frame expects %d colors, got %d", RowsNumber*ColumnsNumber, len(fields)-1)
+		}
+
+		for k, val := range fields[1:] {
+			c, err := r.color(val)
+			if err != nil {
+				return err
+			}
+			updated[k/ColumnsNumber][k%ColumnsNumber] = *c
+		}
+
+	default:
+		if len(fields)%3 != 0 {
+			return errors.New("expected row, column and color triplets")
+		}
+
+		for k := 0; k < len(fields); k += 3 {
+			cell, err := parseCell(fields[k], fields[k+1])
+			if err != nil {
+				return err
+			}
+
+			c, err := r.color(fields[k+2])
+			if err != nil {
+				return err
+			}
+			updated.Set(cell, c)
+		}
+	}
+
+	*frame = updated
+
+	return nil
+}
+
+// parseCell returns the cell given by its row and column.
+func parseCell(row, column string) (Cell, error) {
+
+	var cell Cell
+	var err error
+
+	if cell.Row, err = strconv.Atoi(row); err != nil {
+		return cell, fmt.Errorf("invalid row %q", row)
+	}
+	if cell.Column, err = strconv.Atoi(column); err != nil {
+		return cell, fmt.Errorf("invalid column %q", column)
+	}
+	if !cell.Valid() {
+		return cell, fmt.Errorf("row %d, column %d is outside the keyboard matrix", cell.Row, cell.Column)
+	}
+
+	return cell, nil
+}
+
+// Stream reads frames from r and writes them to w as they arrive. It
+// returns nil at the end of the stream or once ctx is done. Reading
+// of r is not interrupted when ctx is done, it is left to finish in
+// background.
+func Stream(ctx context.Context, w FrameWriter, r FrameReader) error {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	frames := make(chan Frame)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(frames)
+
+		var frame Frame
+		for {
+			if err := r.ReadFrame(&frame); err != nil {
+				if !errors.Is(err, io.EOF) {
+					errs <- err
+				}
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case frames <- frame:
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case frame, ok := <-frames:
+			if !ok {
+				select {
+				case err := <-errs:
+					return err
+				default:
+					return nil
+				}
+			}
+
+			if err := w.WriteFrame(&frame); err != nil {
+				return err
+			}
+		}
+	}
+}
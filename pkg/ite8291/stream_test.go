@@ -0,0 +1,116 @@
+package ite8291
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// readFrames reads all frames of the stream in the given format.
+func readFrames(input string, format string) ([]Frame, error) {
+
+	r, err := NewFrameReader(strings.NewReader(input), format, ParseColor)
+	if err != nil {
+		return nil, err
+	}
+
+	var frames []Frame
+	var frame Frame
+	for {
+		if err := r.ReadFrame(&frame); err != nil {
+			if errors.Is(err, io.EOF) {
+				return frames, nil
+			}
+			return frames, err
+		}
+		frames = append(frames, frame)
+	}
+}
+
+var _ = Describe("Frame stream", func() {
+
+	red, green := NewColor(0xff, 0, 0), NewColor(0, 0xff, 0)
+
+	It("reads text frames", func() {
+
+		all := strings.Repeat(" 00FF00", RowsNumber*ColumnsNumber)
+		frames, err := readFrames("fill #F00\n\n0 0 #00F 5 20 #0F0\nFRAME"+all+"\n", StreamText)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		second := *NewFrame(red)
+		second.Set(Cell{Row: 0, Column: 0}, NewColor(0, 0, 0xff))
+		second.Set(Cell{Row: 5, Column: 20}, green)
+		Ω(frames).Should(Equal([]Frame{*NewFrame(red), second, *NewFrame(green)}))
+	})
+
+	It("reads raw frames", func() {
+
+		raw := bytes.Repeat([]byte{0xff, 0, 0}, RowsNumber*ColumnsNumber)
+		raw = append(raw, bytes.Repeat([]byte{0, 0xff, 0}, RowsNumber*ColumnsNumber)...)
+
+		frames, err := readFrames(string(raw), StreamRaw)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(frames).Should(Equal([]Frame{*NewFrame(red), *NewFrame(green)}))
+	})
+
+	DescribeTable("fails on invalid stream",
+		func(input, format string) {
+			_, err := readFrames(input, format)
+			Ω(err).Should(MatchError(ErrInvalidStream))
+		},
+		Entry("unknown format", "", "json"),
+		Entry("incomplete raw frame", "\x01\x02\x03", StreamRaw),
+		Entry("missing fill color", "fill", StreamText),
+		Entry("invalid color", "fill nocolor", StreamText),
+		Entry("too few frame colors", "frame #000 #FFF", StreamText),
+		Entry("incomplete triplet", "0 0", StreamText),
+		Entry("invalid row", "r 0 #000", StreamText),
+		Entry("invalid column", "0 c #000", StreamText),
+		Entry("cell outside matrix", "6 0 #000", StreamText),
+	)
+
+	It("writes frames as they arrive", func() {
+
+		r, err := NewFrameReader(strings.NewReader("fill #F00\nfill #0F0\n"), StreamText, ParseColor)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		w := &frameWriterStub{}
+		Ω(Stream(context.Background(), w, r)).Should(Succeed())
+		Ω(w.frames).Should(Equal([]*Frame{NewFrame(red), NewFrame(green)}))
+	})
+
+	It("stops on invalid frame", func() {
+
+		r, err := NewFrameReader(strings.NewReader("fill #F00\nfill\nfill #0F0\n"), StreamText, ParseColor)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		w := &frameWriterStub{}
+		Ω(Stream(context.Background(), w, r)).Should(MatchError(ErrInvalidStream))
+		Ω(w.frames).Should(Equal([]*Frame{NewFrame(red)}))
+	})
+
+	It("stops when context is done", func() {
+
+		pr, pw := io.Pipe()
+		defer pw.Close()
+
+		r, err := NewFrameReader(pr, StreamText, ParseColor)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &frameWriterStub{}
+		done := make(chan error)
+		go func() { done <- Stream(ctx, w, r) }()
+
+		_, err = io.WriteString(pw, "fill #F00\n")
+		Ω(err).ShouldNot(HaveOccurred())
+		cancel()
+
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
@@ -0,0 +1,144 @@
+package ite8291
+
+import (
+	"time"
+	"unicode"
+)
+
+// FontHeight - height of the glyphs of the embedded bitmap font in
+// keys.
+const FontHeight = 5
+
+// textRow - row of the keyboard matrix showing the top of the text.
+const textRow = 0
+
+// unknownGlyph - character shown instead of characters missing from
+// the embedded font.
+const unknownGlyph = '?'
+
+// font - embedded bitmap font. Every glyph is given by its rows where
+// '#' marks a lit key.
+var font = map[rune][FontHeight]string{
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#...#", "##.##", "#.#.#", "#...#", "#...#"},
+	'N': {"#..#", "##.#", "#.##", "#..#", "#..#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".##.", "#..#", "#..#", "#.#.", ".#.#"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#...#", "#...#", "#.#.#", "##.##", "#...#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"##.", "..#", ".#.", "#..", "###"},
+	'3': {"##.", "..#", ".#.", "..#", "##."},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "##.", "..#", "##."},
+	'6': {".##", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "##."},
+
+	' ':  {"..", "..", "..", "..", ".."},
+	'.':  {".", ".", ".", ".", "#"},
+	',':  {".", ".", ".", "#", "#"},
+	':':  {".", "#", ".", "#", "."},
+	';':  {"..", ".#", "..", ".#", "#."},
+	'!':  {"#", "#", "#", ".", "#"},
+	'?':  {"##.", "..#", ".#.", "...", ".#."},
+	'-':  {"...", "...", "###", "...", "..."},
+	'+':  {"...", ".#.", "###", ".#.", "..."},
+	'_':  {"...", "...", "...", "...", "###"},
+	'=':  {"...", "###", "...", "###", "..."},
+	'*':  {"...", "#.#", ".#.", "#.#", "..."},
+	'/':  {"..#", "..#", ".#.", "#..", "#.."},
+	'%':  {"#.#", "..#", ".#.", "#..", "#.#"},
+	'<':  {"..#", ".#.", "#..", ".#.", "..#"},
+	'>':  {"#..", ".#.", "..#", ".#.", "#.."},
+	'(':  {".#", "#.", "#.", "#.", ".#"},
+	')':  {"#.", ".#", ".#", ".#", "#."},
+	'[':  {"##", "#.", "#.", "#.", "##"},
+	']':  {"##", ".#", ".#", ".#", "##"},
+	'\'': {"#", "#", ".", ".", "."},
+	'"':  {"#.#", "#.#", "...", "...", "..."},
+	'#':  {".#.#.", "#####", ".#.#.", "#####", ".#.#."},
+}
+
+// TextColumns rasterizes the given text using the embedded bitmap
+// font and returns columns of the resulting bitmap; true marks a lit
+// key. Glyphs are separated by an empty column. Lowercase letters are
+// shown as uppercase ones; characters missing from the font are shown
+// as '?'.
+func TextColumns(text string) [][FontHeight]bool {
+
+	var columns [][FontHeight]bool
+	for i, r := range []rune(text) {
+
+		glyph, found := font[unicode.ToUpper(r)]
+		if !found {
+			glyph = font[unknownGlyph]
+		}
+
+		if i > 0 {
+			columns = append(columns, [FontHeight]bool{})
+		}
+
+		for j := range len(glyph[0]) {
+			var col [FontHeight]bool
+			for k, row := range glyph {
+				col[k] = row[j] == '#'
+			}
+			columns = append(columns, col)
+		}
+	}
+
+	return columns
+}
+
+// TextFrames returns frames scrolling the given text from the right
+// to the left edge of the keyboard. The text is drawn with fg color
+// on bg color starting at the top row of the keyboard matrix. Every
+// frame shifts the text by one column and is shown for the given
+// delay. The text enters the keyboard in the first frame and the
+// last frame shows background only.
+func TextFrames(text string, fg, bg *Color, delay time.Duration) []TimedFrame {
+
+	columns := TextColumns(text)
+
+	frames := make([]TimedFrame, 0, len(columns)+ColumnsNumber)
+	for offset := 1 - ColumnsNumber; offset <= len(columns); offset++ {
+
+		frame := NewFrame(bg)
+		for j := range ColumnsNumber {
+			if k := offset + j; k >= 0 && k < len(columns) {
+				for i, lit := range columns[k] {
+					if lit {
+						frame.Set(Cell{Row: textRow + i, Column: j}, fg)
+					}
+				}
+			}
+		}
+
+		frames = append(frames, TimedFrame{Frame: frame, Delay: delay})
+	}
+
+	return frames
+}
@@ -0,0 +1,65 @@
+package ite8291
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Text", func() {
+
+	// bitmap returns rows of the given text bitmap.
+	bitmap := func(text string) []string {
+		rows := make([]string, FontHeight)
+		for _, col := range TextColumns(text) {
+			for i, lit := range col {
+				if lit {
+					rows[i] += "#"
+				} else {
+					rows[i] += "."
+				}
+			}
+		}
+		return rows
+	}
+
+	It("rasterizes text with spacing between glyphs", func() {
+		Ω(bitmap("Hi!")).Should(Equal([]string{
+			"#.#.###.#",
+			"#.#..#..#",
+			"###..#..#",
+			"#.#..#...",
+			"#.#.###.#",
+		}))
+	})
+
+	It("shows unknown characters as question marks", func() {
+		Ω(bitmap("ж")).Should(Equal(bitmap("?")))
+	})
+
+	It("has glyphs of equal height", func() {
+		for r, glyph := range font {
+			for _, row := range glyph {
+				Ω(row).Should(HaveLen(len(glyph[0])), "glyph %q", r)
+			}
+		}
+	})
+
+	It("scrolls text through the keyboard", func() {
+		white, black := NewColor(0xff, 0xff, 0xff), NewColor(0, 0, 0)
+
+		frames := TextFrames("!", white, black, time.Second)
+
+		Ω(frames).Should(HaveLen(ColumnsNumber + 1))
+		for i, f := range frames {
+			Ω(f.Delay).Should(Equal(time.Second))
+
+			expected := NewFrame(black)
+			if col := ColumnsNumber - 1 - i; col >= 0 {
+				expected.SetCells([]Cell{{0, col}, {1, col}, {2, col}, {4, col}}, white)
+			}
+			Ω(f.Frame).Should(Equal(expected), "frame %d", i)
+		}
+	})
+})
@@ -0,0 +1,189 @@
+package ite8291
+
+import (
+	"sync"
+)
+
+// VirtualDevice provides an emulated ite8291r3 device. It interprets
+// commands sent by Controller and keeps the resulting state of the
+// keyboard backlight instead of applying it to a real device. It is
+// used to preview effects and frames.
+type VirtualDevice struct {
+
+	// OnFrame, if set, is called after colors of all rows of 'user'
+	// effect are written.
+	OnFrame func(frame *Frame)
+
+	mu      sync.Mutex
+	state   EffectState
+	colors  [ColorNumMaxValue]Color
+	frame   Frame
+	row     int
+	request byte
+}
+
+// virtualDefaultColors - predefined colors of a virtual device.
+var virtualDefaultColors = []*Color{
+	NewColor(0xff, 0xff, 0xff),
+	NewColor(0xff, 0, 0),
+	NewColor(0xff, 0xff, 0),
+	NewColor(0, 0xff, 0),
+	NewColor(0, 0, 0xff),
+	NewColor(0, 0xff, 0xff),
+	NewColor(0xff, 0, 0xff),
+}
+
+// NewVirtualDevice creates a virtual device in 'user' effect with
+// all keys turned off.
+func NewVirtualDevice() *VirtualDevice {
+
+	d := &VirtualDevice{state: EffectState{
+		Control: SetEffectOp, Effect: UserEffect, Brightness: BrightnessMaxValue / 2,
+	}}
+
+	for i, col := range virtualDefaultColors {
+		d.colors[i+CustomColorNumMinValue] = *col
+	}
+
+	return d
+}
+
+// ControlTransfer interprets commands sent to the device and answers
+// its requests.
+func (d *VirtualDevice) ControlTransfer(requestType byte, request byte, value uint16, index uint16,
+	data []byte, length int, timeout int) (int, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if requestType == ReceiveControlRequestType {
+		d.receive(data)
+		return len(data), nil
+	}
+
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	switch data[0] {
+	case SetEffectCommand:
+		if len(data) >= 7 {
+			if data[1] == SetOffOp {
+				d.state.Control = OffState
+			} else {
+				d.state = EffectState{Control: data[1], Effect: data[2], Speed: data[3], Brightness: data[4],
+					ColorNum: data[5], ReactOrDiv: data[6]}
+			}
+		}
+	case SetBrightnessCommand:
+		if len(data) >= 3 {
+			d.state.Brightness = data[2]
+		}
+	case SetColorCommand:
+		if len(data) >= 6 && data[2] >= CustomColorNumMinValue && data[2] <= CustomColorNumMaxValue {
+			d.colors[data[2]] = Color{Red: data[3], Green: data[4], Blue: data[5]}
+		}
+	case SetRowIndexCommand:
+		if len(data) >= 3 {
+			d.row = int(data[2])
+		}
+	default:
+		d.request = data[0]
+	}
+
+	return len(data), nil
+}
+
+// receive fills data with the answer to the last request.
+func (d *VirtualDevice) receive(data []byte) {
+
+	clear(data)
+	if d.request == GetEffectCommand && len(data) >= 7 {
+		copy(data[1:], []byte{d.state.Control, d.state.Effect, d.state.Speed, d.state.Brightness,
+			d.state.ColorNum, d.state.ReactOrDiv})
+	}
+}
+
+// GetBulkWrite returns function that writes colors of the current
+// row of 'user' effect.
+func (d *VirtualDevice) GetBulkWrite() (WriteFunc, error) {
+
+	return func(p []byte) (int, error) {
+
+		var frame *Frame
+
+		d.mu.Lock()
+		if d.row >= 0 && d.row < RowsNumber && len(p) >= rowBufferLength {
+			for j := range ColumnsNumber {
+				d.frame[d.row][j] = Color{
+					Red: p[j+rowRedOffset], Green: p[j+rowGreenOffset], Blue: p[j+rowBlueOffset],
+				}
+			}
+
+			if d.row == RowsNumber-1 && d.OnFrame != nil {
+				frame = &Frame{}
+				*frame = d.frame
+			}
+		}
+		d.mu.Unlock()
+
+		if frame != nil {
+			d.OnFrame(frame)
+		}
+
+		return len(p), nil
+	}, nil
+}
+
+// Close does nothing.
+func (d *VirtualDevice) Close() error {
+	return nil
+}
+
+// Effect returns current effect of the device and its attributes.
+func (d *VirtualDevice) Effect() *EffectState {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state := d.state
+	return &state
+}
+
+// Color returns predefined color given by its number.
+func (d *VirtualDevice) Color(colorNum byte) *Color {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if colorNum >= CustomColorNumMinValue && colorNum <= CustomColorNumMaxValue {
+		col := d.colors[colorNum]
+		return &col
+	}
+
+	return nil
+}
+
+// Frame returns colors of the keys of 'user' effect.
+func (d *VirtualDevice) Frame() *Frame {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	frame := d.frame
+	return &frame
+}
+
+// Snapshot returns frame showing the keyboard backlight as it looks
+// in the current state: colors of the keys in 'user' effect, a
+// simulation of any other effect (see SimulateEffect) or black keys
+// if it is turned off.
+func (d *VirtualDevice) Snapshot(layout *Layout) *Frame {
+
+	state := d.Effect()
+	if state.Effect == UserEffect && state.Control != OffState {
+		return d.Frame()
+	}
+
+	return SimulateEffect(state, d.Color, layout)
+}
@@ -0,0 +1,80 @@
+package ite8291
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VirtualDevice", func() {
+
+	var dev *VirtualDevice
+	var ctl *Controller
+	black, red := NewColor(0, 0, 0), NewColor(0xff, 0, 0)
+
+	BeforeEach(func() {
+		dev = NewVirtualDevice()
+		ctl = NewController(dev)
+	})
+
+	It("starts in 'user' effect with black keys", func() {
+		Ω(dev.Effect()).Should(Equal(&EffectState{Control: SetEffectOp, Effect: UserEffect, Brightness: 25}))
+		Ω(dev.Snapshot(DefaultLayout())).Should(Equal(NewFrame(black)))
+	})
+
+	It("keeps frames written in 'user' effect", func() {
+		frame := NewFrame(black)
+		frame.Set(Cell{Row: 4, Column: 17}, red)
+
+		var observed []*Frame
+		dev.OnFrame = func(f *Frame) { observed = append(observed, f) }
+
+		Ω(ctl.SetFrameMode(30, frame, false)).Should(Succeed())
+
+		Ω(dev.Frame()).Should(Equal(frame))
+		Ω(dev.Snapshot(DefaultLayout())).Should(Equal(frame))
+		Ω(observed).Should(Equal([]*Frame{frame}))
+		Ω(ctl.Brightness()).Should(BeEquivalentTo(30))
+	})
+
+	It("reports effect set by the controller", func() {
+		Ω(ctl.SetWaveMode(3, 40, DirectionUp, true)).Should(Succeed())
+
+		Ω(ctl.Effect()).Should(Equal(&EffectState{Control: SetEffectOp, Effect: WaveEffect, Speed: 3, Brightness: 40,
+			ReactOrDiv: byte(DirectionUp)}))
+		Ω(ctl.SetBrightness(7)).Should(Succeed())
+		Ω(ctl.Brightness()).Should(BeEquivalentTo(7))
+
+		Ω(ctl.SetOffMode()).Should(Succeed())
+		Ω(ctl.State()).Should(BeFalse())
+		Ω(dev.Snapshot(DefaultLayout())).Should(Equal(NewFrame(black)))
+	})
+
+	It("keeps predefined colors", func() {
+		Ω(dev.Color(2)).Should(Equal(red))
+		Ω(ctl.SetColor(2, NewColor(1, 2, 3))).Should(Succeed())
+		Ω(dev.Color(2)).Should(Equal(NewColor(1, 2, 3)))
+		Ω(dev.Color(ColorRandom)).Should(BeNil())
+	})
+
+	DescribeTable("simulates built-in effects",
+		func(set func() error, lit Cell, color *Color, unlit *Cell) {
+			Ω(set()).Should(Succeed())
+
+			frame := dev.Snapshot(DefaultLayout())
+			Ω(frame.Get(lit)).Should(Equal(color))
+			if unlit != nil {
+				Ω(frame.Get(*unlit)).Should(Equal(black))
+			}
+		},
+		Entry("breathing", func() error { return ctl.SetBreathingMode(1, 10, 2, false) },
+			Cell{Row: 3, Column: 3}, red, nil),
+		Entry("wave to the right", func() error { return ctl.SetWaveMode(1, 10, DirectionRight, false) },
+			Cell{Row: 3, Column: 0}, red, nil),
+		Entry("wave to the left", func() error { return ctl.SetWaveMode(1, 10, DirectionLeft, false) },
+			Cell{Row: 3, Column: 20}, red, nil),
+		Entry("marquee", func() error { return ctl.SetMarqueeMode(1, 10, false) },
+			Cell{Row: 0, Column: 5}, NewColor(0xff, 0xff, 0xff), &Cell{Row: 2, Column: 5}),
+		Entry("raindrop", func() error { return ctl.SetRaindropMode(1, 10, 2, false) },
+			Cell{Row: 0, Column: 0}, red, &Cell{Row: 0, Column: 1}),
+	)
+})
@@ -0,0 +1,358 @@
+package mqttbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// payloads of the light state and availability.
+const (
+	StateOn  = "ON"
+	StateOff = "OFF"
+
+	Online  = "online"
+	Offline = "offline"
+)
+
+// colorModeRGB - Home Assistant color mode of the light.
+const colorModeRGB = "rgb"
+
+// RGB provides color of the light.
+type RGB struct {
+	R uint8 `json:"r"`
+	G uint8 `json:"g"`
+	B uint8 `json:"b"`
+}
+
+// State provides state of the light published on the state topic.
+type State struct {
+	State      string `json:"state"`
+	Brightness byte   `json:"brightness"`
+	ColorMode  string `json:"color_mode"`
+	Color      RGB    `json:"color"`
+	Effect     string `json:"effect,omitempty"`
+}
+
+// Command provides command received on the command topic. The
+// omitted attributes are not changed.
+type Command struct {
+	State      string `json:"state"`
+	Brightness *byte  `json:"brightness"`
+	Color      *RGB   `json:"color"`
+	Effect     string `json:"effect"`
+}
+
+// Device provides device of the light in the discovery configuration.
+type Device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+}
+
+// Config provides Home Assistant discovery configuration of the
+// light in JSON schema.
+type Config struct {
+	Name                string   `json:"name"`
+	UniqueID            string   `json:"unique_id"`
+	Schema              string   `json:"schema"`
+	CommandTopic        string   `json:"command_topic"`
+	StateTopic          string   `json:"state_topic"`
+	AvailabilityTopic   string   `json:"availability_topic"`
+	Brightness          bool     `json:"brightness"`
+	BrightnessScale     int      `json:"brightness_scale"`
+	SupportedColorModes []string `json:"supported_color_modes"`
+	Effect              bool     `json:"effect"`
+	EffectList          []string `json:"effect_list"`
+	Device              Device   `json:"device"`
+}
+
+// Bridge exposes the keyboard backlight as a Home Assistant light.
+type Bridge struct {
+
+	// ID identifies the light; it's part of its topics and unique id.
+	ID string
+
+	// Name is the name of the light and its device.
+	Name string
+
+	// Version, if set, is the version of the device, e.g. firmware
+	// version.
+	Version string
+
+	// DiscoveryPrefix is the prefix of Home Assistant discovery topics.
+	DiscoveryPrefix string
+
+	// Effects are the names of the effects of the light.
+	Effects []string
+
+	// ApplyEffect, if set, applies the effect of the given name with
+	// the given brightness.
+	ApplyEffect func(ctx context.Context, effect string, brightness byte) error
+
+	// EffectName, if set, returns name of the effect of the given
+	// state or empty string if it's not one of the effects.
+	EffectName func(state *ite8291.EffectState) string
+
+	// OnError, if set, is called with errors of the commands.
+	OnError func(err error)
+
+	dev *ite8291.SharedDevice
+	ctl *ite8291.Controller
+
+	changes chan struct{} // signals possible change of the state
+
+	mu        sync.Mutex // serializes commands; guards fields below
+	ctx       context.Context
+	color     ite8291.Color // color set last
+	published []byte        // state published last
+}
+
+// NewBridge creates a new bridge of the given device with the given id.
+func NewBridge(dev ite8291.Device, id string) *Bridge {
+
+	b := &Bridge{ID: id, Name: "Keyboard Backlight", DiscoveryPrefix: "homeassistant",
+		changes: make(chan struct{}, 1), color: ite8291.Color{Red: 0xff, Green: 0xff, Blue: 0xff}}
+	b.dev = ite8291.NewSharedDevice(dev)
+	b.dev.OnChange = b.changed
+	b.ctl = ite8291.NewController(b.dev)
+
+	return b
+}
+
+// Device returns the device of the bridge. It can be used
+// concurrently with the bridge; its Close does nothing.
+func (b *Bridge) Device() ite8291.Device {
+	return b.dev
+}
+
+// baseTopic returns prefix of the topics of the light.
+func (b *Bridge) baseTopic() string {
+	return "itectl/" + b.ID
+}
+
+// StateTopic returns topic the state of the light is published to.
+func (b *Bridge) StateTopic() string {
+	return b.baseTopic() + "/state"
+}
+
+// CommandTopic returns topic the commands are received on.
+func (b *Bridge) CommandTopic() string {
+	return b.baseTopic() + "/set"
+}
+
+// AvailabilityTopic returns topic the availability of the light is
+// published to.
+func (b *Bridge) AvailabilityTopic() string {
+	return b.baseTopic() + "/availability"
+}
+
+// DiscoveryTopic returns topic the discovery configuration of the
+// light is published to.
+func (b *Bridge) DiscoveryTopic() string {
+	return fmt.Sprintf("%s/light/%s/config", b.DiscoveryPrefix, b.ID)
+}
+
+// Config returns discovery configuration of the light.
+func (b *Bridge) Config() *Config {
+
+	uniqueID := "itectl_" + b.ID
+
+	return &Config{
+		Name: b.Name, UniqueID: uniqueID, Schema: "json",
+		CommandTopic: b.CommandTopic(), StateTopic: b.StateTopic(), AvailabilityTopic: b.AvailabilityTopic(),
+		Brightness: true, BrightnessScale: ite8291.BrightnessMaxValue,
+		SupportedColorModes: []string{colorModeRGB},
+		Effect:              len(b.Effects) > 0, EffectList: b.Effects,
+		Device: Device{Identifiers: []string{uniqueID}, Name: b.Name, Manufacturer: "ITE", Model: "8291",
+			SWVersion: b.Version},
+	}
+}
+
+// Run publishes the discovery configuration and availability of the
+// light and applies the commands received via the given client. It
+// publishes the state after every change made via the bridge and
+// every refresh interval, if it's positive, till ctx is done or the
+// state can't be read from the device. Errors publishing the state are
+// reported via OnError. The light is published as offline then.
+func (b *Bridge) Run(ctx context.Context, client Client, refresh time.Duration) error {
+
+	b.mu.Lock()
+	b.ctx = ctx
+	b.mu.Unlock()
+
+	config, err := json.Marshal(b.Config())
+	if err != nil {
+		return err
+	}
+
+	if err := client.Subscribe(b.CommandTopic(), b.command); err != nil {
+		return err
+	}
+
+	if err := client.Publish(b.DiscoveryTopic(), true, config); err != nil {
+		return err
+	}
+
+	if err := client.Publish(b.AvailabilityTopic(), true, []byte(Online)); err != nil {
+		return err
+	}
+	defer func() { _ = client.Publish(b.AvailabilityTopic(), true, []byte(Offline)) }()
+
+	var tick <-chan time.Time
+	if refresh > 0 {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		if err := b.publishState(client); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-b.changes:
+		case <-tick:
+		}
+	}
+}
+
+// State returns the current state of the light.
+func (b *Bridge) State() (*State, error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state()
+}
+
+// state returns the current state of the light; it's called with
+// b.mu locked.
+func (b *Bridge) state() (*State, error) {
+
+	e, err := b.ctl.Effect()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{State: StateOn, Brightness: e.Brightness, ColorMode: colorModeRGB,
+		Color: RGB{R: b.color.Red, G: b.color.Green, B: b.color.Blue}}
+	if e.Control == ite8291.OffState {
+		state.State = StateOff
+	}
+	if b.EffectName != nil && state.State == StateOn {
+		state.Effect = b.EffectName(e)
+	}
+
+	return state, nil
+}
+
+// publishState publishes the state of the light if it has changed. It
+// returns error only if the state can't be read; errors publishing it
+// are reported via OnError and it's published again on the next
+// change or refresh.
+func (b *Bridge) publishState(client Client) error {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, err := b.state()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(state)
+	if err != nil || slices.Equal(payload, b.published) {
+		return err
+	}
+
+	if err := client.Publish(b.StateTopic(), true, payload); err != nil {
+		if b.OnError != nil {
+			b.OnError(fmt.Errorf("publishing state: %w", err))
+		}
+		return nil
+	}
+	b.published = payload
+
+	return nil
+}
+
+// changed signals that the state might have changed.
+func (b *Bridge) changed() {
+
+	select {
+	case b.changes <- struct{}{}:
+	default: // already signaled
+	}
+}
+
+// command applies the command given by payload.
+func (b *Bridge) command(payload []byte) {
+
+	var cmd Command
+	err := json.Unmarshal(payload, &cmd)
+	if err == nil {
+		err = b.Apply(&cmd)
+	}
+
+	if err != nil && b.OnError != nil {
+		b.OnError(fmt.Errorf("command %q: %w", payload, err))
+	}
+}
+
+// Apply applies the given command. Turning the light on with a color
+// sets all keys to the color; with an effect it applies the effect.
+// Turning it on without them restores the color set last if it's
+// off or changes its brightness otherwise.
+func (b *Bridge) Apply(cmd *Command) error {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	defer b.changed()
+
+	switch strings.ToUpper(cmd.State) {
+	case StateOff:
+		return b.ctl.SetOffMode()
+	case StateOn, "":
+	default:
+		return fmt.Errorf("unknown state %q; expected %q or %q", cmd.State, StateOn, StateOff)
+	}
+
+	e, err := b.ctl.Effect()
+	if err != nil {
+		return err
+	}
+
+	brightness := e.Brightness
+	if cmd.Brightness != nil {
+		brightness = min(*cmd.Brightness, ite8291.BrightnessMaxValue)
+	}
+
+	switch {
+	case len(cmd.Effect) > 0:
+		if b.ApplyEffect == nil || !slices.Contains(b.Effects, cmd.Effect) {
+			return fmt.Errorf("unknown effect %q; expected one of %q", cmd.Effect, b.Effects)
+		}
+		return b.ApplyEffect(b.ctx, cmd.Effect, brightness)
+
+	case cmd.Color != nil:
+		b.color = ite8291.Color{Red: cmd.Color.R, Green: cmd.Color.G, Blue: cmd.Color.B}
+		return b.ctl.SetSingleColorMode(brightness, &b.color, false)
+
+	case e.Control == ite8291.OffState:
+		return b.ctl.SetSingleColorMode(brightness, &b.color, false)
+	}
+
+	return b.ctl.SetBrightness(brightness)
+}
@@ -0,0 +1,277 @@
+package mqttbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeClient provides Client keeping the messages published last by
+// their topics.
+type fakeClient struct {
+	mu        sync.Mutex
+	published map[string][]byte
+	retained  map[string]bool
+	handlers  map[string]func(payload []byte)
+	failures  map[string]error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{published: make(map[string][]byte), retained: make(map[string]bool),
+		handlers: make(map[string]func(payload []byte)), failures: make(map[string]error)}
+}
+
+func (c *fakeClient) Publish(topic string, retained bool, payload []byte) error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.failures[topic]; err != nil {
+		return err
+	}
+
+	c.published[topic] = payload
+	c.retained[topic] = retained
+
+	return nil
+}
+
+func (c *fakeClient) Subscribe(topic string, handler func(payload []byte)) error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.handlers[topic] = handler
+
+	return nil
+}
+
+func (c *fakeClient) Close() error {
+	return nil
+}
+
+// message returns payload published last to the given topic.
+func (c *fakeClient) message(topic string) string {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return string(c.published[topic])
+}
+
+// fail makes publishing to the given topic fail with the given error
+// or succeed again if it's nil.
+func (c *fakeClient) fail(topic string, err error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures[topic] = err
+}
+
+// deliver delivers the given payload to the handler of the given topic.
+func (c *fakeClient) deliver(topic, payload string) {
+
+	c.mu.Lock()
+	handler := c.handlers[topic]
+	c.mu.Unlock()
+
+	Ω(handler).ShouldNot(BeNil())
+	handler([]byte(payload))
+}
+
+var _ = Describe("MQTT bridge", func() {
+
+	var dev *ite8291.VirtualDevice
+	var bridge *Bridge
+	var client *fakeClient
+	var applied []string
+	var errs chan error
+	var cancel context.CancelFunc
+	var ran chan error
+
+	// run starts the bridge.
+	run := func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		ran = make(chan error, 1)
+		go func() { ran <- bridge.Run(ctx, client, 0) }()
+
+		Eventually(func() string { return client.message(bridge.StateTopic()) }).ShouldNot(BeEmpty())
+	}
+
+	// state returns state published last.
+	state := func() *State {
+		var s State
+		Ω(json.Unmarshal([]byte(client.message(bridge.StateTopic())), &s)).Should(Succeed())
+		return &s
+	}
+
+	BeforeEach(func() {
+		dev = ite8291.NewVirtualDevice()
+		bridge = NewBridge(dev, "kbd")
+		bridge.Version = "0.02"
+		bridge.Effects = []string{"rainbow", "wave"}
+		applied = nil
+		bridge.ApplyEffect = func(_ context.Context, effect string, brightness byte) error {
+			applied = append(applied, effect)
+			return ite8291.NewController(bridge.Device()).SetWaveMode(5, brightness, ite8291.DirectionLeft, false)
+		}
+		bridge.EffectName = func(state *ite8291.EffectState) string {
+			if state.Effect == ite8291.WaveEffect {
+				return "wave"
+			}
+			return ""
+		}
+		errs = make(chan error, 10)
+		bridge.OnError = func(err error) { errs <- err }
+		client = newFakeClient()
+	})
+
+	AfterEach(func() {
+		if cancel != nil {
+			cancel()
+			Eventually(ran).Should(Receive(BeNil()))
+			cancel = nil
+		}
+	})
+
+	It("publishes discovery configuration, availability and state", func() {
+		Ω(ite8291.NewController(dev).SetUserMode(20, false)).Should(Succeed())
+		run()
+
+		var config map[string]any
+		Ω(json.Unmarshal([]byte(client.message("homeassistant/light/kbd/config")), &config)).Should(Succeed())
+		Ω(config).Should(HaveKeyWithValue("schema", "json"))
+		Ω(config).Should(HaveKeyWithValue("unique_id", "itectl_kbd"))
+		Ω(config).Should(HaveKeyWithValue("command_topic", "itectl/kbd/set"))
+		Ω(config).Should(HaveKeyWithValue("state_topic", "itectl/kbd/state"))
+		Ω(config).Should(HaveKeyWithValue("availability_topic", "itectl/kbd/availability"))
+		Ω(config).Should(HaveKeyWithValue("brightness_scale", BeNumerically("==", ite8291.BrightnessMaxValue)))
+		Ω(config).Should(HaveKeyWithValue("supported_color_modes", ConsistOf("rgb")))
+		Ω(config).Should(HaveKeyWithValue("effect_list", ConsistOf("rainbow", "wave")))
+		Ω(config).Should(HaveKeyWithValue("device", HaveKeyWithValue("sw_version", "0.02")))
+		Ω(client.retained).Should(HaveKeyWithValue("homeassistant/light/kbd/config", true))
+
+		Ω(client.message(bridge.AvailabilityTopic())).Should(Equal(Online))
+		Ω(state()).Should(Equal(&State{State: StateOn, Brightness: 20, ColorMode: "rgb",
+			Color: RGB{R: 0xff, G: 0xff, B: 0xff}}))
+
+		cancel()
+		Eventually(ran).Should(Receive(BeNil()))
+		cancel = nil
+		Ω(client.message(bridge.AvailabilityTopic())).Should(Equal(Offline))
+	})
+
+	It("applies color, brightness and state commands", func() {
+		run()
+
+		client.deliver(bridge.CommandTopic(), `{"state": "ON", "color": {"r": 0, "g": 255, "b": 0}, "brightness": 30}`)
+		Ω(dev.Effect().Effect).Should(Equal(byte(ite8291.UserEffect)))
+		Ω(dev.Effect().Brightness).Should(Equal(byte(30)))
+		Ω(dev.Frame()[0][0]).Should(Equal(*ite8291.NewColor(0, 0xff, 0)))
+		Eventually(state).Should(Equal(&State{State: StateOn, Brightness: 30, ColorMode: "rgb",
+			Color: RGB{G: 0xff}}))
+
+		client.deliver(bridge.CommandTopic(), `{"state": "OFF"}`)
+		Ω(dev.Effect().Control).Should(Equal(byte(ite8291.OffState)))
+		Eventually(func() string { return state().State }).Should(Equal(StateOff))
+
+		// turning on restores the color
+		client.deliver(bridge.CommandTopic(), `{"state": "ON", "brightness": 60}`)
+		Ω(dev.Effect().Control).ShouldNot(Equal(byte(ite8291.OffState)))
+		Ω(dev.Effect().Brightness).Should(Equal(byte(ite8291.BrightnessMaxValue)))
+		Ω(dev.Frame()[5][17]).Should(Equal(*ite8291.NewColor(0, 0xff, 0)))
+
+		client.deliver(bridge.CommandTopic(), `{"brightness": 10}`)
+		Ω(dev.Effect().Brightness).Should(Equal(byte(10)))
+		Eventually(func() byte { return state().Brightness }).Should(Equal(byte(10)))
+		Ω(errs).ShouldNot(Receive())
+	})
+
+	It("applies effects", func() {
+		run()
+
+		client.deliver(bridge.CommandTopic(), `{"state": "ON", "effect": "wave", "brightness": 40}`)
+		Ω(applied).Should(Equal([]string{"wave"}))
+		Ω(dev.Effect().Effect).Should(Equal(byte(ite8291.WaveEffect)))
+		Ω(dev.Effect().Brightness).Should(Equal(byte(40)))
+		Eventually(func() string { return state().Effect }).Should(Equal("wave"))
+
+		client.deliver(bridge.CommandTopic(), `{"effect": "breath"}`)
+		Ω(errs).Should(Receive(MatchError(ContainSubstring(`unknown effect "breath"`))))
+		Ω(applied).Should(HaveLen(1))
+	})
+
+	It("reports invalid commands", func() {
+		run()
+
+		client.deliver(bridge.CommandTopic(), `{"state": "DIM"}`)
+		Ω(errs).Should(Receive(MatchError(ContainSubstring(`unknown state "DIM"`))))
+
+		client.deliver(bridge.CommandTopic(), `on`)
+		Ω(errs).Should(Receive(MatchError(ContainSubstring(`command "on"`))))
+	})
+
+	It("reports errors publishing the state and keeps running", func() {
+		run()
+
+		client.fail(bridge.StateTopic(), errors.New("broker is gone"))
+		client.deliver(bridge.CommandTopic(), `{"brightness": 10}`)
+		Eventually(errs).Should(Receive(MatchError(ContainSubstring("broker is gone"))))
+		Ω(ran).ShouldNot(Receive())
+
+		client.fail(bridge.StateTopic(), nil)
+		client.deliver(bridge.CommandTopic(), `{"brightness": 20}`)
+		Eventually(func() byte { return state().Brightness }).Should(Equal(byte(20)))
+	})
+
+	It("communicates via MQTT broker", func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		address := l.Addr().String()
+		Ω(l.Close()).Should(Succeed())
+
+		broker := mochi.New(&mochi.Options{InlineClient: true,
+			Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+		Ω(broker.AddHook(new(auth.AllowHook), nil)).Should(Succeed())
+		Ω(broker.AddListener(listeners.NewTCP(listeners.Config{ID: "test", Address: address}))).Should(Succeed())
+		Ω(broker.Serve()).Should(Succeed())
+		DeferCleanup(broker.Close)
+
+		states := make(chan string, 10)
+		Ω(broker.Subscribe(bridge.StateTopic(), 1, func(_ *mochi.Client, _ packets.Subscription, pk packets.Packet) {
+			states <- string(pk.Payload)
+		})).Should(Succeed())
+
+		c, err := Dial(&Options{Broker: "tcp://" + address, ClientID: "itectl-test",
+			WillTopic: bridge.AvailabilityTopic(), WillPayload: Offline, Timeout: 5 * time.Second})
+		Ω(err).ShouldNot(HaveOccurred())
+		DeferCleanup(c.Close)
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		ran = make(chan error, 1)
+		go func() { ran <- bridge.Run(ctx, c, 0) }()
+
+		Eventually(states).Should(Receive(ContainSubstring(`"state":"ON"`)))
+
+		Ω(broker.Publish(bridge.CommandTopic(), []byte(`{"state": "OFF"}`), false, 1)).Should(Succeed())
+		Eventually(states).Should(Receive(ContainSubstring(`"state":"OFF"`)))
+		Ω(dev.Effect().Control).Should(Equal(byte(ite8291.OffState)))
+	})
+})
@@ -0,0 +1,132 @@
+package mqttbridge
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// ErrTimeout error indicates that MQTT operation has not completed in
+// time.
+var ErrTimeout = errors.New("MQTT operation timed out")
+
+// quality of service of the published and subscribed messages.
+const qos = 1
+
+// disconnectQuiesce - time in milliseconds to wait for the pending
+// work on disconnect.
+const disconnectQuiesce = 250
+
+// Client abstracts MQTT client used by the bridge.
+type Client interface {
+
+	// Publish publishes the given payload to the given topic. Retained
+	// messages are kept by the broker for the future subscribers.
+	Publish(topic string, retained bool, payload []byte) error
+
+	// Subscribe calls handler with the payload of every message
+	// received on the given topic.
+	Subscribe(topic string, handler func(payload []byte)) error
+
+	// Close disconnects the client.
+	Close() error
+}
+
+// Options provides options of the connection to MQTT broker.
+type Options struct {
+	Broker   string // e.g. tcp://localhost:1883
+	ClientID string
+	Username string
+	Password string
+
+	// WillTopic, if set, is the topic the broker publishes retained
+	// WillPayload to when the client disconnects unexpectedly.
+	WillTopic   string
+	WillPayload string
+
+	Timeout time.Duration // timeout of connecting and publishing
+}
+
+// pahoClient provides Client connected to MQTT broker by paho MQTT
+// client.
+type pahoClient struct {
+	client  mqtt.Client
+	timeout time.Duration
+
+	mu       sync.Mutex // guards handlers
+	handlers map[string]func(payload []byte)
+}
+
+// Dial connects to MQTT broker with the given options. The connection
+// is restored and the subscriptions are renewed if it's lost.
+func Dial(opts *Options) (Client, error) {
+
+	c := &pahoClient{timeout: opts.Timeout, handlers: make(map[string]func(payload []byte))}
+
+	o := mqtt.NewClientOptions().AddBroker(opts.Broker).SetClientID(opts.ClientID).
+		SetUsername(opts.Username).SetPassword(opts.Password).SetConnectTimeout(opts.Timeout).
+		SetAutoReconnect(true).SetOrderMatters(false).SetOnConnectHandler(c.resubscribe)
+	if len(opts.WillTopic) > 0 {
+		o.SetWill(opts.WillTopic, opts.WillPayload, qos, true)
+	}
+
+	c.client = mqtt.NewClient(o)
+	if err := c.wait(c.client.Connect()); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// wait waits for the given token to complete and returns its error.
+func (c *pahoClient) wait(t mqtt.Token) error {
+
+	if c.timeout > 0 && !t.WaitTimeout(c.timeout) {
+		return ErrTimeout
+	}
+	t.Wait()
+
+	return t.Error()
+}
+
+// resubscribe renews the subscriptions after the connection is restored.
+func (c *pahoClient) resubscribe(mqtt.Client) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for topic, handler := range c.handlers {
+		c.client.Subscribe(topic, qos, message(handler)) // completes in background
+	}
+}
+
+// Publish publishes the given payload to the given topic.
+func (c *pahoClient) Publish(topic string, retained bool, payload []byte) error {
+	return c.wait(c.client.Publish(topic, qos, retained, payload))
+}
+
+// Subscribe calls handler with the payload of every message received
+// on the given topic.
+func (c *pahoClient) Subscribe(topic string, handler func(payload []byte)) error {
+
+	c.mu.Lock()
+	c.handlers[topic] = handler
+	c.mu.Unlock()
+
+	return c.wait(c.client.Subscribe(topic, qos, message(handler)))
+}
+
+// Close disconnects the client.
+func (c *pahoClient) Close() error {
+
+	c.client.Disconnect(disconnectQuiesce)
+
+	return nil
+}
+
+// message returns paho message handler calling the given handler.
+func message(handler func(payload []byte)) mqtt.MessageHandler {
+	return func(_ mqtt.Client, m mqtt.Message) { handler(m.Payload()) }
+}
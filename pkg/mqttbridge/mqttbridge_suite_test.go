@@ -0,0 +1,13 @@
+package mqttbridge
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMqttbridge(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Mqttbridge Suite")
+}
@@ -0,0 +1,13 @@
+package openrgb
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOpenrgb(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Openrgb Suite")
+}
@@ -0,0 +1,288 @@
+package openrgb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// ErrInvalidPacket error indicates that a packet received from a
+// client is malformed.
+var ErrInvalidPacket = errors.New("invalid OpenRGB packet")
+
+// ProtocolVersion - the latest version of OpenRGB SDK protocol
+// supported by the server.
+const ProtocolVersion = 3
+
+// DefaultPort - default port of OpenRGB SDK server.
+const DefaultPort = 6742
+
+// magic - magic of the packet headers.
+const magic = "ORGB"
+
+// headerSize - size of the packet header.
+const headerSize = 16
+
+// maxPacketSize - maximum size of a packet received by the server.
+const maxPacketSize = 1 << 20
+
+// OpenRGB SDK packet ids.
+const (
+	RequestControllerCount = 0
+	RequestControllerData  = 1
+	RequestProtocolVersion = 40
+	SetClientName          = 50
+	DeviceListUpdated      = 100
+	RequestProfileList     = 150
+	RequestSaveProfile     = 151
+	RequestLoadProfile     = 152
+	RequestDeleteProfile   = 153
+	ResizeZone             = 1000
+	UpdateLEDs             = 1050
+	UpdateZoneLEDs         = 1051
+	UpdateSingleLED        = 1052
+	SetCustomMode          = 1100
+	UpdateMode             = 1101
+	SaveMode               = 1102
+)
+
+// OpenRGB device type of keyboards.
+const DeviceTypeKeyboard = 5
+
+// OpenRGB zone types.
+const (
+	ZoneTypeSingle = 0
+	ZoneTypeLinear = 1
+	ZoneTypeMatrix = 2
+)
+
+// OpenRGB mode flags.
+const (
+	ModeFlagHasSpeed             = 1 << 0
+	ModeFlagHasDirectionLR       = 1 << 1
+	ModeFlagHasDirectionUD       = 1 << 2
+	ModeFlagHasDirectionHV       = 1 << 3
+	ModeFlagHasBrightness        = 1 << 4
+	ModeFlagHasPerLEDColor       = 1 << 5
+	ModeFlagHasModeSpecificColor = 1 << 6
+	ModeFlagHasRandomColor       = 1 << 7
+	ModeFlagManualSave           = 1 << 8
+)
+
+// OpenRGB mode color modes.
+const (
+	ColorModeNone         = 0
+	ColorModePerLED       = 1
+	ColorModeModeSpecific = 2
+	ColorModeRandom       = 3
+)
+
+// OpenRGB mode directions.
+const (
+	DirectionLeft  = 0
+	DirectionRight = 1
+	DirectionUp    = 2
+	DirectionDown  = 3
+)
+
+// noLED - value of the cells of the zone matrix without a LED.
+const noLED = 0xFFFFFFFF
+
+// Header provides header of OpenRGB SDK packets.
+type Header struct {
+	DeviceIndex uint32
+	ID          uint32
+	Size        uint32
+}
+
+// ReadHeader reads packet header from the given reader.
+func ReadHeader(r io.Reader) (*Header, error) {
+
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, err
+	}
+
+	if string(buf[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: magic %q", ErrInvalidPacket, buf[:len(magic)])
+	}
+
+	return &Header{DeviceIndex: binary.LittleEndian.Uint32(buf[4:]), ID: binary.LittleEndian.Uint32(buf[8:]),
+		Size: binary.LittleEndian.Uint32(buf[12:])}, nil
+}
+
+// WritePacket writes packet of the given device index and id with the
+// given data to the given writer.
+func WritePacket(w io.Writer, devIdx, id uint32, data []byte) error {
+
+	buf := make([]byte, headerSize, headerSize+len(data))
+	copy(buf, magic)
+	binary.LittleEndian.PutUint32(buf[4:], devIdx)
+	binary.LittleEndian.PutUint32(buf[8:], id)
+	binary.LittleEndian.PutUint32(buf[12:], uint32(len(data)))
+
+	_, err := w.Write(append(buf, data...))
+
+	return err
+}
+
+// Mode provides OpenRGB mode description.
+type Mode struct {
+	Name          string
+	Value         int32
+	Flags         uint32
+	SpeedMin      uint32
+	SpeedMax      uint32
+	BrightnessMin uint32
+	BrightnessMax uint32
+	ColorsMin     uint32
+	ColorsMax     uint32
+	Speed         uint32
+	Brightness    uint32
+	Direction     uint32
+	ColorMode     uint32
+	Colors        []ite8291.Color
+}
+
+// encoder encodes values of OpenRGB SDK packets.
+type encoder struct {
+	bytes.Buffer
+}
+
+// u16 encodes the given uint16 value.
+func (e *encoder) u16(v uint16) {
+	_ = binary.Write(e, binary.LittleEndian, v)
+}
+
+// u32 encodes the given uint32 value.
+func (e *encoder) u32(v uint32) {
+	_ = binary.Write(e, binary.LittleEndian, v)
+}
+
+// str encodes the given string with its length and terminating null.
+func (e *encoder) str(s string) {
+	e.u16(uint16(len(s) + 1))
+	e.WriteString(s)
+	e.WriteByte(0)
+}
+
+// color encodes the given color.
+func (e *encoder) color(c *ite8291.Color) {
+	e.Write([]byte{c.Red, c.Green, c.Blue, 0})
+}
+
+// colors encodes the given colors with their number.
+func (e *encoder) colors(colors []ite8291.Color) {
+	e.u16(uint16(len(colors)))
+	for i := range colors {
+		e.color(&colors[i])
+	}
+}
+
+// mode encodes the given mode in the given protocol version.
+func (e *encoder) mode(m *Mode, version uint32) {
+
+	e.str(m.Name)
+	e.u32(uint32(m.Value))
+	e.u32(m.Flags)
+	e.u32(m.SpeedMin)
+	e.u32(m.SpeedMax)
+	if version >= 3 {
+		e.u32(m.BrightnessMin)
+		e.u32(m.BrightnessMax)
+	}
+	e.u32(m.ColorsMin)
+	e.u32(m.ColorsMax)
+	e.u32(m.Speed)
+	if version >= 3 {
+		e.u32(m.Brightness)
+	}
+	e.u32(m.Direction)
+	e.u32(m.ColorMode)
+	e.colors(m.Colors)
+}
+
+// sized returns the encoded data prefixed by its size including the
+// size itself.
+func (e *encoder) sized() []byte {
+	return binary.LittleEndian.AppendUint32(nil, uint32(e.Len()+4))
+}
+
+// decoder decodes values of OpenRGB SDK packets. The first error
+// stops decoding; the following values are zero.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+// next returns the next n bytes of the data.
+func (d *decoder) next(n int) []byte {
+
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	if len(d.data) < n {
+		d.err = fmt.Errorf("%w: %d bytes left, %d expected", ErrInvalidPacket, len(d.data), n)
+		return make([]byte, n)
+	}
+
+	b := d.data[:n]
+	d.data = d.data[n:]
+
+	return b
+}
+
+// u16 decodes uint16 value.
+func (d *decoder) u16() uint16 {
+	return binary.LittleEndian.Uint16(d.next(2))
+}
+
+// u32 decodes uint32 value.
+func (d *decoder) u32() uint32 {
+	return binary.LittleEndian.Uint32(d.next(4))
+}
+
+// str decodes string with its length and terminating null.
+func (d *decoder) str() string {
+	return string(bytes.TrimRight(d.next(int(d.u16())), "\x00"))
+}
+
+// color decodes color.
+func (d *decoder) color() ite8291.Color {
+
+	b := d.next(4)
+	return ite8291.Color{Red: b[0], Green: b[1], Blue: b[2]}
+}
+
+// colors decodes colors with their number.
+func (d *decoder) colors() []ite8291.Color {
+
+	colors := make([]ite8291.Color, d.u16())
+	for i := range colors {
+		if colors[i] = d.color(); d.err != nil {
+			return nil
+		}
+	}
+
+	return colors
+}
+
+// mode decodes mode in the given protocol version.
+func (d *decoder) mode(version uint32) *Mode {
+
+	m := &Mode{Name: d.str(), Value: int32(d.u32()), Flags: d.u32(), SpeedMin: d.u32(), SpeedMax: d.u32()}
+	if version >= 3 {
+		m.BrightnessMin, m.BrightnessMax = d.u32(), d.u32()
+	}
+	m.ColorsMin, m.ColorsMax, m.Speed = d.u32(), d.u32(), d.u32()
+	if version >= 3 {
+		m.Brightness = d.u32()
+	}
+	m.Direction, m.ColorMode, m.Colors = d.u32(), d.u32(), d.colors()
+
+	return m
+}
@@ -0,0 +1,558 @@
+package openrgb
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// device description reported to the clients.
+const (
+	deviceName        = "ITE 8291 Keyboard Backlight"
+	deviceVendor      = "ITE"
+	deviceDescription = "ITE 8291 (rev 0.0.3) keyboard backlight controlled by itectl"
+	deviceLocation    = "itectl"
+	zoneName          = "Keyboard"
+)
+
+// effectMode provides OpenRGB mode of ite8291r3 effect.
+type effectMode struct {
+	name   string
+	effect byte
+	flags  uint32
+}
+
+// indexes of the modes without built-in effect.
+const (
+	directModeIdx = 0
+	offModeIdx    = 1
+)
+
+// effect modes flags.
+const (
+	colorModeFlags  = ModeFlagHasModeSpecificColor | ModeFlagHasRandomColor
+	effectModeFlags = ModeFlagHasSpeed | ModeFlagHasBrightness | ModeFlagManualSave
+)
+
+// modes are OpenRGB modes presented by the server. Direct mode shows
+// colors of the LEDs in 'user' effect.
+var modes = []effectMode{
+	{name: "Direct", effect: ite8291.UserEffect,
+		flags: ModeFlagHasPerLEDColor | ModeFlagHasBrightness | ModeFlagManualSave},
+	{name: "Off"},
+	{name: "Aurora", effect: ite8291.AuroraEffect, flags: effectModeFlags | colorModeFlags},
+	{name: "Breathing", effect: ite8291.BreathingEffect, flags: effectModeFlags | colorModeFlags},
+	{name: "Fireworks", effect: ite8291.FireworksEffect, flags: effectModeFlags | colorModeFlags},
+	{name: "Marquee", effect: ite8291.MarqueeEffect, flags: effectModeFlags},
+	{name: "Rainbow", effect: ite8291.RainbowEffect, flags: ModeFlagHasBrightness | ModeFlagManualSave},
+	{name: "Raindrop", effect: ite8291.RaindropEffect, flags: effectModeFlags | colorModeFlags},
+	{name: "Random", effect: ite8291.RandomEffect, flags: effectModeFlags | colorModeFlags},
+	{name: "Ripple", effect: ite8291.RippleEffect, flags: effectModeFlags | colorModeFlags},
+	{name: "Wave", effect: ite8291.WaveEffect,
+		flags: effectModeFlags | ModeFlagHasDirectionLR | ModeFlagHasDirectionUD},
+}
+
+// directions maps OpenRGB directions to ite8291r3 directions.
+var directions = map[uint32]ite8291.Direction{
+	DirectionLeft:  ite8291.DirectionLeft,
+	DirectionRight: ite8291.DirectionRight,
+	DirectionUp:    ite8291.DirectionUp,
+	DirectionDown:  ite8291.DirectionDown,
+}
+
+// Server serves ite8291r3 keyboard backlight to OpenRGB clients as a
+// keyboard device with a single matrix zone. Its LEDs are the keys of
+// the layout; modes are Direct showing colors of the LEDs, Off and
+// the built-in effects.
+type Server struct {
+
+	// Version is the version of the device reported to the clients,
+	// e.g. firmware version.
+	Version string
+
+	// Colors are the predefined colors of the controller reported as
+	// colors of the effects until they are set by the clients.
+	Colors []*ite8291.Color
+
+	// Frame, if set, provides initial colors of the LEDs.
+	Frame *ite8291.Frame
+
+	dev    *ite8291.SharedDevice
+	ctl    *ite8291.Controller
+	leds   []ite8291.Key
+	matrix [ite8291.RowsNumber][ite8291.ColumnsNumber]uint32
+
+	once      sync.Once
+	mu        sync.Mutex // serializes operations; guards fields below
+	ledColors []ite8291.Color
+	colors    [ite8291.CustomColorNumMaxValue]ite8291.Color // predefined colors
+}
+
+// NewServer creates a new server of the given device. Its LEDs are
+// the keys of the given layout.
+func NewServer(dev ite8291.Device, layout *ite8291.Layout) *Server {
+
+	s := &Server{dev: ite8291.NewSharedDevice(dev)}
+	s.ctl = ite8291.NewController(s.dev)
+
+	for i := range s.matrix {
+		for j := range s.matrix[i] {
+			s.matrix[i][j] = noLED
+		}
+	}
+
+	for _, key := range layout.Keys {
+		if !key.Cell().Valid() {
+			continue
+		}
+		if s.matrix[key.Row][key.Column] == noLED {
+			s.matrix[key.Row][key.Column] = uint32(len(s.leds))
+		}
+		s.leds = append(s.leds, key)
+	}
+
+	return s
+}
+
+// Device returns the device served by s. It can be used concurrently
+// with the clients of s; its Close does nothing.
+func (s *Server) Device() ite8291.Device {
+	return s.dev
+}
+
+// init initializes colors of the LEDs and the predefined colors.
+func (s *Server) init() {
+
+	s.once.Do(func() {
+		s.ledColors = make([]ite8291.Color, len(s.leds))
+		if s.Frame != nil {
+			for i := range s.leds {
+				s.ledColors[i] = *s.Frame.Get(s.leds[i].Cell())
+			}
+		}
+
+		for i, color := range s.Colors {
+			if i < len(s.colors) && color != nil {
+				s.colors[i] = *color
+			}
+		}
+	})
+}
+
+// Serve accepts connections of l and serves their requests till ctx
+// is done. It closes l and all connections and waits for their
+// requests to finish before returning.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+
+	s.init()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := context.AfterFunc(ctx, func() { _ = l.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+
+	var err error
+	for {
+		var nc net.Conn
+		if nc, err = l.Accept(); err != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, nc)
+		}()
+	}
+
+	if ctx.Err() != nil {
+		err = nil // closed on purpose
+	}
+
+	cancel()
+	wg.Wait()
+
+	return err
+}
+
+// serveConn serves requests received over the given connection till
+// it's closed or a malformed packet is received.
+func (s *Server) serveConn(ctx context.Context, nc net.Conn) {
+
+	defer nc.Close()
+	stop := context.AfterFunc(ctx, func() { _ = nc.Close() })
+	defer stop()
+
+	r := bufio.NewReader(nc)
+	var version uint32 // protocol version negotiated with the client
+
+	for {
+		h, err := ReadHeader(r)
+		if err != nil || h.Size > maxPacketSize {
+			return
+		}
+
+		data := make([]byte, h.Size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return
+		}
+
+		reply, err := s.handle(h, data, &version)
+		if err != nil {
+			continue // OpenRGB SDK protocol has no errors; the request is ignored
+		}
+
+		if reply != nil {
+			if WritePacket(nc, h.DeviceIndex, h.ID, reply) != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle handles the request with the given header and data and
+// returns data of the reply, if the request is answered. version is
+// the protocol version negotiated with the client.
+//
+//nolint:cyclop
+func (s *Server) handle(h *Header, data []byte, version *uint32) ([]byte, error) {
+
+	d := &decoder{data: data}
+
+	switch h.ID {
+	case RequestControllerCount:
+		var e encoder
+		e.u32(1)
+		return e.Bytes(), nil
+
+	case RequestProtocolVersion:
+		*version = min(d.u32(), ProtocolVersion)
+		var e encoder
+		e.u32(ProtocolVersion)
+		return e.Bytes(), d.err
+
+	case SetClientName, ResizeZone, RequestSaveProfile, RequestLoadProfile, RequestDeleteProfile:
+		return nil, nil // nothing to do
+
+	case RequestProfileList:
+		var e encoder
+		e.u16(0) // profiles are not supported
+		return append(e.sized(), e.Bytes()...), nil
+	}
+
+	if h.DeviceIndex != 0 {
+		return nil, fmt.Errorf("%w: device %d", ErrInvalidPacket, h.DeviceIndex)
+	}
+
+	switch h.ID {
+	case RequestControllerData:
+		v := *version
+		if len(data) >= 4 {
+			v = min(d.u32(), ProtocolVersion)
+		}
+		return s.controllerData(v)
+
+	case UpdateLEDs:
+		d.u32() // data size
+		return nil, s.updateLEDs(0, d.colors(), d)
+
+	case UpdateZoneLEDs:
+		d.u32() // data size
+		if zone := d.u32(); zone != 0 {
+			return nil, fmt.Errorf("%w: zone %d", ErrInvalidPacket, zone)
+		}
+		return nil, s.updateLEDs(0, d.colors(), d)
+
+	case UpdateSingleLED:
+		idx := d.u32()
+		return nil, s.updateLEDs(int(idx), []ite8291.Color{d.color()}, d)
+
+	case SetCustomMode:
+		return nil, s.showLEDs()
+
+	case UpdateMode, SaveMode:
+		d.u32() // data size
+		idx := d.u32()
+		m := d.mode(*version)
+		if d.err != nil {
+			return nil, d.err
+		}
+		return nil, s.setMode(int(idx), m, *version >= 3, h.ID == SaveMode)
+	}
+
+	return nil, fmt.Errorf("%w: id %d", ErrInvalidPacket, h.ID)
+}
+
+// controllerData returns description of the device in the given
+// protocol version.
+func (s *Server) controllerData(version uint32) ([]byte, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.ctl.Effect()
+	if err != nil {
+		return nil, err
+	}
+
+	var e encoder
+	e.u32(DeviceTypeKeyboard)
+	e.str(deviceName)
+	if version >= 1 {
+		e.str(deviceVendor)
+	}
+	e.str(deviceDescription)
+	e.str(s.Version)
+	e.str("") // serial
+	e.str(deviceLocation)
+
+	e.u16(uint16(len(modes)))
+	e.u32(uint32(activeMode(state)))
+	for i := range modes {
+		e.mode(s.describeMode(i, state), version)
+	}
+
+	e.u16(1) // zones
+	e.str(zoneName)
+	e.u32(ZoneTypeMatrix)
+	e.u32(uint32(len(s.leds)))
+	e.u32(uint32(len(s.leds)))
+	e.u32(uint32(len(s.leds)))
+	e.u16(uint16(8 + 4*ite8291.RowsNumber*ite8291.ColumnsNumber)) // matrix size
+	e.u32(ite8291.RowsNumber)
+	e.u32(ite8291.ColumnsNumber)
+	for i := range s.matrix {
+		for j := range s.matrix[i] {
+			e.u32(s.matrix[i][j])
+		}
+	}
+
+	e.u16(uint16(len(s.leds)))
+	for i := range s.leds {
+		e.str(s.leds[i].Name)
+		e.u32(uint32(s.leds[i].Row*ite8291.ColumnsNumber + s.leds[i].Column))
+	}
+
+	e.colors(s.ledColors)
+
+	return append(e.sized(), e.Bytes()...), nil
+}
+
+// activeMode returns index of the mode of the given state.
+func activeMode(state *ite8291.EffectState) int {
+
+	if state.Control == ite8291.OffState {
+		return offModeIdx
+	}
+
+	for i := range modes {
+		if i != offModeIdx && modes[i].effect == state.Effect {
+			return i
+		}
+	}
+
+	return directModeIdx
+}
+
+// describeMode returns description of the mode of the given index
+// with the attributes of the given state if it's the active mode.
+func (s *Server) describeMode(idx int, state *ite8291.EffectState) *Mode {
+
+	em := &modes[idx]
+	active := idx == activeMode(state)
+
+	m := &Mode{Name: em.name, Value: int32(em.effect), Flags: em.flags}
+
+	if em.flags&ModeFlagHasBrightness != 0 {
+		m.BrightnessMax = ite8291.BrightnessMaxValue
+		m.Brightness = uint32(state.Brightness)
+	}
+
+	if em.flags&ModeFlagHasSpeed != 0 {
+		m.SpeedMax = ite8291.SpeedMaxValue
+		m.Speed = ite8291.SpeedMaxValue / 2
+		if active && state.Speed <= ite8291.SpeedMaxValue {
+			m.Speed = uint32(ite8291.SpeedMaxValue - state.Speed) // the device is faster with lower values
+		}
+	}
+
+	if em.flags&ModeFlagHasDirectionLR != 0 {
+		m.Direction = DirectionRight
+		for dir, ite8291Dir := range directions {
+			if active && byte(ite8291Dir) == state.ReactOrDiv {
+				m.Direction = dir
+			}
+		}
+	}
+
+	switch {
+	case em.flags&ModeFlagHasPerLEDColor != 0:
+		m.ColorMode = ColorModePerLED
+
+	case em.flags&ModeFlagHasModeSpecificColor != 0:
+		m.ColorsMin, m.ColorsMax = 1, 1
+		m.ColorMode = ColorModeModeSpecific
+		m.Colors = []ite8291.Color{s.colors[0]}
+
+		if active {
+			switch {
+			case state.ColorNum == ite8291.ColorRandom:
+				m.ColorMode = ColorModeRandom
+			case state.ColorNum >= ite8291.CustomColorNumMinValue && state.ColorNum <= ite8291.CustomColorNumMaxValue:
+				m.Colors[0] = s.colors[state.ColorNum-ite8291.CustomColorNumMinValue]
+			}
+		}
+	}
+
+	return m
+}
+
+// setMode sets the mode of the given index with the attributes of
+// the given mode. Brightness of the mode is ignored unless
+// hasBrightness is true.
+func (s *Server) setMode(idx int, m *Mode, hasBrightness, save bool) error {
+
+	if idx < 0 || idx >= len(modes) {
+		return fmt.Errorf("%w: mode %d", ErrInvalidPacket, idx)
+	}
+	em := &modes[idx]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx == offModeIdx {
+		return s.ctl.SetOffMode()
+	}
+
+	state, err := s.ctl.Effect()
+	if err != nil {
+		return err
+	}
+
+	brightness := state.Brightness
+	if hasBrightness {
+		brightness = byte(min(m.Brightness, ite8291.BrightnessMaxValue))
+	}
+
+	if idx == directModeIdx {
+		if err := s.ctl.SetUserMode(brightness, save); err != nil {
+			return err
+		}
+		return s.ctl.WriteFrame(s.frame())
+	}
+
+	var speed byte
+	if em.flags&ModeFlagHasSpeed != 0 {
+		speed = byte(ite8291.SpeedMaxValue - min(m.Speed, ite8291.SpeedMaxValue))
+	}
+
+	var colorNum byte
+	if em.flags&ModeFlagHasModeSpecificColor != 0 {
+		colorNum = ite8291.ColorRandom
+		if m.ColorMode != ColorModeRandom && len(m.Colors) > 0 {
+			if colorNum, err = s.colorNum(&m.Colors[0], state.ColorNum); err != nil {
+				return err
+			}
+		}
+	}
+
+	var reactOrDiv byte
+	switch {
+	case em.flags&ModeFlagHasDirectionLR != 0:
+		reactOrDiv = byte(directions[m.Direction])
+	case state.Effect == em.effect:
+		reactOrDiv = state.ReactOrDiv // e.g. reactive effect stays reactive
+	}
+
+	return s.ctl.SetEffect(ite8291.SetEffectOp, em.effect, speed, brightness, colorNum, reactOrDiv, save)
+}
+
+// colorNum returns number of the predefined color equal to the given
+// color. If there is no such color, the given color is set as the
+// predefined color of the given number or, if it's not a predefined
+// color number, of the first number.
+func (s *Server) colorNum(color *ite8291.Color, num byte) (byte, error) {
+
+	for i := range s.colors {
+		if s.colors[i] == *color {
+			return byte(i + ite8291.CustomColorNumMinValue), nil
+		}
+	}
+
+	if num < ite8291.CustomColorNumMinValue || num > ite8291.CustomColorNumMaxValue {
+		num = ite8291.CustomColorNumMinValue
+	}
+
+	if err := s.ctl.SetColor(num, color); err != nil {
+		return 0, err
+	}
+	s.colors[num-ite8291.CustomColorNumMinValue] = *color
+
+	return num, nil
+}
+
+// updateLEDs sets colors of the LEDs starting with the given index
+// decoded by d and shows them.
+func (s *Server) updateLEDs(idx int, colors []ite8291.Color, d *decoder) error {
+
+	if d.err != nil {
+		return d.err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if idx < 0 || idx+len(colors) > len(s.ledColors) {
+		return fmt.Errorf("%w: %d colors of LEDs from %d", ErrInvalidPacket, len(colors), idx)
+	}
+	copy(s.ledColors[idx:], colors)
+
+	return s.show()
+}
+
+// showLEDs shows colors of the LEDs switching to 'user' effect if
+// needed.
+func (s *Server) showLEDs() error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.show()
+}
+
+// show shows colors of the LEDs switching to 'user' effect if needed;
+// it's called with s.mu locked.
+func (s *Server) show() error {
+
+	state, err := s.ctl.Effect()
+	if err != nil {
+		return err
+	}
+
+	if state.Control == ite8291.OffState || state.Effect != ite8291.UserEffect {
+		if err := s.ctl.SetUserMode(state.Brightness, false); err != nil {
+			return err
+		}
+	}
+
+	return s.ctl.WriteFrame(s.frame())
+}
+
+// frame returns frame of the colors of the LEDs.
+func (s *Server) frame() *ite8291.Frame {
+
+	var frame ite8291.Frame
+	for i := range s.leds {
+		frame.Set(s.leds[i].Cell(), &s.ledColors[i])
+	}
+
+	return &frame
+}
@@ -0,0 +1,283 @@
+package openrgb
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// controller provides controller description decoded by the test client.
+type controller struct {
+	deviceType uint32
+	name       string
+	vendor     string
+	version    string
+	active     uint32
+	modes      []*Mode
+	zoneType   uint32
+	ledsCount  uint32
+	matrix     []uint32
+	leds       []string
+	colors     []ite8291.Color
+}
+
+// decodeController decodes controller description in the given
+// protocol version.
+func decodeController(data []byte, version uint32) *controller {
+
+	d := &decoder{data: data}
+	Ω(d.u32()).Should(Equal(uint32(len(data))))
+
+	c := &controller{deviceType: d.u32(), name: d.str()}
+	if version >= 1 {
+		c.vendor = d.str()
+	}
+	d.str() // description
+	c.version = d.str()
+	d.str() // serial
+	d.str() // location
+
+	c.modes = make([]*Mode, d.u16())
+	c.active = d.u32()
+	for i := range c.modes {
+		c.modes[i] = d.mode(version)
+	}
+
+	Ω(d.u16()).Should(Equal(uint16(1)))
+	Ω(d.str()).Should(Equal(zoneName))
+	c.zoneType = d.u32()
+	d.u32() // leds min
+	d.u32() // leds max
+	c.ledsCount = d.u32()
+	Ω(d.u16()).Should(Equal(uint16(8 + 4*ite8291.RowsNumber*ite8291.ColumnsNumber)))
+	Ω(d.u32()).Should(Equal(uint32(ite8291.RowsNumber)))
+	Ω(d.u32()).Should(Equal(uint32(ite8291.ColumnsNumber)))
+	c.matrix = make([]uint32, ite8291.RowsNumber*ite8291.ColumnsNumber)
+	for i := range c.matrix {
+		c.matrix[i] = d.u32()
+	}
+
+	c.leds = make([]string, d.u16())
+	for i := range c.leds {
+		c.leds[i] = d.str()
+		d.u32() // value
+	}
+	c.colors = d.colors()
+
+	Ω(d.err).ShouldNot(HaveOccurred())
+	Ω(d.data).Should(BeEmpty())
+
+	return c
+}
+
+var _ = Describe("OpenRGB server", func() {
+
+	var dev *ite8291.VirtualDevice
+	var layout *ite8291.Layout
+	var server *Server
+	var conn net.Conn
+	var cancel context.CancelFunc
+	var served chan error
+
+	red := ite8291.NewColor(0xff, 0, 0)
+	green := ite8291.NewColor(0, 0xff, 0)
+
+	// serve starts serving the virtual device and connects the client.
+	serve := func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		served = make(chan error, 1)
+		go func() { served <- server.Serve(ctx, l) }()
+
+		conn, err = net.Dial("tcp", l.Addr().String())
+		Ω(err).ShouldNot(HaveOccurred())
+		DeferCleanup(conn.Close)
+	}
+
+	// send sends packet with the given id and data to the server.
+	send := func(id uint32, data []byte) {
+		Ω(WritePacket(conn, 0, id, data)).Should(Succeed())
+	}
+
+	// request sends request with the given id and data and returns
+	// data of the reply.
+	request := func(id uint32, data []byte) []byte {
+		send(id, data)
+
+		h, err := ReadHeader(conn)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(h.ID).Should(Equal(id))
+
+		reply := make([]byte, h.Size)
+		_, err = io.ReadFull(conn, reply)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		return reply
+	}
+
+	// u32 returns the given value encoded.
+	u32 := func(v uint32) []byte {
+		var e encoder
+		e.u32(v)
+		return e.Bytes()
+	}
+
+	// negotiate negotiates the given protocol version and returns
+	// the controller description.
+	negotiate := func(version uint32) *controller {
+		Ω(request(RequestProtocolVersion, u32(version))).Should(Equal(u32(ProtocolVersion)))
+		send(SetClientName, []byte("test\x00"))
+		Ω(request(RequestControllerCount, nil)).Should(Equal(u32(1)))
+
+		return decodeController(request(RequestControllerData, u32(version)), min(version, ProtocolVersion))
+	}
+
+	// updateMode sends the given mode of the given index.
+	updateMode := func(idx uint32, m *Mode, version uint32) {
+		var e encoder
+		e.u32(idx)
+		e.mode(m, version)
+		send(UpdateMode, append(e.sized(), e.Bytes()...))
+	}
+
+	BeforeEach(func() {
+		dev = ite8291.NewVirtualDevice()
+		layout = &ite8291.Layout{Name: "test", Keys: []ite8291.Key{
+			{Name: "Esc", Row: 0, Column: 0, Width: 1, Height: 1},
+			{Name: "F1", Row: 0, Column: 1, Width: 1, Height: 1},
+			{Name: "Space", Row: 5, Column: 7, Width: 6, Height: 1},
+		}}
+		server = NewServer(dev, layout)
+		server.Version = "1.2.3"
+		server.Colors = []*ite8291.Color{red}
+	})
+
+	AfterEach(func() {
+		if cancel != nil {
+			cancel()
+			Eventually(served).Should(Receive(BeNil()))
+			cancel = nil
+		}
+	})
+
+	It("describes the keyboard with its matrix zone, LEDs and modes", func() {
+		frame := ite8291.NewFrame(green)
+		server.Frame = frame
+		Ω(ite8291.NewController(dev).SetWaveMode(3, 20, ite8291.DirectionUp, false)).Should(Succeed())
+		serve()
+
+		c := negotiate(ProtocolVersion)
+		Ω(c.deviceType).Should(Equal(uint32(DeviceTypeKeyboard)))
+		Ω(c.name).Should(Equal(deviceName))
+		Ω(c.vendor).Should(Equal(deviceVendor))
+		Ω(c.version).Should(Equal("1.2.3"))
+		Ω(c.zoneType).Should(Equal(uint32(ZoneTypeMatrix)))
+		Ω(c.ledsCount).Should(Equal(uint32(3)))
+		Ω(c.leds).Should(Equal([]string{"Esc", "F1", "Space"}))
+		Ω(c.colors).Should(Equal([]ite8291.Color{*green, *green, *green}))
+		Ω(c.matrix[0]).Should(Equal(uint32(0)))
+		Ω(c.matrix[1]).Should(Equal(uint32(1)))
+		Ω(c.matrix[2]).Should(Equal(uint32(noLED)))
+		Ω(c.matrix[5*ite8291.ColumnsNumber+7]).Should(Equal(uint32(2)))
+
+		Ω(c.modes).Should(HaveLen(len(modes)))
+		wave := c.modes[c.active]
+		Ω(wave.Name).Should(Equal("Wave"))
+		Ω(wave.Speed).Should(Equal(uint32(7)))
+		Ω(wave.Brightness).Should(Equal(uint32(20)))
+		Ω(wave.Direction).Should(Equal(uint32(DirectionUp)))
+		Ω(c.modes[directModeIdx].ColorMode).Should(Equal(uint32(ColorModePerLED)))
+	})
+
+	It("describes the keyboard in older protocol versions", func() {
+		serve()
+
+		c := negotiate(0)
+		Ω(c.name).Should(Equal(deviceName))
+		Ω(c.vendor).Should(BeEmpty())
+		Ω(c.modes[c.active].Name).Should(Equal("Direct"))
+	})
+
+	It("updates colors of the LEDs in 'user' effect", func() {
+		Ω(ite8291.NewController(dev).SetRainbowMode(30, false)).Should(Succeed())
+		serve()
+		negotiate(ProtocolVersion)
+
+		var e encoder
+		e.colors([]ite8291.Color{*red, *green, *red})
+		send(UpdateLEDs, append(e.sized(), e.Bytes()...))
+
+		expected := &ite8291.Frame{}
+		expected[0][0], expected[0][1], expected[5][7] = *red, *green, *red
+		Eventually(dev.Frame).Should(Equal(expected))
+		Ω(dev.Effect().Effect).Should(Equal(byte(ite8291.UserEffect)))
+		Ω(dev.Effect().Brightness).Should(Equal(byte(30)))
+
+		e.Reset()
+		e.u32(0) // zone
+		e.colors([]ite8291.Color{*green, *green, *green})
+		send(UpdateZoneLEDs, append(e.sized(), e.Bytes()...))
+		expected[0][0], expected[5][7] = *green, *green
+		Eventually(dev.Frame).Should(Equal(expected))
+
+		e.Reset()
+		e.u32(2)
+		e.color(red)
+		send(UpdateSingleLED, e.Bytes())
+		expected[5][7] = *red
+		Eventually(dev.Frame).Should(Equal(expected))
+
+		Ω(decodeController(request(RequestControllerData, u32(ProtocolVersion)), ProtocolVersion).colors).
+			Should(Equal([]ite8291.Color{*green, *green, *red}))
+	})
+
+	It("sets modes mapped to the built-in effects", func() {
+		serve()
+		c := negotiate(ProtocolVersion)
+
+		breathing := c.modes[3]
+		Ω(breathing.Name).Should(Equal("Breathing"))
+		breathing.Speed, breathing.Brightness = 10, 40
+		breathing.ColorMode, breathing.Colors = ColorModeModeSpecific, []ite8291.Color{*green}
+		updateMode(3, breathing, ProtocolVersion)
+
+		Eventually(dev.Effect).Should(Equal(&ite8291.EffectState{Control: ite8291.SetEffectOp,
+			Effect: ite8291.BreathingEffect, Speed: 0, Brightness: 40, ColorNum: 1}))
+		Ω(dev.Color(1)).Should(Equal(green))
+
+		c = decodeController(request(RequestControllerData, u32(ProtocolVersion)), ProtocolVersion)
+		Ω(c.active).Should(Equal(uint32(3)))
+		Ω(c.modes[3].Colors).Should(Equal([]ite8291.Color{*green}))
+
+		wave := c.modes[len(modes)-1]
+		wave.Direction = DirectionLeft
+		updateMode(uint32(len(modes)-1), wave, ProtocolVersion)
+		Eventually(func() byte { return dev.Effect().ReactOrDiv }).Should(Equal(byte(ite8291.DirectionLeft)))
+		Ω(dev.Effect().Effect).Should(Equal(byte(ite8291.WaveEffect)))
+
+		updateMode(offModeIdx, c.modes[offModeIdx], ProtocolVersion)
+		Eventually(func() byte { return dev.Effect().Control }).Should(Equal(byte(ite8291.OffState)))
+
+		send(SetCustomMode, nil)
+		Eventually(func() byte { return dev.Effect().Effect }).Should(Equal(byte(ite8291.UserEffect)))
+	})
+
+	It("ignores invalid requests", func() {
+		serve()
+		negotiate(ProtocolVersion)
+
+		send(UpdateSingleLED, u32(100)) // no color
+		updateMode(100, &Mode{}, ProtocolVersion)
+		Ω(WritePacket(conn, 1, RequestControllerData, nil)).Should(Succeed())
+
+		Ω(request(RequestControllerCount, nil)).Should(Equal(u32(1)))
+	})
+})
@@ -0,0 +1,256 @@
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+// ErrUnsupportedFormat error indicates that a preview format is not
+// supported.
+var ErrUnsupportedFormat = errors.New("unsupported preview format")
+
+// Format represents output format of a preview.
+type Format int
+
+// supported preview formats.
+const (
+	// ANSIFormat renders keys as truecolor ANSI terminal blocks.
+	ANSIFormat Format = iota
+	// PNGFormat renders keys to PNG image.
+	PNGFormat
+	// SVGFormat renders keys to SVG image.
+	SVGFormat
+)
+
+// geometry of rendered keys.
+const (
+	// imageUnit - size of a regular key with its gap in image pixels.
+	imageUnit = 40
+	// imageGap - gap between keys in image pixels.
+	imageGap = 4
+
+	// ansiUnitX - width of a regular key with its gap in terminal columns.
+	ansiUnitX = 5
+	// ansiUnitY - height of a regular key with its gap in half lines.
+	ansiUnitY = 3
+	// ansiGap - gap between keys in terminal columns and half lines.
+	ansiGap = 1
+)
+
+// backgroundColor - color of the keyboard between keys in images.
+var backgroundColor = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+
+// FormatOf returns format of the preview file given by its
+// extension: ".png", ".svg" or ".ans" and ".txt" for ANSI
+// format.
+func FormatOf(file string) (Format, error) {
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".png":
+		return PNGFormat, nil
+	case ".svg":
+		return SVGFormat, nil
+	case ".ans", ".txt":
+		return ANSIFormat, nil
+	}
+
+	return 0, fmt.Errorf("%w: %q; expected one of %q", ErrUnsupportedFormat, file, []string{".png", ".svg", ".ans", ".txt"})
+}
+
+// Write renders the given frame using key geometry of the given
+// layout in the given format to w.
+func Write(w io.Writer, format Format, frame *ite8291.Frame, layout *ite8291.Layout) error {
+
+	switch format {
+	case ANSIFormat:
+		return ANSI(w, frame, layout)
+	case PNGFormat:
+		return PNG(w, frame, layout)
+	case SVGFormat:
+		return SVG(w, frame, layout)
+	}
+
+	return fmt.Errorf("%w: %d", ErrUnsupportedFormat, format)
+}
+
+// keyRect returns rectangle of the given key scaled by the given
+// units with the given gap at its right and bottom sides.
+func keyRect(k *ite8291.Key, unitX, unitY, gap int) image.Rectangle {
+
+	return image.Rect(
+		int(math.Round(k.X*float64(unitX))), int(math.Round(k.Y*float64(unitY))),
+		int(math.Round((k.X+k.Width)*float64(unitX)))-gap, int(math.Round((k.Y+k.Height)*float64(unitY)))-gap)
+}
+
+// bounds returns rectangle containing all keys of the given layout
+// scaled by the given units.
+func bounds(layout *ite8291.Layout, unitX, unitY, gap int) image.Rectangle {
+
+	w, h := layout.Bounds()
+	return image.Rect(0, 0, int(math.Round(w*float64(unitX)))-gap, int(math.Round(h*float64(unitY)))-gap)
+}
+
+// rgba converts the given key color to color.RGBA.
+func rgba(c *ite8291.Color) color.RGBA {
+	return color.RGBA{R: c.Red, G: c.Green, B: c.Blue, A: 0xff}
+}
+
+// Image renders the given frame to an image using key geometry of
+// the given layout.
+func Image(frame *ite8291.Frame, layout *ite8291.Layout) *image.RGBA {
+
+	img := image.NewRGBA(bounds(layout, imageUnit, imageUnit, -imageGap))
+	draw.Draw(img, img.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+
+	for i := range layout.Keys {
+		k := &layout.Keys[i]
+		r := keyRect(k, imageUnit, imageUnit, imageGap).Add(image.Pt(imageGap, imageGap))
+		draw.Draw(img, r, image.NewUniform(rgba(frame.Get(k.Cell()))), image.Point{}, draw.Src)
+	}
+
+	return img
+}
+
+// PNG renders the given frame to PNG image using key geometry of the
+// given layout.
+func PNG(w io.Writer, frame *ite8291.Frame, layout *ite8291.Layout) error {
+	return png.Encode(w, Image(frame, layout))
+}
+
+// SVG renders the given frame to SVG image using key geometry of the
+// given layout. Every key is titled by its name.
+func SVG(w io.Writer, frame *ite8291.Frame, layout *ite8291.Layout) error {
+
+	b := bounds(layout, imageUnit, imageUnit, -imageGap)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`+"\n",
+		b.Dx(), b.Dy())
+	fmt.Fprintf(&sb, `  <rect width="100%%" height="100%%" fill="#%02X%02X%02X"/>`+"\n",
+		backgroundColor.R, backgroundColor.G, backgroundColor.B)
+
+	for i := range layout.Keys {
+		k := &layout.Keys[i]
+		r := keyRect(k, imageUnit, imageUnit, imageGap).Add(image.Pt(imageGap, imageGap))
+		fmt.Fprintf(&sb, `  <rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="%s"><title>%s</title></rect>`+"\n",
+			r.Min.X, r.Min.Y, r.Dx(), r.Dy(), frame.Get(k.Cell()), escapeXML(k.Name))
+	}
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeXML escapes characters of the given text that are special in
+// XML.
+func escapeXML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// ANSI renders the given frame as truecolor ANSI terminal drawing
+// using key geometry of the given layout. Every line of the drawing
+// shows two rows of pixels by upper half block characters.
+func ANSI(w io.Writer, frame *ite8291.Frame, layout *ite8291.Layout) error {
+	_, err := io.WriteString(w, strings.Join(ansiLines(frame, layout), "\n")+"\n")
+	return err
+}
+
+// ansiLines returns lines of ANSI drawing of the given frame.
+func ansiLines(frame *ite8291.Frame, layout *ite8291.Layout) []string {
+
+	b := bounds(layout, ansiUnitX, ansiUnitY, ansiGap)
+	if b.Empty() {
+		return nil
+	}
+
+	pixels := make([][]*ite8291.Color, b.Dy()+b.Dy()%2)
+	for y := range pixels {
+		pixels[y] = make([]*ite8291.Color, b.Dx())
+	}
+
+	for i := range layout.Keys {
+		k := &layout.Keys[i]
+		r := keyRect(k, ansiUnitX, ansiUnitY, ansiGap).Intersect(b)
+		col := frame.Get(k.Cell())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				pixels[y][x] = col
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(pixels)/2)
+	for y := 0; y < len(pixels); y += 2 {
+
+		var sb strings.Builder
+		var last string
+		for x := range pixels[y] {
+			upper, lower := pixels[y][x], pixels[y+1][x]
+
+			var code, char string
+			switch {
+			case upper == nil && lower == nil:
+				code, char = "\x1b[0m", " "
+			case lower == nil:
+				code, char = fmt.Sprintf("\x1b[0;38;2;%d;%d;%dm", upper.Red, upper.Green, upper.Blue), "▀"
+			case upper == nil:
+				code, char = fmt.Sprintf("\x1b[0;38;2;%d;%d;%dm", lower.Red, lower.Green, lower.Blue), "▄"
+			default:
+				code, char = fmt.Sprintf("\x1b[0;38;2;%d;%d;%d;48;2;%d;%d;%dm",
+					upper.Red, upper.Green, upper.Blue, lower.Red, lower.Green, lower.Blue), "▀"
+			}
+
+			if code != last {
+				sb.WriteString(code)
+				last = code
+			}
+			sb.WriteString(char)
+		}
+		sb.WriteString("\x1b[0m")
+
+		lines = append(lines, sb.String())
+	}
+
+	return lines
+}
+
+// Terminal draws frames in place on a terminal: every drawing
+// replaces the previous one.
+type Terminal struct {
+	w     io.Writer
+	lines int
+}
+
+// NewTerminal creates a terminal drawing frames to w.
+func NewTerminal(w io.Writer) *Terminal {
+	return &Terminal{w: w}
+}
+
+// Draw draws the given frame using key geometry of the given layout
+// over the previously drawn frame.
+func (t *Terminal) Draw(frame *ite8291.Frame, layout *ite8291.Layout) error {
+
+	var sb strings.Builder
+	if t.lines > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA\r", t.lines)
+	}
+
+	lines := ansiLines(frame, layout)
+	for _, l := range lines {
+		sb.WriteString(l + "\n")
+	}
+	t.lines = len(lines)
+
+	_, err := io.WriteString(t.w, sb.String())
+	return err
+}
@@ -0,0 +1,93 @@
+package preview
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/v4n6/itectl/pkg/ite8291"
+)
+
+var _ = Describe("Render", func() {
+
+	var frame *ite8291.Frame
+	var layout *ite8291.Layout
+
+	BeforeEach(func() {
+		frame = ite8291.NewFrame(ite8291.NewColor(0, 0, 0xff))
+		frame.Set(ite8291.Cell{Row: 0, Column: 0}, ite8291.NewColor(0xff, 0, 0))
+		layout = &ite8291.Layout{Keys: []ite8291.Key{
+			{Name: "esc", Row: 0, Column: 0, X: 0, Y: 0, Width: 1, Height: 1},
+			{Name: "<space>", Row: 5, Column: 8, X: 1, Y: 1, Width: 2, Height: 1},
+		}}
+	})
+
+	DescribeTable("format of file",
+		func(file string, format Format, valid bool) {
+			f, err := FormatOf(file)
+			if !valid {
+				Ω(err).Should(MatchError(ErrUnsupportedFormat))
+				return
+			}
+			Ω(err).Should(Succeed())
+			Ω(f).Should(Equal(format))
+		},
+		Entry(nil, "kbd.png", PNGFormat, true),
+		Entry(nil, "/tmp/KBD.SVG", SVGFormat, true),
+		Entry(nil, "kbd.ans", ANSIFormat, true),
+		Entry(nil, "kbd.txt", ANSIFormat, true),
+		Entry(nil, "kbd.gif", Format(0), false),
+		Entry(nil, "kbd", Format(0), false),
+	)
+
+	It("renders PNG image using key geometry", func() {
+		var buf bytes.Buffer
+		Ω(Write(&buf, PNGFormat, frame, layout)).Should(Succeed())
+
+		img, err := png.Decode(&buf)
+		Ω(err).Should(Succeed())
+		Ω(img.Bounds().Dx()).Should(Equal(3*imageUnit + imageGap))
+		Ω(img.Bounds().Dy()).Should(Equal(2*imageUnit + imageGap))
+
+		Ω(color.RGBAModel.Convert(img.At(20, 20))).Should(Equal(color.RGBA{R: 0xff, A: 0xff}))
+		Ω(color.RGBAModel.Convert(img.At(100, 60))).Should(Equal(color.RGBA{B: 0xff, A: 0xff}))
+		Ω(color.RGBAModel.Convert(img.At(60, 20))).Should(Equal(backgroundColor))
+	})
+
+	It("renders SVG image with titled keys", func() {
+		var buf bytes.Buffer
+		Ω(Write(&buf, SVGFormat, frame, layout)).Should(Succeed())
+
+		Ω(buf.String()).Should(SatisfyAll(
+			HavePrefix(`<svg xmlns="http://www.w3.org/2000/svg" width="124" height="84"`),
+			ContainSubstring(`<rect x="4" y="4" width="36" height="36" rx="4" fill="#FF0000"><title>esc</title></rect>`),
+			ContainSubstring(`<rect x="44" y="44" width="76" height="36" rx="4" fill="#0000FF"><title>&lt;space&gt;</title></rect>`),
+		))
+	})
+
+	It("renders truecolor ANSI drawing", func() {
+		var buf bytes.Buffer
+		Ω(Write(&buf, ANSIFormat, frame, layout)).Should(Succeed())
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		Ω(lines).Should(HaveLen(3))
+		Ω(lines[0]).Should(HavePrefix("\x1b[0;38;2;255;0;0;48;2;255;0;0m▀▀▀▀\x1b[0m "))
+		Ω(lines[1]).Should(Equal("\x1b[0m     \x1b[0;38;2;0;0;255m▄▄▄▄▄▄▄▄▄\x1b[0m"))
+		Ω(lines[2]).Should(Equal("\x1b[0m     \x1b[0;38;2;0;0;255m▀▀▀▀▀▀▀▀▀\x1b[0m"))
+	})
+
+	It("redraws frames in place on terminal", func() {
+		var buf bytes.Buffer
+		term := NewTerminal(&buf)
+
+		Ω(term.Draw(frame, layout)).Should(Succeed())
+		Ω(buf.String()).ShouldNot(ContainSubstring("\x1b[3A"))
+
+		buf.Reset()
+		Ω(term.Draw(frame, layout)).Should(Succeed())
+		Ω(buf.String()).Should(HavePrefix("\x1b[3A\r"))
+	})
+})